@@ -12,13 +12,17 @@ var Exit = os.Exit
 var LoggerSuccess = logger_module.Success
 
 var IpClearCache = ip_cache_module.ClearCache
+var TokenClearCache = ip_cache_module.ClearTokenCache
+var GraphQLSchemaClearCache = ip_cache_module.ClearGraphQLSchemaCache
 
 func Init(rootCmd *cobra.Command) {
 	cmd := &cobra.Command{
 		Use:   "cleancache",
-		Short: "Clear the app cache, like the IP cache and other temporary data",
+		Short: "Clear the app cache, like the IP cache, OAuth2 tokens, GraphQL schemas and other temporary data",
 		Run: func(cmd *cobra.Command, args []string) {
 			IpClearCache()
+			TokenClearCache()
+			GraphQLSchemaClearCache()
 			LoggerSuccess("Your cache was cleared successfully!", 50)
 			Exit(0)
 		},
@@ -23,17 +23,23 @@ func TestInit_AddsCommand(t *testing.T) {
 }
 
 func TestCleancache_Run(t *testing.T) {
-	var cleared, logged, exited bool
+	var cleared, tokensCleared, schemasCleared, logged, exited bool
 	oldClearCache := ip_cache_module.ClearCache
+	oldClearTokenCache := ip_cache_module.ClearTokenCache
+	oldClearSchemaCache := ip_cache_module.ClearGraphQLSchemaCache
 	oldSuccess := logger_module.Success
 	oldExit := Exit
 	defer func() {
 		IpClearCache = oldClearCache
+		TokenClearCache = oldClearTokenCache
+		GraphQLSchemaClearCache = oldClearSchemaCache
 		LoggerSuccess = oldSuccess
 		Exit = oldExit
 	}()
 
 	IpClearCache = func() { cleared = true }
+	TokenClearCache = func() { tokensCleared = true }
+	GraphQLSchemaClearCache = func() { schemasCleared = true }
 	LoggerSuccess = func(msg string, width int) { logged = true }
 	Exit = func(code int) { exited = code == 0 }
 
@@ -52,6 +58,8 @@ func TestCleancache_Run(t *testing.T) {
 	assert.NotNil(t, cleancacheCmd)
 	cleancacheCmd.Run(cleancacheCmd, []string{})
 	assert.True(t, cleared)
+	assert.True(t, tokensCleared)
+	assert.True(t, schemasCleared)
 	assert.True(t, logged)
 	assert.True(t, exited)
 }
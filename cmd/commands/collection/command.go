@@ -0,0 +1,303 @@
+package collection_command
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	collection_module "github.com/diogopereiradev/httpzen/internal/collection"
+	config_module "github.com/diogopereiradev/httpzen/internal/config"
+	environment_module "github.com/diogopereiradev/httpzen/internal/environment"
+	headless_module "github.com/diogopereiradev/httpzen/internal/headless"
+	history_module "github.com/diogopereiradev/httpzen/internal/history"
+	logger_module "github.com/diogopereiradev/httpzen/internal/logger"
+	"github.com/diogopereiradev/httpzen/internal/menus/request_menu"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	script_module "github.com/diogopereiradev/httpzen/internal/script"
+	"github.com/spf13/cobra"
+)
+
+var Exit = os.Exit
+var LoggerError = logger_module.Error
+var LoggerWarn = logger_module.Warn
+var LoggerSuccess = logger_module.Success
+var GetConfigFunc = config_module.GetConfig
+var GetCollectionFunc = collection_module.GetCollection
+var SaveCollectionFunc = collection_module.SaveCollection
+var GetEnvironmentFunc = environment_module.GetEnvironment
+var RunRequestFunc = request_module.RunRequest
+var RequestMenuNewFunc = request_menu.New
+var HeadlessPrintFunc = headless_module.Print
+var HeadlessPrintScriptFunc = headless_module.PrintScript
+var LoadScriptFunc = script_module.Load
+var AddHistoryFunc = history_module.Add
+
+func Init(rootCmd *cobra.Command) {
+	cmd := &cobra.Command{
+		Use:   "collection",
+		Short: "Run saved collections and chain their requests",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	extractCmd := &cobra.Command{
+		Use:   "extract COLLECTION REQUEST [VAR=TYPE:EXPRESSION...]",
+		Short: "Set the values a saved request extracts from its response, for the requests run after it",
+		Long: "Set the values a saved request extracts from its response, for the requests run after it.\n" +
+			"TYPE is json (a JSONPath on the body), header, cookie or regex (the first group on the body):\n" +
+			"  httpzen collection extract api auth/login token=json:$.data.token sid=cookie:session",
+		Args: cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			clear, _ := cmd.Flags().GetBool("clear")
+			if !clear && len(args) == 2 {
+				LoggerError("Give at least one VAR=TYPE:EXPRESSION, or --clear.", 70)
+				Exit(1)
+				return
+			}
+
+			var extractors []collection_module.Extractor
+			for _, value := range args[2:] {
+				extractor, err := collection_module.ParseExtractor(value)
+				if err != nil {
+					LoggerError(err.Error(), 70)
+					Exit(1)
+					return
+				}
+				extractors = append(extractors, extractor)
+			}
+
+			collection, index, ok := findRequest(args[0], args[1])
+			if !ok {
+				return
+			}
+			saved := &collection.Requests[index]
+			if clear {
+				saved.Extract = nil
+			}
+			// An extractor for the same variable replaces the previous one.
+			for _, extractor := range extractors {
+				replaced := false
+				for i, existing := range saved.Extract {
+					if existing.Variable == extractor.Variable {
+						saved.Extract[i], replaced = extractor, true
+						break
+					}
+				}
+				if !replaced {
+					saved.Extract = append(saved.Extract, extractor)
+				}
+			}
+
+			if err := SaveCollectionFunc(collection); err != nil {
+				LoggerError("Could not save the collection: "+err.Error(), 70)
+				Exit(1)
+				return
+			}
+			if len(saved.Extract) == 0 {
+				LoggerSuccess("\""+saved.Path()+"\" extracts nothing now.", 70)
+				return
+			}
+			lines := make([]string, 0, len(saved.Extract))
+			for _, extractor := range saved.Extract {
+				lines = append(lines, extractor.String())
+			}
+			LoggerSuccess("\""+saved.Path()+"\" extracts:\n  "+strings.Join(lines, "\n  "), 70)
+		},
+	}
+	extractCmd.Flags().Bool("clear", false, "Remove the extractors of the request before adding the given ones")
+
+	scriptCmd := &cobra.Command{
+		Use:   "script COLLECTION REQUEST",
+		Short: "Set the JavaScript a saved request runs before it is sent and on its response",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			prePath, _ := cmd.Flags().GetString("pre")
+			postPath, _ := cmd.Flags().GetString("post")
+			clear, _ := cmd.Flags().GetBool("clear")
+			if prePath == "" && postPath == "" && !clear {
+				LoggerError("Give --pre, --post or --clear.", 70)
+				Exit(1)
+				return
+			}
+
+			var sources [2]string
+			for i, path := range []string{prePath, postPath} {
+				source, err := LoadScriptFunc(path)
+				if err != nil {
+					LoggerError("Could not read the script: "+err.Error(), 70)
+					Exit(1)
+					return
+				}
+				sources[i] = source
+			}
+
+			collection, index, ok := findRequest(args[0], args[1])
+			if !ok {
+				return
+			}
+			saved := &collection.Requests[index]
+			if clear {
+				saved.PreScript, saved.PostScript = "", ""
+			}
+			if sources[0] != "" {
+				saved.PreScript = sources[0]
+			}
+			if sources[1] != "" {
+				saved.PostScript = sources[1]
+			}
+
+			if err := SaveCollectionFunc(collection); err != nil {
+				LoggerError("Could not save the collection: "+err.Error(), 70)
+				Exit(1)
+				return
+			}
+			LoggerSuccess("Saved the scripts of \""+saved.Path()+"\".", 70)
+		},
+	}
+	scriptCmd.Flags().String("pre", "", "JavaScript file run before the request is sent")
+	scriptCmd.Flags().String("post", "", "JavaScript file run on the response")
+	scriptCmd.Flags().Bool("clear", false, "Remove the scripts of the request before setting the given ones")
+
+	runCmd := &cobra.Command{
+		Use:   "run COLLECTION",
+		Short: "Send the requests of a collection in order, the values extracted from a response fill the {{variables}} of the next ones",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			headless, _ := cmd.Flags().GetBool("headless")
+			envName, _ := cmd.Flags().GetString("env")
+
+			collection, ok := GetCollectionFunc(args[0])
+			if !ok {
+				LoggerError("The collection \""+args[0]+"\" does not exist.", 70)
+				Exit(1)
+				return
+			}
+			if len(collection.Requests) == 0 {
+				LoggerError("The collection \""+args[0]+"\" has no requests.", 70)
+				Exit(1)
+				return
+			}
+
+			config := GetConfigFunc()
+			if envName == "" {
+				envName = config.ActiveEnvironment
+			}
+			environment := GetEnvironmentFunc(envName)
+
+			session := collection_module.NewSession()
+
+			code := 0
+			for _, saved := range collection.Requests {
+				if headless {
+					fmt.Fprintln(headless_module.Output, "### "+saved.Path())
+				}
+				options := requestOptions(collection, saved, config, environment.Variables, session.Variables)
+				if result := runRequest(session, saved, options, headless); code == 0 {
+					code = result
+				}
+			}
+			if code != 0 {
+				Exit(code)
+			}
+		},
+	}
+	runCmd.Flags().Bool("headless", false, "Print the responses instead of opening the viewer")
+	runCmd.Flags().StringP("env", "e", "", "Environment to take the {{variables}} from (default: the active one)")
+
+	cmd.AddCommand(extractCmd)
+	cmd.AddCommand(scriptCmd)
+	cmd.AddCommand(runCmd)
+	rootCmd.AddCommand(cmd)
+}
+
+// findRequest loads the collection and looks the request up by its path or
+// its name, reporting what is missing.
+func findRequest(collectionName string, requestName string) (collection_module.Collection, int, bool) {
+	collection, ok := GetCollectionFunc(collectionName)
+	if !ok {
+		LoggerError("The collection \""+collectionName+"\" does not exist.", 70)
+		Exit(1)
+		return collection, -1, false
+	}
+	index, ok := collection.FindRequest(requestName)
+	if !ok {
+		LoggerError("The collection \""+collectionName+"\" has no request \""+requestName+"\", or several with that name: use folder/name.", 70)
+		Exit(1)
+		return collection, -1, false
+	}
+	return collection, index, true
+}
+
+func requestOptions(collection collection_module.Collection, saved collection_module.SavedRequest, config config_module.Config, variables ...map[string]string) request_module.RequestOptions {
+	options := collection.Resolve(saved, variables...)
+	timeout, timeouts := request_module.ConfigTimeouts(config)
+	if options.Timeout == 0 {
+		options.Timeout = timeout
+	}
+	options.Timeouts = timeouts
+	return options
+}
+
+// runRequest sends a request of the run with its scripts and returns its
+// exit code. The session variables are shared with the scripts.
+func runRequest(session *collection_module.Session, saved collection_module.SavedRequest, options request_module.RequestOptions, headless bool) int {
+	var runner *script_module.Runner
+	if saved.PreScript != "" || saved.PostScript != "" {
+		runner = script_module.NewRunner(saved.PreScript, saved.PostScript, session.Variables)
+		if err := runner.BeforeRequest(&options); err != nil {
+			if headless {
+				HeadlessPrintScriptFunc(runner.PreResult())
+			} else {
+				LoggerError("\""+saved.Path()+"\" was not sent, "+err.Error(), 70)
+			}
+			return 1
+		}
+		// The variables set by the script fill the {{name}} references left.
+		options = collection_module.Collection{}.Resolve(collection_module.SavedRequest{Request: options}, session.Variables)
+	}
+
+	res, err := history_module.Send(options, RunRequestFunc, AddHistoryFunc, LoggerWarn)
+	// The values are extracted before the response is shown, so the
+	// Variables tab and the post-response script have them.
+	if err == nil {
+		extract(session, saved, res, headless)
+	}
+
+	if !headless {
+		viewer := request_menu.Options{Variables: func() map[string]string { return session.Variables }}
+		if runner != nil {
+			viewer.Script = runner.AfterResponse
+		}
+		return request_module.ExitCode(RequestMenuNewFunc(&res, err, viewer))
+	}
+
+	code := HeadlessPrintFunc(res, err, nil)
+	if runner != nil {
+		result := runner.PreResult()
+		if err == nil {
+			result = runner.AfterResponse(res)
+		}
+		if scriptCode := HeadlessPrintScriptFunc(result); code == 0 {
+			code = scriptCode
+		}
+	}
+	return code
+}
+
+// extract sets the session variables of a response. Headless runs report
+// the names only on stderr, the values may be secrets.
+func extract(session *collection_module.Session, saved collection_module.SavedRequest, res request_module.RequestResponse, headless bool) {
+	names, errs := session.Extract(saved, res)
+	for _, err := range errs {
+		message := "\"" + saved.Path() + "\" could not extract " + err.Error()
+		if headless {
+			fmt.Fprintln(headless_module.ErrorOutput, message)
+		} else {
+			LoggerWarn(message, 70)
+		}
+	}
+	if headless && len(names) > 0 {
+		fmt.Fprintln(headless_module.ErrorOutput, "Extracted "+strings.Join(names, ", "))
+	}
+}
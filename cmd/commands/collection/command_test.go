@@ -0,0 +1,322 @@
+package collection_command
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+
+	collection_module "github.com/diogopereiradev/httpzen/internal/collection"
+	config_module "github.com/diogopereiradev/httpzen/internal/config"
+	environment_module "github.com/diogopereiradev/httpzen/internal/environment"
+	headless_module "github.com/diogopereiradev/httpzen/internal/headless"
+	"github.com/diogopereiradev/httpzen/internal/menus/request_menu"
+	openapi_module "github.com/diogopereiradev/httpzen/internal/openapi"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	script_module "github.com/diogopereiradev/httpzen/internal/script"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+type collectionStubs struct {
+	codes     []int
+	errors    []string
+	saved     []collection_module.Collection
+	sent      []request_module.RequestOptions
+	variables []map[string]string
+	output    *bytes.Buffer
+	errOutput *bytes.Buffer
+}
+
+func testCollection() collection_module.Collection {
+	return collection_module.Collection{
+		Name:      "api",
+		Variables: map[string]string{"host": "http://a"},
+		Requests: []collection_module.SavedRequest{
+			{Name: "login", Folder: "auth", Request: request_module.RequestOptions{Method: "POST", Url: "{{host}}/login"},
+				Extract: []collection_module.Extractor{
+					{Variable: "token", Type: collection_module.ExtractJson, Expression: "$.token"},
+					{Variable: "sid", Type: collection_module.ExtractCookie, Expression: "session"},
+				}},
+			{Name: "me", Request: request_module.RequestOptions{Method: "GET", Url: "{{host}}/me", Headers: http.Header{"Authorization": {"Bearer {{token}}"}}}},
+		},
+	}
+}
+
+func stubCollection(t *testing.T) *collectionStubs {
+	stubs := &collectionStubs{output: &bytes.Buffer{}, errOutput: &bytes.Buffer{}}
+	oldExit, oldError, oldWarn, oldSuccess := Exit, LoggerError, LoggerWarn, LoggerSuccess
+	oldConfig, oldGet, oldSave, oldEnvironment := GetConfigFunc, GetCollectionFunc, SaveCollectionFunc, GetEnvironmentFunc
+	oldRun, oldMenu, oldPrint, oldHistory := RunRequestFunc, RequestMenuNewFunc, HeadlessPrintFunc, AddHistoryFunc
+	oldPrintScript, oldLoadScript := HeadlessPrintScriptFunc, LoadScriptFunc
+	oldOutput, oldErrorOutput := headless_module.Output, headless_module.ErrorOutput
+	t.Cleanup(func() {
+		HeadlessPrintScriptFunc, LoadScriptFunc = oldPrintScript, oldLoadScript
+		Exit, LoggerError, LoggerWarn, LoggerSuccess = oldExit, oldError, oldWarn, oldSuccess
+		GetConfigFunc, GetCollectionFunc, SaveCollectionFunc, GetEnvironmentFunc = oldConfig, oldGet, oldSave, oldEnvironment
+		RunRequestFunc, RequestMenuNewFunc, HeadlessPrintFunc, AddHistoryFunc = oldRun, oldMenu, oldPrint, oldHistory
+		headless_module.Output, headless_module.ErrorOutput = oldOutput, oldErrorOutput
+	})
+
+	Exit = func(code int) { stubs.codes = append(stubs.codes, code) }
+	LoggerError = func(message string, _ int) { stubs.errors = append(stubs.errors, message) }
+	LoggerWarn = func(string, int) {}
+	LoggerSuccess = func(string, int) {}
+	GetConfigFunc = func() config_module.Config { return config_module.Config{ActiveEnvironment: "dev", Timeout: 1000} }
+	GetCollectionFunc = func(name string) (collection_module.Collection, bool) {
+		if name != "api" {
+			return collection_module.Collection{Name: name}, false
+		}
+		return testCollection(), true
+	}
+	SaveCollectionFunc = func(collection collection_module.Collection) error {
+		stubs.saved = append(stubs.saved, collection)
+		return nil
+	}
+	GetEnvironmentFunc = func(name string) environment_module.Environment {
+		return environment_module.Environment{Name: name, Variables: map[string]string{"token": "environment"}}
+	}
+	RunRequestFunc = func(options request_module.RequestOptions) (request_module.RequestResponse, error) {
+		stubs.sent = append(stubs.sent, options)
+		return request_module.RequestResponse{
+			StatusCode: 200,
+			Request:    options,
+			Result:     `{"token":"abc"}`,
+			Headers:    http.Header{"Set-Cookie": {"session=s1"}},
+		}, nil
+	}
+	RequestMenuNewFunc = func(_ *request_module.RequestResponse, _ error, viewer request_menu.Options) error {
+		stubs.variables = append(stubs.variables, copyMap(viewer.Variables()))
+		return nil
+	}
+	AddHistoryFunc = func(request_module.RequestResponse) error { return nil }
+	headless_module.Output, headless_module.ErrorOutput = stubs.output, stubs.errOutput
+	return stubs
+}
+
+func copyMap(values map[string]string) map[string]string {
+	copied := map[string]string{}
+	for key, value := range values {
+		copied[key] = value
+	}
+	return copied
+}
+
+func runCollection(args ...string) {
+	rootCmd := &cobra.Command{Use: "root"}
+	Init(rootCmd)
+	rootCmd.SetArgs(append([]string{"collection"}, args...))
+	rootCmd.Execute()
+}
+
+func TestInit_AddsCommands(t *testing.T) {
+	rootCmd := &cobra.Command{Use: "root"}
+	Init(rootCmd)
+
+	cmd, _, err := rootCmd.Find([]string{"collection", "run"})
+	assert.NoError(t, err)
+	assert.NotNil(t, cmd.Flags().Lookup("headless"))
+	assert.NotNil(t, cmd.Flags().Lookup("env"))
+
+	cmd, _, err = rootCmd.Find([]string{"collection", "extract"})
+	assert.NoError(t, err)
+	assert.NotNil(t, cmd.Flags().Lookup("clear"))
+
+	cmd, _, err = rootCmd.Find([]string{"collection", "script"})
+	assert.NoError(t, err)
+	assert.NotNil(t, cmd.Flags().Lookup("pre"))
+	assert.NotNil(t, cmd.Flags().Lookup("post"))
+}
+
+func TestScript_Sets(t *testing.T) {
+	stubs := stubCollection(t)
+	LoadScriptFunc = func(path string) (string, error) {
+		switch path {
+		case "":
+			return "", nil
+		case "sign.js":
+			return "sign()", nil
+		case "check.js":
+			return "check()", nil
+		}
+		return "", errors.New("not found")
+	}
+
+	runCollection("script", "api", "me", "--pre", "sign.js", "--post", "check.js")
+	runCollection("script", "api", "me", "--clear", "--post", "check.js")
+	runCollection("script", "api", "me")
+	runCollection("script", "api", "me", "--pre", "missing.js")
+	runCollection("script", "api", "nothing", "--pre", "sign.js")
+
+	assert.Equal(t, []int{1, 1, 1}, stubs.codes)
+	assert.Len(t, stubs.saved, 2)
+	assert.Equal(t, "sign()", stubs.saved[0].Requests[1].PreScript)
+	assert.Equal(t, "check()", stubs.saved[0].Requests[1].PostScript)
+	assert.Empty(t, stubs.saved[1].Requests[1].PreScript)
+	assert.Equal(t, "check()", stubs.saved[1].Requests[1].PostScript)
+}
+
+func TestExtract_AddsAndReplaces(t *testing.T) {
+	stubs := stubCollection(t)
+
+	runCollection("extract", "api", "auth/login", "token=header:X-Token", "etag=header:ETag")
+
+	assert.Empty(t, stubs.codes)
+	assert.Len(t, stubs.saved, 1)
+	assert.Equal(t, []collection_module.Extractor{
+		{Variable: "token", Type: collection_module.ExtractHeader, Expression: "X-Token"},
+		{Variable: "sid", Type: collection_module.ExtractCookie, Expression: "session"},
+		{Variable: "etag", Type: collection_module.ExtractHeader, Expression: "ETag"},
+	}, stubs.saved[0].Requests[0].Extract)
+}
+
+func TestExtract_Clear(t *testing.T) {
+	stubs := stubCollection(t)
+
+	runCollection("extract", "api", "login", "--clear")
+	runCollection("extract", "api", "me", "--clear", "id=regex:id=(\\d+)")
+
+	assert.Empty(t, stubs.codes)
+	assert.Empty(t, stubs.saved[0].Requests[0].Extract)
+	assert.Equal(t, "id=(\\d+)", stubs.saved[1].Requests[1].Extract[0].Expression)
+}
+
+func TestExtract_Errors(t *testing.T) {
+	stubs := stubCollection(t)
+
+	runCollection("extract", "api", "login")
+	runCollection("extract", "api", "login", "token")
+	runCollection("extract", "missing", "login", "token=json:$.a")
+	runCollection("extract", "api", "nothing", "token=json:$.a")
+
+	assert.Equal(t, []int{1, 1, 1, 1}, stubs.codes)
+	assert.Empty(t, stubs.saved)
+}
+
+func TestRun_ChainsInMenu(t *testing.T) {
+	stubs := stubCollection(t)
+
+	runCollection("run", "api")
+
+	assert.Empty(t, stubs.codes)
+	assert.Len(t, stubs.sent, 2)
+	assert.Equal(t, "http://a/login", stubs.sent[0].Url)
+	assert.Equal(t, "Bearer abc", stubs.sent[1].Headers.Get("Authorization"), "the extracted token should win over the environment")
+	assert.Equal(t, int64(1000), stubs.sent[0].Timeout.Milliseconds())
+	assert.Equal(t, map[string]string{"token": "abc", "sid": "s1"}, stubs.variables[0])
+}
+
+func TestRun_Headless(t *testing.T) {
+	stubs := stubCollection(t)
+	var printed int
+	HeadlessPrintFunc = func(res request_module.RequestResponse, err error, _ *openapi_module.Validation) int {
+		printed++
+		if printed == 2 {
+			return 4
+		}
+		return 0
+	}
+
+	runCollection("run", "api", "--headless", "-e", "prod")
+
+	assert.Equal(t, []int{4}, stubs.codes)
+	assert.Equal(t, "### auth/login\n### me\n", stubs.output.String())
+	assert.Equal(t, "Extracted token, sid\n", stubs.errOutput.String())
+	assert.NotContains(t, stubs.errOutput.String(), "abc", "the values should not be printed")
+}
+
+func TestRun_FailedRequestKeepsGoing(t *testing.T) {
+	stubs := stubCollection(t)
+	RunRequestFunc = func(options request_module.RequestOptions) (request_module.RequestResponse, error) {
+		stubs.sent = append(stubs.sent, options)
+		return request_module.RequestResponse{}, errors.New("connection refused")
+	}
+	RequestMenuNewFunc = func(_ *request_module.RequestResponse, err error, _ request_menu.Options) error { return err }
+
+	runCollection("run", "api")
+
+	assert.Len(t, stubs.sent, 2)
+	assert.Equal(t, "Bearer environment", stubs.sent[1].Headers.Get("Authorization"))
+	assert.Len(t, stubs.codes, 1)
+}
+
+func TestRun_Errors(t *testing.T) {
+	stubs := stubCollection(t)
+
+	runCollection("run", "missing")
+	GetCollectionFunc = func(name string) (collection_module.Collection, bool) {
+		return collection_module.Collection{Name: name}, true
+	}
+	runCollection("run", "empty")
+
+	assert.Equal(t, []int{1, 1}, stubs.codes)
+	assert.Empty(t, stubs.sent)
+}
+
+func withScripts(pre string, post string) func(string) (collection_module.Collection, bool) {
+	return func(name string) (collection_module.Collection, bool) {
+		collection := testCollection()
+		collection.Requests[1].PreScript = pre
+		collection.Requests[1].PostScript = post
+		return collection, true
+	}
+}
+
+func TestRun_Scripts(t *testing.T) {
+	stubs := stubCollection(t)
+	GetCollectionFunc = withScripts(
+		`request.headers["X-Sid"] = variables.get("sid"); variables.set("page", 2); request.url += "?page={{page}}"`,
+		`test("ok", function () { assert(response.status === 200) }); variables.set("done", "yes")`,
+	)
+	var results []script_module.Result
+	HeadlessPrintFunc = func(request_module.RequestResponse, error, *openapi_module.Validation) int { return 0 }
+	HeadlessPrintScriptFunc = func(result script_module.Result) int {
+		results = append(results, result)
+		return 0
+	}
+
+	runCollection("run", "api", "--headless")
+
+	assert.Empty(t, stubs.codes)
+	assert.Equal(t, "http://a/me?page=2", stubs.sent[1].Url)
+	assert.Equal(t, "s1", stubs.sent[1].Headers.Get("X-Sid"))
+	assert.Equal(t, "Bearer abc", stubs.sent[1].Headers.Get("Authorization"))
+	assert.Len(t, results, 1, "only the request with scripts should print their result")
+	assert.True(t, results[0].Passed())
+}
+
+func TestRun_ScriptsInMenu(t *testing.T) {
+	stubs := stubCollection(t)
+	GetCollectionFunc = withScripts("", `variables.set("done", "yes")`)
+	var scripts []bool
+	RequestMenuNewFunc = func(res *request_module.RequestResponse, _ error, viewer request_menu.Options) error {
+		scripts = append(scripts, viewer.Script != nil)
+		if viewer.Script != nil {
+			viewer.Script(*res)
+		}
+		stubs.variables = append(stubs.variables, copyMap(viewer.Variables()))
+		return nil
+	}
+
+	runCollection("run", "api")
+
+	assert.Equal(t, []bool{false, true}, scripts)
+	assert.Equal(t, "yes", stubs.variables[1]["done"])
+}
+
+func TestRun_FailingPreScript(t *testing.T) {
+	stubs := stubCollection(t)
+	GetCollectionFunc = withScripts(`throw new Error("no key")`, "")
+	var printed []script_module.Result
+	HeadlessPrintFunc = func(request_module.RequestResponse, error, *openapi_module.Validation) int { return 0 }
+	HeadlessPrintScriptFunc = func(result script_module.Result) int {
+		printed = append(printed, result)
+		return script_module.TestsFailedExitCode
+	}
+
+	runCollection("run", "api", "--headless")
+
+	assert.Equal(t, []int{1}, stubs.codes)
+	assert.Len(t, stubs.sent, 1, "the request should not be sent")
+	assert.Equal(t, []string{"pre-request script: no key"}, printed[0].Errors)
+}
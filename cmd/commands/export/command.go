@@ -0,0 +1,138 @@
+package export_command
+
+import (
+	"os"
+	"strconv"
+
+	collection_module "github.com/diogopereiradev/httpzen/internal/collection"
+	config_module "github.com/diogopereiradev/httpzen/internal/config"
+	environment_module "github.com/diogopereiradev/httpzen/internal/environment"
+	har_module "github.com/diogopereiradev/httpzen/internal/har"
+	history_module "github.com/diogopereiradev/httpzen/internal/history"
+	logger_module "github.com/diogopereiradev/httpzen/internal/logger"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	script_module "github.com/diogopereiradev/httpzen/internal/script"
+	"github.com/spf13/cobra"
+)
+
+var Exit = os.Exit
+var LoggerError = logger_module.Error
+var LoggerWarn = logger_module.Warn
+var LoggerSuccess = logger_module.Success
+var GetConfigFunc = config_module.GetConfig
+var LastHistoryFunc = history_module.Last
+var GetCollectionFunc = collection_module.GetCollection
+var GetEnvironmentFunc = environment_module.GetEnvironment
+var RunRequestFunc = request_module.RunRequest
+var WriteHarFunc = har_module.Write
+
+func Init(rootCmd *cobra.Command) {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export requests and responses to other formats",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	harCmd := &cobra.Command{
+		Use:   "har",
+		Short: "Write the request history, or a collection run, to a HAR 1.2 file",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			count, _ := cmd.Flags().GetInt("history")
+			collectionName, _ := cmd.Flags().GetString("collection")
+			output, _ := cmd.Flags().GetString("output")
+
+			if cmd.Flags().Changed("history") && collectionName != "" {
+				LoggerError("Use either --history or --collection, not both.", 70)
+				Exit(1)
+				return
+			}
+			if count < 0 {
+				LoggerError("--history can't be negative.", 70)
+				Exit(1)
+				return
+			}
+
+			var responses []request_module.RequestResponse
+			if collectionName != "" {
+				collection, ok := GetCollectionFunc(collectionName)
+				if !ok {
+					LoggerError("The collection \""+collectionName+"\" does not exist.", 70)
+					Exit(1)
+					return
+				}
+				responses = runCollection(collection)
+			} else {
+				entries, err := LastHistoryFunc(count)
+				if err != nil {
+					LoggerError("Could not read the history: "+err.Error(), 70)
+					Exit(1)
+					return
+				}
+				responses = entries
+			}
+
+			if len(responses) == 0 {
+				LoggerError("There are no requests to export.", 70)
+				Exit(1)
+				return
+			}
+			if err := WriteHarFunc(output, har_module.Export(responses)); err != nil {
+				LoggerError("Could not write the HAR file: "+err.Error(), 70)
+				Exit(1)
+				return
+			}
+			LoggerSuccess("Exported "+strconv.Itoa(len(responses))+" entries to "+output, 70)
+		},
+	}
+
+	harCmd.Flags().Int("history", 0, "Export the last N requests of the history, 0 for all of them")
+	harCmd.Flags().String("collection", "", "Run the requests of a collection and export their responses")
+	harCmd.Flags().StringP("output", "o", "httpzen.har", "Path of the HAR file")
+
+	cmd.AddCommand(harCmd)
+	rootCmd.AddCommand(cmd)
+}
+
+// runCollection sends the saved requests one after the other, with the
+// variables of the active environment and the ones extracted or set by the
+// scripts of the previous requests. Failed requests have no response to record, they are
+// reported and left out.
+func runCollection(collection collection_module.Collection) []request_module.RequestResponse {
+	config := GetConfigFunc()
+	environment := GetEnvironmentFunc(config.ActiveEnvironment)
+
+	session := collection_module.NewSession()
+	var responses []request_module.RequestResponse
+	for _, saved := range collection.Requests {
+		options := collection.Resolve(saved, environment.Variables, session.Variables)
+		timeout, timeouts := request_module.ConfigTimeouts(config)
+		if options.Timeout == 0 {
+			options.Timeout = timeout
+		}
+		options.Timeouts = timeouts
+		runner := script_module.NewRunner(saved.PreScript, saved.PostScript, session.Variables)
+		if err := runner.BeforeRequest(&options); err != nil {
+			LoggerWarn("\""+saved.Path()+"\" was not sent and is left out: "+err.Error(), 70)
+			continue
+		}
+		options = collection_module.Collection{}.Resolve(collection_module.SavedRequest{Request: options}, session.Variables)
+
+		res, err := RunRequestFunc(options)
+		if err != nil {
+			LoggerWarn("\""+saved.Path()+"\" failed and is left out: "+err.Error(), 70)
+			continue
+		}
+		_, errs := session.Extract(saved, res)
+		for _, err := range errs {
+			LoggerWarn("\""+saved.Path()+"\" could not extract "+err.Error(), 70)
+		}
+		if result := runner.AfterResponse(res); !result.Passed() {
+			LoggerWarn("\""+saved.Path()+"\" failed its post-response script tests", 70)
+		}
+		responses = append(responses, res)
+	}
+	return responses
+}
@@ -0,0 +1,182 @@
+package export_command
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	collection_module "github.com/diogopereiradev/httpzen/internal/collection"
+	config_module "github.com/diogopereiradev/httpzen/internal/config"
+	environment_module "github.com/diogopereiradev/httpzen/internal/environment"
+	har_module "github.com/diogopereiradev/httpzen/internal/har"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+type written struct {
+	path string
+	har  har_module.Har
+}
+
+func stubCommand(t *testing.T) (*[]int, *[]written) {
+	codes := &[]int{}
+	writes := &[]written{}
+	oldExit, oldError, oldWarn, oldSuccess := Exit, LoggerError, LoggerWarn, LoggerSuccess
+	oldConfig, oldHistory, oldCollection, oldRun, oldWrite := GetConfigFunc, LastHistoryFunc, GetCollectionFunc, RunRequestFunc, WriteHarFunc
+	oldEnvironment := GetEnvironmentFunc
+	t.Cleanup(func() {
+		Exit, LoggerError, LoggerWarn, LoggerSuccess = oldExit, oldError, oldWarn, oldSuccess
+		GetConfigFunc, LastHistoryFunc, GetCollectionFunc, RunRequestFunc, WriteHarFunc = oldConfig, oldHistory, oldCollection, oldRun, oldWrite
+		GetEnvironmentFunc = oldEnvironment
+	})
+
+	Exit = func(code int) { *codes = append(*codes, code) }
+	LoggerError = func(string, int) {}
+	LoggerWarn = func(string, int) {}
+	LoggerSuccess = func(string, int) {}
+	GetConfigFunc = func() config_module.Config { return config_module.Config{Timeout: 5000, ActiveEnvironment: "dev"} }
+	GetEnvironmentFunc = func(name string) environment_module.Environment {
+		return environment_module.Environment{Name: name, Variables: map[string]string{"host": "http://a"}}
+	}
+	WriteHarFunc = func(path string, har har_module.Har) error {
+		*writes = append(*writes, written{path, har})
+		return nil
+	}
+	return codes, writes
+}
+
+func runExport(args ...string) {
+	rootCmd := &cobra.Command{Use: "root"}
+	Init(rootCmd)
+	rootCmd.SetArgs(append([]string{"export", "har"}, args...))
+	rootCmd.Execute()
+}
+
+func TestInit_AddsCommand(t *testing.T) {
+	rootCmd := &cobra.Command{Use: "root"}
+	Init(rootCmd)
+
+	cmd, _, err := rootCmd.Find([]string{"export", "har"})
+	assert.NoError(t, err)
+	assert.Equal(t, "har", cmd.Use)
+	assert.NotNil(t, cmd.Flags().Lookup("history"))
+	assert.NotNil(t, cmd.Flags().Lookup("collection"))
+	assert.Equal(t, "httpzen.har", cmd.Flags().Lookup("output").DefValue)
+}
+
+func TestExportHar_History(t *testing.T) {
+	codes, writes := stubCommand(t)
+
+	var gotCount int
+	LastHistoryFunc = func(count int) ([]request_module.RequestResponse, error) {
+		gotCount = count
+		return []request_module.RequestResponse{{StatusCode: 200}, {StatusCode: 404}}, nil
+	}
+
+	runExport("--history", "2", "-o", "out.har")
+
+	assert.Empty(t, *codes)
+	assert.Equal(t, 2, gotCount)
+	assert.Len(t, *writes, 1)
+	assert.Equal(t, "out.har", (*writes)[0].path)
+	assert.Len(t, (*writes)[0].har.Log.Entries, 2)
+}
+
+func TestExportHar_EmptyHistory(t *testing.T) {
+	codes, writes := stubCommand(t)
+	LastHistoryFunc = func(int) ([]request_module.RequestResponse, error) { return nil, nil }
+
+	runExport()
+
+	assert.Equal(t, []int{1}, *codes)
+	assert.Empty(t, *writes)
+}
+
+func TestExportHar_Collection(t *testing.T) {
+	codes, writes := stubCommand(t)
+	GetConfigFunc = func() config_module.Config {
+		return config_module.Config{Timeout: 5000, ConnectTimeout: 1000, IdleTimeout: 2000, ActiveEnvironment: "dev"}
+	}
+
+	GetCollectionFunc = func(name string) (collection_module.Collection, bool) {
+		return collection_module.Collection{Name: name, Requests: []collection_module.SavedRequest{
+			{Name: "ok", Request: request_module.RequestOptions{Method: "GET", Url: "{{host}}/ok"}},
+			{Name: "broken", Request: request_module.RequestOptions{Method: "GET", Url: "http://a/broken"}},
+		}}, true
+	}
+	var timeouts []int64
+	var phases []request_module.Timeouts
+	RunRequestFunc = func(options request_module.RequestOptions) (request_module.RequestResponse, error) {
+		timeouts = append(timeouts, options.Timeout.Milliseconds())
+		phases = append(phases, options.Timeouts)
+		if options.Url == "http://a/broken" {
+			return request_module.RequestResponse{}, errors.New("connection refused")
+		}
+		return request_module.RequestResponse{StatusCode: 200, Request: options}, nil
+	}
+
+	runExport("--collection", "api")
+
+	assert.Empty(t, *codes)
+	assert.Equal(t, []int64{5000, 5000}, timeouts)
+	assert.Equal(t, request_module.Timeouts{Connect: time.Second, Idle: 2 * time.Second}, phases[0])
+	assert.Len(t, (*writes)[0].har.Log.Entries, 1)
+	assert.Equal(t, "http://a/ok", (*writes)[0].har.Log.Entries[0].Request.Url)
+}
+
+func TestExportHar_CollectionChaining(t *testing.T) {
+	codes, writes := stubCommand(t)
+
+	GetCollectionFunc = func(name string) (collection_module.Collection, bool) {
+		return collection_module.Collection{Name: name, Requests: []collection_module.SavedRequest{
+			{Name: "login", Request: request_module.RequestOptions{Method: "POST", Url: "{{host}}/login"},
+				Extract: []collection_module.Extractor{{Variable: "token", Type: collection_module.ExtractJson, Expression: "$.token"}}},
+			{Name: "me", Request: request_module.RequestOptions{Method: "GET", Url: "{{host}}/me?token={{token}}"}},
+		}}, true
+	}
+	RunRequestFunc = func(options request_module.RequestOptions) (request_module.RequestResponse, error) {
+		return request_module.RequestResponse{StatusCode: 200, Request: options, Result: `{"token":"abc"}`}, nil
+	}
+
+	runExport("--collection", "api")
+
+	assert.Empty(t, *codes)
+	assert.Equal(t, "http://a/me?token=abc", (*writes)[0].har.Log.Entries[1].Request.Url)
+}
+
+func TestExportHar_CollectionScripts(t *testing.T) {
+	codes, writes := stubCommand(t)
+
+	GetCollectionFunc = func(name string) (collection_module.Collection, bool) {
+		return collection_module.Collection{Name: name, Requests: []collection_module.SavedRequest{
+			{Name: "signed", Request: request_module.RequestOptions{Method: "GET", Url: "{{host}}/signed"},
+				PreScript: `request.headers["X-Signature"] = crypto.sha256(request.url)`},
+			{Name: "broken", Request: request_module.RequestOptions{Method: "GET", Url: "{{host}}/broken"},
+				PreScript: `throw new Error("no key")`},
+		}}, true
+	}
+	var sent []request_module.RequestOptions
+	RunRequestFunc = func(options request_module.RequestOptions) (request_module.RequestResponse, error) {
+		sent = append(sent, options)
+		return request_module.RequestResponse{StatusCode: 200, Request: options}, nil
+	}
+
+	runExport("--collection", "api")
+
+	assert.Empty(t, *codes)
+	assert.Len(t, sent, 1, "a request with a failing pre-request script should not be sent")
+	assert.Len(t, sent[0].Headers.Get("X-Signature"), 64)
+	assert.Len(t, (*writes)[0].har.Log.Entries, 1)
+}
+
+func TestExportHar_Errors(t *testing.T) {
+	codes, _ := stubCommand(t)
+	GetCollectionFunc = func(string) (collection_module.Collection, bool) { return collection_module.Collection{}, false }
+
+	runExport("--collection", "missing")
+	runExport("--collection", "api", "--history", "1")
+	runExport("--history", "-1")
+
+	assert.Equal(t, []int{1, 1, 1}, *codes)
+}
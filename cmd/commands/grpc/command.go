@@ -0,0 +1,144 @@
+package grpc_command
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	config_module "github.com/diogopereiradev/httpzen/internal/config"
+	grpc_module "github.com/diogopereiradev/httpzen/internal/grpc"
+	logger_module "github.com/diogopereiradev/httpzen/internal/logger"
+	"github.com/diogopereiradev/httpzen/internal/menus/request_menu"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/diogopereiradev/httpzen/internal/utils/http_utility"
+	"github.com/diogopereiradev/httpzen/internal/utils/theme"
+	"github.com/spf13/cobra"
+)
+
+var Exit = os.Exit
+var LoggerError = logger_module.Error
+var Println = fmt.Println
+var ReadFileFunc = os.ReadFile
+var ListServicesFunc = grpc_module.ListServices
+var GetConfigFunc = config_module.GetConfig
+var RequestMenuGrpcFunc = request_menu.NewGrpc
+
+func Init(rootCmd *cobra.Command) {
+	cmd := &cobra.Command{
+		Use:   "grpc [HOST] [SERVICE/METHOD]",
+		Short: "Call a gRPC method, or list the services of a server when no method is given",
+		Args:  cobra.ArbitraryArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) < 1 {
+				cmd.Help()
+				return
+			}
+
+			headers, _ := cmd.Flags().GetStringSlice("header")
+			data, _ := cmd.Flags().GetString("data")
+			protoFiles, _ := cmd.Flags().GetStringSlice("proto")
+			importPaths, _ := cmd.Flags().GetStringSlice("import-path")
+			plaintext, _ := cmd.Flags().GetBool("plaintext")
+			insecure, _ := cmd.Flags().GetBool("insecure")
+
+			timeout, _ := request_module.ConfigTimeouts(GetConfigFunc())
+			if cmd.Flags().Changed("timeout") {
+				timeout, _ = cmd.Flags().GetDuration("timeout")
+			}
+			if timeout < 0 {
+				LoggerError("Invalid timeout: --timeout can't be negative", 70)
+				Exit(1)
+				return
+			}
+
+			if address, _ := http_utility.ParseGrpcTarget(args[0], plaintext); address == "" {
+				LoggerError("Invalid host. Please provide a gRPC server address like localhost:50051.", 70)
+				Exit(request_module.ExitCodes[request_module.ErrorClassInvalidUrl])
+				return
+			}
+
+			if path, isFile := strings.CutPrefix(data, "@"); isFile {
+				content, err := ReadFileFunc(path)
+				if err != nil {
+					LoggerError("Failed to read the request message: "+err.Error(), 70)
+					Exit(1)
+					return
+				}
+				data = string(content)
+			}
+
+			options := grpc_module.CallOptions{
+				Target:      args[0],
+				Data:        data,
+				Headers:     http_utility.ParseHeaders(headers),
+				ProtoFiles:  protoFiles,
+				ImportPaths: importPaths,
+				Plaintext:   plaintext,
+				Insecure:    insecure,
+				Timeout:     timeout,
+			}
+
+			if len(args) < 2 {
+				services, err := ListServicesFunc(options)
+				if err != nil {
+					LoggerError(err.Error(), 70)
+					Exit(request_module.ExitCode(err))
+					return
+				}
+				Println(renderServices(services))
+				return
+			}
+
+			if _, _, ok := grpc_module.ParseMethodName(args[1]); !ok {
+				LoggerError("Invalid method. Please use the SERVICE/METHOD form, like helloworld.Greeter/SayHello.", 70)
+				Exit(1)
+				return
+			}
+			options.Method = args[1]
+
+			if err := RequestMenuGrpcFunc(options); err != nil {
+				Exit(request_module.ExitCode(err))
+			}
+		},
+	}
+
+	cmd.Flags().StringSliceP("header", "H", []string{}, "Add request metadata, like \"Authorization: Bearer <token>\" (can be used multiple times)")
+	cmd.Flags().StringP("data", "d", "", "Request message as JSON, or @file to read it from a file")
+	cmd.Flags().StringSlice("proto", []string{}, "Describe the services with local .proto files instead of server reflection")
+	cmd.Flags().StringSliceP("import-path", "I", []string{}, "Directory to resolve the imports of the .proto files from")
+	cmd.Flags().Bool("plaintext", false, "Connect without TLS")
+	cmd.Flags().Bool("insecure", false, "Skip the verification of the server certificate")
+	cmd.Flags().Duration("timeout", 0, "Time limit for the server reflection and unary calls, 0 for none (default: from the config, 30s)")
+
+	rootCmd.AddCommand(cmd)
+}
+
+func renderServices(services []grpc_module.ServiceInfo) string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Primary)
+	fieldKeyStyle := lipgloss.NewStyle().Foreground(theme.Secondary)
+	greyTextStyle := lipgloss.NewStyle().Foreground(theme.DarkenText)
+
+	if len(services) == 0 {
+		return greyTextStyle.Render("The server does not advertise any service.")
+	}
+
+	var lines []string
+	for i, service := range services {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, titleStyle.Render(service.Name))
+		for _, method := range service.Methods {
+			input, output := method.Input, method.Output
+			if method.ClientStreaming {
+				input = "stream " + input
+			}
+			if method.ServerStreaming {
+				output = "stream " + output
+			}
+			lines = append(lines, "  "+fieldKeyStyle.Render(method.Name)+greyTextStyle.Render("("+input+") returns ("+output+")"))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
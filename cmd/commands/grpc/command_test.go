@@ -0,0 +1,162 @@
+package grpc_command
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	config_module "github.com/diogopereiradev/httpzen/internal/config"
+	grpc_module "github.com/diogopereiradev/httpzen/internal/grpc"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func stubCommand(t *testing.T) *[]int {
+	codes := &[]int{}
+	oldExit, oldLogger, oldPrintln, oldReadFile := Exit, LoggerError, Println, ReadFileFunc
+	oldList, oldMenu, oldConfig := ListServicesFunc, RequestMenuGrpcFunc, GetConfigFunc
+	t.Cleanup(func() {
+		Exit, LoggerError, Println, ReadFileFunc = oldExit, oldLogger, oldPrintln, oldReadFile
+		ListServicesFunc, RequestMenuGrpcFunc, GetConfigFunc = oldList, oldMenu, oldConfig
+	})
+
+	Exit = func(code int) { *codes = append(*codes, code) }
+	LoggerError = func(string, int) {}
+	GetConfigFunc = func() config_module.Config { return config_module.Config{Timeout: 1500} }
+	Println = func(...any) (int, error) { return 0, nil }
+	ListServicesFunc = func(grpc_module.CallOptions) ([]grpc_module.ServiceInfo, error) {
+		t.Error("unexpected service listing")
+		return nil, nil
+	}
+	RequestMenuGrpcFunc = func(grpc_module.CallOptions) error {
+		t.Error("unexpected call")
+		return nil
+	}
+	return codes
+}
+
+func runGrpc(args ...string) {
+	rootCmd := &cobra.Command{Use: "root"}
+	Init(rootCmd)
+	rootCmd.SetArgs(append([]string{"grpc"}, args...))
+	rootCmd.Execute()
+}
+
+func TestInit_AddsCommand(t *testing.T) {
+	rootCmd := &cobra.Command{Use: "root"}
+	Init(rootCmd)
+
+	cmd, _, err := rootCmd.Find([]string{"grpc"})
+	assert.NoError(t, err)
+	assert.Equal(t, "grpc [HOST] [SERVICE/METHOD]", cmd.Use)
+	for _, flag := range []string{"header", "data", "proto", "import-path", "plaintext", "insecure"} {
+		assert.NotNil(t, cmd.Flags().Lookup(flag), flag)
+	}
+}
+
+func TestGrpc_Call(t *testing.T) {
+	codes := stubCommand(t)
+
+	var got grpc_module.CallOptions
+	RequestMenuGrpcFunc = func(options grpc_module.CallOptions) error {
+		got = options
+		return nil
+	}
+
+	runGrpc("localhost:50051", "helloworld.Greeter/SayHello", "-d", `{"name":"zen"}`, "-H", "Authorization: Bearer abc",
+		"--proto", "greeter.proto", "-I", "protos", "--plaintext")
+
+	assert.Empty(t, *codes)
+	assert.Equal(t, "localhost:50051", got.Target)
+	assert.Equal(t, "helloworld.Greeter/SayHello", got.Method)
+	assert.Equal(t, `{"name":"zen"}`, got.Data)
+	assert.Equal(t, "Bearer abc", got.Headers.Get("Authorization"))
+	assert.Equal(t, []string{"greeter.proto"}, got.ProtoFiles)
+	assert.Equal(t, []string{"protos"}, got.ImportPaths)
+	assert.True(t, got.Plaintext)
+	assert.Equal(t, 1500*time.Millisecond, got.Timeout, "the timeout should come from the config")
+}
+
+func TestGrpc_Timeout(t *testing.T) {
+	codes := stubCommand(t)
+
+	var got grpc_module.CallOptions
+	RequestMenuGrpcFunc = func(options grpc_module.CallOptions) error {
+		got = options
+		return nil
+	}
+
+	runGrpc("localhost:50051", "helloworld.Greeter/SayHello", "--timeout", "0")
+	assert.Empty(t, *codes)
+	assert.Zero(t, got.Timeout)
+
+	runGrpc("localhost:50051", "helloworld.Greeter/SayHello", "--timeout", "-1s")
+	assert.Equal(t, []int{1}, *codes)
+}
+
+func TestGrpc_DataFromFile(t *testing.T) {
+	codes := stubCommand(t)
+
+	var got grpc_module.CallOptions
+	RequestMenuGrpcFunc = func(options grpc_module.CallOptions) error {
+		got = options
+		return nil
+	}
+	ReadFileFunc = func(path string) ([]byte, error) {
+		assert.Equal(t, "message.json", path)
+		return []byte(`{"name":"file"}`), nil
+	}
+
+	runGrpc("localhost:50051", "helloworld.Greeter/SayHello", "-d", "@message.json")
+	assert.Empty(t, *codes)
+	assert.Equal(t, `{"name":"file"}`, got.Data)
+
+	ReadFileFunc = func(string) ([]byte, error) { return nil, errors.New("missing") }
+	runGrpc("localhost:50051", "helloworld.Greeter/SayHello", "-d", "@message.json")
+	assert.Equal(t, []int{1}, *codes)
+}
+
+func TestGrpc_ListsServices(t *testing.T) {
+	codes := stubCommand(t)
+
+	var printed string
+	Println = func(a ...any) (int, error) {
+		printed = a[0].(string)
+		return 0, nil
+	}
+	ListServicesFunc = func(options grpc_module.CallOptions) ([]grpc_module.ServiceInfo, error) {
+		return []grpc_module.ServiceInfo{{
+			Name: "helloworld.Greeter",
+			Methods: []grpc_module.MethodInfo{
+				{Name: "SayHello", Input: "helloworld.HelloRequest", Output: "helloworld.HelloReply"},
+				{Name: "Watch", Input: "helloworld.HelloRequest", Output: "helloworld.HelloReply", ServerStreaming: true},
+			},
+		}}, nil
+	}
+
+	runGrpc("localhost:50051")
+	assert.Empty(t, *codes)
+	assert.Contains(t, printed, "helloworld.Greeter")
+	assert.Contains(t, printed, "(helloworld.HelloRequest) returns (helloworld.HelloReply)")
+	assert.Contains(t, printed, "returns (stream helloworld.HelloReply)")
+}
+
+func TestGrpc_Failures(t *testing.T) {
+	codes := stubCommand(t)
+
+	runGrpc("localhost:50051/x", "helloworld.Greeter/SayHello")
+	runGrpc("localhost:50051", "Greeter")
+
+	ListServicesFunc = func(grpc_module.CallOptions) ([]grpc_module.ServiceInfo, error) {
+		return nil, &request_module.RequestError{Class: request_module.ErrorClassConnectionRefused}
+	}
+	runGrpc("localhost:50051")
+
+	RequestMenuGrpcFunc = func(grpc_module.CallOptions) error {
+		return &request_module.RequestError{Class: request_module.ErrorClassTls}
+	}
+	runGrpc("localhost:50051", "helloworld.Greeter/SayHello")
+
+	assert.Equal(t, []int{3, 1, 7, 35}, *codes)
+}
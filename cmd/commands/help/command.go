@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/diogopereiradev/httpzen/internal/utils/http_utility"
 	logoascii "github.com/diogopereiradev/httpzen/internal/utils/logo_ascii"
 	"github.com/diogopereiradev/httpzen/internal/utils/terminal_utility"
 	"github.com/diogopereiradev/httpzen/internal/utils/theme"
@@ -13,13 +14,33 @@ import (
 )
 
 var CategorizedFlags = map[string][]string{
-	"Main parameters": {"help"},
-	"Data":            {"header", "body"},
+	"Main parameters":   {"help", "custom-method", "stream", "protocol", "download", "checksum", "watch", "until"},
+	"Data":              {"header", "body", "force-body", "form", "upload-file"},
+	"Connection":        {"unix-socket", "resolve", "connect-to", "all-ips", "timeout", "connect-timeout", "tls-timeout", "header-timeout", "idle-timeout"},
+	"Retries":           {"retry", "retry-status", "retry-on", "retry-backoff", "retry-max-wait"},
+	"Authentication":    {"auth", "user", "token", "token-url", "client-id", "client-secret", "scope", "env", "save-auth"},
+	"GraphQL":           {"graphql", "variables", "operation", "refresh-schema"},
+	"Scripting":         {"headless", "validate", "pre-script", "post-script", "all", "clear", "pre", "post"},
+	"Testing":           {"iteration-data", "iterations", "parallel", "stop-on-failure", "junit", "json"},
+	"Snapshots":         {"save-snapshot", "ignore", "update"},
+	"WebSocket":         {"script", "interval", "export"},
+	"gRPC":              {"data", "proto", "import-path", "plaintext", "insecure"},
+	"Import and export": {"history", "collection", "output", "host", "method", "environment", "name", "browse"},
 }
 
 var CategorizedFlagsOrder = []string{
 	"Main parameters",
 	"Data",
+	"Connection",
+	"Retries",
+	"Authentication",
+	"GraphQL",
+	"Scripting",
+	"Testing",
+	"Snapshots",
+	"WebSocket",
+	"gRPC",
+	"Import and export",
 }
 
 func padRight(str string, length int) string {
@@ -43,6 +64,22 @@ func renderCommands(cmds []*cobra.Command, maxUseLen int) string {
 	return b.String()
 }
 
+func renderMethods(methods []http_utility.HttpMethodInfo) string {
+	var b strings.Builder
+
+	maxNameLen := 0
+	for _, method := range methods {
+		maxNameLen = max(maxNameLen, len(method.Name))
+	}
+
+	b.WriteString("Methods\n\n")
+	for _, method := range methods {
+		b.WriteString("  " + padRight(method.Name, maxNameLen+4) + method.Hint + "\n")
+	}
+	b.WriteString("  " + padRight("OTHER", maxNameLen+4) + "Any uppercase token, like PURGE, with --custom-method\n")
+	return b.String()
+}
+
 func renderFlags(cmd *cobra.Command, maxFlagNameLen int) string {
 	var b strings.Builder
 	shown := map[string]bool{}
@@ -64,6 +101,9 @@ func renderFlags(cmd *cobra.Command, maxFlagNameLen int) string {
 		b.WriteString(category + "\n\n")
 		for _, flagName := range flagNames {
 			flag := cmd.Flags().Lookup(flagName)
+			if flag == nil {
+				continue
+			}
 			shown[flagName] = true
 
 			pad := padRight("", maxFlagNameLen-len(flagName)+4)
@@ -159,6 +199,9 @@ func Init(rootCmd *cobra.Command) {
 			}
 		}
 		b.WriteString(renderFlags(cmd, maxFlagNameLen))
+		if cmd.Flags().Lookup("custom-method") != nil {
+			b.WriteString("\n" + renderMethods(http_utility.HttpMethods))
+		}
 
 		fmt.Println(borderStyle.Render(b.String()))
 	})
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/diogopereiradev/httpzen/internal/utils/http_utility"
 	"github.com/diogopereiradev/httpzen/internal/utils/terminal_utility"
 	"github.com/diogopereiradev/httpzen/internal/utils/theme"
 	"github.com/spf13/cobra"
@@ -30,6 +31,18 @@ func TestRenderCommands(t *testing.T) {
 	assert.Equal(t, expected, out)
 }
 
+func TestRenderMethods(t *testing.T) {
+	out := renderMethods([]http_utility.HttpMethodInfo{
+		{Name: "GET", Hint: "Fetch a resource"},
+		{Name: "OPTIONS", Hint: "List allowed methods"},
+	})
+
+	assert.Contains(t, out, "Methods")
+	assert.Contains(t, out, "  GET        Fetch a resource\n")
+	assert.Contains(t, out, "  OPTIONS    List allowed methods\n")
+	assert.Contains(t, out, "--custom-method")
+}
+
 func TestRenderFlags_CategorizedAndUncategorized(t *testing.T) {
 	cmd := &cobra.Command{Use: "test"}
 	cmd.Flags().String("help", "", "Show help")
@@ -0,0 +1,199 @@
+package import_command
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	collection_module "github.com/diogopereiradev/httpzen/internal/collection"
+	har_module "github.com/diogopereiradev/httpzen/internal/har"
+	insomnia_module "github.com/diogopereiradev/httpzen/internal/insomnia"
+	logger_module "github.com/diogopereiradev/httpzen/internal/logger"
+	"github.com/diogopereiradev/httpzen/internal/menus/har_menu"
+	"github.com/diogopereiradev/httpzen/internal/menus/openapi_menu"
+	openapi_module "github.com/diogopereiradev/httpzen/internal/openapi"
+	postman_module "github.com/diogopereiradev/httpzen/internal/postman"
+	"github.com/spf13/cobra"
+)
+
+var Exit = os.Exit
+var LoggerError = logger_module.Error
+var LoggerWarn = logger_module.Warn
+var LoggerSuccess = logger_module.Success
+var ReadFileFunc = os.ReadFile
+var SaveImportFunc = collection_module.SaveImport
+var ReadHarFunc = har_module.Read
+var HarMenuNewFunc = har_menu.New
+var LoadOpenApiFunc = openapi_module.Load
+var OpenApiMenuNewFunc = openapi_menu.New
+
+func Init(rootCmd *cobra.Command) {
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import requests from other tools",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	harCmd := &cobra.Command{
+		Use:   "har [FILE]",
+		Short: "Browse the entries of a HAR file, to replay them or save them as requests",
+		Args:  cobra.ArbitraryArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				cmd.Help()
+				return
+			}
+			host, _ := cmd.Flags().GetString("host")
+			method, _ := cmd.Flags().GetString("method")
+
+			har, err := ReadHarFunc(args[0])
+			if err != nil {
+				LoggerError("Could not read the HAR file: "+err.Error(), 70)
+				Exit(1)
+				return
+			}
+			if len(har_module.Filter(har.Log.Entries, host, method)) == 0 {
+				LoggerError("The HAR file has no entries matching the filters.", 70)
+				Exit(1)
+				return
+			}
+			HarMenuNewFunc(har.Log.Entries, host, method)
+		},
+	}
+
+	harCmd.Flags().String("host", "", "Only list the entries sent to this host")
+	harCmd.Flags().String("method", "", "Only list the entries with this method")
+
+	postmanCmd := &cobra.Command{
+		Use:   "postman [FILE]",
+		Short: "Import a Postman v2.1 collection, and its environments, as saved requests",
+		Args:  cobra.ArbitraryArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				cmd.Help()
+				return
+			}
+			environmentPaths, _ := cmd.Flags().GetStringSlice("environment")
+
+			data, ok := readFile(args[0])
+			if !ok {
+				return
+			}
+			var environments [][]byte
+			for _, path := range environmentPaths {
+				environment, ok := readFile(path)
+				if !ok {
+					return
+				}
+				environments = append(environments, environment)
+			}
+
+			result, err := postman_module.Parse(data, environments...)
+			saveImport(cmd, result, err)
+		},
+	}
+	postmanCmd.Flags().StringSlice("environment", []string{}, "A Postman environment export to import too (can be used multiple times)")
+	postmanCmd.Flags().String("name", "", "Name of the collection, instead of the one in the file")
+
+	insomniaCmd := &cobra.Command{
+		Use:   "insomnia [FILE]",
+		Short: "Import an Insomnia JSON export, and its environments, as saved requests",
+		Args:  cobra.ArbitraryArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				cmd.Help()
+				return
+			}
+			data, ok := readFile(args[0])
+			if !ok {
+				return
+			}
+			result, err := insomnia_module.Parse(data)
+			saveImport(cmd, result, err)
+		},
+	}
+	insomniaCmd.Flags().String("name", "", "Name of the collection, instead of the one in the file")
+
+	openapiCmd := &cobra.Command{
+		Use:   "openapi [SPEC]",
+		Short: "Create a saved request per operation of an OpenAPI 3 spec, in YAML or JSON",
+		Args:  cobra.ArbitraryArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				cmd.Help()
+				return
+			}
+			browse, _ := cmd.Flags().GetBool("browse")
+
+			spec, err := LoadOpenApiFunc(args[0])
+			if err != nil {
+				LoggerError("Could not read the OpenAPI spec: "+err.Error(), 70)
+				Exit(1)
+				return
+			}
+			result := spec.Import()
+			if len(result.Collection.Requests) == 0 {
+				LoggerError("The OpenAPI spec has no operations.", 70)
+				Exit(1)
+				return
+			}
+			if saveImport(cmd, result, nil) && browse {
+				name, _ := cmd.Flags().GetString("name")
+				if name == "" {
+					name = result.Collection.Name
+				}
+				OpenApiMenuNewFunc(spec, name)
+			}
+		},
+	}
+	openapiCmd.Flags().String("name", "", "Name of the collection, instead of the title of the spec")
+	openapiCmd.Flags().Bool("browse", false, "Open the operations grouped by tag after the import, to fill in their parameters and send them")
+
+	cmd.AddCommand(harCmd, postmanCmd, insomniaCmd, openapiCmd)
+	rootCmd.AddCommand(cmd)
+}
+
+func readFile(path string) ([]byte, bool) {
+	data, err := ReadFileFunc(path)
+	if err != nil {
+		LoggerError("Could not read the file: "+err.Error(), 70)
+		Exit(1)
+		return nil, false
+	}
+	return data, true
+}
+
+// saveImport saves what was read and reports what couldn't be translated,
+// the import still goes through then. It tells if the import was saved.
+func saveImport(cmd *cobra.Command, result collection_module.ImportResult, err error) bool {
+	if err != nil {
+		LoggerError(err.Error(), 70)
+		Exit(1)
+		return false
+	}
+	if name, _ := cmd.Flags().GetString("name"); name != "" {
+		result.Collection.Name = name
+	}
+	if err := SaveImportFunc(result); err != nil {
+		LoggerError("Could not save the import: "+err.Error(), 70)
+		Exit(1)
+		return false
+	}
+
+	message := "Imported " + strconv.Itoa(len(result.Collection.Requests)) + " requests into the collection \"" + result.Collection.Name + "\""
+	if len(result.Environments) > 0 {
+		var names []string
+		for _, env := range result.Environments {
+			names = append(names, env.Name)
+		}
+		message += " and the environments " + strings.Join(names, ", ")
+	}
+	LoggerSuccess(message+".", 70)
+
+	if len(result.Skipped) > 0 {
+		LoggerWarn("Some parts could not be translated:\n  "+strings.Join(result.Skipped, "\n  "), 70)
+	}
+	return true
+}
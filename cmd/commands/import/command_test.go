@@ -0,0 +1,220 @@
+package import_command
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	collection_module "github.com/diogopereiradev/httpzen/internal/collection"
+	har_module "github.com/diogopereiradev/httpzen/internal/har"
+	openapi_module "github.com/diogopereiradev/httpzen/internal/openapi"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func stubCommand(t *testing.T) *[]int {
+	codes := &[]int{}
+	oldExit, oldLogger, oldRead, oldMenu := Exit, LoggerError, ReadHarFunc, HarMenuNewFunc
+	oldWarn, oldSuccess, oldReadFile, oldSave := LoggerWarn, LoggerSuccess, ReadFileFunc, SaveImportFunc
+	oldLoad, oldOpenApiMenu := LoadOpenApiFunc, OpenApiMenuNewFunc
+	t.Cleanup(func() {
+		Exit, LoggerError, ReadHarFunc, HarMenuNewFunc = oldExit, oldLogger, oldRead, oldMenu
+		LoggerWarn, LoggerSuccess, ReadFileFunc, SaveImportFunc = oldWarn, oldSuccess, oldReadFile, oldSave
+		LoadOpenApiFunc, OpenApiMenuNewFunc = oldLoad, oldOpenApiMenu
+	})
+
+	Exit = func(code int) { *codes = append(*codes, code) }
+	LoggerError = func(string, int) {}
+	LoggerWarn = func(string, int) {}
+	LoggerSuccess = func(string, int) {}
+	ReadHarFunc = func(string) (har_module.Har, error) {
+		return har_module.Har{Log: har_module.Log{Entries: []har_module.Entry{
+			{Request: har_module.Request{Method: "GET", Url: "https://a.com/"}},
+			{Request: har_module.Request{Method: "POST", Url: "https://b.com/"}},
+		}}}, nil
+	}
+	return codes
+}
+
+func runImport(args ...string) {
+	runImportCommand("har", args...)
+}
+
+func runImportCommand(command string, args ...string) {
+	rootCmd := &cobra.Command{Use: "root"}
+	Init(rootCmd)
+	rootCmd.SetArgs(append([]string{"import", command}, args...))
+	rootCmd.Execute()
+}
+
+func TestImportHar_OpensMenu(t *testing.T) {
+	codes := stubCommand(t)
+
+	var gotEntries int
+	var gotHost, gotMethod string
+	HarMenuNewFunc = func(entries []har_module.Entry, host string, method string) {
+		gotEntries, gotHost, gotMethod = len(entries), host, method
+	}
+
+	runImport("session.har", "--host", "b.com", "--method", "post")
+
+	assert.Empty(t, *codes)
+	assert.Equal(t, 2, gotEntries)
+	assert.Equal(t, "b.com", gotHost)
+	assert.Equal(t, "post", gotMethod)
+}
+
+func TestImportHar_Errors(t *testing.T) {
+	codes := stubCommand(t)
+	opened := false
+	HarMenuNewFunc = func([]har_module.Entry, string, string) { opened = true }
+
+	runImport("session.har", "--host", "c.com")
+
+	ReadHarFunc = func(string) (har_module.Har, error) { return har_module.Har{}, errors.New("invalid character") }
+	runImport("broken.har")
+
+	assert.Equal(t, []int{1, 1}, *codes)
+	assert.False(t, opened)
+}
+
+const postmanCollection = `{"info": {"name": "Shop", "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"},
+	"event": [{"listen": "test", "script": {"exec": ["pm.test()"]}}],
+	"item": [{"name": "Ping", "request": {"method": "GET", "url": "{{baseUrl}}/ping"}}]}`
+
+func stubFiles(files map[string]string) {
+	ReadFileFunc = func(path string) ([]byte, error) {
+		if data, ok := files[path]; ok {
+			return []byte(data), nil
+		}
+		return nil, os.ErrNotExist
+	}
+}
+
+func TestImportPostman(t *testing.T) {
+	codes := stubCommand(t)
+	stubFiles(map[string]string{
+		"shop.json":    postmanCollection,
+		"staging.json": `{"name": "Staging", "values": [{"key": "baseUrl", "value": "https://staging"}]}`,
+	})
+
+	var saved collection_module.ImportResult
+	SaveImportFunc = func(result collection_module.ImportResult) error {
+		saved = result
+		return nil
+	}
+	var warning, success string
+	LoggerWarn = func(message string, _ int) { warning = message }
+	LoggerSuccess = func(message string, _ int) { success = message }
+
+	runImportCommand("postman", "shop.json", "--environment", "staging.json", "--name", "shop-v2")
+
+	assert.Empty(t, *codes)
+	assert.Equal(t, "shop-v2", saved.Collection.Name)
+	assert.Len(t, saved.Collection.Requests, 1)
+	assert.Equal(t, "Staging", saved.Environments[0].Name)
+	assert.Contains(t, success, "Imported 1 requests into the collection \"shop-v2\" and the environments Staging")
+	assert.True(t, strings.Contains(warning, "the test script"), "the skipped script should be reported")
+}
+
+func TestImportPostman_Errors(t *testing.T) {
+	codes := stubCommand(t)
+	stubFiles(map[string]string{"shop.json": postmanCollection, "broken.json": "{"})
+	saved := false
+	SaveImportFunc = func(collection_module.ImportResult) error {
+		saved = true
+		return nil
+	}
+
+	runImportCommand("postman", "missing.json")
+	runImportCommand("postman", "broken.json")
+	runImportCommand("postman", "shop.json", "--environment", "missing.json")
+
+	assert.Equal(t, []int{1, 1, 1}, *codes)
+	assert.False(t, saved)
+}
+
+func TestImportInsomnia(t *testing.T) {
+	codes := stubCommand(t)
+	stubFiles(map[string]string{"insomnia.json": `{"_type": "export", "__export_format": 4, "resources": [
+		{"_id": "wrk_1", "_type": "workspace", "name": "Shop"},
+		{"_id": "req_1", "_type": "request", "parentId": "wrk_1", "name": "Ping", "method": "GET", "url": "https://api/ping"}]}`})
+
+	var saved collection_module.ImportResult
+	SaveImportFunc = func(result collection_module.ImportResult) error {
+		saved = result
+		return nil
+	}
+
+	runImportCommand("insomnia", "insomnia.json")
+
+	assert.Empty(t, *codes)
+	assert.Equal(t, "Shop", saved.Collection.Name)
+	assert.Equal(t, "https://api/ping", saved.Collection.Requests[0].Request.Url)
+
+	SaveImportFunc = func(collection_module.ImportResult) error { return errors.New("read-only") }
+	runImportCommand("insomnia", "insomnia.json")
+	assert.Equal(t, []int{1}, *codes)
+}
+
+func stubOpenApi(spec string) {
+	LoadOpenApiFunc = func(path string) (*openapi_module.Spec, error) {
+		if path != "api.yaml" {
+			return nil, os.ErrNotExist
+		}
+		return openapi_module.Parse([]byte(spec))
+	}
+}
+
+const openApiSpec = `
+openapi: 3.0.0
+info: {title: Users}
+servers: [{url: https://api.example.com}]
+paths:
+  /users/{id}:
+    get:
+      tags: [users]
+      parameters: [{name: id, in: path, required: true}]
+      responses: {200: {description: OK}}
+`
+
+func TestImportOpenApi(t *testing.T) {
+	codes := stubCommand(t)
+	stubOpenApi(openApiSpec)
+
+	var saved collection_module.ImportResult
+	SaveImportFunc = func(result collection_module.ImportResult) error {
+		saved = result
+		return nil
+	}
+	var browsed string
+	OpenApiMenuNewFunc = func(spec *openapi_module.Spec, collection string) { browsed = collection }
+
+	runImportCommand("openapi", "api.yaml")
+
+	assert.Empty(t, *codes)
+	assert.Equal(t, "Users", saved.Collection.Name)
+	assert.Equal(t, "{{baseUrl}}/users/{{id}}", saved.Collection.Requests[0].Request.Url)
+	assert.Empty(t, browsed, "the browser should only open with --browse")
+
+	runImportCommand("openapi", "api.yaml", "--name", "users-v2", "--browse")
+	assert.Equal(t, "users-v2", browsed)
+}
+
+func TestImportOpenApi_Errors(t *testing.T) {
+	codes := stubCommand(t)
+	stubOpenApi(`{"openapi": "3.1.0", "info": {"title": "Empty"}}`)
+	SaveImportFunc = func(collection_module.ImportResult) error { return errors.New("read-only") }
+	browsed := false
+	OpenApiMenuNewFunc = func(*openapi_module.Spec, string) { browsed = true }
+
+	runImportCommand("openapi", "missing.yaml")
+	runImportCommand("openapi", "api.yaml")
+
+	stubOpenApi(openApiSpec)
+	runImportCommand("openapi", "api.yaml", "--browse")
+
+	assert.Equal(t, []int{1, 1, 1}, *codes)
+	assert.False(t, browsed, "the browser should not open when the import failed")
+}
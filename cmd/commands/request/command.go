@@ -1,17 +1,36 @@
 package request_command
 
 import (
-	"net/http"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"strings"
 	"time"
 
+	auth_module "github.com/diogopereiradev/httpzen/internal/auth"
+	collection_module "github.com/diogopereiradev/httpzen/internal/collection"
+	progress_bar_component "github.com/diogopereiradev/httpzen/internal/components/progress_bar"
+	"github.com/diogopereiradev/httpzen/internal/components/prompt"
+	config_module "github.com/diogopereiradev/httpzen/internal/config"
+	environment_module "github.com/diogopereiradev/httpzen/internal/environment"
+	graphql_module "github.com/diogopereiradev/httpzen/internal/graphql"
+	headless_module "github.com/diogopereiradev/httpzen/internal/headless"
+	history_module "github.com/diogopereiradev/httpzen/internal/history"
 	logger_module "github.com/diogopereiradev/httpzen/internal/logger"
 	"github.com/diogopereiradev/httpzen/internal/menus/body_menu"
 	"github.com/diogopereiradev/httpzen/internal/menus/request_menu"
+	openapi_module "github.com/diogopereiradev/httpzen/internal/openapi"
 	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	script_module "github.com/diogopereiradev/httpzen/internal/script"
+	snapshot_module "github.com/diogopereiradev/httpzen/internal/snapshot"
 	"github.com/diogopereiradev/httpzen/internal/utils/http_utility"
+	watch_module "github.com/diogopereiradev/httpzen/internal/watch"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 type RequestFlags struct {
@@ -19,22 +38,659 @@ type RequestFlags struct {
 	Body    bool
 }
 
+type GraphQLFlags struct {
+	Query         string
+	Variables     string
+	Operation     string
+	RefreshSchema bool
+}
+
+type AuthFlags struct {
+	Type         string
+	User         string
+	Token        string
+	TokenUrl     string
+	ClientId     string
+	ClientSecret string
+	Scopes       string
+	Environment  string
+	Save         bool
+}
+
 var Exit = os.Exit
 var RunRequestFunc = request_module.RunRequest
 var BodyMenuNewFunc = body_menu.New
 var RequestMenuNewFunc = request_menu.New
+var RequestMenuStreamFunc = request_menu.NewStream
+var RequestMenuDownloadFunc = request_menu.NewDownload
+var PromptNewFunc = prompt.New
+var LoggerWarn = logger_module.Warn
+var LoggerSuccess = logger_module.Success
+var GetConfigFunc = config_module.GetConfig
+var GetEnvironmentFunc = environment_module.GetEnvironment
+var SaveEnvironmentAuthFunc = environment_module.SaveEnvironmentAuth
+var AddHistoryFunc = history_module.Add
+var GetGraphQLSchemaFunc = graphql_module.GetSchema
+var LoadValidatorFunc = openapi_module.LoadValidator
+var HeadlessPrintFunc = headless_module.Print
+var HeadlessPrintScriptFunc = headless_module.PrintScript
+var LoadScriptFunc = script_module.Load
+var WatchFunc = watch_module.Watch
+var SaveSnapshotFunc = snapshot_module.SaveSnapshot
+var ReadFileFunc = os.ReadFile
+var StatFunc = os.Stat
+var UploadProgressOutput io.Writer = os.Stderr
+var IsTerminalFunc = func() bool { return term.IsTerminal(int(os.Stderr.Fd())) }
+
+var parseHeaders = http_utility.ParseHeaders
+
+func getAuthFlags(cmd *cobra.Command) AuthFlags {
+	authType, _ := cmd.Flags().GetString("auth")
+	user, _ := cmd.Flags().GetString("user")
+	token, _ := cmd.Flags().GetString("token")
+	tokenUrl, _ := cmd.Flags().GetString("token-url")
+	clientId, _ := cmd.Flags().GetString("client-id")
+	clientSecret, _ := cmd.Flags().GetString("client-secret")
+	scopes, _ := cmd.Flags().GetString("scope")
+	env, _ := cmd.Flags().GetString("env")
+	save, _ := cmd.Flags().GetBool("save-auth")
+
+	return AuthFlags{
+		Type:         authType,
+		User:         user,
+		Token:        token,
+		TokenUrl:     tokenUrl,
+		ClientId:     clientId,
+		ClientSecret: clientSecret,
+		Scopes:       scopes,
+		Environment:  env,
+		Save:         save,
+	}
+}
+
+// resolveAuth builds the auth options from the command line flags, falling
+// back to the auth saved in the selected environment when no --auth is given.
+func resolveAuth(flags AuthFlags) (auth_module.AuthOptions, error) {
+	envName := flags.Environment
+	if envName == "" {
+		envName = GetConfigFunc().ActiveEnvironment
+	}
+
+	if flags.Type == "" {
+		if flags.Save {
+			return auth_module.AuthOptions{}, errors.New("--save-auth requires --auth to be set")
+		}
+		return GetEnvironmentFunc(envName).Auth, nil
+	}
+
+	authType, err := auth_module.ParseAuthType(flags.Type)
+	if err != nil {
+		return auth_module.AuthOptions{}, err
+	}
+
+	auth := auth_module.AuthOptions{
+		Type:         authType,
+		Token:        flags.Token,
+		TokenUrl:     flags.TokenUrl,
+		ClientId:     flags.ClientId,
+		ClientSecret: flags.ClientSecret,
+		Scopes:       flags.Scopes,
+	}
+
+	if authType == auth_module.AuthBasic || authType == auth_module.AuthDigest {
+		username, password, hasPassword := strings.Cut(flags.User, ":")
+		auth.Username = username
+		auth.Password = password
+
+		if !hasPassword && username != "" {
+			PromptNewFunc(prompt.PromptImpl{
+				Title:     "Password for " + username,
+				Password:  true,
+				MaxLength: 256,
+				Events: prompt.PromptEvents{
+					OnSubmit: func(result string) {
+						auth.Password = result
+					},
+				},
+			})
+		}
+	}
+
+	if err := auth.Validate(); err != nil {
+		return auth_module.AuthOptions{}, err
+	}
+
+	if flags.Save {
+		if err := SaveEnvironmentAuthFunc(envName, auth); err != nil {
+			return auth_module.AuthOptions{}, errors.New("failed to save auth to environment: " + err.Error())
+		}
+	}
+	return auth, nil
+}
+
+// readFlagValue returns the flag value, or the content of the file it points
+// to when it starts with '@', like curl does.
+func readFlagValue(value string) (string, error) {
+	path, isFile := strings.CutPrefix(value, "@")
+	if !isFile {
+		return value, nil
+	}
+	content, err := ReadFileFunc(path)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+func getGraphQLFlags(cmd *cobra.Command) (GraphQLFlags, error) {
+	query, _ := cmd.Flags().GetString("graphql")
+	variables, _ := cmd.Flags().GetString("variables")
+	operation, _ := cmd.Flags().GetString("operation")
+	refresh, _ := cmd.Flags().GetBool("refresh-schema")
+
+	var err error
+	if query, err = readFlagValue(query); err != nil {
+		return GraphQLFlags{}, errors.New("failed to read the query: " + err.Error())
+	}
+	if variables, err = readFlagValue(variables); err != nil {
+		return GraphQLFlags{}, errors.New("failed to read the variables: " + err.Error())
+	}
+	if query == "" && (variables != "" || operation != "") {
+		return GraphQLFlags{}, errors.New("--variables and --operation require --graphql")
+	}
+	if strings.TrimSpace(variables) != "" && !json.Valid([]byte(variables)) {
+		return GraphQLFlags{}, errors.New("--variables must be a JSON object")
+	}
+
+	return GraphQLFlags{
+		Query:         query,
+		Variables:     variables,
+		Operation:     operation,
+		RefreshSchema: refresh,
+	}, nil
+}
+
+// getRetryOptions reads the retry flags, the status codes and failure
+// classes fall back to the request module defaults when not given.
+func getRetryOptions(cmd *cobra.Command) (request_module.RetryOptions, error) {
+	attempts, _ := cmd.Flags().GetInt("retry")
+	statusCodes, _ := cmd.Flags().GetString("retry-status")
+	errorClasses, _ := cmd.Flags().GetString("retry-on")
+	backoff, _ := cmd.Flags().GetDuration("retry-backoff")
+	maxWait, _ := cmd.Flags().GetDuration("retry-max-wait")
+
+	if attempts < 0 {
+		return request_module.RetryOptions{}, errors.New("--retry can't be negative")
+	}
+	options := request_module.RetryOptions{Backoff: backoff, MaxWait: maxWait}
+	if attempts > 0 {
+		// --retry counts the retries, the options count every try.
+		options.Attempts = attempts + 1
+	}
+
+	var err error
+	if statusCodes != "" {
+		if options.StatusCodes, err = request_module.ParseRetryStatusCodes(statusCodes); err != nil {
+			return request_module.RetryOptions{}, err
+		}
+	}
+	if errorClasses != "" {
+		if options.ErrorClasses, err = request_module.ParseRetryErrorClasses(errorClasses); err != nil {
+			return request_module.RetryOptions{}, err
+		}
+	}
+	return options, nil
+}
+
+// getTimeouts reads the timeout flags, each one not given falls back to the
+// value in the config.
+func getTimeouts(cmd *cobra.Command) (time.Duration, request_module.Timeouts, error) {
+	timeout, timeouts := request_module.ConfigTimeouts(GetConfigFunc())
+	for flag, duration := range map[string]*time.Duration{
+		"timeout":         &timeout,
+		"connect-timeout": &timeouts.Connect,
+		"tls-timeout":     &timeouts.TlsHandshake,
+		"header-timeout":  &timeouts.ResponseHeader,
+		"idle-timeout":    &timeouts.Idle,
+	} {
+		if cmd.Flags().Changed(flag) {
+			*duration, _ = cmd.Flags().GetDuration(flag)
+		}
+		if *duration < 0 {
+			return 0, request_module.Timeouts{}, errors.New("--" + flag + " can't be negative")
+		}
+	}
+	return timeout, timeouts, nil
+}
+
+// getDownloadOptions reads --download and --checksum, nil means the body is
+// shown rather than saved.
+func getDownloadOptions(cmd *cobra.Command) (*request_module.DownloadOptions, error) {
+	checksum, _ := cmd.Flags().GetString("checksum")
+	if !cmd.Flags().Changed("download") {
+		if checksum != "" {
+			return nil, errors.New("--checksum needs --download")
+		}
+		return nil, nil
+	}
+	if checksum != "" {
+		if _, _, err := request_module.ParseChecksum(checksum); err != nil {
+			return nil, err
+		}
+	}
+	path, _ := cmd.Flags().GetString("download")
+	return &request_module.DownloadOptions{Path: path, Checksum: checksum}, nil
+}
+
+// uploadProgress prints a line with the bytes sent and the speed while a body
+// is streamed from disk. The line is cleared once the request is done, so the
+// viewer opens on a clean screen.
+type uploadProgress struct {
+	out     io.Writer
+	start   time.Time
+	printed bool
+}
+
+func newUploadProgress() *uploadProgress {
+	if !IsTerminalFunc() {
+		return &uploadProgress{}
+	}
+	return &uploadProgress{out: UploadProgressOutput, start: time.Now()}
+}
+
+func (p *uploadProgress) report(sent int64, total int64) {
+	if p.out == nil {
+		return
+	}
+	speed := float64(sent) / max(time.Since(p.start).Seconds(), 0.001)
+
+	line := "Uploading "
+	if total > 0 {
+		line += progress_bar_component.Render(sent, total, 30) + "  " + progress_bar_component.FormatBytes(sent) + " / " + progress_bar_component.FormatBytes(total)
+	} else {
+		line += progress_bar_component.FormatBytes(sent)
+	}
+	line += "  " + progress_bar_component.FormatBytes(int64(speed)) + "/s"
+
+	fmt.Fprint(p.out, "\r\033[K"+line)
+	p.printed = true
+}
+
+func (p *uploadProgress) clear() {
+	if p.printed {
+		fmt.Fprint(p.out, "\r\033[K")
+		p.printed = false
+	}
+}
+
+// loadScripts reads the --pre-script and --post-script files, the runner is
+// nil when there are none. A post-response script needs the whole response,
+// it can't run on a stream or a download.
+func loadScripts(cmd *cobra.Command, noResponse bool) (*script_module.Runner, bool) {
+	prePath, _ := cmd.Flags().GetString("pre-script")
+	postPath, _ := cmd.Flags().GetString("post-script")
+	if prePath == "" && postPath == "" {
+		return nil, true
+	}
+	if postPath != "" && noResponse {
+		logger_module.Error("--post-script can't be used with --stream or --download.", 70)
+		Exit(1)
+		return nil, false
+	}
+
+	var sources [2]string
+	for i, path := range []string{prePath, postPath} {
+		source, err := LoadScriptFunc(path)
+		if err != nil {
+			logger_module.Error("Could not read the script: "+err.Error(), 70)
+			Exit(1)
+			return nil, false
+		}
+		sources[i] = source
+	}
+	return script_module.NewRunner(sources[0], sources[1], nil), true
+}
+
+// getWatchOptions reads --watch and --until, nil means the request is sent
+// once.
+func getWatchOptions(cmd *cobra.Command) (*request_menu.WatchOptions, error) {
+	until, _ := cmd.Flags().GetStringArray("until")
+	if !cmd.Flags().Changed("watch") {
+		if len(until) > 0 {
+			return nil, errors.New("--until needs --watch")
+		}
+		return nil, nil
+	}
+
+	interval, _ := cmd.Flags().GetDuration("watch")
+	if interval <= 0 {
+		return nil, errors.New("--watch must be a positive interval, like 5s")
+	}
+	options := &request_menu.WatchOptions{Interval: interval}
+	for _, value := range until {
+		condition, err := watch_module.ParseCondition(value)
+		if err != nil {
+			return nil, err
+		}
+		options.Until = append(options.Until, condition)
+	}
+	return options, nil
+}
+
+// watchHeadless sends the request until the --until conditions are met, or
+// until ctrl+c, with a line per request on stderr. The last response is
+// printed as usual.
+func watchHeadless(options request_module.RequestOptions, watch *request_menu.WatchOptions) (request_module.RequestResponse, bool, error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	watchOptions := watch_module.Options{Request: options, Interval: watch.Interval, Until: watch.Until}
+	return WatchFunc(ctx, watchOptions, watch_module.NewHistory(), func(sample watch_module.Sample, _ request_module.RequestResponse, _ error) {
+		fmt.Fprintln(headless_module.ErrorOutput, watch_module.FormatSample(sample))
+	})
+}
+
+func untilString(conditions []watch_module.Condition) string {
+	values := make([]string, 0, len(conditions))
+	for _, condition := range conditions {
+		values = append(values, condition.String())
+	}
+	return strings.Join(values, " and ")
+}
+
+// saveSnapshotOf keeps the response for `httpzen snapshot diff`, headless
+// runs report on stderr so stdout only has the response.
+func saveSnapshotOf(name string, res request_module.RequestResponse, headless bool) {
+	if err := SaveSnapshotFunc(name, res); err != nil {
+		LoggerWarn("Could not save the snapshot: "+err.Error(), 70)
+		return
+	}
+	message := "Saved the response as the snapshot \"" + name + "\"."
+	if headless {
+		fmt.Fprintln(headless_module.ErrorOutput, message)
+		return
+	}
+	LoggerSuccess(message, 70)
+}
+
+// requestModes holds what the mode flags turn on: a stream or a download
+// skip the viewer, the others change how the response is shown.
+type requestModes struct {
+	stream       bool
+	download     *request_module.DownloadOptions
+	watch        *request_menu.WatchOptions
+	saveSnapshot string
+	headless     bool
+	validator    *openapi_module.Validator
+	runner       *script_module.Runner
+}
+
+// getRequestModes reads the mode flags and refuses the ones that can't be
+// used together, the command exits when it returns false.
+func getRequestModes(cmd *cobra.Command, body bool, allIps bool, unixSocket string) (requestModes, bool) {
+	modes := requestModes{}
+	modes.stream, _ = cmd.Flags().GetBool("stream")
+	if allIps && (modes.stream || unixSocket != "") {
+		logger_module.Error("--all-ips can't be used with --stream or --unix-socket.", 70)
+		Exit(1)
+		return modes, false
+	}
+
+	ok := getDownloadMode(cmd, &modes, allIps) &&
+		getWatchMode(cmd, &modes) &&
+		getSnapshotMode(cmd, &modes) &&
+		getHeadlessMode(cmd, &modes, body) &&
+		getValidateMode(cmd, &modes)
+	if !ok {
+		return modes, false
+	}
+	modes.runner, ok = loadScripts(cmd, modes.noResponse())
+	return modes, ok
+}
+
+// noResponse reports whether the body isn't kept as a whole response.
+func (m requestModes) noResponse() bool {
+	return m.stream || m.download != nil
+}
+
+func getDownloadMode(cmd *cobra.Command, modes *requestModes, allIps bool) bool {
+	download, err := getDownloadOptions(cmd)
+	if err != nil {
+		logger_module.Error("Invalid download options: "+err.Error(), 70)
+		Exit(1)
+		return false
+	}
+	if download != nil && (modes.stream || allIps) {
+		logger_module.Error("--download can't be used with --stream or --all-ips.", 70)
+		Exit(1)
+		return false
+	}
+	modes.download = download
+	return true
+}
+
+func getWatchMode(cmd *cobra.Command, modes *requestModes) bool {
+	watch, err := getWatchOptions(cmd)
+	if err != nil {
+		logger_module.Error("Invalid watch options: "+err.Error(), 70)
+		Exit(1)
+		return false
+	}
+	if watch != nil && modes.noResponse() {
+		logger_module.Error("--watch can't be used with --stream or --download.", 70)
+		Exit(1)
+		return false
+	}
+	modes.watch = watch
+	return true
+}
+
+func getSnapshotMode(cmd *cobra.Command, modes *requestModes) bool {
+	saveSnapshot, _ := cmd.Flags().GetString("save-snapshot")
+	if saveSnapshot != "" && modes.noResponse() {
+		logger_module.Error("--save-snapshot can't be used with --stream or --download.", 70)
+		Exit(1)
+		return false
+	}
+	modes.saveSnapshot = saveSnapshot
+	return true
+}
+
+func getHeadlessMode(cmd *cobra.Command, modes *requestModes, body bool) bool {
+	headless, _ := cmd.Flags().GetBool("headless")
+	validatePath, _ := cmd.Flags().GetString("validate")
+	if (headless || validatePath != "") && modes.noResponse() {
+		logger_module.Error("--headless and --validate can't be used with --stream or --download.", 70)
+		Exit(1)
+		return false
+	}
+	if headless && body {
+		logger_module.Error("--headless can't be used with --body, send the body with data items or --upload-file.", 70)
+		Exit(1)
+		return false
+	}
+	modes.headless = headless
+	return true
+}
+
+func getValidateMode(cmd *cobra.Command, modes *requestModes) bool {
+	validatePath, _ := cmd.Flags().GetString("validate")
+	if validatePath == "" {
+		return true
+	}
+	validator, err := LoadValidatorFunc(validatePath)
+	if err != nil {
+		logger_module.Error("Could not load the spec or schema to validate against: "+err.Error(), 70)
+		Exit(1)
+		return false
+	}
+	modes.validator = validator
+	return true
+}
+
+// runPreScript runs the --pre-script on the request, the command exits when
+// it returns false.
+func runPreScript(modes requestModes, options *request_module.RequestOptions) bool {
+	if modes.runner == nil {
+		return true
+	}
+	if err := modes.runner.BeforeRequest(options); err != nil {
+		if modes.headless {
+			HeadlessPrintScriptFunc(modes.runner.PreResult())
+		} else {
+			logger_module.Error(err.Error(), 70)
+		}
+		Exit(1)
+		return false
+	}
+	// The variables set by the script fill the {{name}} references.
+	*options = collection_module.Collection{}.Resolve(collection_module.SavedRequest{Request: *options}, modes.runner.Variables)
+	return true
+}
+
+func sendDownload(options request_module.RequestOptions) {
+	if err := RequestMenuDownloadFunc(options); err != nil {
+		Exit(request_module.ExitCode(err))
+	}
+}
+
+func sendStream(options request_module.RequestOptions) {
+	if err := RequestMenuStreamFunc(options, request_menu.Options{}); err != nil {
+		Exit(request_module.ExitCode(err))
+	}
+}
+
+// send sends the request once, or until the --until conditions are met when
+// a headless run watches it, and saves it to the history and the snapshot.
+// met reports whether the conditions were met.
+func send(options request_module.RequestOptions, modes requestModes) (request_module.RequestResponse, bool, error) {
+	progress := newUploadProgress()
+	options.OnUploadProgress = progress.report
+	met := false
+	run := func(options request_module.RequestOptions) (request_module.RequestResponse, error) {
+		// The progress line is cleared before a history warning or the
+		// response is printed.
+		defer progress.clear()
+		if modes.watch != nil && modes.headless {
+			var res request_module.RequestResponse
+			var err error
+			res, met, err = watchHeadless(options, modes.watch)
+			return res, err
+		}
+		return RunRequestFunc(options)
+	}
+	// The history feeds `export har --history`.
+	res, err := history_module.Send(options, run, AddHistoryFunc, LoggerWarn)
+	if err == nil && modes.saveSnapshot != "" {
+		saveSnapshotOf(modes.saveSnapshot, res, modes.headless)
+	}
+	return res, met, err
+}
 
-func parseHeaders(headers []string) http.Header {
-	result := http.Header{}
-	for _, header := range headers {
-		parts := strings.SplitN(header, ":", 2)
-		if len(parts) == 2 {
-			key := strings.TrimSpace(parts[0])
-			value := strings.TrimSpace(parts[1])
-			result.Add(key, value)
+// printHeadless prints the response with its validation and script results,
+// and exits with the first code that isn't 0.
+func printHeadless(res request_module.RequestResponse, met bool, err error, modes requestModes) {
+	var validation *openapi_module.Validation
+	if modes.validator != nil && err == nil {
+		result := modes.validator.Validate(res)
+		validation = &result
+	}
+	code := HeadlessPrintFunc(res, err, validation)
+	if modes.runner != nil {
+		result := modes.runner.PreResult()
+		if err == nil {
+			result = modes.runner.AfterResponse(res)
+		}
+		if scriptCode := HeadlessPrintScriptFunc(result); code == 0 {
+			code = scriptCode
 		}
 	}
-	return result
+	// A watch stopped before its conditions were met failed.
+	if modes.watch != nil && len(modes.watch.Until) > 0 && !met && code == 0 {
+		code = 1
+	}
+	if code != 0 {
+		Exit(code)
+	}
+}
+
+// openViewer shows the response, a watch exits with 1 when the viewer is
+// closed before its conditions were met.
+func openViewer(res request_module.RequestResponse, err error, modes requestModes) {
+	watch := modes.watch
+	if watch != nil && watch_module.Met(watch.Until, res, err) {
+		LoggerSuccess("The response already meets "+untilString(watch.Until)+".", 70)
+		return
+	}
+	viewer := request_menu.Options{Watch: watch}
+	if modes.validator != nil {
+		viewer.Validate = modes.validator.Validate
+	}
+	if modes.runner != nil {
+		viewer.Script = modes.runner.AfterResponse
+	}
+	err = RequestMenuNewFunc(&res, err, viewer)
+	if watch != nil && watch.Met {
+		LoggerSuccess("The response meets "+untilString(watch.Until)+".", 70)
+		return
+	}
+	if err != nil {
+		Exit(request_module.ExitCode(err))
+		return
+	}
+	if watch != nil && len(watch.Until) > 0 {
+		Exit(1)
+	}
+}
+
+// validateGraphQL checks the query against the introspected schema before it
+// is sent. A server without introspection only gets a warning, problems in the
+// query let the user decide whether to send it anyway.
+func validateGraphQL(options request_module.RequestOptions, query string, operation string, refresh bool) bool {
+	schema, err := GetGraphQLSchemaFunc(options, refresh)
+	if err != nil {
+		LoggerWarn("Could not load the GraphQL schema, the query is sent without validation: "+err.Error(), 70)
+		return true
+	}
+
+	problems := graphql_module.Validate(schema, query, operation)
+	if len(problems) == 0 {
+		return true
+	}
+
+	LoggerWarn("The GraphQL query does not match the schema:\n  "+strings.Join(problems, "\n  "), 70)
+
+	confirmed := false
+	PromptNewFunc(prompt.PromptImpl{
+		Title:          "Send the query anyway?",
+		Boolean:        true,
+		BooleanDefault: false,
+		Events: prompt.PromptEvents{
+			OnSubmit: func(result string) {
+				confirmed = result == "y"
+			},
+		},
+	})
+	return confirmed
+}
+
+// confirmBodyForMethod warns that servers usually ignore or reject a body on
+// the given method and lets the user decide whether to send it anyway.
+func confirmBodyForMethod(method string) bool {
+	LoggerWarn("A body in "+method+" requests is usually ignored or rejected by servers. Use --force-body to skip this warning.", 70)
+
+	confirmed := false
+	PromptNewFunc(prompt.PromptImpl{
+		Title:          "Send a body with " + method + " anyway?",
+		Boolean:        true,
+		BooleanDefault: false,
+		Events: prompt.PromptEvents{
+			OnSubmit: func(result string) {
+				confirmed = result == "y"
+			},
+		},
+	})
+	return confirmed
 }
 
 func Init(rootCmd *cobra.Command) {
@@ -43,17 +699,36 @@ func Init(rootCmd *cobra.Command) {
 			cmd.Help()
 			return
 		}
+		allowCustomMethod, _ := cmd.Flags().GetBool("custom-method")
 		method := http_utility.ParseHttpMethod(args[0])
+		if method == "" && allowCustomMethod {
+			method = http_utility.ParseCustomHttpMethod(args[0])
+		}
 		if method == "" {
-			logger_module.Error("Invalid HTTP method. Please provide a valid HTTP method (GET, POST, PATCH, PUT, DELETE, HEAD).", 70)
+			if allowCustomMethod {
+				logger_module.Error("Invalid HTTP method. Custom methods must be uppercase tokens, like PURGE or X-SYNC.", 70)
+			} else {
+				logger_module.Error("Invalid HTTP method. Please provide a valid HTTP method (GET, POST, PUT, PATCH, DELETE, HEAD, OPTIONS, TRACE or a WebDAV method), or use --custom-method.", 70)
+			}
 			Exit(1)
+			return
 		}
 
 		url := http_utility.ParseUrl(args[1])
 		if url == "" {
-			logger_module.Error("Invalid URL. Please provide a valid URL (http:// or https://).", 70)
+			logger_module.Error("Invalid URL. Please provide a valid URL (http://, https:// or http+unix://).", 70)
+			Exit(request_module.ExitCodes[request_module.ErrorClassInvalidUrl])
+			return
+		}
+
+		form, _ := cmd.Flags().GetBool("form")
+		items, err := http_utility.ParseRequestItems(args[2:], form)
+		if err != nil {
+			logger_module.Error(err.Error(), 70)
 			Exit(1)
+			return
 		}
+		url = http_utility.AddQuery(url, items.Query)
 
 		headers, _ := cmd.Flags().GetStringSlice("header")
 		flags := RequestFlags{
@@ -61,29 +736,193 @@ func Init(rootCmd *cobra.Command) {
 			Body:    cmd.Flag("body").Value.String() == "true",
 		}
 
-		if flags.Body && (method == "GET" || method == "HEAD") {
-			logger_module.Error("Body cannot be included in GET or HEAD requests.", 70)
+		graphqlFlags, err := getGraphQLFlags(cmd)
+		if err != nil {
+			logger_module.Error("Invalid GraphQL options: "+err.Error(), 70)
+			Exit(1)
+			return
+		}
+		uploadFile, _ := cmd.Flags().GetString("upload-file")
+		bodySources := 0
+		for _, given := range []bool{flags.Body, graphqlFlags.Query != "", uploadFile != "", items.Body != nil} {
+			if given {
+				bodySources++
+			}
+		}
+		if bodySources > 1 {
+			logger_module.Error("Only one body can be sent: --body, --graphql, --upload-file or data items.", 70)
+			Exit(1)
+			return
+		}
+		files := items.Files
+		if uploadFile != "" {
+			files = append(files, uploadFile)
+		}
+		for _, file := range files {
+			if info, err := StatFunc(file); err != nil || info.IsDir() {
+				logger_module.Error("Invalid upload file: "+file+" is not a readable file.", 70)
+				Exit(1)
+				return
+			}
+		}
+		hasBody := bodySources > 0
+
+		forceBody, _ := cmd.Flags().GetBool("force-body")
+		if hasBody && !forceBody && !http_utility.HttpMethodAllowsBody(method) {
+			if !confirmBodyForMethod(method) {
+				Exit(1)
+				return
+			}
+		}
+
+		protocolFlag, _ := cmd.Flags().GetString("protocol")
+		protocol, err := request_module.ParseProtocol(protocolFlag)
+		if err != nil {
+			logger_module.Error(err.Error(), 70)
+			Exit(1)
+			return
+		}
+
+		retry, err := getRetryOptions(cmd)
+		if err != nil {
+			logger_module.Error("Invalid retry options: "+err.Error(), 70)
 			Exit(1)
 			return
 		}
 
+		timeout, timeouts, err := getTimeouts(cmd)
+		if err != nil {
+			logger_module.Error("Invalid timeout: "+err.Error(), 70)
+			Exit(1)
+			return
+		}
+
+		auth, err := resolveAuth(getAuthFlags(cmd))
+		if err != nil {
+			logger_module.Error("Invalid authentication options: "+err.Error(), 70)
+			Exit(request_module.ExitCodes[request_module.ErrorClassAuth])
+			return
+		}
+
+		unixSocket, _ := cmd.Flags().GetString("unix-socket")
+		resolve, _ := cmd.Flags().GetStringArray("resolve")
+		connectTo, _ := cmd.Flags().GetStringArray("connect-to")
+		allIps, _ := cmd.Flags().GetBool("all-ips")
+
+		modes, ok := getRequestModes(cmd, flags.Body, allIps, unixSocket)
+		if !ok {
+			return
+		}
+
+		requestHeaders := parseHeaders(flags.Headers)
+		for key, values := range items.Headers {
+			for _, value := range values {
+				requestHeaders.Add(key, value)
+			}
+		}
+
 		requestOptions := request_module.RequestOptions{
-			Url:     url,
-			Headers: parseHeaders(flags.Headers),
-			Method:  method,
-			Timeout: 30 * time.Second,
+			Url:          url,
+			Headers:      requestHeaders,
+			Method:       method,
+			CustomMethod: allowCustomMethod,
+			Timeout:      timeout,
+			Timeouts:     timeouts,
+			Auth:         auth,
+			Protocol:     protocol,
+			UnixSocket:   unixSocket,
+			Resolve:      resolve,
+			ConnectTo:    connectTo,
+			AllIps:       allIps,
+			Retry:        retry,
+			Download:     modes.download,
 		}
 
 		var body []http_utility.HttpContentData
-		if flags.Body {
+		if items.Body != nil {
+			body = items.Body
+			// A raw =@file body takes its type from -H too.
+			if contentType := requestOptions.Headers.Get("Content-Type"); body[0].Key == http_utility.FileBodyKey && contentType != "" {
+				body[0].ContentType = contentType
+			}
+		} else if uploadFile != "" {
+			body = http_utility.NewFileBody(uploadFile, requestOptions.Headers.Get("Content-Type"))
+		} else if graphqlFlags.Query != "" {
+			body = http_utility.NewGraphQLBody(graphqlFlags.Query, graphqlFlags.Variables, graphqlFlags.Operation)
+		} else if flags.Body {
 			BodyMenuNewFunc(&requestOptions, &body)
 		}
 		requestOptions.Body = body
 
-		res := RunRequestFunc(requestOptions)
-		RequestMenuNewFunc(&res)
+		if !runPreScript(modes, &requestOptions) {
+			return
+		}
+
+		if query, _, operation, ok := http_utility.GetGraphQLBody(body); ok {
+			if !validateGraphQL(requestOptions, query, operation, graphqlFlags.RefreshSchema) {
+				Exit(1)
+				return
+			}
+		}
+
+		switch {
+		case modes.download != nil:
+			sendDownload(requestOptions)
+		case modes.stream:
+			sendStream(requestOptions)
+		default:
+			res, met, err := send(requestOptions, modes)
+			if modes.headless {
+				printHeadless(res, met, err, modes)
+			} else {
+				openViewer(res, err, modes)
+			}
+		}
 	}
 
 	rootCmd.Flags().BoolP("body", "b", false, "Include body in the request (default: false)")
 	rootCmd.Flags().StringSliceP("header", "H", []string{}, "Add a header to the request (can be used multiple times)")
+	rootCmd.Flags().BoolP("form", "f", false, "Send data items as a form instead of JSON, multipart when a file is uploaded")
+	rootCmd.Flags().StringP("upload-file", "T", "", "Send a file as the raw body, streamed from disk (Content-Type from -H or the extension)")
+	rootCmd.Flags().Bool("force-body", false, "Send a body even with methods that don't expect one, like GET")
+	rootCmd.Flags().BoolP("stream", "S", false, "Show the response as it arrives, for Server-Sent Events, NDJSON or chunked bodies")
+	rootCmd.Flags().String("download", "", "Save the body to a file or directory, resuming a partial download (use --download=path)")
+	rootCmd.Flags().Lookup("download").NoOptDefVal = "."
+	rootCmd.Flags().String("checksum", "", "Verify the download against algorithm:hex, with sha256, sha512, sha1 or md5")
+	rootCmd.Flags().String("graphql", "", "Send a GraphQL query, or @file to read it from a file")
+	rootCmd.Flags().String("variables", "", "GraphQL variables as a JSON object, or @file")
+	rootCmd.Flags().String("operation", "", "GraphQL operation to run when the query defines several")
+	rootCmd.Flags().Bool("refresh-schema", false, "Fetch the GraphQL schema again instead of using the cached one")
+	rootCmd.Flags().String("protocol", "", "Force the HTTP version: http1.1, http2, h2c (cleartext HTTP/2) or http3")
+	rootCmd.Flags().String("unix-socket", "", "Connect through a Unix domain socket, like /var/run/docker.sock")
+	rootCmd.Flags().StringArray("resolve", []string{}, "Use an address for a host as host:port:addr[,addr] (can be used multiple times)")
+	rootCmd.Flags().StringArray("connect-to", []string{}, "Connect to another host and port as HOST1:PORT1:HOST2:PORT2 (can be used multiple times)")
+	rootCmd.Flags().Bool("all-ips", false, "Also send the request to every address of the host and compare the results")
+	rootCmd.Flags().Int("retry", 0, "Retry transient failures up to this many times")
+	rootCmd.Flags().String("retry-status", "", "Status codes to retry, comma separated (default: 429,502,503,504)")
+	rootCmd.Flags().String("retry-on", "", "Failure classes to retry for any method, comma separated (default: connection_refused,timeout,protocol, for idempotent methods only)")
+	rootCmd.Flags().Duration("retry-backoff", request_module.DefaultRetryBackoff, "Wait before the first retry, doubled on every retry with some jitter")
+	rootCmd.Flags().Duration("retry-max-wait", request_module.DefaultRetryMaxWait, "Longest wait between retries, a longer Retry-After stops retrying")
+	rootCmd.Flags().Duration("timeout", 0, "Time limit for the whole request, 0 for none (default: from the config, 30s)")
+	rootCmd.Flags().Duration("connect-timeout", 0, "Time limit to open the connection")
+	rootCmd.Flags().Duration("tls-timeout", 0, "Time limit for the TLS handshake")
+	rootCmd.Flags().Duration("header-timeout", 0, "Time limit to wait for the response headers once the request is sent")
+	rootCmd.Flags().Duration("idle-timeout", 0, "Longest wait between two chunks of the response body")
+	rootCmd.Flags().Bool("headless", false, "Print the response instead of opening the viewer, for scripts and CI")
+	rootCmd.Flags().String("validate", "", "Check the response against an OpenAPI 3 spec or a JSON Schema file, headless runs exit with 65 when it doesn't match")
+	rootCmd.Flags().String("pre-script", "", "JavaScript file run before the request, it can change the request and set {{variables}}")
+	rootCmd.Flags().String("post-script", "", "JavaScript file run on the response, with test() assertions, headless runs exit with 66 when one fails")
+	rootCmd.Flags().Duration("watch", 0, "Send the request again on this interval, like 5s, with a status timeline and latency sparkline")
+	rootCmd.Flags().StringArray("until", []string{}, "Stop watching once the response meets a condition, like status=200, latency<500ms or body~ready (can be used multiple times)")
+	rootCmd.Flags().String("save-snapshot", "", "Save the response as a named snapshot, to diff later responses against it with httpzen snapshot diff")
+	rootCmd.Flags().Bool("custom-method", false, "Allow non-standard methods written as uppercase tokens, like PURGE")
+	rootCmd.Flags().StringP("auth", "A", "", "Authentication type: basic, bearer, digest or oauth2")
+	rootCmd.Flags().StringP("user", "u", "", "Basic/digest credentials as user[:password]")
+	rootCmd.Flags().String("token", "", "Token used by bearer auth")
+	rootCmd.Flags().String("token-url", "", "OAuth2 token endpoint for the client credentials flow")
+	rootCmd.Flags().String("client-id", "", "OAuth2 client ID")
+	rootCmd.Flags().String("client-secret", "", "OAuth2 client secret")
+	rootCmd.Flags().String("scope", "", "OAuth2 scopes, space separated")
+	rootCmd.Flags().StringP("env", "e", "", "Environment to load saved auth from")
+	rootCmd.Flags().Bool("save-auth", false, "Save the given auth options to the environment")
 }
@@ -1,14 +1,30 @@
 package request_command
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"net/http"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
 
+	auth_module "github.com/diogopereiradev/httpzen/internal/auth"
+	"github.com/diogopereiradev/httpzen/internal/components/prompt"
+	config_module "github.com/diogopereiradev/httpzen/internal/config"
+	environment_module "github.com/diogopereiradev/httpzen/internal/environment"
+	headless_module "github.com/diogopereiradev/httpzen/internal/headless"
+	"github.com/diogopereiradev/httpzen/internal/menus/request_menu"
+	openapi_module "github.com/diogopereiradev/httpzen/internal/openapi"
 	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	script_module "github.com/diogopereiradev/httpzen/internal/script"
 	http_utility "github.com/diogopereiradev/httpzen/internal/utils/http_utility"
+	watch_module "github.com/diogopereiradev/httpzen/internal/watch"
 )
 
 type exitCalled struct{ code int }
@@ -55,11 +71,115 @@ func Test_parseHeaders(t *testing.T) {
 	}
 }
 
+func Test_resolveAuth(t *testing.T) {
+	oldGetConfig := GetConfigFunc
+	oldGetEnvironment := GetEnvironmentFunc
+	oldSaveEnvironmentAuth := SaveEnvironmentAuthFunc
+	oldPrompt := PromptNewFunc
+	defer func() {
+		GetConfigFunc = oldGetConfig
+		GetEnvironmentFunc = oldGetEnvironment
+		SaveEnvironmentAuthFunc = oldSaveEnvironmentAuth
+		PromptNewFunc = oldPrompt
+	}()
+
+	GetConfigFunc = func() config_module.Config {
+		return config_module.Config{ActiveEnvironment: "default"}
+	}
+	var requestedEnv string
+	GetEnvironmentFunc = func(name string) environment_module.Environment {
+		requestedEnv = name
+		return environment_module.Environment{
+			Name: name,
+			Auth: auth_module.AuthOptions{Type: auth_module.AuthBearer, Token: "saved"},
+		}
+	}
+
+	t.Run("falls back to the active environment", func(t *testing.T) {
+		auth, err := resolveAuth(AuthFlags{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if requestedEnv != "default" || auth.Token != "saved" {
+			t.Errorf("expected auth from the default environment, got %+v from %q", auth, requestedEnv)
+		}
+	})
+
+	t.Run("uses the selected environment", func(t *testing.T) {
+		_, _ = resolveAuth(AuthFlags{Environment: "staging"})
+		if requestedEnv != "staging" {
+			t.Errorf("expected staging environment, got %q", requestedEnv)
+		}
+	})
+
+	t.Run("basic with inline password", func(t *testing.T) {
+		auth, err := resolveAuth(AuthFlags{Type: "basic", User: "alice:secret"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if auth.Username != "alice" || auth.Password != "secret" {
+			t.Errorf("unexpected credentials %+v", auth)
+		}
+	})
+
+	t.Run("basic prompts for the password", func(t *testing.T) {
+		PromptNewFunc = func(options prompt.PromptImpl) {
+			if !options.Password {
+				t.Error("expected a password prompt")
+			}
+			options.Events.OnSubmit("Prompted")
+		}
+		auth, err := resolveAuth(AuthFlags{Type: "digest", User: "alice"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if auth.Password != "Prompted" {
+			t.Errorf("expected prompted password, got %q", auth.Password)
+		}
+	})
+
+	t.Run("invalid auth type", func(t *testing.T) {
+		if _, err := resolveAuth(AuthFlags{Type: "ntlm"}); err == nil {
+			t.Error("expected error for unsupported auth type")
+		}
+	})
+
+	t.Run("missing bearer token", func(t *testing.T) {
+		if _, err := resolveAuth(AuthFlags{Type: "bearer"}); err == nil {
+			t.Error("expected error for bearer without token")
+		}
+	})
+
+	t.Run("save requires auth", func(t *testing.T) {
+		if _, err := resolveAuth(AuthFlags{Save: true}); err == nil {
+			t.Error("expected error when saving without --auth")
+		}
+	})
+
+	t.Run("saves to environment", func(t *testing.T) {
+		var savedEnv string
+		var saved auth_module.AuthOptions
+		SaveEnvironmentAuthFunc = func(name string, auth auth_module.AuthOptions) error {
+			savedEnv = name
+			saved = auth
+			return nil
+		}
+		_, err := resolveAuth(AuthFlags{Type: "oauth2", TokenUrl: "http://auth", ClientId: "id", Environment: "prod", Save: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if savedEnv != "prod" || saved.ClientId != "id" {
+			t.Errorf("expected auth saved to prod, got %q %+v", savedEnv, saved)
+		}
+	})
+}
+
 func Test_Init(t *testing.T) {
 	var (
 		calledBodyMenu    bool
 		calledRunRequest  bool
 		calledRequestMenu bool
+		viewer            request_menu.Options
 	)
 
 	oldExit := Exit
@@ -73,18 +193,28 @@ func Test_Init(t *testing.T) {
 	defer func() { BodyMenuNewFunc = oldBodyMenu }()
 
 	oldRunRequest := RunRequestFunc
-	RunRequestFunc = func(opts request_module.RequestOptions) request_module.RequestResponse {
+	RunRequestFunc = func(opts request_module.RequestOptions) (request_module.RequestResponse, error) {
 		calledRunRequest = true
-		return request_module.RequestResponse{}
+		return request_module.RequestResponse{}, nil
 	}
 	defer func() { RunRequestFunc = oldRunRequest }()
 
 	oldRequestMenu := RequestMenuNewFunc
-	RequestMenuNewFunc = func(res *request_module.RequestResponse) {
+	RequestMenuNewFunc = func(res *request_module.RequestResponse, err error, options request_menu.Options) error {
 		calledRequestMenu = true
+		viewer = options
+		return err
 	}
 	defer func() { RequestMenuNewFunc = oldRequestMenu }()
 
+	var history []request_module.RequestResponse
+	oldAddHistory := AddHistoryFunc
+	AddHistoryFunc = func(res request_module.RequestResponse) error {
+		history = append(history, res)
+		return nil
+	}
+	defer func() { AddHistoryFunc = oldAddHistory }()
+
 	t.Run("calls help if not enough args", func(t *testing.T) {
 		cmd := &cobra.Command{Use: "test"}
 		Init(cmd)
@@ -127,7 +257,13 @@ func Test_Init(t *testing.T) {
 		cmd.Execute()
 	})
 
-	t.Run("body not allowed for GET", func(t *testing.T) {
+	oldPrompt := PromptNewFunc
+	oldWarn := LoggerWarn
+	defer func() { PromptNewFunc = oldPrompt; LoggerWarn = oldWarn }()
+	LoggerWarn = func(string, int) {}
+
+	t.Run("body on GET declined", func(t *testing.T) {
+		PromptNewFunc = func(options prompt.PromptImpl) { options.Events.OnSubmit("n") }
 		cmd := &cobra.Command{Use: "test"}
 		Init(cmd)
 
@@ -141,7 +277,8 @@ func Test_Init(t *testing.T) {
 		cmd.Execute()
 	})
 
-	t.Run("body not allowed for HEAD", func(t *testing.T) {
+	t.Run("body on HEAD declined", func(t *testing.T) {
+		PromptNewFunc = func(options prompt.PromptImpl) { options.Events.OnSubmit("n") }
 		cmd := &cobra.Command{Use: "test"}
 		Init(cmd)
 
@@ -155,6 +292,82 @@ func Test_Init(t *testing.T) {
 		cmd.Execute()
 	})
 
+	t.Run("body on GET confirmed", func(t *testing.T) {
+		calledBodyMenu = false
+		PromptNewFunc = func(options prompt.PromptImpl) { options.Events.OnSubmit("y") }
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+
+		cmd.SetArgs([]string{"GET", "http://test"})
+		cmd.Flags().Set("body", "true")
+		cmd.Execute()
+		if !calledBodyMenu {
+			t.Error("expected body menu after confirmation")
+		}
+	})
+
+	t.Run("body on GET forced", func(t *testing.T) {
+		calledBodyMenu = false
+		PromptNewFunc = func(options prompt.PromptImpl) { t.Error("prompt should be skipped with --force-body") }
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+
+		cmd.SetArgs([]string{"GET", "http://test"})
+		cmd.Flags().Set("body", "true")
+		cmd.Flags().Set("force-body", "true")
+		cmd.Execute()
+		if !calledBodyMenu {
+			t.Error("expected body menu with --force-body")
+		}
+	})
+
+	t.Run("custom method requires flag", func(t *testing.T) {
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+
+		cmd.SetArgs([]string{"PURGE", "http://test"})
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected exit to be called")
+			}
+		}()
+		cmd.Execute()
+	})
+
+	t.Run("custom method with flag", func(t *testing.T) {
+		var gotMethod string
+		var gotCustom bool
+		RunRequestFunc = func(opts request_module.RequestOptions) (request_module.RequestResponse, error) {
+			gotMethod, gotCustom = opts.Method, opts.CustomMethod
+			return request_module.RequestResponse{}, nil
+		}
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+
+		cmd.SetArgs([]string{"PURGE", "http://test"})
+		cmd.Flags().Set("custom-method", "true")
+		cmd.Execute()
+		if gotMethod != "PURGE" || !gotCustom {
+			t.Errorf("expected PURGE allowed as a custom method, got %q, %v", gotMethod, gotCustom)
+		}
+		RunRequestFunc = func(opts request_module.RequestOptions) (request_module.RequestResponse, error) {
+			calledRunRequest = true
+			return request_module.RequestResponse{}, nil
+		}
+	})
+
+	t.Run("options method", func(t *testing.T) {
+		calledRunRequest = false
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+
+		cmd.SetArgs([]string{"options", "http://test"})
+		cmd.Execute()
+		if !calledRunRequest {
+			t.Error("expected OPTIONS request to run")
+		}
+	})
+
 	t.Run("valid request with body", func(t *testing.T) {
 		calledBodyMenu = false
 		calledRunRequest = false
@@ -170,6 +383,215 @@ func Test_Init(t *testing.T) {
 		}
 	})
 
+	t.Run("records successful requests in the history", func(t *testing.T) {
+		history = nil
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+
+		cmd.SetArgs([]string{"GET", "http://test"})
+		cmd.Execute()
+		if len(history) != 1 {
+			t.Errorf("expected the response in the history, got %d entries", len(history))
+		}
+	})
+
+	t.Run("exits with the failure class code", func(t *testing.T) {
+		history = nil
+		defer func() {
+			if len(history) != 0 {
+				t.Error("expected failed requests to stay out of the history")
+			}
+		}()
+		RunRequestFunc = func(opts request_module.RequestOptions) (request_module.RequestResponse, error) {
+			return request_module.RequestResponse{Request: opts}, &request_module.RequestError{Class: request_module.ErrorClassTimeout}
+		}
+		defer func() {
+			RunRequestFunc = func(opts request_module.RequestOptions) (request_module.RequestResponse, error) {
+				calledRunRequest = true
+				return request_module.RequestResponse{}, nil
+			}
+		}()
+
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+
+		cmd.SetArgs([]string{"GET", "http://test"})
+		defer func() {
+			r := recover()
+			if exit, ok := r.(exitCalled); !ok || exit.code != 28 {
+				t.Errorf("expected exit code 28, got %v", r)
+			}
+		}()
+		cmd.Execute()
+	})
+
+	t.Run("headless prints the response and exits with its code", func(t *testing.T) {
+		calledRequestMenu = false
+		oldPrint := HeadlessPrintFunc
+		defer func() { HeadlessPrintFunc = oldPrint }()
+
+		var printed bool
+		HeadlessPrintFunc = func(res request_module.RequestResponse, err error, validation *openapi_module.Validation) int {
+			printed = true
+			if validation != nil {
+				t.Error("expected no validation without --validate")
+			}
+			return 0
+		}
+
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+		cmd.SetArgs([]string{"GET", "http://test", "--headless"})
+		cmd.Execute()
+		if !printed || calledRequestMenu {
+			t.Error("expected the response to be printed instead of opening the viewer")
+		}
+	})
+
+	t.Run("validate checks the response", func(t *testing.T) {
+		oldPrint, oldLoad := HeadlessPrintFunc, LoadValidatorFunc
+		defer func() {
+			HeadlessPrintFunc, LoadValidatorFunc = oldPrint, oldLoad
+		}()
+
+		LoadValidatorFunc = func(path string) (*openapi_module.Validator, error) {
+			if path != "user.json" {
+				return nil, errors.New("not found")
+			}
+			return &openapi_module.Validator{Source: path, Schema: &openapi_module.Schema{Type: openapi_module.SchemaType{"object"}}}, nil
+		}
+		HeadlessPrintFunc = func(res request_module.RequestResponse, err error, validation *openapi_module.Validation) int {
+			if validation == nil || validation.Passed() {
+				t.Errorf("expected a failed validation, got %+v", validation)
+				return 0
+			}
+			return openapi_module.ValidationExitCode
+		}
+
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+		cmd.SetArgs([]string{"GET", "http://test", "--headless", "--validate", "user.json"})
+		func() {
+			defer func() {
+				if exit, ok := recover().(exitCalled); !ok || exit.code != 65 {
+					t.Errorf("expected exit code 65, got %v", exit)
+				}
+			}()
+			cmd.Execute()
+		}()
+
+		cmd = &cobra.Command{Use: "test"}
+		Init(cmd)
+		cmd.SetArgs([]string{"GET", "http://test", "--validate", "user.json"})
+		cmd.Execute()
+		if viewer.Validate == nil {
+			t.Error("expected the viewer to validate the responses")
+		}
+	})
+
+	t.Run("scripts change the request and test the response", func(t *testing.T) {
+		oldPrint, oldPrintScript, oldLoad, oldRun := HeadlessPrintFunc, HeadlessPrintScriptFunc, LoadScriptFunc, RunRequestFunc
+		defer func() {
+			HeadlessPrintFunc, HeadlessPrintScriptFunc, LoadScriptFunc, RunRequestFunc = oldPrint, oldPrintScript, oldLoad, oldRun
+		}()
+
+		LoadScriptFunc = func(path string) (string, error) {
+			switch path {
+			case "":
+				return "", nil
+			case "pre.js":
+				return `variables.set("id", "42"); request.headers["X-Signed"] = "yes"`, nil
+			case "post.js":
+				return `test("created", function () { assert(response.status === 201, "status " + response.status) })`, nil
+			}
+			return "", errors.New("not found")
+		}
+		var sent request_module.RequestOptions
+		RunRequestFunc = func(opts request_module.RequestOptions) (request_module.RequestResponse, error) {
+			sent = opts
+			return request_module.RequestResponse{StatusCode: 200, Request: opts}, nil
+		}
+		HeadlessPrintFunc = func(request_module.RequestResponse, error, *openapi_module.Validation) int { return 0 }
+		var result script_module.Result
+		HeadlessPrintScriptFunc = func(r script_module.Result) int {
+			result = r
+			if !r.Passed() {
+				return script_module.TestsFailedExitCode
+			}
+			return 0
+		}
+
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+		cmd.SetArgs([]string{"GET", "http://test/users/{{id}}", "--headless", "--pre-script", "pre.js", "--post-script", "post.js"})
+		func() {
+			defer func() {
+				if exit, ok := recover().(exitCalled); !ok || exit.code != script_module.TestsFailedExitCode {
+					t.Errorf("expected exit code 66, got %v", exit)
+				}
+			}()
+			cmd.Execute()
+		}()
+		if sent.Url != "http://test/users/42" || sent.Headers.Get("X-Signed") != "yes" {
+			t.Errorf("expected the pre-request script to change the request, got %s %v", sent.Url, sent.Headers)
+		}
+		if len(result.Tests) != 1 || result.Tests[0].Message != "status 200" {
+			t.Errorf("expected the failed test, got %+v", result.Tests)
+		}
+
+		cmd = &cobra.Command{Use: "test"}
+		Init(cmd)
+		cmd.SetArgs([]string{"GET", "http://test", "--post-script", "post.js"})
+		cmd.Execute()
+		if viewer.Script == nil {
+			t.Error("expected the viewer to run the post-response script")
+		}
+	})
+
+	t.Run("a failing pre-request script stops the request", func(t *testing.T) {
+		oldLoad := LoadScriptFunc
+		defer func() { LoadScriptFunc = oldLoad }()
+		LoadScriptFunc = func(path string) (string, error) { return `throw new Error("no key")`, nil }
+		calledRunRequest = false
+
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+		cmd.SetArgs([]string{"GET", "http://test", "--pre-script", "pre.js"})
+		func() {
+			defer func() {
+				if exit, ok := recover().(exitCalled); !ok || exit.code != 1 {
+					t.Errorf("expected exit code 1, got %v", exit)
+				}
+			}()
+			cmd.Execute()
+		}()
+		if calledRunRequest {
+			t.Error("expected the request not to be sent")
+		}
+	})
+
+	for _, args := range [][]string{
+		{"--validate", "missing.json"},
+		{"--headless", "--stream"},
+		{"--validate", "user.json", "--download"},
+		{"--headless", "--body"},
+		{"--post-script", "post.js", "--stream"},
+		{"--pre-script", "missing.js"},
+	} {
+		t.Run("invalid headless options exit "+strings.Join(args, " "), func(t *testing.T) {
+			cmd := &cobra.Command{Use: "test"}
+			Init(cmd)
+
+			cmd.SetArgs(append([]string{"POST", "https://test"}, args...))
+			defer func() {
+				if _, ok := recover().(exitCalled); !ok {
+					t.Error("expected exit to be called")
+				}
+			}()
+			cmd.Execute()
+		})
+	}
+
 	t.Run("valid request without body", func(t *testing.T) {
 		calledRunRequest = false
 		calledRequestMenu = false
@@ -182,4 +604,711 @@ func Test_Init(t *testing.T) {
 			t.Error("expected RunRequestFunc and RequestMenuNewFunc to be called")
 		}
 	})
+
+	t.Run("stream opens the streaming viewer", func(t *testing.T) {
+		calledRunRequest = false
+		calledRequestMenu = false
+		var streamed request_module.RequestOptions
+
+		oldStreamMenu := RequestMenuStreamFunc
+		RequestMenuStreamFunc = func(opts request_module.RequestOptions, _ request_menu.Options) error {
+			streamed = opts
+			return nil
+		}
+		defer func() { RequestMenuStreamFunc = oldStreamMenu }()
+
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+
+		cmd.SetArgs([]string{"GET", "http://test/events", "--stream"})
+		cmd.Execute()
+		if calledRunRequest || calledRequestMenu {
+			t.Error("expected the buffered request not to run")
+		}
+		if streamed.Url != "http://test/events" || streamed.Method != "GET" {
+			t.Errorf("expected stream options to be passed, got %+v", streamed)
+		}
+	})
+
+	t.Run("stream failure sets the exit code", func(t *testing.T) {
+		oldStreamMenu := RequestMenuStreamFunc
+		RequestMenuStreamFunc = func(opts request_module.RequestOptions, _ request_menu.Options) error {
+			return &request_module.RequestError{Class: request_module.ErrorClassConnectionRefused}
+		}
+		defer func() { RequestMenuStreamFunc = oldStreamMenu }()
+
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+
+		cmd.SetArgs([]string{"GET", "http://test/events", "--stream"})
+		defer func() {
+			r := recover()
+			if exit, ok := r.(exitCalled); !ok || exit.code != 7 {
+				t.Errorf("expected exit code 7, got %v", r)
+			}
+		}()
+		cmd.Execute()
+	})
+
+	t.Run("graphql sends the query after validation", func(t *testing.T) {
+		var sent request_module.RequestOptions
+		RunRequestFunc = func(opts request_module.RequestOptions) (request_module.RequestResponse, error) {
+			sent = opts
+			return request_module.RequestResponse{}, nil
+		}
+		oldSchema := GetGraphQLSchemaFunc
+		GetGraphQLSchemaFunc = func(request_module.RequestOptions, bool) (*ast.Schema, error) {
+			return testSchema, nil
+		}
+		defer func() { GetGraphQLSchemaFunc = oldSchema }()
+		PromptNewFunc = func(options prompt.PromptImpl) { t.Error("a valid query should not prompt") }
+
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+
+		cmd.SetArgs([]string{"POST", "http://test/graphql", "--graphql", "query Q($id: ID) { user(id: $id) }", "--variables", `{"id":"1"}`})
+		cmd.Execute()
+
+		query, variables, _, ok := http_utility.GetGraphQLBody(sent.Body)
+		if !ok || query != "query Q($id: ID) { user(id: $id) }" || variables != `{"id":"1"}` {
+			t.Errorf("expected the GraphQL body to be sent, got %+v", sent.Body)
+		}
+	})
+
+	t.Run("graphql invalid query declined", func(t *testing.T) {
+		calledRunRequest = false
+		RunRequestFunc = func(opts request_module.RequestOptions) (request_module.RequestResponse, error) {
+			calledRunRequest = true
+			return request_module.RequestResponse{}, nil
+		}
+		oldSchema := GetGraphQLSchemaFunc
+		GetGraphQLSchemaFunc = func(request_module.RequestOptions, bool) (*ast.Schema, error) {
+			return testSchema, nil
+		}
+		defer func() { GetGraphQLSchemaFunc = oldSchema }()
+		PromptNewFunc = func(options prompt.PromptImpl) { options.Events.OnSubmit("n") }
+
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+
+		cmd.SetArgs([]string{"POST", "http://test/graphql", "--graphql", "{ unknown }"})
+		defer func() {
+			if _, ok := recover().(exitCalled); !ok {
+				t.Error("expected exit to be called")
+			}
+			if calledRunRequest {
+				t.Error("expected the request not to run")
+			}
+		}()
+		cmd.Execute()
+	})
+
+	t.Run("graphql without schema still sends", func(t *testing.T) {
+		calledRunRequest = false
+		oldSchema := GetGraphQLSchemaFunc
+		GetGraphQLSchemaFunc = func(request_module.RequestOptions, bool) (*ast.Schema, error) {
+			return nil, errors.New("introspection is disabled on this server")
+		}
+		defer func() { GetGraphQLSchemaFunc = oldSchema }()
+
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+
+		cmd.SetArgs([]string{"POST", "http://test/graphql", "--graphql", "{ anything }"})
+		cmd.Execute()
+		if !calledRunRequest {
+			t.Error("expected the request to run without validation")
+		}
+	})
+
+	t.Run("protocol is passed to the request", func(t *testing.T) {
+		var got request_module.RequestOptions
+		RunRequestFunc = func(opts request_module.RequestOptions) (request_module.RequestResponse, error) {
+			got = opts
+			return request_module.RequestResponse{}, nil
+		}
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+
+		cmd.SetArgs([]string{"GET", "https://test", "--protocol", "2"})
+		cmd.Execute()
+		if got.Protocol != request_module.ProtocolHttp2 {
+			t.Errorf("expected http2, got %q", got.Protocol)
+		}
+	})
+
+	t.Run("routing flags are passed to the request", func(t *testing.T) {
+		var got request_module.RequestOptions
+		RunRequestFunc = func(opts request_module.RequestOptions) (request_module.RequestResponse, error) {
+			got = opts
+			return request_module.RequestResponse{}, nil
+		}
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+
+		cmd.SetArgs([]string{"GET", "http://docker/info", "--unix-socket", "/var/run/docker.sock",
+			"--resolve", "a.test:443:10.0.0.1", "--resolve", "b.test:443:10.0.0.2", "--connect-to", "::backend:8080"})
+		cmd.Execute()
+		if got.UnixSocket != "/var/run/docker.sock" {
+			t.Errorf("expected the socket path, got %q", got.UnixSocket)
+		}
+		if len(got.Resolve) != 2 || got.Resolve[1] != "b.test:443:10.0.0.2" {
+			t.Errorf("expected both resolve rules, got %v", got.Resolve)
+		}
+		if len(got.ConnectTo) != 1 || got.ConnectTo[0] != "::backend:8080" {
+			t.Errorf("expected the connect-to rule, got %v", got.ConnectTo)
+		}
+	})
+
+	t.Run("all-ips is passed to the request", func(t *testing.T) {
+		var got request_module.RequestOptions
+		RunRequestFunc = func(opts request_module.RequestOptions) (request_module.RequestResponse, error) {
+			got = opts
+			return request_module.RequestResponse{}, nil
+		}
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+
+		cmd.SetArgs([]string{"GET", "https://test", "--all-ips"})
+		cmd.Execute()
+		if !got.AllIps {
+			t.Error("expected all-ips to be set")
+		}
+	})
+
+	t.Run("all-ips with stream exits", func(t *testing.T) {
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+
+		cmd.SetArgs([]string{"GET", "https://test", "--all-ips", "--stream"})
+		defer func() {
+			if _, ok := recover().(exitCalled); !ok {
+				t.Error("expected exit to be called")
+			}
+		}()
+		cmd.Execute()
+	})
+
+	t.Run("download opens the download viewer", func(t *testing.T) {
+		calledRunRequest = false
+		var got request_module.RequestOptions
+
+		oldDownloadMenu := RequestMenuDownloadFunc
+		RequestMenuDownloadFunc = func(opts request_module.RequestOptions) error {
+			got = opts
+			return nil
+		}
+		defer func() { RequestMenuDownloadFunc = oldDownloadMenu }()
+
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+
+		cmd.SetArgs([]string{"GET", "https://test/file.zip", "--download=out/", "--checksum", "md5:" + strings.Repeat("a", 32)})
+		cmd.Execute()
+		if calledRunRequest {
+			t.Error("expected the buffered request not to run")
+		}
+		if got.Download == nil || got.Download.Path != "out/" || got.Download.Checksum == "" {
+			t.Errorf("unexpected download options %+v", got.Download)
+		}
+
+		cmd = &cobra.Command{Use: "test"}
+		Init(cmd)
+		cmd.SetArgs([]string{"GET", "https://test/file.zip", "--download"})
+		cmd.Execute()
+		if got.Download == nil || got.Download.Path != "." {
+			t.Errorf("expected --download alone to save in the current directory, got %+v", got.Download)
+		}
+	})
+
+	t.Run("download failure sets the exit code", func(t *testing.T) {
+		oldDownloadMenu := RequestMenuDownloadFunc
+		RequestMenuDownloadFunc = func(opts request_module.RequestOptions) error {
+			return &request_module.RequestError{Class: request_module.ErrorClassConnectionRefused}
+		}
+		defer func() { RequestMenuDownloadFunc = oldDownloadMenu }()
+
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+
+		cmd.SetArgs([]string{"GET", "https://test/file.zip", "--download"})
+		defer func() {
+			if exit, ok := recover().(exitCalled); !ok || exit.code != 7 {
+				t.Errorf("expected exit code 7, got %v", exit)
+			}
+		}()
+		cmd.Execute()
+	})
+
+	for _, args := range [][]string{
+		{"--download", "--stream"},
+		{"--checksum", "sha256:abcd", "--download"},
+		{"--checksum", "md5:" + strings.Repeat("a", 32)},
+	} {
+		t.Run("invalid download options exit "+strings.Join(args, " "), func(t *testing.T) {
+			cmd := &cobra.Command{Use: "test"}
+			Init(cmd)
+
+			cmd.SetArgs(append([]string{"GET", "https://test/file.zip"}, args...))
+			defer func() {
+				if _, ok := recover().(exitCalled); !ok {
+					t.Error("expected exit to be called")
+				}
+			}()
+			cmd.Execute()
+		})
+	}
+
+	t.Run("upload-file sends the file as the body", func(t *testing.T) {
+		var got request_module.RequestOptions
+		RunRequestFunc = func(opts request_module.RequestOptions) (request_module.RequestResponse, error) {
+			got = opts
+			return request_module.RequestResponse{}, nil
+		}
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+
+		cmd.SetArgs([]string{"PUT", "https://test/upload", "-T", "command.go", "-H", "Content-Type: text/x-go"})
+		cmd.Execute()
+		if len(got.Body) != 1 || got.Body[0].Key != http_utility.FileBodyKey || got.Body[0].Value != "command.go" || got.Body[0].ContentType != "text/x-go" {
+			t.Errorf("unexpected body %+v", got.Body)
+		}
+		if got.OnUploadProgress == nil {
+			t.Error("expected the upload progress to be reported")
+		}
+	})
+
+	for _, args := range [][]string{
+		{"-T", "does-not-exist.bin"},
+		{"-T", "."},
+		{"-T", "command.go", "--graphql", "{ me }"},
+	} {
+		t.Run("invalid upload file exits "+strings.Join(args, " "), func(t *testing.T) {
+			cmd := &cobra.Command{Use: "test"}
+			Init(cmd)
+
+			cmd.SetArgs(append([]string{"POST", "https://test/upload"}, args...))
+			defer func() {
+				if _, ok := recover().(exitCalled); !ok {
+					t.Error("expected exit to be called")
+				}
+			}()
+			cmd.Execute()
+		})
+	}
+
+	t.Run("request items build the request", func(t *testing.T) {
+		var got request_module.RequestOptions
+		RunRequestFunc = func(opts request_module.RequestOptions) (request_module.RequestResponse, error) {
+			got = opts
+			return request_module.RequestResponse{}, nil
+		}
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+
+		cmd.SetArgs([]string{"POST", "https://test/users?x=1", "X-Trace:abc", "page==2", "name=John", "admin:=true", "-H", "Accept: application/json"})
+		cmd.Execute()
+		if got.Url != "https://test/users?x=1&page=2" {
+			t.Errorf("unexpected url %q", got.Url)
+		}
+		if got.Headers.Get("X-Trace") != "abc" || got.Headers.Get("Accept") != "application/json" {
+			t.Errorf("unexpected headers %v", got.Headers)
+		}
+		if len(got.Body) != 1 || got.Body[0].ContentType != "application/json" || got.Body[0].Value != `{"name":"John","admin":true}` {
+			t.Errorf("unexpected body %+v", got.Body)
+		}
+	})
+
+	t.Run("request items are sent as a form with --form", func(t *testing.T) {
+		var got request_module.RequestOptions
+		RunRequestFunc = func(opts request_module.RequestOptions) (request_module.RequestResponse, error) {
+			got = opts
+			return request_module.RequestResponse{}, nil
+		}
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+
+		cmd.SetArgs([]string{"POST", "https://test/upload", "--form", "title=Report", "file@command.go"})
+		cmd.Execute()
+		if len(got.Body) != 2 || got.Body[1].ContentType != "multipart/form-data" || got.Body[1].Value != "./command.go" {
+			t.Errorf("unexpected body %+v", got.Body)
+		}
+	})
+
+	t.Run("raw body item takes the type from -H", func(t *testing.T) {
+		var got request_module.RequestOptions
+		RunRequestFunc = func(opts request_module.RequestOptions) (request_module.RequestResponse, error) {
+			got = opts
+			return request_module.RequestResponse{}, nil
+		}
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+
+		cmd.SetArgs([]string{"PUT", "https://test/upload", "=@command.go", "-H", "Content-Type: text/x-go"})
+		cmd.Execute()
+		if len(got.Body) != 1 || got.Body[0].Key != http_utility.FileBodyKey || got.Body[0].ContentType != "text/x-go" {
+			t.Errorf("unexpected body %+v", got.Body)
+		}
+	})
+
+	for _, args := range [][]string{
+		{"invalid-item"},
+		{"file@missing.png", "--form"},
+		{"name=John", "-T", "command.go"},
+		{"name=John", "--graphql", "{ me }"},
+	} {
+		t.Run("invalid request items exit "+strings.Join(args, " "), func(t *testing.T) {
+			cmd := &cobra.Command{Use: "test"}
+			Init(cmd)
+
+			cmd.SetArgs(append([]string{"POST", "https://test"}, args...))
+			defer func() {
+				if _, ok := recover().(exitCalled); !ok {
+					t.Error("expected exit to be called")
+				}
+			}()
+			cmd.Execute()
+		})
+	}
+
+	t.Run("retry options are passed to the request", func(t *testing.T) {
+		var got request_module.RequestOptions
+		RunRequestFunc = func(opts request_module.RequestOptions) (request_module.RequestResponse, error) {
+			got = opts
+			return request_module.RequestResponse{}, nil
+		}
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+
+		cmd.SetArgs([]string{"GET", "https://test", "--retry", "2", "--retry-status", "500"})
+		cmd.Execute()
+		if got.Retry.Attempts != 3 || len(got.Retry.StatusCodes) != 1 || got.Retry.StatusCodes[0] != 500 {
+			t.Errorf("unexpected retry options %+v", got.Retry)
+		}
+	})
+
+	t.Run("invalid retry options exit", func(t *testing.T) {
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+
+		cmd.SetArgs([]string{"GET", "https://test", "--retry", "2", "--retry-on", "flaky"})
+		defer func() {
+			if _, ok := recover().(exitCalled); !ok {
+				t.Error("expected exit to be called")
+			}
+		}()
+		cmd.Execute()
+	})
+
+	t.Run("http+unix url is accepted", func(t *testing.T) {
+		var got request_module.RequestOptions
+		RunRequestFunc = func(opts request_module.RequestOptions) (request_module.RequestResponse, error) {
+			got = opts
+			return request_module.RequestResponse{}, nil
+		}
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+
+		cmd.SetArgs([]string{"GET", "http+unix://%2Fvar%2Frun%2Fdocker.sock/info"})
+		cmd.Execute()
+		if got.Url != "http+unix://%2Fvar%2Frun%2Fdocker.sock/info" {
+			t.Errorf("expected the http+unix url, got %q", got.Url)
+		}
+	})
+
+	t.Run("unknown protocol exits", func(t *testing.T) {
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+
+		cmd.SetArgs([]string{"GET", "https://test", "--protocol", "spdy"})
+		defer func() {
+			if _, ok := recover().(exitCalled); !ok {
+				t.Error("expected exit to be called")
+			}
+		}()
+		cmd.Execute()
+	})
+
+	t.Run("watch sends the request until the condition is met", func(t *testing.T) {
+		oldPrint, oldWatch, oldSuccess, oldOutput := HeadlessPrintFunc, WatchFunc, LoggerSuccess, headless_module.ErrorOutput
+		oldRun := RunRequestFunc
+		defer func() {
+			HeadlessPrintFunc, WatchFunc, LoggerSuccess, headless_module.ErrorOutput = oldPrint, oldWatch, oldSuccess, oldOutput
+			RunRequestFunc, RequestMenuNewFunc = oldRun, oldRequestMenu
+		}()
+
+		var printed request_module.RequestResponse
+		HeadlessPrintFunc = func(res request_module.RequestResponse, err error, validation *openapi_module.Validation) int {
+			printed = res
+			return 0
+		}
+		var successes []string
+		LoggerSuccess = func(message string, _ int) { successes = append(successes, message) }
+		var lines bytes.Buffer
+		headless_module.ErrorOutput = &lines
+
+		met := true
+		var watched watch_module.Options
+		WatchFunc = func(ctx context.Context, options watch_module.Options, history *watch_module.History, onSample func(watch_module.Sample, request_module.RequestResponse, error)) (request_module.RequestResponse, bool, error) {
+			watched = options
+			res := request_module.RequestResponse{StatusCode: 200, ExecutionTime: 12}
+			onSample(history.Add(res, nil, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)), res, nil)
+			return res, met, nil
+		}
+
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+		cmd.SetArgs([]string{"GET", "http://test", "--headless", "--watch", "2s", "--until", "status=200"})
+		cmd.Execute()
+		if watched.Interval != 2*time.Second || len(watched.Until) != 1 || watched.Request.Url != "http://test" {
+			t.Errorf("unexpected watch options %+v", watched)
+		}
+		if printed.StatusCode != 200 || !strings.HasPrefix(lines.String(), "[03:04:05] #1 200 12ms") {
+			t.Errorf("expected a line per request and the last response, got %q", lines.String())
+		}
+
+		met = false
+		cmd = &cobra.Command{Use: "test"}
+		Init(cmd)
+		cmd.SetArgs([]string{"GET", "http://test", "--headless", "--watch", "2s", "--until", "status=200"})
+		func() {
+			defer func() {
+				if exit, ok := recover().(exitCalled); !ok || exit.code != 1 {
+					t.Errorf("expected exit code 1 when stopped before the condition, got %v", exit)
+				}
+			}()
+			cmd.Execute()
+		}()
+
+		RunRequestFunc = func(opts request_module.RequestOptions) (request_module.RequestResponse, error) {
+			return request_module.RequestResponse{StatusCode: 200, Request: opts}, nil
+		}
+		calledRequestMenu = false
+		var watch *request_menu.WatchOptions
+		RequestMenuNewFunc = func(res *request_module.RequestResponse, err error, options request_menu.Options) error {
+			calledRequestMenu = true
+			watch = options.Watch
+			watch.Met = true
+			return nil
+		}
+		cmd = &cobra.Command{Use: "test"}
+		Init(cmd)
+		cmd.SetArgs([]string{"GET", "http://test", "--watch", "5s", "--until", "status=503"})
+		cmd.Execute()
+		if !calledRequestMenu || watch == nil || watch.Interval != 5*time.Second {
+			t.Error("expected the viewer to watch the request")
+		}
+		if len(successes) != 1 || successes[0] != "The response meets status=503." {
+			t.Errorf("expected the met condition to be reported, got %v", successes)
+		}
+
+		calledRequestMenu = false
+		cmd = &cobra.Command{Use: "test"}
+		Init(cmd)
+		cmd.SetArgs([]string{"GET", "http://test", "--watch", "5s", "--until", "status=2xx"})
+		cmd.Execute()
+		if calledRequestMenu || len(successes) != 2 || successes[1] != "The response already meets status=2xx." {
+			t.Errorf("expected no viewer when the first response meets the condition, got %v", successes)
+		}
+	})
+
+	t.Run("watch options must be valid", func(t *testing.T) {
+		for _, args := range [][]string{
+			{"GET", "http://test", "--until", "status=200"},
+			{"GET", "http://test", "--watch", "0s"},
+			{"GET", "http://test", "--watch", "1s", "--until", "code=200"},
+			{"GET", "http://test", "--watch", "1s", "--stream"},
+		} {
+			cmd := &cobra.Command{Use: "test"}
+			Init(cmd)
+			cmd.SetArgs(args)
+			func() {
+				defer func() {
+					if exit, ok := recover().(exitCalled); !ok || exit.code != 1 {
+						t.Errorf("expected exit code 1 for %v, got %v", args, exit)
+					}
+				}()
+				cmd.Execute()
+			}()
+		}
+	})
+
+	t.Run("save-snapshot keeps the response", func(t *testing.T) {
+		oldSave, oldPrint, oldOutput, oldRun := SaveSnapshotFunc, HeadlessPrintFunc, headless_module.ErrorOutput, RunRequestFunc
+		defer func() {
+			SaveSnapshotFunc, HeadlessPrintFunc, headless_module.ErrorOutput, RunRequestFunc = oldSave, oldPrint, oldOutput, oldRun
+		}()
+
+		saved := map[string]request_module.RequestResponse{}
+		SaveSnapshotFunc = func(name string, res request_module.RequestResponse) error {
+			saved[name] = res
+			return nil
+		}
+		HeadlessPrintFunc = func(request_module.RequestResponse, error, *openapi_module.Validation) int { return 0 }
+		var lines bytes.Buffer
+		headless_module.ErrorOutput = &lines
+		RunRequestFunc = func(opts request_module.RequestOptions) (request_module.RequestResponse, error) {
+			return request_module.RequestResponse{StatusCode: 200, Request: opts}, nil
+		}
+
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+		cmd.SetArgs([]string{"GET", "http://test", "--headless", "--save-snapshot", "users"})
+		cmd.Execute()
+		if saved["users"].Request.Url != "http://test" || lines.String() != "Saved the response as the snapshot \"users\".\n" {
+			t.Errorf("expected the response to be saved, got %v and %q", saved, lines.String())
+		}
+
+		RunRequestFunc = func(opts request_module.RequestOptions) (request_module.RequestResponse, error) {
+			return request_module.RequestResponse{Request: opts}, errors.New("connection refused")
+		}
+		delete(saved, "users")
+		cmd = &cobra.Command{Use: "test"}
+		Init(cmd)
+		cmd.SetArgs([]string{"GET", "http://test", "--headless", "--save-snapshot", "users"})
+		cmd.Execute()
+		if len(saved) != 0 {
+			t.Error("expected a failed request not to be saved")
+		}
+
+		cmd = &cobra.Command{Use: "test"}
+		Init(cmd)
+		cmd.SetArgs([]string{"GET", "http://test", "--save-snapshot", "users", "--stream"})
+		func() {
+			defer func() {
+				if exit, ok := recover().(exitCalled); !ok || exit.code != 1 {
+					t.Errorf("expected exit code 1 with --stream, got %v", exit)
+				}
+			}()
+			cmd.Execute()
+		}()
+	})
+
+}
+
+var testSchema = gqlparser.MustLoadSchema(&ast.Source{Input: "type Query { user(id: ID): String }"})
+
+func Test_getGraphQLFlags(t *testing.T) {
+	oldReadFile := ReadFileFunc
+	defer func() { ReadFileFunc = oldReadFile }()
+	ReadFileFunc = func(path string) ([]byte, error) {
+		if path == "query.graphql" {
+			return []byte("{ user }"), nil
+		}
+		return nil, errors.New("no such file")
+	}
+
+	newCmd := func(args ...string) *cobra.Command {
+		cmd := &cobra.Command{Use: "test"}
+		Init(cmd)
+		cmd.ParseFlags(args)
+		return cmd
+	}
+
+	flags, err := getGraphQLFlags(newCmd("--graphql", "@query.graphql", "--operation", "Q", "--refresh-schema"))
+	if err != nil || flags.Query != "{ user }" || flags.Operation != "Q" || !flags.RefreshSchema {
+		t.Errorf("unexpected flags %+v, %v", flags, err)
+	}
+
+	if _, err := getGraphQLFlags(newCmd("--graphql", "@missing.graphql")); err == nil {
+		t.Error("expected an error for a missing query file")
+	}
+	if _, err := getGraphQLFlags(newCmd("--graphql", "{ user }", "--variables", "{nope")); err == nil {
+		t.Error("expected an error for invalid variables")
+	}
+	if _, err := getGraphQLFlags(newCmd("--variables", "{}")); err == nil {
+		t.Error("expected an error for variables without a query")
+	}
+}
+
+func Test_getRetryOptions(t *testing.T) {
+	cmd := &cobra.Command{Use: "test"}
+	Init(cmd)
+
+	options, err := getRetryOptions(cmd)
+	if err != nil || options.Attempts != 0 {
+		t.Errorf("expected no retries by default, got %+v, %v", options, err)
+	}
+	if options.Backoff != request_module.DefaultRetryBackoff || options.MaxWait != request_module.DefaultRetryMaxWait {
+		t.Errorf("expected the default waits, got %+v", options)
+	}
+
+	cmd.Flags().Set("retry", "1")
+	cmd.Flags().Set("retry-on", "dns,timeout")
+	cmd.Flags().Set("retry-backoff", "2s")
+	options, err = getRetryOptions(cmd)
+	if err != nil || options.Attempts != 2 || len(options.ErrorClasses) != 2 || options.Backoff != 2*time.Second {
+		t.Errorf("unexpected options %+v, %v", options, err)
+	}
+
+	cmd.Flags().Set("retry", "-1")
+	if _, err := getRetryOptions(cmd); err == nil {
+		t.Error("expected a negative retry count to fail")
+	}
+
+	cmd.Flags().Set("retry", "1")
+	cmd.Flags().Set("retry-status", "abc")
+	if _, err := getRetryOptions(cmd); err == nil {
+		t.Error("expected an invalid status code to fail")
+	}
+}
+
+func Test_getTimeouts(t *testing.T) {
+	oldConfig := GetConfigFunc
+	defer func() { GetConfigFunc = oldConfig }()
+	GetConfigFunc = func() config_module.Config {
+		return config_module.Config{Timeout: 30000, IdleTimeout: 2000}
+	}
+
+	cmd := &cobra.Command{Use: "test"}
+	Init(cmd)
+
+	timeout, timeouts, err := getTimeouts(cmd)
+	if err != nil || timeout != 30*time.Second || timeouts.Idle != 2*time.Second || timeouts.Connect != 0 {
+		t.Errorf("expected the config values, got %v %+v %v", timeout, timeouts, err)
+	}
+
+	cmd.Flags().Set("timeout", "5s")
+	cmd.Flags().Set("connect-timeout", "1s")
+	cmd.Flags().Set("idle-timeout", "0")
+	timeout, timeouts, err = getTimeouts(cmd)
+	if err != nil || timeout != 5*time.Second || timeouts.Connect != time.Second || timeouts.Idle != 0 {
+		t.Errorf("expected the flags to win, got %v %+v %v", timeout, timeouts, err)
+	}
+
+	cmd.Flags().Set("tls-timeout", "-1s")
+	if _, _, err := getTimeouts(cmd); err == nil {
+		t.Error("expected a negative timeout to fail")
+	}
+}
+
+func Test_uploadProgress(t *testing.T) {
+	oldIsTerminal, oldOutput := IsTerminalFunc, UploadProgressOutput
+	defer func() { IsTerminalFunc, UploadProgressOutput = oldIsTerminal, oldOutput }()
+
+	var out strings.Builder
+	UploadProgressOutput = &out
+
+	IsTerminalFunc = func() bool { return false }
+	progress := newUploadProgress()
+	progress.report(10, 100)
+	progress.clear()
+	if out.Len() != 0 {
+		t.Errorf("expected nothing to be printed without a terminal, got %q", out.String())
+	}
+
+	IsTerminalFunc = func() bool { return true }
+	progress = newUploadProgress()
+	progress.report(512, 2048)
+	if !strings.Contains(out.String(), "Uploading") || !strings.Contains(out.String(), "25%") || !strings.Contains(out.String(), "512 B / 2.0 KB") {
+		t.Errorf("unexpected progress line %q", out.String())
+	}
+	progress.report(4096, -1)
+	if !strings.Contains(out.String(), "4.0 KB  ") {
+		t.Errorf("expected the sent bytes without a total, got %q", out.String())
+	}
+	progress.clear()
+	if !strings.HasSuffix(out.String(), "\r\033[K") {
+		t.Error("expected the line to be cleared")
+	}
 }
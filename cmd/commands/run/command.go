@@ -0,0 +1,176 @@
+package run_command
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	select_menu_component "github.com/diogopereiradev/httpzen/internal/components/select_menu"
+	config_module "github.com/diogopereiradev/httpzen/internal/config"
+	environment_module "github.com/diogopereiradev/httpzen/internal/environment"
+	headless_module "github.com/diogopereiradev/httpzen/internal/headless"
+	history_module "github.com/diogopereiradev/httpzen/internal/history"
+	httpfile_module "github.com/diogopereiradev/httpzen/internal/httpfile"
+	logger_module "github.com/diogopereiradev/httpzen/internal/logger"
+	"github.com/diogopereiradev/httpzen/internal/menus/request_menu"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/spf13/cobra"
+)
+
+var Exit = os.Exit
+var LoggerError = logger_module.Error
+var LoggerWarn = logger_module.Warn
+var LoadFileFunc = httpfile_module.Load
+var GetConfigFunc = config_module.GetConfig
+var GetEnvironmentFunc = environment_module.GetEnvironment
+var RunRequestFunc = request_module.RunRequest
+var RequestMenuNewFunc = request_menu.New
+var SelectMenuNewFunc = select_menu_component.New
+var HeadlessPrintFunc = headless_module.Print
+var AddHistoryFunc = history_module.Add
+
+func Init(rootCmd *cobra.Command) {
+	cmd := &cobra.Command{
+		Use:   "run [FILE]",
+		Short: "Run the requests of a .http or .rest file, as written for the VS Code REST Client or the JetBrains HTTP Client",
+		Args:  cobra.ArbitraryArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				cmd.Help()
+				return
+			}
+			name, _ := cmd.Flags().GetString("name")
+			all, _ := cmd.Flags().GetBool("all")
+			headless, _ := cmd.Flags().GetBool("headless")
+			envName, _ := cmd.Flags().GetString("env")
+
+			if name != "" && all {
+				LoggerError("Use either --name or --all, not both.", 70)
+				Exit(1)
+				return
+			}
+
+			file, err := LoadFileFunc(args[0])
+			if err != nil {
+				LoggerError("Could not read the request file: "+err.Error(), 70)
+				Exit(1)
+				return
+			}
+			if len(file.Skipped) > 0 {
+				message := "Some parts of the file are not run:\n  " + strings.Join(file.Skipped, "\n  ")
+				if headless {
+					fmt.Fprintln(headless_module.ErrorOutput, message)
+				} else {
+					LoggerWarn(message, 70)
+				}
+			}
+
+			requests, ok := pickRequests(file, name, all || headless)
+			if !ok {
+				return
+			}
+
+			config := GetConfigFunc()
+			if envName == "" {
+				envName = config.ActiveEnvironment
+			}
+			environment := GetEnvironmentFunc(envName)
+
+			code := 0
+			for _, request := range requests {
+				var result int
+				if headless {
+					if len(requests) > 1 {
+						fmt.Fprintln(headless_module.Output, "### "+request.Title())
+					}
+					result = runHeadless(file, request, environment.Variables, config)
+				} else {
+					result = runInMenu(file, request, environment.Variables, config)
+				}
+				if code == 0 {
+					code = result
+				}
+			}
+			if code != 0 {
+				Exit(code)
+			}
+		},
+	}
+
+	cmd.Flags().String("name", "", "Run the request with this name, from its ### line or # @name")
+	cmd.Flags().Bool("all", false, "Run every request of the file in order")
+	cmd.Flags().Bool("headless", false, "Print the responses instead of opening the viewer, runs every request unless --name is given")
+	cmd.Flags().StringP("env", "e", "", "Environment to take the {{variables}} from (default: the active one)")
+
+	rootCmd.AddCommand(cmd)
+}
+
+// pickRequests selects the request named, every request, or lets the user
+// pick one or all of them in a list.
+func pickRequests(file httpfile_module.File, name string, all bool) ([]httpfile_module.Request, bool) {
+	if name != "" {
+		for _, request := range file.Requests {
+			if strings.EqualFold(request.Name, name) || strings.EqualFold(request.Title(), name) {
+				return []httpfile_module.Request{request}, true
+			}
+		}
+		LoggerError("The file has no request named \""+name+"\".", 70)
+		Exit(1)
+		return nil, false
+	}
+	if all || len(file.Requests) == 1 {
+		return file.Requests, true
+	}
+
+	choices := make([]string, 0, len(file.Requests)+1)
+	for _, request := range file.Requests {
+		choices = append(choices, request.Title())
+	}
+	choices = append(choices, "Run all requests")
+
+	var requests []httpfile_module.Request
+	SelectMenuNewFunc(select_menu_component.MenuImpl{
+		Choices:  choices,
+		PerPage:  10,
+		Messages: select_menu_component.MenuMessages{Title: "Pick a request of " + file.Path},
+		Events: select_menu_component.MenuEvents{
+			OnSelect: func(choice int) {
+				if choice == len(file.Requests) {
+					requests = file.Requests
+				} else {
+					requests = []httpfile_module.Request{file.Requests[choice]}
+				}
+			},
+		},
+	})
+	return requests, len(requests) > 0
+}
+
+func requestOptions(file httpfile_module.File, request httpfile_module.Request, variables map[string]string, config config_module.Config) (request_module.RequestOptions, error) {
+	options, err := file.RequestOptions(request, variables)
+	if err != nil {
+		return options, err
+	}
+	options.Timeout, options.Timeouts = request_module.ConfigTimeouts(config)
+	return options, nil
+}
+
+func runHeadless(file httpfile_module.File, request httpfile_module.Request, variables map[string]string, config config_module.Config) int {
+	options, err := requestOptions(file, request, variables, config)
+	if err != nil {
+		fmt.Fprintln(headless_module.ErrorOutput, err.Error())
+		return 1
+	}
+	res, err := history_module.Send(options, RunRequestFunc, AddHistoryFunc, LoggerWarn)
+	return HeadlessPrintFunc(res, err, nil)
+}
+
+func runInMenu(file httpfile_module.File, request httpfile_module.Request, variables map[string]string, config config_module.Config) int {
+	options, err := requestOptions(file, request, variables, config)
+	if err != nil {
+		LoggerError(err.Error(), 70)
+		return 1
+	}
+	res, err := history_module.Send(options, RunRequestFunc, AddHistoryFunc, LoggerWarn)
+	return request_module.ExitCode(RequestMenuNewFunc(&res, err, request_menu.Options{}))
+}
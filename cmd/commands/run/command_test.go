@@ -0,0 +1,152 @@
+package run_command
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	select_menu_component "github.com/diogopereiradev/httpzen/internal/components/select_menu"
+	config_module "github.com/diogopereiradev/httpzen/internal/config"
+	environment_module "github.com/diogopereiradev/httpzen/internal/environment"
+	headless_module "github.com/diogopereiradev/httpzen/internal/headless"
+	httpfile_module "github.com/diogopereiradev/httpzen/internal/httpfile"
+	"github.com/diogopereiradev/httpzen/internal/menus/request_menu"
+	openapi_module "github.com/diogopereiradev/httpzen/internal/openapi"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+const requestsFile = `@host = api.example.com
+
+### Health
+GET https://{{host}}/health
+
+### Login
+POST https://{{host}}/login
+Content-Type: application/json
+
+{"token": "{{token}}"}
+
+> {% client.global.set("token", response.body.token) %}
+`
+
+type runStubs struct {
+	codes  []int
+	sent   []request_module.RequestOptions
+	menus  int
+	output *bytes.Buffer
+	errors []string
+}
+
+func stubRun(t *testing.T) *runStubs {
+	stubs := &runStubs{output: &bytes.Buffer{}}
+	oldExit, oldError, oldWarn, oldLoad := Exit, LoggerError, LoggerWarn, LoadFileFunc
+	oldConfig, oldEnvironment, oldRun, oldMenu := GetConfigFunc, GetEnvironmentFunc, RunRequestFunc, RequestMenuNewFunc
+	oldSelect, oldPrint, oldHistory := SelectMenuNewFunc, HeadlessPrintFunc, AddHistoryFunc
+	oldOutput, oldErrorOutput := headless_module.Output, headless_module.ErrorOutput
+	t.Cleanup(func() {
+		Exit, LoggerError, LoggerWarn, LoadFileFunc = oldExit, oldError, oldWarn, oldLoad
+		GetConfigFunc, GetEnvironmentFunc, RunRequestFunc, RequestMenuNewFunc = oldConfig, oldEnvironment, oldRun, oldMenu
+		SelectMenuNewFunc, HeadlessPrintFunc, AddHistoryFunc = oldSelect, oldPrint, oldHistory
+		headless_module.Output, headless_module.ErrorOutput = oldOutput, oldErrorOutput
+	})
+
+	Exit = func(code int) { stubs.codes = append(stubs.codes, code) }
+	LoggerError = func(message string, _ int) { stubs.errors = append(stubs.errors, message) }
+	LoggerWarn = func(string, int) {}
+	LoadFileFunc = func(path string) (httpfile_module.File, error) {
+		if path != "api.http" {
+			return httpfile_module.File{}, errors.New("not found")
+		}
+		return httpfile_module.Parse([]byte(requestsFile), path)
+	}
+	GetConfigFunc = func() config_module.Config {
+		return config_module.Config{ActiveEnvironment: "dev", Timeout: 1000}
+	}
+	GetEnvironmentFunc = func(name string) environment_module.Environment {
+		return environment_module.Environment{Name: name, Variables: map[string]string{"token": name + "-token"}}
+	}
+	RunRequestFunc = func(options request_module.RequestOptions) (request_module.RequestResponse, error) {
+		stubs.sent = append(stubs.sent, options)
+		return request_module.RequestResponse{Request: options, StatusCode: 200}, nil
+	}
+	RequestMenuNewFunc = func(*request_module.RequestResponse, error, request_menu.Options) error {
+		stubs.menus++
+		return nil
+	}
+	AddHistoryFunc = func(request_module.RequestResponse) error { return nil }
+	headless_module.Output, headless_module.ErrorOutput = stubs.output, &bytes.Buffer{}
+	return stubs
+}
+
+func runCommand(args ...string) {
+	rootCmd := &cobra.Command{Use: "root"}
+	Init(rootCmd)
+	rootCmd.SetArgs(append([]string{"run"}, args...))
+	rootCmd.Execute()
+}
+
+func TestRun_PickOne(t *testing.T) {
+	stubs := stubRun(t)
+	var choices []string
+	SelectMenuNewFunc = func(options select_menu_component.MenuImpl) {
+		choices = options.Choices
+		options.Events.OnSelect(1)
+	}
+
+	runCommand("api.http")
+
+	assert.Equal(t, []string{"Health", "Login", "Run all requests"}, choices)
+	assert.Empty(t, stubs.codes)
+	assert.Equal(t, 1, stubs.menus)
+	assert.Len(t, stubs.sent, 1)
+	assert.Equal(t, "https://api.example.com/login", stubs.sent[0].Url)
+	assert.Equal(t, `{"token": "dev-token"}`, stubs.sent[0].Body[0].Value)
+}
+
+func TestRun_All(t *testing.T) {
+	stubs := stubRun(t)
+	SelectMenuNewFunc = func(options select_menu_component.MenuImpl) { options.Events.OnSelect(2) }
+
+	runCommand("api.http", "--env", "prod")
+
+	assert.Equal(t, 2, stubs.menus)
+	assert.Equal(t, `{"token": "prod-token"}`, stubs.sent[1].Body[0].Value)
+}
+
+func TestRun_Headless(t *testing.T) {
+	stubs := stubRun(t)
+	SelectMenuNewFunc = func(select_menu_component.MenuImpl) { t.Error("expected no menu in headless mode") }
+	var printed int
+	HeadlessPrintFunc = func(res request_module.RequestResponse, err error, _ *openapi_module.Validation) int {
+		printed++
+		if res.Request.Method == "POST" {
+			return 28
+		}
+		return 0
+	}
+
+	runCommand("api.http", "--headless")
+
+	assert.Equal(t, 2, printed)
+	assert.Equal(t, 0, stubs.menus)
+	assert.Equal(t, "### Health\n### Login\n", stubs.output.String())
+	assert.Equal(t, []int{28}, stubs.codes)
+
+	printed = 0
+	runCommand("api.http", "--headless", "--name", "health")
+	assert.Equal(t, 1, printed)
+}
+
+func TestRun_Errors(t *testing.T) {
+	stubs := stubRun(t)
+
+	runCommand("missing.http")
+	runCommand("api.http", "--name", "nope")
+	runCommand("api.http", "--name", "Health", "--all")
+
+	assert.Equal(t, []int{1, 1, 1}, stubs.codes)
+	assert.Empty(t, stubs.sent)
+	assert.Contains(t, stubs.errors[1], "no request named \"nope\"")
+}
@@ -0,0 +1,201 @@
+package snapshot_command
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	config_module "github.com/diogopereiradev/httpzen/internal/config"
+	diff_module "github.com/diogopereiradev/httpzen/internal/diff"
+	environment_module "github.com/diogopereiradev/httpzen/internal/environment"
+	headless_module "github.com/diogopereiradev/httpzen/internal/headless"
+	history_module "github.com/diogopereiradev/httpzen/internal/history"
+	logger_module "github.com/diogopereiradev/httpzen/internal/logger"
+	"github.com/diogopereiradev/httpzen/internal/menus/request_menu"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	snapshot_module "github.com/diogopereiradev/httpzen/internal/snapshot"
+	"github.com/spf13/cobra"
+)
+
+var Exit = os.Exit
+var LoggerError = logger_module.Error
+var LoggerWarn = logger_module.Warn
+var LoggerSuccess = logger_module.Success
+var GetSnapshotFunc = snapshot_module.GetSnapshot
+var ListSnapshotsFunc = snapshot_module.ListSnapshots
+var SaveSnapshotFunc = snapshot_module.SaveSnapshot
+var DeleteSnapshotFunc = snapshot_module.DeleteSnapshot
+var LastHistoryFunc = history_module.Last
+var AddHistoryFunc = history_module.Add
+var RunRequestFunc = request_module.RunRequest
+var RequestMenuNewFunc = request_menu.New
+var HeadlessPrintFunc = headless_module.Print
+var GetConfigFunc = config_module.GetConfig
+var GetEnvironmentFunc = environment_module.GetEnvironment
+
+func Init(rootCmd *cobra.Command) {
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Keep responses as golden snapshots and diff the current response against them",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	saveCmd := &cobra.Command{
+		Use:   "save NAME",
+		Short: "Save the last response in the history as a snapshot, use --save-snapshot to save it while sending",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			last, err := LastHistoryFunc(1)
+			if err != nil {
+				LoggerError("Could not read the history: "+err.Error(), 70)
+				Exit(1)
+				return
+			}
+			if len(last) == 0 {
+				LoggerError("The history is empty, send a request first.", 70)
+				Exit(1)
+				return
+			}
+			if err := SaveSnapshotFunc(args[0], last[0]); err != nil {
+				LoggerError("Could not save the snapshot: "+err.Error(), 70)
+				Exit(1)
+				return
+			}
+			LoggerSuccess("Saved the response of "+describe(last[0])+" as the snapshot \""+args[0]+"\".", 70)
+		},
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the saved snapshots",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			snapshots := ListSnapshotsFunc()
+			if len(snapshots) == 0 {
+				fmt.Fprintln(headless_module.Output, "No snapshots saved.")
+				return
+			}
+			for _, snapshot := range snapshots {
+				fmt.Fprintln(headless_module.Output, snapshot.Name+"  "+describe(snapshot.Response)+"  "+
+					strconv.Itoa(snapshot.Response.StatusCode)+"  "+snapshot.SavedAt.Format("2006-01-02 15:04:05"))
+			}
+		},
+	}
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete NAME",
+		Short: "Delete a snapshot",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			deleted, err := DeleteSnapshotFunc(args[0])
+			if err != nil {
+				LoggerError("Could not delete the snapshot: "+err.Error(), 70)
+				Exit(1)
+				return
+			}
+			if !deleted {
+				LoggerError("The snapshot \""+args[0]+"\" does not exist.", 70)
+				Exit(1)
+				return
+			}
+			LoggerSuccess("Deleted the snapshot \""+args[0]+"\".", 70)
+		},
+	}
+
+	diffCmd := &cobra.Command{
+		Use:   "diff NAME",
+		Short: "Send the request of a snapshot again and diff the response against it",
+		Long: "Send the request of a snapshot again and diff the response against it: status, latency, headers\n" +
+			"and the body, path by path when both bodies are JSON. Headless runs exit with 1 when they differ.\n" +
+			"--ignore skips a header by name or a body path and what is under it:\n" +
+			"  httpzen snapshot diff users --ignore Date --ignore '$.meta.requestId' --headless",
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			headless, _ := cmd.Flags().GetBool("headless")
+			ignore, _ := cmd.Flags().GetStringArray("ignore")
+			update, _ := cmd.Flags().GetBool("update")
+
+			snapshot, ok := GetSnapshotFunc(args[0])
+			if !ok {
+				LoggerError("The snapshot \""+args[0]+"\" does not exist.", 70)
+				Exit(1)
+				return
+			}
+
+			res, err := history_module.Send(resolveAuth(snapshot.Response.Request), RunRequestFunc, AddHistoryFunc, LoggerWarn)
+
+			if headless {
+				if err != nil {
+					Exit(HeadlessPrintFunc(res, err, nil))
+					return
+				}
+				result := diff_module.Compare(snapshot.Response, res, ignore)
+				fmt.Fprintln(headless_module.Output, diff_module.Format(result))
+				if update {
+					updateSnapshot(snapshot.Name, res, true)
+				}
+				if !result.Same() && !update {
+					Exit(diff_module.DifferentExitCode)
+				}
+				return
+			}
+
+			baseline := &request_menu.Baseline{
+				Label:    "snapshot \"" + snapshot.Name + "\"",
+				Response: snapshot.Response,
+				Ignore:   ignore,
+			}
+			if err := RequestMenuNewFunc(&res, err, request_menu.Options{Baseline: baseline}); err != nil {
+				Exit(request_module.ExitCode(err))
+				return
+			}
+			if update {
+				updateSnapshot(snapshot.Name, res, false)
+			}
+		},
+	}
+	diffCmd.Flags().StringArray("ignore", []string{}, "Header name or body path like $.meta.id to leave out of the diff (can be used multiple times)")
+	diffCmd.Flags().Bool("headless", false, "Print the diff instead of opening the viewer, exit with 1 when the response changed")
+	diffCmd.Flags().Bool("update", false, "Replace the snapshot with the new response once it is diffed")
+
+	cmd.AddCommand(saveCmd)
+	cmd.AddCommand(listCmd)
+	cmd.AddCommand(deleteCmd)
+	cmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(cmd)
+}
+
+// updateSnapshot replaces a snapshot after a diff, headless runs report on
+// stderr so stdout only has the diff.
+func updateSnapshot(name string, res request_module.RequestResponse, headless bool) {
+	if err := SaveSnapshotFunc(name, res); err != nil {
+		LoggerError("Could not update the snapshot: "+err.Error(), 70)
+		Exit(1)
+		return
+	}
+	message := "Updated the snapshot \"" + name + "\"."
+	if headless {
+		fmt.Fprintln(headless_module.ErrorOutput, message)
+		return
+	}
+	LoggerSuccess(message, 70)
+}
+
+// resolveAuth fills the credentials of a request saved from the history,
+// which only keeps the auth type, from the active environment.
+func resolveAuth(options request_module.RequestOptions) request_module.RequestOptions {
+	if options.Auth.IsEmpty() || options.Auth != options.Auth.Redacted() {
+		return options
+	}
+	if auth := GetEnvironmentFunc(GetConfigFunc().ActiveEnvironment).Auth; auth.Type == options.Auth.Type {
+		options.Auth = auth
+	}
+	return options
+}
+
+func describe(res request_module.RequestResponse) string {
+	return strings.TrimSpace(res.Request.Method + " " + res.Request.Url)
+}
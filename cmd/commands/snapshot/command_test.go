@@ -0,0 +1,247 @@
+package snapshot_command
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	auth_module "github.com/diogopereiradev/httpzen/internal/auth"
+	config_module "github.com/diogopereiradev/httpzen/internal/config"
+	environment_module "github.com/diogopereiradev/httpzen/internal/environment"
+	headless_module "github.com/diogopereiradev/httpzen/internal/headless"
+	"github.com/diogopereiradev/httpzen/internal/menus/request_menu"
+	openapi_module "github.com/diogopereiradev/httpzen/internal/openapi"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	snapshot_module "github.com/diogopereiradev/httpzen/internal/snapshot"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+type snapshotStubs struct {
+	codes     []int
+	errors    []string
+	successes []string
+	snapshots map[string]snapshot_module.Snapshot
+	history   []request_module.RequestResponse
+	sent      []request_module.RequestOptions
+	response  request_module.RequestResponse
+	err       error
+	baseline  *request_menu.Baseline
+	output    *bytes.Buffer
+	errOutput *bytes.Buffer
+}
+
+func testResponse(body string) request_module.RequestResponse {
+	return request_module.RequestResponse{
+		StatusCode:    200,
+		ExecutionTime: 100,
+		Headers:       http.Header{"Content-Type": {"application/json"}},
+		Result:        body,
+		Request:       request_module.RequestOptions{Method: "GET", Url: "http://a/users"},
+	}
+}
+
+func stubSnapshot(t *testing.T) *snapshotStubs {
+	stubs := &snapshotStubs{
+		snapshots: map[string]snapshot_module.Snapshot{
+			"users": {Name: "users", SavedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), Response: testResponse(`{"id":1,"name":"a"}`)},
+		},
+		response:  testResponse(`{"id":1,"name":"a"}`),
+		output:    &bytes.Buffer{},
+		errOutput: &bytes.Buffer{},
+	}
+	oldExit, oldError, oldWarn, oldSuccess := Exit, LoggerError, LoggerWarn, LoggerSuccess
+	oldGet, oldList, oldSave, oldDelete := GetSnapshotFunc, ListSnapshotsFunc, SaveSnapshotFunc, DeleteSnapshotFunc
+	oldLast, oldAdd, oldRun, oldMenu, oldPrint := LastHistoryFunc, AddHistoryFunc, RunRequestFunc, RequestMenuNewFunc, HeadlessPrintFunc
+	oldOutput, oldErrorOutput := headless_module.Output, headless_module.ErrorOutput
+	oldConfig, oldEnvironment := GetConfigFunc, GetEnvironmentFunc
+	t.Cleanup(func() {
+		GetConfigFunc, GetEnvironmentFunc = oldConfig, oldEnvironment
+		Exit, LoggerError, LoggerWarn, LoggerSuccess = oldExit, oldError, oldWarn, oldSuccess
+		GetSnapshotFunc, ListSnapshotsFunc, SaveSnapshotFunc, DeleteSnapshotFunc = oldGet, oldList, oldSave, oldDelete
+		LastHistoryFunc, AddHistoryFunc, RunRequestFunc, RequestMenuNewFunc, HeadlessPrintFunc = oldLast, oldAdd, oldRun, oldMenu, oldPrint
+		headless_module.Output, headless_module.ErrorOutput = oldOutput, oldErrorOutput
+	})
+
+	Exit = func(code int) { stubs.codes = append(stubs.codes, code) }
+	LoggerError = func(message string, _ int) { stubs.errors = append(stubs.errors, message) }
+	LoggerWarn = func(string, int) {}
+	LoggerSuccess = func(message string, _ int) { stubs.successes = append(stubs.successes, message) }
+	GetSnapshotFunc = func(name string) (snapshot_module.Snapshot, bool) {
+		snapshot, ok := stubs.snapshots[name]
+		return snapshot, ok
+	}
+	ListSnapshotsFunc = func() []snapshot_module.Snapshot {
+		list := []snapshot_module.Snapshot{}
+		for _, snapshot := range stubs.snapshots {
+			list = append(list, snapshot)
+		}
+		return list
+	}
+	SaveSnapshotFunc = func(name string, res request_module.RequestResponse) error {
+		stubs.snapshots[name] = snapshot_module.Snapshot{Name: name, Response: res}
+		return nil
+	}
+	DeleteSnapshotFunc = func(name string) (bool, error) {
+		_, ok := stubs.snapshots[name]
+		delete(stubs.snapshots, name)
+		return ok, nil
+	}
+	LastHistoryFunc = func(count int) ([]request_module.RequestResponse, error) {
+		return stubs.history, nil
+	}
+	AddHistoryFunc = func(request_module.RequestResponse) error { return nil }
+	RunRequestFunc = func(options request_module.RequestOptions) (request_module.RequestResponse, error) {
+		stubs.sent = append(stubs.sent, options)
+		return stubs.response, stubs.err
+	}
+	RequestMenuNewFunc = func(res *request_module.RequestResponse, err error, options request_menu.Options) error {
+		stubs.baseline = options.Baseline
+		return err
+	}
+	HeadlessPrintFunc = func(res request_module.RequestResponse, err error, validation *openapi_module.Validation) int {
+		return request_module.ExitCode(err)
+	}
+	headless_module.Output, headless_module.ErrorOutput = stubs.output, stubs.errOutput
+	GetConfigFunc = func() config_module.Config { return config_module.Config{ActiveEnvironment: "dev"} }
+	GetEnvironmentFunc = func(name string) environment_module.Environment {
+		return environment_module.Environment{Name: name, Auth: auth_module.AuthOptions{Type: auth_module.AuthBearer, Token: "from-" + name}}
+	}
+	return stubs
+}
+
+func execute(args ...string) {
+	root := &cobra.Command{Use: "httpzen"}
+	Init(root)
+	root.SetArgs(args)
+	root.Execute()
+}
+
+func TestSave(t *testing.T) {
+	stubs := stubSnapshot(t)
+	execute("snapshot", "save", "me")
+	assert.Equal(t, []int{1}, stubs.codes)
+	assert.Equal(t, []string{"The history is empty, send a request first."}, stubs.errors)
+
+	stubs = stubSnapshot(t)
+	stubs.history = []request_module.RequestResponse{testResponse(`{"id":2}`)}
+	execute("snapshot", "save", "me")
+	assert.Empty(t, stubs.codes)
+	assert.Equal(t, `{"id":2}`, stubs.snapshots["me"].Response.Result)
+	assert.Equal(t, []string{`Saved the response of GET http://a/users as the snapshot "me".`}, stubs.successes)
+}
+
+func TestList(t *testing.T) {
+	stubs := stubSnapshot(t)
+	execute("snapshot", "list")
+	assert.Equal(t, "users  GET http://a/users  200  2026-01-02 03:04:05\n", stubs.output.String())
+
+	stubs = stubSnapshot(t)
+	stubs.snapshots = map[string]snapshot_module.Snapshot{}
+	execute("snapshot", "list")
+	assert.Equal(t, "No snapshots saved.\n", stubs.output.String())
+}
+
+func TestDelete(t *testing.T) {
+	stubs := stubSnapshot(t)
+	execute("snapshot", "delete", "users")
+	assert.Empty(t, stubs.codes)
+	assert.Empty(t, stubs.snapshots)
+
+	execute("snapshot", "delete", "users")
+	assert.Equal(t, []int{1}, stubs.codes)
+	assert.Equal(t, []string{`The snapshot "users" does not exist.`}, stubs.errors)
+
+	stubs = stubSnapshot(t)
+	DeleteSnapshotFunc = func(string) (bool, error) { return false, errors.New("read-only") }
+	execute("snapshot", "delete", "users")
+	assert.Equal(t, []int{1}, stubs.codes)
+	assert.Equal(t, []string{"Could not delete the snapshot: read-only"}, stubs.errors)
+}
+
+func TestDiff(t *testing.T) {
+	t.Run("missing snapshot", func(t *testing.T) {
+		stubs := stubSnapshot(t)
+		execute("snapshot", "diff", "nope", "--headless")
+		assert.Equal(t, []int{1}, stubs.codes)
+		assert.Empty(t, stubs.sent)
+	})
+
+	t.Run("headless same response", func(t *testing.T) {
+		stubs := stubSnapshot(t)
+		execute("snapshot", "diff", "users", "--headless")
+		assert.Empty(t, stubs.codes)
+		assert.Equal(t, "http://a/users", stubs.sent[0].Url)
+		assert.Contains(t, stubs.output.String(), "Body (JSON): no changes")
+	})
+
+	t.Run("headless changed response", func(t *testing.T) {
+		stubs := stubSnapshot(t)
+		stubs.response = testResponse(`{"id":1,"name":"b"}`)
+		stubs.response.Headers.Set("Date", "today")
+		execute("snapshot", "diff", "users", "--headless")
+		assert.Equal(t, []int{1}, stubs.codes)
+		assert.Contains(t, stubs.output.String(), `~ $.name: "a" → "b"`)
+		assert.Contains(t, stubs.output.String(), "+ Date: today")
+	})
+
+	t.Run("headless ignores headers and paths", func(t *testing.T) {
+		stubs := stubSnapshot(t)
+		stubs.response = testResponse(`{"id":1,"name":"b"}`)
+		stubs.response.Headers.Set("Date", "today")
+		execute("snapshot", "diff", "users", "--headless", "--ignore", "date", "--ignore", "$.name")
+		assert.Empty(t, stubs.codes)
+	})
+
+	t.Run("headless update", func(t *testing.T) {
+		stubs := stubSnapshot(t)
+		stubs.response = testResponse(`{"id":3}`)
+		execute("snapshot", "diff", "users", "--headless", "--update")
+		assert.Empty(t, stubs.codes)
+		assert.Equal(t, `{"id":3}`, stubs.snapshots["users"].Response.Result)
+		assert.Equal(t, "Updated the snapshot \"users\".\n", stubs.errOutput.String())
+	})
+
+	t.Run("headless request error", func(t *testing.T) {
+		stubs := stubSnapshot(t)
+		stubs.err = errors.New("dial tcp: connection refused")
+		execute("snapshot", "diff", "users", "--headless")
+		assert.Len(t, stubs.codes, 1)
+		assert.NotZero(t, stubs.codes[0])
+		assert.Empty(t, stubs.output.String())
+	})
+
+	t.Run("redacted auth comes from the environment", func(t *testing.T) {
+		stubs := stubSnapshot(t)
+		snapshot := stubs.snapshots["users"]
+		snapshot.Response.Request.Auth = auth_module.AuthOptions{Type: auth_module.AuthBearer}
+		stubs.snapshots["users"] = snapshot
+		execute("snapshot", "diff", "users", "--headless")
+		assert.Equal(t, "from-dev", stubs.sent[0].Auth.Token)
+
+		snapshot.Response.Request.Auth = auth_module.AuthOptions{Type: auth_module.AuthBearer, Token: "saved"}
+		stubs.snapshots["users"] = snapshot
+		execute("snapshot", "diff", "users", "--headless")
+		assert.Equal(t, "saved", stubs.sent[1].Auth.Token)
+	})
+
+	t.Run("viewer diffs against the snapshot", func(t *testing.T) {
+		stubs := stubSnapshot(t)
+		execute("snapshot", "diff", "users", "--ignore", "Date")
+		assert.Empty(t, stubs.codes)
+		if assert.NotNil(t, stubs.baseline) {
+			assert.Equal(t, `snapshot "users"`, stubs.baseline.Label)
+			assert.Equal(t, []string{"Date"}, stubs.baseline.Ignore)
+			assert.Equal(t, `{"id":1,"name":"a"}`, stubs.baseline.Response.Result)
+		}
+	})
+}
+
+func TestDescribe(t *testing.T) {
+	assert.Equal(t, "GET http://a/users", describe(testResponse("")))
+	assert.Equal(t, "", describe(request_module.RequestResponse{}))
+	assert.False(t, strings.HasPrefix(describe(request_module.RequestResponse{Request: request_module.RequestOptions{Url: "http://a"}}), " "))
+}
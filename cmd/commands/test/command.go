@@ -0,0 +1,197 @@
+package test_command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	collection_module "github.com/diogopereiradev/httpzen/internal/collection"
+	config_module "github.com/diogopereiradev/httpzen/internal/config"
+	environment_module "github.com/diogopereiradev/httpzen/internal/environment"
+	headless_module "github.com/diogopereiradev/httpzen/internal/headless"
+	logger_module "github.com/diogopereiradev/httpzen/internal/logger"
+	"github.com/diogopereiradev/httpzen/internal/menus/test_menu"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	script_module "github.com/diogopereiradev/httpzen/internal/script"
+	testrunner_module "github.com/diogopereiradev/httpzen/internal/testrunner"
+	"github.com/spf13/cobra"
+)
+
+var Exit = os.Exit
+var LoggerError = logger_module.Error
+var LoggerSuccess = logger_module.Success
+var GetConfigFunc = config_module.GetConfig
+var GetCollectionFunc = collection_module.GetCollection
+var GetEnvironmentFunc = environment_module.GetEnvironment
+var LoadDataFunc = testrunner_module.LoadData
+var ExecuteFunc = testrunner_module.Execute
+var TestMenuNewFunc = test_menu.New
+var WriteReportFunc = testrunner_module.WriteReport
+
+func Init(rootCmd *cobra.Command) {
+	cmd := &cobra.Command{
+		Use:   "test COLLECTION",
+		Short: "Run the requests of a collection as tests and report the results",
+		Long: "Run the requests of a collection as tests. A request fails when it can't be sent, when one of\n" +
+			"its values can't be extracted, or when its scripts throw or a test fails:\n" +
+			"  httpzen test api --iteration-data users.csv --junit report.xml",
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			options, err := getOptions(cmd, args[0])
+			if err != nil {
+				LoggerError(err.Error(), 70)
+				Exit(1)
+				return
+			}
+
+			headless, _ := cmd.Flags().GetBool("headless")
+			var run testrunner_module.Run
+			if headless {
+				run = runHeadless(options)
+			} else {
+				run = TestMenuNewFunc(options)
+			}
+
+			if !writeReports(cmd, run, headless) {
+				Exit(1)
+				return
+			}
+			if _, failed, _ := run.Counts(); failed > 0 {
+				Exit(script_module.TestsFailedExitCode)
+			}
+		},
+	}
+	cmd.Flags().Bool("headless", false, "Print a line per request instead of the live progress list")
+	cmd.Flags().StringP("env", "e", "", "Environment to take the {{variables}} from (default: the active one)")
+	cmd.Flags().String("iteration-data", "", "CSV or JSON file with the {{variables}} of each iteration")
+	cmd.Flags().Int("iterations", 1, "Run the collection this many times, when there is no --iteration-data")
+	cmd.Flags().Int("parallel", 1, "Send up to this many requests at once, the requests then don't share variables")
+	cmd.Flags().Bool("stop-on-failure", false, "Skip the requests left after the first failure")
+	cmd.Flags().Duration("timeout", 0, "Time limit of each request without one of its own (default: from the config, 30s)")
+	cmd.Flags().String("junit", "", "Write a JUnit XML report to this file")
+	cmd.Flags().String("json", "", "Write a JSON report to this file")
+
+	rootCmd.AddCommand(cmd)
+}
+
+func getOptions(cmd *cobra.Command, name string) (testrunner_module.Options, error) {
+	collection, ok := GetCollectionFunc(name)
+	if !ok {
+		return testrunner_module.Options{}, errors.New("The collection \"" + name + "\" does not exist.")
+	}
+
+	config := GetConfigFunc()
+	envName, _ := cmd.Flags().GetString("env")
+	if envName == "" {
+		envName = config.ActiveEnvironment
+	}
+
+	iterations, _ := cmd.Flags().GetInt("iterations")
+	parallel, _ := cmd.Flags().GetInt("parallel")
+	if iterations < 1 || parallel < 1 {
+		return testrunner_module.Options{}, errors.New("--iterations and --parallel must be at least 1")
+	}
+	timeout, timeouts := request_module.ConfigTimeouts(config)
+	if cmd.Flags().Changed("timeout") {
+		timeout, _ = cmd.Flags().GetDuration("timeout")
+	}
+	if timeout < 0 {
+		return testrunner_module.Options{}, errors.New("--timeout can't be negative")
+	}
+	stopOnFailure, _ := cmd.Flags().GetBool("stop-on-failure")
+
+	options := testrunner_module.Options{
+		Collection:    collection,
+		Environment:   GetEnvironmentFunc(envName).Variables,
+		Iterations:    iterations,
+		Parallel:      parallel,
+		StopOnFailure: stopOnFailure,
+		Timeout:       timeout,
+		Timeouts:      timeouts,
+	}
+
+	if path, _ := cmd.Flags().GetString("iteration-data"); path != "" {
+		data, err := LoadDataFunc(path)
+		if err != nil {
+			return testrunner_module.Options{}, errors.New("Could not read the data: " + err.Error())
+		}
+		options.Data = data
+	}
+	return options, nil
+}
+
+// runHeadless prints each case once it is over, in the order they end, and
+// a summary.
+func runHeadless(options testrunner_module.Options) testrunner_module.Run {
+	iterations := len(options.Data) > 1 || options.Iterations > 1
+	var mutex sync.Mutex
+	run := ExecuteFunc(context.Background(), options, func(_ int, c testrunner_module.Case) {
+		if c.Status == testrunner_module.StatusPending || c.Status == testrunner_module.StatusRunning {
+			return
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		fmt.Fprintln(headless_module.Output, caseLine(c, iterations))
+		for _, failure := range c.Failures {
+			fmt.Fprintln(headless_module.Output, "    "+failure)
+		}
+	})
+
+	passed, failed, skipped := run.Counts()
+	fmt.Fprintf(headless_module.Output, "Tests: %d passed, %d failed, %d skipped (%s)\n", passed, failed, skipped, run.Duration.Round(time.Millisecond))
+	return run
+}
+
+func caseLine(c testrunner_module.Case, iterations bool) string {
+	icon := map[testrunner_module.Status]string{
+		testrunner_module.StatusPassed:  "✓",
+		testrunner_module.StatusFailed:  "✗",
+		testrunner_module.StatusSkipped: "-",
+	}[c.Status]
+
+	parts := []string{icon}
+	if iterations {
+		parts = append(parts, fmt.Sprintf("#%d", c.Iteration))
+	}
+	parts = append(parts, c.Name())
+	if c.Response != nil {
+		parts = append(parts, fmt.Sprintf("%d", c.Response.StatusCode))
+	}
+	if c.Status != testrunner_module.StatusSkipped {
+		parts = append(parts, c.Duration.Round(time.Millisecond).String())
+	}
+	return strings.Join(parts, " ")
+}
+
+// writeReports writes the --junit and --json reports, telling whether they
+// were all written. Headless runs note them on stderr, stdout has the results.
+func writeReports(cmd *cobra.Command, run testrunner_module.Run, headless bool) bool {
+	written := true
+	for _, report := range []struct {
+		flag  string
+		build func(run testrunner_module.Run) ([]byte, error)
+	}{
+		{"junit", testrunner_module.JUnit},
+		{"json", testrunner_module.Json},
+	} {
+		path, _ := cmd.Flags().GetString(report.flag)
+		if path == "" {
+			continue
+		}
+		if err := WriteReportFunc(path, report.build, run); err != nil {
+			LoggerError("Could not write the "+report.flag+" report: "+err.Error(), 70)
+			written = false
+			continue
+		}
+		if headless {
+			fmt.Fprintln(headless_module.ErrorOutput, "Report written to "+path)
+		} else {
+			LoggerSuccess("Report written to "+path, 70)
+		}
+	}
+	return written
+}
@@ -0,0 +1,175 @@
+package test_command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	collection_module "github.com/diogopereiradev/httpzen/internal/collection"
+	config_module "github.com/diogopereiradev/httpzen/internal/config"
+	environment_module "github.com/diogopereiradev/httpzen/internal/environment"
+	headless_module "github.com/diogopereiradev/httpzen/internal/headless"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	testrunner_module "github.com/diogopereiradev/httpzen/internal/testrunner"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+type testStubs struct {
+	codes     []int
+	errors    []string
+	options   []testrunner_module.Options
+	reports   []string
+	menu      bool
+	output    *bytes.Buffer
+	errOutput *bytes.Buffer
+}
+
+func testCollection() collection_module.Collection {
+	return collection_module.Collection{
+		Name: "api",
+		Requests: []collection_module.SavedRequest{
+			{Name: "login", Folder: "auth", Request: request_module.RequestOptions{Method: "POST", Url: "http://a/login"}},
+			{Name: "me", Request: request_module.RequestOptions{Method: "GET", Url: "http://a/me"}},
+		},
+	}
+}
+
+// stubTest runs every case with the given statuses, in order.
+func stubTest(t *testing.T, statuses ...testrunner_module.Status) *testStubs {
+	stubs := &testStubs{output: &bytes.Buffer{}, errOutput: &bytes.Buffer{}}
+	oldExit, oldError, oldSuccess := Exit, LoggerError, LoggerSuccess
+	oldConfig, oldCollection, oldEnvironment, oldData := GetConfigFunc, GetCollectionFunc, GetEnvironmentFunc, LoadDataFunc
+	oldExecute, oldMenu, oldWrite := ExecuteFunc, TestMenuNewFunc, WriteReportFunc
+	oldOutput, oldErrorOutput := headless_module.Output, headless_module.ErrorOutput
+	t.Cleanup(func() {
+		Exit, LoggerError, LoggerSuccess = oldExit, oldError, oldSuccess
+		GetConfigFunc, GetCollectionFunc, GetEnvironmentFunc, LoadDataFunc = oldConfig, oldCollection, oldEnvironment, oldData
+		ExecuteFunc, TestMenuNewFunc, WriteReportFunc = oldExecute, oldMenu, oldWrite
+		headless_module.Output, headless_module.ErrorOutput = oldOutput, oldErrorOutput
+	})
+
+	Exit = func(code int) { stubs.codes = append(stubs.codes, code) }
+	LoggerError = func(message string, _ int) { stubs.errors = append(stubs.errors, message) }
+	LoggerSuccess = func(string, int) {}
+	GetConfigFunc = func() config_module.Config {
+		return config_module.Config{ActiveEnvironment: "dev", Timeout: 1000, ConnectTimeout: 200}
+	}
+	GetCollectionFunc = func(name string) (collection_module.Collection, bool) {
+		return testCollection(), name == "api"
+	}
+	GetEnvironmentFunc = func(name string) environment_module.Environment {
+		return environment_module.Environment{Name: name, Variables: map[string]string{"env": name}}
+	}
+	LoadDataFunc = func(path string) ([]map[string]string, error) {
+		if path != "users.csv" {
+			return nil, errors.New("no such file")
+		}
+		return []map[string]string{{"user": "ana"}, {"user": "bob"}}, nil
+	}
+	ExecuteFunc = func(_ context.Context, options testrunner_module.Options, update func(int, testrunner_module.Case)) testrunner_module.Run {
+		stubs.options = append(stubs.options, options)
+		run := testrunner_module.Run{Collection: options.Collection.Name, Cases: testrunner_module.Plan(options)}
+		for i := range run.Cases {
+			c := &run.Cases[i]
+			c.Status = statuses[i%len(statuses)]
+			if c.Status == testrunner_module.StatusFailed {
+				c.Failures = []string{"test \"ok\" failed: status 500"}
+				c.Response = &request_module.RequestResponse{StatusCode: 500}
+			}
+			if c.Status == testrunner_module.StatusPassed {
+				c.Response = &request_module.RequestResponse{StatusCode: 200}
+				c.Duration = 12 * time.Millisecond
+			}
+			update(i, *c)
+		}
+		return run
+	}
+	TestMenuNewFunc = func(options testrunner_module.Options) testrunner_module.Run {
+		stubs.menu = true
+		return ExecuteFunc(context.Background(), options, func(int, testrunner_module.Case) {})
+	}
+	WriteReportFunc = func(path string, build func(testrunner_module.Run) ([]byte, error), run testrunner_module.Run) error {
+		if path == "denied.xml" {
+			return errors.New("permission denied")
+		}
+		stubs.reports = append(stubs.reports, path)
+		_, err := build(run)
+		return err
+	}
+	headless_module.Output, headless_module.ErrorOutput = stubs.output, stubs.errOutput
+	return stubs
+}
+
+func runTest(args ...string) {
+	root := &cobra.Command{Use: "httpzen"}
+	Init(root)
+	root.SetArgs(append([]string{"test"}, args...))
+	root.Execute()
+}
+
+func TestTest_Headless(t *testing.T) {
+	stubs := stubTest(t, testrunner_module.StatusPassed, testrunner_module.StatusFailed)
+
+	runTest("api", "--headless", "--iteration-data", "users.csv", "-e", "prod", "--parallel", "2", "--stop-on-failure")
+
+	assert.False(t, stubs.menu)
+	assert.Equal(t, []int{66}, stubs.codes)
+	options := stubs.options[0]
+	assert.Equal(t, map[string]string{"env": "prod"}, options.Environment)
+	assert.Len(t, options.Data, 2)
+	assert.Equal(t, 2, options.Parallel)
+	assert.True(t, options.StopOnFailure)
+	assert.Equal(t, time.Second, options.Timeout, "the timeout should come from the config")
+	assert.Equal(t, 200*time.Millisecond, options.Timeouts.Connect)
+	assert.Equal(t, "✓ #1 auth/login 200 12ms\n"+
+		"✗ #1 me 500 0s\n"+
+		"    test \"ok\" failed: status 500\n"+
+		"✓ #2 auth/login 200 12ms\n"+
+		"✗ #2 me 500 0s\n"+
+		"    test \"ok\" failed: status 500\n"+
+		"Tests: 2 passed, 2 failed, 0 skipped (0s)\n", stubs.output.String())
+}
+
+func TestTest_Passed(t *testing.T) {
+	stubs := stubTest(t, testrunner_module.StatusPassed)
+
+	runTest("api", "--iterations", "3", "--timeout", "250ms", "--junit", "report.xml", "--json", "report.json")
+
+	assert.True(t, stubs.menu)
+	assert.Empty(t, stubs.codes)
+	assert.Equal(t, 3, stubs.options[0].Iterations)
+	assert.Equal(t, 250*time.Millisecond, stubs.options[0].Timeout)
+	assert.Equal(t, map[string]string{"env": "dev"}, stubs.options[0].Environment)
+	assert.Equal(t, []string{"report.xml", "report.json"}, stubs.reports)
+}
+
+func TestTest_Skipped(t *testing.T) {
+	stubs := stubTest(t, testrunner_module.StatusSkipped)
+
+	runTest("api", "--headless", "--json", "report.json")
+
+	assert.Empty(t, stubs.codes)
+	assert.Equal(t, "- auth/login\n- me\nTests: 0 passed, 0 failed, 2 skipped (0s)\n", stubs.output.String())
+	assert.Equal(t, "Report written to report.json\n", stubs.errOutput.String())
+}
+
+func TestTest_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		args    []string
+		message string
+	}{
+		{[]string{"missing"}, "The collection \"missing\" does not exist."},
+		{[]string{"api", "--iteration-data", "users.json"}, "Could not read the data: no such file"},
+		{[]string{"api", "--parallel", "0"}, "--iterations and --parallel must be at least 1"},
+		{[]string{"api", "--timeout", "-1s"}, "--timeout can't be negative"},
+		{[]string{"api", "--headless", "--junit", "denied.xml"}, "Could not write the junit report: permission denied"},
+	} {
+		stubs := stubTest(t, testrunner_module.StatusPassed)
+		runTest(tc.args...)
+		assert.Equal(t, []string{tc.message}, stubs.errors, tc.args)
+		assert.Equal(t, []int{1}, stubs.codes, tc.args)
+	}
+}
@@ -0,0 +1,89 @@
+package ws_command
+
+import (
+	"os"
+	"time"
+
+	config_module "github.com/diogopereiradev/httpzen/internal/config"
+	logger_module "github.com/diogopereiradev/httpzen/internal/logger"
+	"github.com/diogopereiradev/httpzen/internal/menus/websocket_menu"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/diogopereiradev/httpzen/internal/utils/http_utility"
+	websocket_module "github.com/diogopereiradev/httpzen/internal/websocket"
+	"github.com/spf13/cobra"
+)
+
+var Exit = os.Exit
+var LoggerError = logger_module.Error
+var GetConfigFunc = config_module.GetConfig
+var WebsocketMenuNewFunc = websocket_menu.New
+var ReadScriptFunc = websocket_module.ReadScript
+
+func Init(rootCmd *cobra.Command) {
+	cmd := &cobra.Command{
+		Use:   "ws [URL]",
+		Short: "Open an interactive console on a WebSocket connection",
+		Args:  cobra.ArbitraryArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) < 1 {
+				cmd.Help()
+				return
+			}
+
+			url := http_utility.ParseWebSocketUrl(args[0])
+			if url == "" {
+				LoggerError("Invalid URL. Please provide a valid WebSocket URL (ws://, wss://, http:// or https://).", 70)
+				Exit(request_module.ExitCodes[request_module.ErrorClassInvalidUrl])
+				return
+			}
+
+			headers, _ := cmd.Flags().GetStringSlice("header")
+			scriptPath, _ := cmd.Flags().GetString("script")
+			interval, _ := cmd.Flags().GetDuration("interval")
+			exportPath, _ := cmd.Flags().GetString("export")
+
+			timeout, _ := request_module.ConfigTimeouts(GetConfigFunc())
+			if cmd.Flags().Changed("timeout") {
+				timeout, _ = cmd.Flags().GetDuration("timeout")
+			}
+			if timeout < 0 {
+				LoggerError("Invalid timeout: --timeout can't be negative", 70)
+				Exit(1)
+				return
+			}
+
+			options := websocket_menu.Options{
+				Connect: websocket_module.ConnectOptions{
+					Url:     url,
+					Headers: http_utility.ParseHeaders(headers),
+					Timeout: timeout,
+				},
+				Interval:   interval,
+				ExportPath: exportPath,
+			}
+
+			if scriptPath != "" {
+				script, err := ReadScriptFunc(scriptPath)
+				if err != nil {
+					LoggerError("Failed to read the script file: "+err.Error(), 70)
+					Exit(1)
+					return
+				}
+				options.Script = script
+			}
+
+			if err := WebsocketMenuNewFunc(options); err != nil {
+				LoggerError(err.Error(), 70)
+				Exit(request_module.ExitCode(err))
+			}
+		},
+	}
+
+	cmd.Flags().StringSliceP("header", "H", []string{}, "Add a header to the handshake request (can be used multiple times)")
+	cmd.Flags().String("script", "", "Send each line of a file as a text message once connected")
+	cmd.Flags().Duration("interval", 500*time.Millisecond, "Delay between the messages of a script")
+	cmd.Flags().String("export", "", "Write the session log to a file when the console is closed")
+	cmd.Flags().Duration("timeout", 0, "Time limit for the handshake, 0 for none (default: from the config, 30s)")
+
+	rootCmd.AddCommand(cmd)
+}
@@ -0,0 +1,119 @@
+package ws_command
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	config_module "github.com/diogopereiradev/httpzen/internal/config"
+	"github.com/diogopereiradev/httpzen/internal/menus/websocket_menu"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func stubCommand(t *testing.T) *[]int {
+	codes := &[]int{}
+	oldExit, oldLogger, oldMenu, oldScript, oldConfig := Exit, LoggerError, WebsocketMenuNewFunc, ReadScriptFunc, GetConfigFunc
+	t.Cleanup(func() {
+		Exit, LoggerError, WebsocketMenuNewFunc, ReadScriptFunc, GetConfigFunc = oldExit, oldLogger, oldMenu, oldScript, oldConfig
+	})
+
+	Exit = func(code int) { *codes = append(*codes, code) }
+	LoggerError = func(string, int) {}
+	GetConfigFunc = func() config_module.Config { return config_module.Config{Timeout: 1500} }
+	return codes
+}
+
+func runWs(args ...string) {
+	rootCmd := &cobra.Command{Use: "root"}
+	Init(rootCmd)
+	rootCmd.SetArgs(append([]string{"ws"}, args...))
+	rootCmd.Execute()
+}
+
+func TestInit_AddsCommand(t *testing.T) {
+	rootCmd := &cobra.Command{Use: "root"}
+	Init(rootCmd)
+
+	cmd, _, err := rootCmd.Find([]string{"ws"})
+	assert.NoError(t, err)
+	assert.Equal(t, "ws [URL]", cmd.Use)
+	assert.NotNil(t, cmd.Flags().Lookup("header"))
+	assert.NotNil(t, cmd.Flags().Lookup("script"))
+	assert.NotNil(t, cmd.Flags().Lookup("export"))
+}
+
+func TestWs_OpensConsole(t *testing.T) {
+	codes := stubCommand(t)
+
+	var got websocket_menu.Options
+	WebsocketMenuNewFunc = func(options websocket_menu.Options) error {
+		got = options
+		return nil
+	}
+
+	runWs("https://example.com/socket", "-H", "Authorization: Bearer abc", "--export", "session.log", "--interval", "1s")
+
+	assert.Empty(t, *codes)
+	assert.Equal(t, "wss://example.com/socket", got.Connect.Url)
+	assert.Equal(t, "Bearer abc", got.Connect.Headers.Get("Authorization"))
+	assert.Equal(t, "session.log", got.ExportPath)
+	assert.Equal(t, time.Second, got.Interval)
+	assert.Equal(t, 1500*time.Millisecond, got.Connect.Timeout, "the handshake timeout should come from the config")
+	assert.Nil(t, got.Script)
+}
+
+func TestWs_Timeout(t *testing.T) {
+	codes := stubCommand(t)
+
+	var got websocket_menu.Options
+	WebsocketMenuNewFunc = func(options websocket_menu.Options) error {
+		got = options
+		return nil
+	}
+
+	runWs("ws://localhost:8080", "--timeout", "5s")
+	assert.Empty(t, *codes)
+	assert.Equal(t, 5*time.Second, got.Connect.Timeout)
+
+	runWs("ws://localhost:8080", "--timeout", "-1s")
+	assert.Equal(t, []int{1}, *codes)
+}
+
+func TestWs_Script(t *testing.T) {
+	codes := stubCommand(t)
+
+	var got websocket_menu.Options
+	WebsocketMenuNewFunc = func(options websocket_menu.Options) error {
+		got = options
+		return nil
+	}
+	ReadScriptFunc = func(path string) ([]string, error) {
+		assert.Equal(t, "messages.txt", path)
+		return []string{"one", "two"}, nil
+	}
+
+	runWs("ws://localhost:8080", "--script", "messages.txt")
+	assert.Empty(t, *codes)
+	assert.Equal(t, []string{"one", "two"}, got.Script)
+
+	ReadScriptFunc = func(path string) ([]string, error) { return nil, errors.New("missing") }
+	WebsocketMenuNewFunc = func(options websocket_menu.Options) error {
+		t.Error("console should not open without the script")
+		return nil
+	}
+	runWs("ws://localhost:8080", "--script", "missing.txt")
+	assert.Equal(t, []int{1}, *codes)
+}
+
+func TestWs_Errors(t *testing.T) {
+	codes := stubCommand(t)
+	WebsocketMenuNewFunc = func(options websocket_menu.Options) error {
+		return &request_module.RequestError{Class: request_module.ErrorClassConnectionRefused}
+	}
+
+	runWs("ftp://example.com")
+	runWs("ws://localhost:1")
+	assert.Equal(t, []int{3, 7}, *codes)
+}
@@ -4,10 +4,19 @@ import (
 	"os"
 
 	clean_cache_command "github.com/diogopereiradev/httpzen/cmd/commands/clean-cache"
+	collection_command "github.com/diogopereiradev/httpzen/cmd/commands/collection"
 	config_command "github.com/diogopereiradev/httpzen/cmd/commands/config"
+	export_command "github.com/diogopereiradev/httpzen/cmd/commands/export"
+	grpc_command "github.com/diogopereiradev/httpzen/cmd/commands/grpc"
 	help_command "github.com/diogopereiradev/httpzen/cmd/commands/help"
+	import_command "github.com/diogopereiradev/httpzen/cmd/commands/import"
 	request_command "github.com/diogopereiradev/httpzen/cmd/commands/request"
+	run_command "github.com/diogopereiradev/httpzen/cmd/commands/run"
+	snapshot_command "github.com/diogopereiradev/httpzen/cmd/commands/snapshot"
+	test_command "github.com/diogopereiradev/httpzen/cmd/commands/test"
 	version_command "github.com/diogopereiradev/httpzen/cmd/commands/version"
+	ws_command "github.com/diogopereiradev/httpzen/cmd/commands/ws"
+	har_module "github.com/diogopereiradev/httpzen/internal/har"
 	logger_module "github.com/diogopereiradev/httpzen/internal/logger"
 	"github.com/spf13/cobra"
 )
@@ -37,6 +46,16 @@ func init() {
 	request_command.Init(rootCmd)
 	clean_cache_command.Init(rootCmd)
 	config_command.Init(rootCmd)
+	ws_command.Init(rootCmd)
+	grpc_command.Init(rootCmd)
+	export_command.Init(rootCmd)
+	import_command.Init(rootCmd)
+	run_command.Init(rootCmd)
+	collection_command.Init(rootCmd)
+	test_command.Init(rootCmd)
+	snapshot_command.Init(rootCmd)
+
+	har_module.Version = version_command.Version
 
 	setFlagErrorFunc(rootCmd)
 }
@@ -0,0 +1,208 @@
+package auth_module
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	ip_cache_module "github.com/diogopereiradev/httpzen/internal/cache"
+	"github.com/go-resty/resty/v2"
+)
+
+type AuthType string
+
+const (
+	AuthNone   AuthType = ""
+	AuthBasic  AuthType = "basic"
+	AuthBearer AuthType = "bearer"
+	AuthDigest AuthType = "digest"
+	AuthOAuth2 AuthType = "oauth2"
+)
+
+var AuthTypes = []AuthType{AuthNone, AuthBasic, AuthBearer, AuthDigest, AuthOAuth2}
+
+type AuthOptions struct {
+	Type         AuthType `json:"type,omitempty"`
+	Username     string   `json:"username,omitempty"`
+	Password     string   `json:"password,omitempty"`
+	Token        string   `json:"token,omitempty"`
+	TokenUrl     string   `json:"token_url,omitempty"`
+	ClientId     string   `json:"client_id,omitempty"`
+	ClientSecret string   `json:"client_secret,omitempty"`
+	Scopes       string   `json:"scopes,omitempty"`
+}
+
+type OAuth2Token struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// Tokens are refreshed slightly before the server says they expire, so a
+// request never goes out with a token that dies in flight.
+const tokenExpiryMargin = 30 * time.Second
+
+var restyNew = resty.New
+var now = time.Now
+var getTokenFromCache = ip_cache_module.GetTokenFromCache
+var setTokenToCache = ip_cache_module.SetTokenToCache
+var removeTokenFromCache = ip_cache_module.RemoveTokenFromCache
+
+func ParseAuthType(value string) (AuthType, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "none":
+		return AuthNone, nil
+	case "basic":
+		return AuthBasic, nil
+	case "bearer":
+		return AuthBearer, nil
+	case "digest":
+		return AuthDigest, nil
+	case "oauth2", "oauth":
+		return AuthOAuth2, nil
+	default:
+		return AuthNone, fmt.Errorf("unsupported auth type %q (expected basic, bearer, digest or oauth2)", value)
+	}
+}
+
+func (o AuthOptions) IsEmpty() bool {
+	return o.Type == AuthNone
+}
+
+// Redacted keeps only the auth type, for requests written to disk. A resend
+// takes the credentials from the environment again.
+func (o AuthOptions) Redacted() AuthOptions {
+	return AuthOptions{Type: o.Type}
+}
+
+func (o AuthOptions) Validate() error {
+	switch o.Type {
+	case AuthBasic, AuthDigest:
+		if o.Username == "" {
+			return errors.New(string(o.Type) + " auth requires a username")
+		}
+	case AuthBearer:
+		if o.Token == "" {
+			return errors.New("bearer auth requires a token")
+		}
+	case AuthOAuth2:
+		if o.TokenUrl == "" || o.ClientId == "" {
+			return errors.New("oauth2 auth requires a token URL and a client ID")
+		}
+	}
+	return nil
+}
+
+// Apply configures the resty client so every request it sends carries the
+// credentials described by options. Digest auth is answered by resty, which
+// replays the request once the server sends its challenge.
+func Apply(client *resty.Client, options AuthOptions) error {
+	if err := options.Validate(); err != nil {
+		return err
+	}
+
+	switch options.Type {
+	case AuthBasic:
+		client.SetBasicAuth(options.Username, options.Password)
+	case AuthBearer:
+		client.SetAuthToken(options.Token)
+	case AuthDigest:
+		client.SetDigestAuth(options.Username, options.Password)
+	case AuthOAuth2:
+		token, err := GetClientCredentialsToken(options)
+		if err != nil {
+			return err
+		}
+		if token.TokenType != "" && !strings.EqualFold(token.TokenType, "bearer") {
+			client.SetAuthScheme(token.TokenType)
+		}
+		client.SetAuthToken(token.AccessToken)
+	}
+	return nil
+}
+
+// GetClientCredentialsToken returns a cached token while it is still valid
+// and fetches a new one from the token URL otherwise.
+func GetClientCredentialsToken(options AuthOptions) (OAuth2Token, error) {
+	key := tokenCacheKey(options)
+	if cached, ok := getTokenFromCache(key); ok {
+		var token OAuth2Token
+		b, _ := json.Marshal(cached)
+		if err := json.Unmarshal(b, &token); err == nil && token.AccessToken != "" {
+			if now().Add(tokenExpiryMargin).Before(token.ExpiresAt) {
+				return token, nil
+			}
+		}
+	}
+
+	token, err := FetchClientCredentialsToken(options)
+	if err != nil {
+		return OAuth2Token{}, err
+	}
+
+	// A token the cache can't keep is still good for this request, the next one
+	// fetches a new token.
+	_ = setTokenToCache(key, map[string]any{
+		"access_token": token.AccessToken,
+		"token_type":   token.TokenType,
+		"expires_at":   token.ExpiresAt.Format(time.RFC3339),
+	})
+	return token, nil
+}
+
+func FetchClientCredentialsToken(options AuthOptions) (OAuth2Token, error) {
+	form := map[string]string{
+		"grant_type":    "client_credentials",
+		"client_id":     options.ClientId,
+		"client_secret": options.ClientSecret,
+	}
+	if options.Scopes != "" {
+		form["scope"] = options.Scopes
+	}
+
+	client := restyNew()
+	res, err := client.R().
+		SetHeader("Accept", "application/json").
+		SetFormData(form).
+		Post(options.TokenUrl)
+	if err != nil {
+		return OAuth2Token{}, errors.New("failed to fetch OAuth2 token: " + err.Error())
+	}
+	if res.StatusCode() < 200 || res.StatusCode() >= 300 {
+		return OAuth2Token{}, fmt.Errorf("failed to fetch OAuth2 token: token endpoint answered %s", res.Status())
+	}
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(res.Body(), &body); err != nil || body.AccessToken == "" {
+		return OAuth2Token{}, errors.New("failed to fetch OAuth2 token: token endpoint returned no access_token")
+	}
+
+	expiresIn := body.ExpiresIn
+	if expiresIn <= 0 {
+		expiresIn = 3600
+	}
+	return OAuth2Token{
+		AccessToken: body.AccessToken,
+		TokenType:   body.TokenType,
+		ExpiresAt:   now().Add(time.Duration(expiresIn) * time.Second),
+	}, nil
+}
+
+// InvalidateToken drops the cached token, used when the API rejects it
+// before its advertised expiry.
+func InvalidateToken(options AuthOptions) {
+	_ = removeTokenFromCache(tokenCacheKey(options))
+}
+
+func tokenCacheKey(options AuthOptions) string {
+	sum := sha256.Sum256([]byte(options.TokenUrl + "\x00" + options.ClientId + "\x00" + options.Scopes))
+	return "oauth2_" + hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,142 @@
+package auth_module
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	ip_cache_module "github.com/diogopereiradev/httpzen/internal/cache"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func stubTokenCache() (map[string]map[string]any, func()) {
+	store := map[string]map[string]any{}
+	getTokenFromCache = func(key string) (map[string]any, bool) {
+		v, ok := store[key]
+		return v, ok
+	}
+	setTokenToCache = func(key string, token map[string]any) error { store[key] = token; return nil }
+	removeTokenFromCache = func(key string) error { delete(store, key); return nil }
+	return store, func() {
+		getTokenFromCache = ip_cache_module.GetTokenFromCache
+		setTokenToCache = ip_cache_module.SetTokenToCache
+		removeTokenFromCache = ip_cache_module.RemoveTokenFromCache
+	}
+}
+
+func TestParseAuthType(t *testing.T) {
+	cases := map[string]AuthType{"": AuthNone, "none": AuthNone, "Basic": AuthBasic, "bearer": AuthBearer, "DIGEST": AuthDigest, "oauth": AuthOAuth2}
+	for input, want := range cases {
+		got, err := ParseAuthType(input)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	_, err := ParseAuthType("ntlm")
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	assert.Error(t, AuthOptions{Type: AuthBasic}.Validate())
+	assert.Error(t, AuthOptions{Type: AuthBearer}.Validate())
+	assert.Error(t, AuthOptions{Type: AuthOAuth2, TokenUrl: "http://x"}.Validate())
+	assert.NoError(t, AuthOptions{Type: AuthBasic, Username: "user"}.Validate())
+	assert.NoError(t, AuthOptions{}.Validate())
+}
+
+func TestRedacted(t *testing.T) {
+	auth := AuthOptions{Type: AuthOAuth2, TokenUrl: "http://x", ClientId: "id", ClientSecret: "secret", Scopes: "read"}
+	assert.Equal(t, AuthOptions{Type: AuthOAuth2}, auth.Redacted())
+	assert.Equal(t, AuthOptions{}, AuthOptions{}.Redacted())
+}
+
+func TestApply_BasicAndBearer(t *testing.T) {
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+
+	client := resty.New()
+	assert.NoError(t, Apply(client, AuthOptions{Type: AuthBasic, Username: "user", Password: "pass"}))
+	_, _ = client.R().Get(server.URL)
+	assert.Equal(t, "Basic dXNlcjpwYXNz", gotAuth)
+
+	client = resty.New()
+	assert.NoError(t, Apply(client, AuthOptions{Type: AuthBearer, Token: "abc"}))
+	_, _ = client.R().Get(server.URL)
+	assert.Equal(t, "Bearer abc", gotAuth)
+}
+
+func TestApply_Digest(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("Authorization") == "" {
+			w.Header().Set("WWW-Authenticate", `Digest realm="test", qop="auth", nonce="abc", opaque="xyz"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := resty.New()
+	assert.NoError(t, Apply(client, AuthOptions{Type: AuthDigest, Username: "user", Password: "pass"}))
+	res, err := client.R().Get(server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode())
+	assert.Equal(t, 2, calls)
+}
+
+func TestGetClientCredentialsToken_CachesUntilExpiry(t *testing.T) {
+	_, restore := stubTokenCache()
+	defer restore()
+
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		_ = r.ParseForm()
+		assert.Equal(t, "client_credentials", r.Form.Get("grant_type"))
+		assert.Equal(t, "client", r.Form.Get("client_id"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"token-1","token_type":"Bearer","expires_in":120}`))
+	}))
+	defer server.Close()
+
+	current := time.Now()
+	now = func() time.Time { return current }
+	defer func() { now = time.Now }()
+
+	options := AuthOptions{Type: AuthOAuth2, TokenUrl: server.URL, ClientId: "client", ClientSecret: "secret"}
+
+	token, err := GetClientCredentialsToken(options)
+	assert.NoError(t, err)
+	assert.Equal(t, "token-1", token.AccessToken)
+
+	_, err = GetClientCredentialsToken(options)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, fetches, "should reuse the cached token")
+
+	current = current.Add(100 * time.Second)
+	_, err = GetClientCredentialsToken(options)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, fetches, "should refresh a token close to its expiry")
+
+	InvalidateToken(options)
+	_, err = GetClientCredentialsToken(options)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, fetches, "should refetch after invalidation")
+}
+
+func TestFetchClientCredentialsToken_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	_, err := FetchClientCredentialsToken(AuthOptions{TokenUrl: server.URL, ClientId: "client"})
+	assert.Error(t, err)
+}
@@ -107,19 +107,25 @@ func (o *BenchmarkOptions) runThreads(model *BenchmarkResult, metrics *Metrics)
 }
 
 func (o *BenchmarkOptions) doRequest(model *BenchmarkResult) *request_module.RequestResponse {
-	resp := request_module.RunRequest(request_module.RequestOptions{
-		Method:      o.Request.Method,
-		Url:         o.Request.Url,
-		Headers:     o.Request.Headers,
-		Body:        o.Request.Body,
-		Timeout:     time.Duration(1 * time.Minute),
-		BypassError: true,
+	resp, err := request_module.RunRequest(request_module.RequestOptions{
+		Method:       o.Request.Method,
+		CustomMethod: o.Request.CustomMethod,
+		Url:          o.Request.Url,
+		Headers:      o.Request.Headers,
+		Body:         o.Request.Body,
+		Auth:         o.Request.Auth,
+		Protocol:     o.Request.Protocol,
+		UnixSocket:   o.Request.UnixSocket,
+		Resolve:      o.Request.Resolve,
+		ConnectTo:    o.Request.ConnectTo,
+		Timeout:      o.Request.Timeout,
+		Timeouts:     o.Request.Timeouts,
 	})
 
 	model.mutex.Lock()
 	defer model.mutex.Unlock()
 
-	if resp.StatusCode >= 200 && resp.StatusCode < 400 {
+	if err == nil && resp.StatusCode >= 200 && resp.StatusCode < 400 {
 		model.Metrics.TotalSuccess++
 	} else {
 		model.Metrics.TotalErrors++
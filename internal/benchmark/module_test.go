@@ -20,12 +20,12 @@ func TestInitialResultModel(t *testing.T) {
 
 func TestRunBenchmark(t *testing.T) {
 	origRunRequest := request_module.RunRequest
-	request_module.RunRequest = func(options request_module.RequestOptions) request_module.RequestResponse {
+	request_module.RunRequest = func(options request_module.RequestOptions) (request_module.RequestResponse, error) {
 		return request_module.RequestResponse{
 			StatusCode:    200,
 			ExecutionTime: 10,
 			Result:        "response body",
-		}
+		}, nil
 	}
 	defer func() { request_module.RunRequest = origRunRequest }()
 
@@ -63,8 +63,8 @@ func TestDoRequestSuccessAndError(t *testing.T) {
 	}
 
 	origRunRequest := request_module.RunRequest
-	request_module.RunRequest = func(options request_module.RequestOptions) request_module.RequestResponse {
-		return request_module.RequestResponse{StatusCode: 200, ExecutionTime: 5, Result: "ok"}
+	request_module.RunRequest = func(options request_module.RequestOptions) (request_module.RequestResponse, error) {
+		return request_module.RequestResponse{StatusCode: 200, ExecutionTime: 5, Result: "ok"}, nil
 	}
 	resp := options.doRequest(model)
 	if resp.StatusCode != 200 {
@@ -74,8 +74,8 @@ func TestDoRequestSuccessAndError(t *testing.T) {
 		t.Errorf("Expected TotalSuccess 1, got %d", model.Metrics.TotalSuccess)
 	}
 
-	request_module.RunRequest = func(options request_module.RequestOptions) request_module.RequestResponse {
-		return request_module.RequestResponse{StatusCode: 500, ExecutionTime: 5, Result: "fail"}
+	request_module.RunRequest = func(options request_module.RequestOptions) (request_module.RequestResponse, error) {
+		return request_module.RequestResponse{StatusCode: 500, ExecutionTime: 5, Result: "fail"}, nil
 	}
 	resp = options.doRequest(model)
 	if resp.StatusCode != 500 {
@@ -84,14 +84,34 @@ func TestDoRequestSuccessAndError(t *testing.T) {
 	if model.Metrics.TotalErrors != 1 {
 		t.Errorf("Expected TotalErrors 1, got %d", model.Metrics.TotalErrors)
 	}
+
+	request_module.RunRequest = func(options request_module.RequestOptions) (request_module.RequestResponse, error) {
+		return request_module.RequestResponse{}, &request_module.RequestError{Class: request_module.ErrorClassTimeout}
+	}
+	options.doRequest(model)
+	if model.Metrics.TotalErrors != 2 {
+		t.Errorf("Expected transport failures to count as errors, got %d", model.Metrics.TotalErrors)
+	}
+
+	var got request_module.RequestOptions
+	request_module.RunRequest = func(options request_module.RequestOptions) (request_module.RequestResponse, error) {
+		got = options
+		return request_module.RequestResponse{StatusCode: 200}, nil
+	}
+	options.Request.Timeout = 5 * time.Second
+	options.Request.Timeouts = request_module.Timeouts{Connect: time.Second}
+	options.doRequest(model)
+	if got.Timeout != 5*time.Second || got.Timeouts.Connect != time.Second {
+		t.Errorf("Expected the request timeouts to be used, got %v and %+v", got.Timeout, got.Timeouts)
+	}
 	request_module.RunRequest = origRunRequest
 }
 
 func TestRunThreads(t *testing.T) {
 	origRunRequest := request_module.RunRequest
-	request_module.RunRequest = func(options request_module.RequestOptions) request_module.RequestResponse {
+	request_module.RunRequest = func(options request_module.RequestOptions) (request_module.RequestResponse, error) {
 		time.Sleep(10 * time.Millisecond)
-		return request_module.RequestResponse{StatusCode: 200, ExecutionTime: 1, Result: "ok"}
+		return request_module.RequestResponse{StatusCode: 200, ExecutionTime: 1, Result: "ok"}, nil
 	}
 	defer func() { request_module.RunRequest = origRunRequest }()
 
@@ -0,0 +1,53 @@
+package ip_cache_module
+
+import (
+	"os"
+	"sync"
+
+	app_path_util "github.com/diogopereiradev/httpzen/internal/utils/app_path"
+	"github.com/spf13/viper"
+)
+
+var (
+	GraphQLSchemaCacheFileName = "graphql_schema_cache"
+	graphqlSchemaCacheLock     sync.Mutex
+	graphqlSchemaCacheViper    = newGraphQLSchemaCacheViper()
+)
+
+func newGraphQLSchemaCacheViper() *viper.Viper {
+	v := viper.New()
+	v.SetConfigName(GraphQLSchemaCacheFileName)
+	v.SetConfigType(cacheFileExtension)
+	v.AddConfigPath(app_path_util.GetConfigPath())
+	_ = v.ReadInConfig()
+	return v
+}
+
+// GetGraphQLSchemaFromCache returns the introspection result saved for key
+// and the unix time it was fetched at. The key must not contain dots, viper
+// would read them as nested keys.
+func GetGraphQLSchemaFromCache(key string) (string, int64, bool) {
+	graphqlSchemaCacheLock.Lock()
+	defer graphqlSchemaCacheLock.Unlock()
+	if !graphqlSchemaCacheViper.IsSet(key) {
+		return "", 0, false
+	}
+	schema := graphqlSchemaCacheViper.GetString(key + ".schema")
+	fetchedAt := graphqlSchemaCacheViper.GetInt64(key + ".fetched_at")
+	return schema, fetchedAt, schema != ""
+}
+
+func SetGraphQLSchemaToCache(key string, schema string, fetchedAt int64) {
+	graphqlSchemaCacheLock.Lock()
+	defer graphqlSchemaCacheLock.Unlock()
+	graphqlSchemaCacheViper.Set(key, map[string]any{"schema": schema, "fetched_at": fetchedAt})
+	_ = os.MkdirAll(app_path_util.GetConfigPath(), 0755)
+	_ = graphqlSchemaCacheViper.WriteConfigAs(app_path_util.GetConfigPath() + "/" + GraphQLSchemaCacheFileName + "." + cacheFileExtension)
+}
+
+func ClearGraphQLSchemaCache() {
+	graphqlSchemaCacheLock.Lock()
+	defer graphqlSchemaCacheLock.Unlock()
+	os.Remove(app_path_util.GetConfigPath() + "/" + GraphQLSchemaCacheFileName + "." + cacheFileExtension)
+	graphqlSchemaCacheViper = newGraphQLSchemaCacheViper()
+}
@@ -0,0 +1,54 @@
+package ip_cache_module
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	app_path_util "github.com/diogopereiradev/httpzen/internal/utils/app_path"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupTestGraphQLSchemaCache() func() {
+	GraphQLSchemaCacheFileName = "graphql_schema_cache_test"
+	configPath := app_path_util.GetConfigPath()
+	_ = os.MkdirAll(configPath, 0755)
+	cacheFile := filepath.Join(configPath, "graphql_schema_cache_test.json")
+	_ = os.Remove(cacheFile)
+	graphqlSchemaCacheViper = newGraphQLSchemaCacheViper()
+	return func() {
+		_ = os.Remove(cacheFile)
+	}
+}
+
+func TestGraphQLSchemaCache_SetAndGet(t *testing.T) {
+	teardown := setupTestGraphQLSchemaCache()
+	defer teardown()
+
+	SetGraphQLSchemaToCache("abc123", `{"__schema":{"Types":[]}}`, 1700000000)
+
+	schema, fetchedAt, ok := GetGraphQLSchemaFromCache("abc123")
+	assert.True(t, ok)
+	assert.Equal(t, `{"__schema":{"Types":[]}}`, schema)
+	assert.Equal(t, int64(1700000000), fetchedAt)
+
+	// Reload from disk to make sure the entry survives a restart.
+	graphqlSchemaCacheViper = newGraphQLSchemaCacheViper()
+	schema, fetchedAt, ok = GetGraphQLSchemaFromCache("abc123")
+	assert.True(t, ok)
+	assert.Equal(t, `{"__schema":{"Types":[]}}`, schema)
+	assert.Equal(t, int64(1700000000), fetchedAt)
+}
+
+func TestGraphQLSchemaCache_NotFoundAndClear(t *testing.T) {
+	teardown := setupTestGraphQLSchemaCache()
+	defer teardown()
+
+	_, _, ok := GetGraphQLSchemaFromCache("missing")
+	assert.False(t, ok)
+
+	SetGraphQLSchemaToCache("key", "{}", 1)
+	ClearGraphQLSchemaCache()
+	_, _, ok = GetGraphQLSchemaFromCache("key")
+	assert.False(t, ok)
+}
@@ -0,0 +1,85 @@
+package ip_cache_module
+
+import (
+	"os"
+	"sync"
+
+	app_path_util "github.com/diogopereiradev/httpzen/internal/utils/app_path"
+	"github.com/spf13/viper"
+)
+
+var (
+	TokenCacheFileName = "token_cache"
+	tokenCacheLock     sync.Mutex
+	tokenCacheViper    = newTokenCacheViper()
+)
+
+func newTokenCacheViper() *viper.Viper {
+	v := viper.New()
+	v.SetConfigName(TokenCacheFileName)
+	v.SetConfigType(cacheFileExtension)
+	v.AddConfigPath(app_path_util.GetConfigPath())
+	_ = v.ReadInConfig()
+	return v
+}
+
+func GetTokenFromCache(key string) (map[string]any, bool) {
+	tokenCacheLock.Lock()
+	defer tokenCacheLock.Unlock()
+	if tokenCacheViper.IsSet(key) {
+		return tokenCacheViper.GetStringMap(key), true
+	}
+	return nil, false
+}
+
+func SetTokenToCache(key string, token map[string]any) error {
+	tokenCacheLock.Lock()
+	defer tokenCacheLock.Unlock()
+	tokenCacheViper.Set(key, token)
+	if err := os.MkdirAll(app_path_util.GetConfigPath(), 0755); err != nil {
+		return err
+	}
+	return writeTokenCache()
+}
+
+func RemoveTokenFromCache(key string) error {
+	tokenCacheLock.Lock()
+	defer tokenCacheLock.Unlock()
+	if !tokenCacheViper.IsSet(key) {
+		return nil
+	}
+	settings := tokenCacheViper.AllSettings()
+	delete(settings, key)
+
+	tokenCacheViper = viper.New()
+	tokenCacheViper.SetConfigName(TokenCacheFileName)
+	tokenCacheViper.SetConfigType(cacheFileExtension)
+	for k, v := range settings {
+		tokenCacheViper.Set(k, v)
+	}
+	return writeTokenCache()
+}
+
+// writeTokenCache saves the cache, which holds OAuth2 access tokens, into a
+// file that is private before any token is written to it. The mode of OpenFile
+// only applies to new files, so a file written by an older version is made
+// private first.
+func writeTokenCache() error {
+	path := app_path_util.GetConfigPath() + "/" + TokenCacheFileName + "." + cacheFileExtension
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := f.Chmod(0600); err != nil {
+		return err
+	}
+	return tokenCacheViper.WriteConfigTo(f)
+}
+
+func ClearTokenCache() {
+	tokenCacheLock.Lock()
+	defer tokenCacheLock.Unlock()
+	os.Remove(app_path_util.GetConfigPath() + "/" + TokenCacheFileName + "." + cacheFileExtension)
+	tokenCacheViper = newTokenCacheViper()
+}
@@ -0,0 +1,103 @@
+package ip_cache_module
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	app_path_util "github.com/diogopereiradev/httpzen/internal/utils/app_path"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupTestTokenCache() func() {
+	TokenCacheFileName = "token_cache_test"
+	configPath := app_path_util.GetConfigPath()
+	_ = os.MkdirAll(configPath, 0755)
+	cacheFile := filepath.Join(configPath, "token_cache_test.json")
+	_ = os.Remove(cacheFile)
+	tokenCacheViper = newTokenCacheViper()
+	return func() {
+		_ = os.Remove(cacheFile)
+	}
+}
+
+func TestGetTokenFromCache_SetTokenToCache(t *testing.T) {
+	teardown := setupTestTokenCache()
+	defer teardown()
+
+	token := map[string]any{"access_token": "abc", "expires_at": 10}
+	SetTokenToCache("oauth_key", token)
+
+	result, ok := GetTokenFromCache("oauth_key")
+	assert.True(t, ok)
+	assert.Equal(t, "abc", result["access_token"])
+}
+
+func TestSetTokenToCache_PrivateFile(t *testing.T) {
+	teardown := setupTestTokenCache()
+	defer teardown()
+
+	cacheFile := filepath.Join(app_path_util.GetConfigPath(), "token_cache_test.json")
+	// A file left by an older version is made private on the next write.
+	assert.NoError(t, os.WriteFile(cacheFile, []byte("{}"), 0644))
+	assert.NoError(t, os.Chmod(cacheFile, 0644))
+
+	assert.NoError(t, SetTokenToCache("oauth_key", map[string]any{"access_token": "abc"}))
+	info, err := os.Stat(cacheFile)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	assert.NoError(t, SetTokenToCache("other_key", map[string]any{"access_token": "def"}))
+	assert.NoError(t, RemoveTokenFromCache("oauth_key"))
+	info, err = os.Stat(cacheFile)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestSetTokenToCache_WriteError(t *testing.T) {
+	teardown := setupTestTokenCache()
+	defer teardown()
+
+	cacheFile := filepath.Join(app_path_util.GetConfigPath(), "token_cache_test.json")
+	assert.NoError(t, os.Mkdir(cacheFile, 0755))
+	defer os.Remove(cacheFile)
+
+	assert.Error(t, SetTokenToCache("oauth_key", map[string]any{"access_token": "abc"}))
+}
+
+func TestGetTokenFromCache_NotFound(t *testing.T) {
+	teardown := setupTestTokenCache()
+	defer teardown()
+
+	result, ok := GetTokenFromCache("missing")
+	assert.False(t, ok)
+	assert.Nil(t, result)
+}
+
+func TestRemoveTokenFromCache(t *testing.T) {
+	teardown := setupTestTokenCache()
+	defer teardown()
+
+	SetTokenToCache("first", map[string]any{"access_token": "1"})
+	SetTokenToCache("second", map[string]any{"access_token": "2"})
+	RemoveTokenFromCache("first")
+
+	_, ok := GetTokenFromCache("first")
+	assert.False(t, ok)
+
+	result, ok := GetTokenFromCache("second")
+	assert.True(t, ok)
+	assert.Equal(t, "2", result["access_token"])
+}
+
+func TestClearTokenCache(t *testing.T) {
+	teardown := setupTestTokenCache()
+	defer teardown()
+
+	SetTokenToCache("oauth_key", map[string]any{"access_token": "abc"})
+	ClearTokenCache()
+
+	result, ok := GetTokenFromCache("oauth_key")
+	assert.False(t, ok)
+	assert.Nil(t, result)
+}
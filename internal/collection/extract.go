@@ -0,0 +1,165 @@
+package collection_module
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/diogopereiradev/httpzen/internal/utils/jsonpath_utility"
+)
+
+type ExtractorType string
+
+const (
+	ExtractJson   ExtractorType = "json"
+	ExtractHeader ExtractorType = "header"
+	ExtractCookie ExtractorType = "cookie"
+	ExtractRegex  ExtractorType = "regex"
+)
+
+// Extractor reads a value of a response into a session variable: a JSONPath
+// on the body, a header, a cookie, or the first group of a regex on the body.
+type Extractor struct {
+	Variable   string        `json:"variable"`
+	Type       ExtractorType `json:"type"`
+	Expression string        `json:"expression"`
+}
+
+// ParseExtractor reads "token=json:$.data.token", "etag=header:ETag",
+// "sid=cookie:session" or "id=regex:id=(\d+)".
+func ParseExtractor(value string) (Extractor, error) {
+	variable, rest, ok := strings.Cut(value, "=")
+	kind, expression, hasType := strings.Cut(rest, ":")
+	variable = strings.TrimSpace(variable)
+	if !ok || !hasType || variable == "" || expression == "" {
+		return Extractor{}, errors.New("invalid extractor \"" + value + "\", expected VAR=TYPE:EXPRESSION")
+	}
+
+	extractor := Extractor{Variable: variable, Type: ExtractorType(strings.ToLower(strings.TrimSpace(kind))), Expression: expression}
+	switch extractor.Type {
+	case ExtractJson, ExtractHeader, ExtractCookie:
+	case ExtractRegex:
+		if _, err := regexp.Compile(expression); err != nil {
+			return Extractor{}, errors.New("invalid regex in \"" + value + "\": " + err.Error())
+		}
+	default:
+		return Extractor{}, errors.New("unknown extractor type \"" + kind + "\", use json, header, cookie or regex")
+	}
+	return extractor, nil
+}
+
+func (e Extractor) String() string {
+	return e.Variable + "=" + string(e.Type) + ":" + e.Expression
+}
+
+// Extract reads the value from the response. A JSONPath matching a single
+// string gives the string itself, other values and several matches are
+// written as JSON.
+func (e Extractor) Extract(res request_module.RequestResponse) (string, error) {
+	switch e.Type {
+	case ExtractJson:
+		// Numbers are kept as written, a float64 would round 64-bit IDs.
+		decoder := json.NewDecoder(strings.NewReader(res.Result))
+		decoder.UseNumber()
+		var document any
+		if err := decoder.Decode(&document); err != nil || decoder.More() {
+			return "", errors.New("the body is not JSON")
+		}
+		matches, err := jsonpath_utility.Query(document, e.Expression)
+		if err != nil {
+			return "", err
+		}
+		if len(matches) == 0 {
+			return "", errors.New(e.Expression + " matched nothing")
+		}
+		var value any = matches
+		if len(matches) == 1 {
+			value = matches[0]
+		}
+		if text, ok := value.(string); ok {
+			return text, nil
+		}
+		encoded, err := json.Marshal(value)
+		return string(encoded), err
+	case ExtractHeader:
+		if values := res.Headers.Values(e.Expression); len(values) > 0 {
+			return values[0], nil
+		}
+		return "", errors.New("the response has no " + e.Expression + " header")
+	case ExtractCookie:
+		cookies := res.Cookies
+		if len(cookies) == 0 {
+			// Responses read back from the history may only have the header.
+			cookies = (&http.Response{Header: res.Headers}).Cookies()
+		}
+		for _, cookie := range cookies {
+			if cookie.Name == e.Expression {
+				return cookie.Value, nil
+			}
+		}
+		return "", errors.New("the response sets no " + e.Expression + " cookie")
+	case ExtractRegex:
+		pattern, err := regexp.Compile(e.Expression)
+		if err != nil {
+			return "", err
+		}
+		match := pattern.FindStringSubmatch(res.Result)
+		if match == nil {
+			return "", errors.New(e.Expression + " matched nothing")
+		}
+		if len(match) > 1 {
+			return match[1], nil
+		}
+		return match[0], nil
+	}
+	return "", errors.New("unknown extractor type \"" + string(e.Type) + "\"")
+}
+
+// Session holds the variables extracted during a run, they win over the
+// collection and environment variables for the next requests.
+type Session struct {
+	Variables map[string]string
+}
+
+func NewSession() *Session {
+	return &Session{Variables: map[string]string{}}
+}
+
+// Extract runs the extractors of a saved request on its response and
+// returns the names of the variables set. The variables that could not be
+// read keep their previous value.
+func (s *Session) Extract(saved SavedRequest, res request_module.RequestResponse) ([]string, []error) {
+	var names []string
+	var errs []error
+	for _, extractor := range saved.Extract {
+		value, err := extractor.Extract(res)
+		if err != nil {
+			errs = append(errs, errors.New(extractor.Variable+": "+err.Error()))
+			continue
+		}
+		s.Variables[extractor.Variable] = value
+		names = append(names, extractor.Variable)
+	}
+	return names, errs
+}
+
+// FindRequest looks a request up by its path, like "users/create", or by
+// its name when it is the only one with that name.
+func (c Collection) FindRequest(name string) (int, bool) {
+	found := -1
+	for i, saved := range c.Requests {
+		if saved.Path() == name {
+			return i, true
+		}
+		if saved.Name == name {
+			if found >= 0 {
+				return -1, false
+			}
+			found = i
+		}
+	}
+	return found, found >= 0
+}
@@ -0,0 +1,114 @@
+package collection_module
+
+import (
+	"net/http"
+	"testing"
+
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExtractor(t *testing.T) {
+	extractor, err := ParseExtractor("token=json:$.data.token")
+	assert.NoError(t, err)
+	assert.Equal(t, Extractor{Variable: "token", Type: ExtractJson, Expression: "$.data.token"}, extractor)
+	assert.Equal(t, "token=json:$.data.token", extractor.String())
+
+	extractor, err = ParseExtractor("id=REGEX:id=(\\d+)")
+	assert.NoError(t, err)
+	assert.Equal(t, ExtractRegex, extractor.Type)
+	assert.Equal(t, "id=(\\d+)", extractor.Expression)
+
+	for _, value := range []string{"token", "token=json", "=json:$.a", "token=xml:/a", "id=regex:("} {
+		_, err := ParseExtractor(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestExtractor_Extract(t *testing.T) {
+	res := request_module.RequestResponse{
+		Result: `{"data":{"token":"abc","id":7,"snowflake":1234567890123456789,"price":1.50},"items":[{"id":1},{"id":2}]}`,
+		Headers: http.Header{
+			"Etag":       {`"v1"`},
+			"Set-Cookie": {"session=s3cr3t; Path=/"},
+		},
+	}
+
+	tests := []struct {
+		extractor Extractor
+		want      string
+	}{
+		{Extractor{Type: ExtractJson, Expression: "$.data.token"}, "abc"},
+		{Extractor{Type: ExtractJson, Expression: "$.data.id"}, "7"},
+		{Extractor{Type: ExtractJson, Expression: "$.items[*].id"}, "[1,2]"},
+		{Extractor{Type: ExtractJson, Expression: "$.data.snowflake"}, "1234567890123456789"},
+		{Extractor{Type: ExtractJson, Expression: "$.data.price"}, "1.50"},
+		{Extractor{Type: ExtractHeader, Expression: "ETag"}, `"v1"`},
+		{Extractor{Type: ExtractCookie, Expression: "session"}, "s3cr3t"},
+		{Extractor{Type: ExtractRegex, Expression: `"token":"(\w+)"`}, "abc"},
+		{Extractor{Type: ExtractRegex, Expression: `\d+`}, "7"},
+	}
+	for _, tt := range tests {
+		got, err := tt.extractor.Extract(res)
+		assert.NoError(t, err, tt.extractor.Expression)
+		assert.Equal(t, tt.want, got, tt.extractor.Expression)
+	}
+
+	res.Cookies = []*http.Cookie{{Name: "session", Value: "from-jar"}}
+	got, _ := Extractor{Type: ExtractCookie, Expression: "session"}.Extract(res)
+	assert.Equal(t, "from-jar", got)
+
+	for _, extractor := range []Extractor{
+		{Type: ExtractJson, Expression: "$.missing"},
+		{Type: ExtractJson, Expression: "data"},
+		{Type: ExtractHeader, Expression: "X-Missing"},
+		{Type: ExtractCookie, Expression: "missing"},
+		{Type: ExtractRegex, Expression: "nope"},
+		{Type: "xml", Expression: "/a"},
+	} {
+		_, err := extractor.Extract(res)
+		assert.Error(t, err, extractor.Expression)
+	}
+
+	_, err := Extractor{Type: ExtractJson, Expression: "$.a"}.Extract(request_module.RequestResponse{Result: "<html>"})
+	assert.EqualError(t, err, "the body is not JSON")
+}
+
+func TestSession_Extract(t *testing.T) {
+	session := NewSession()
+	session.Variables["id"] = "old"
+	saved := SavedRequest{Name: "Login", Extract: []Extractor{
+		{Variable: "token", Type: ExtractJson, Expression: "$.token"},
+		{Variable: "id", Type: ExtractJson, Expression: "$.id"},
+	}}
+
+	names, errs := session.Extract(saved, request_module.RequestResponse{Result: `{"token":"abc"}`})
+	assert.Equal(t, []string{"token"}, names)
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "id: ")
+	assert.Equal(t, map[string]string{"token": "abc", "id": "old"}, session.Variables)
+
+	collection := Collection{Variables: map[string]string{"token": "collection", "host": "h"}}
+	options := collection.Resolve(SavedRequest{Request: request_module.RequestOptions{Url: "{{host}}/{{token}}/{{env}}"}},
+		map[string]string{"token": "environment", "env": "e"}, session.Variables)
+	assert.Equal(t, "h/abc/e", options.Url)
+}
+
+func TestCollection_FindRequest(t *testing.T) {
+	collection := Collection{Requests: []SavedRequest{
+		{Name: "Login"},
+		{Name: "Get", Folder: "users"},
+		{Name: "Get", Folder: "orders"},
+	}}
+
+	index, ok := collection.FindRequest("Login")
+	assert.True(t, ok)
+	assert.Equal(t, 0, index)
+	index, ok = collection.FindRequest("orders/Get")
+	assert.True(t, ok)
+	assert.Equal(t, 2, index)
+	_, ok = collection.FindRequest("Get")
+	assert.False(t, ok, "an ambiguous name should not match")
+	_, ok = collection.FindRequest("Missing")
+	assert.False(t, ok)
+}
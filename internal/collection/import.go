@@ -0,0 +1,40 @@
+package collection_module
+
+import (
+	environment_module "github.com/diogopereiradev/httpzen/internal/environment"
+)
+
+// ImportResult is what an importer read from another tool's export.
+// Skipped lists, one line each, what couldn't be translated.
+type ImportResult struct {
+	Collection   Collection
+	Environments []environment_module.Environment
+	Skipped      []string
+}
+
+var saveEnvironment = environment_module.SaveEnvironment
+var getEnvironment = environment_module.GetEnvironment
+
+// SaveImport saves the collection, replacing one with the same name, and
+// merges the variables into the environments, keeping their auth.
+func SaveImport(result ImportResult) error {
+	if err := SaveCollection(result.Collection); err != nil {
+		return err
+	}
+	for _, imported := range result.Environments {
+		env := getEnvironment(imported.Name)
+		if env.Variables == nil {
+			env.Variables = map[string]string{}
+		}
+		for name, value := range imported.Variables {
+			env.Variables[name] = value
+		}
+		if env.Auth.IsEmpty() {
+			env.Auth = imported.Auth
+		}
+		if err := saveEnvironment(env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package collection_module
+
+import (
+	"testing"
+
+	auth_module "github.com/diogopereiradev/httpzen/internal/auth"
+	environment_module "github.com/diogopereiradev/httpzen/internal/environment"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveImport_MergesEnvironments(t *testing.T) {
+	teardown := setupTestCollections()
+	defer teardown()
+
+	saved := map[string]environment_module.Environment{
+		"prod": {Name: "prod", Auth: auth_module.AuthOptions{Type: auth_module.AuthBearer, Token: "t"}, Variables: map[string]string{"keep": "1", "host": "old"}},
+	}
+	oldGet, oldSave := getEnvironment, saveEnvironment
+	defer func() { getEnvironment, saveEnvironment = oldGet, oldSave }()
+	getEnvironment = func(name string) environment_module.Environment {
+		if env, ok := saved[name]; ok {
+			return env
+		}
+		return environment_module.Environment{Name: name}
+	}
+	saveEnvironment = func(env environment_module.Environment) error {
+		saved[env.Name] = env
+		return nil
+	}
+
+	err := SaveImport(ImportResult{
+		Collection: Collection{Name: "api", Requests: []SavedRequest{{Name: "Ping"}}},
+		Environments: []environment_module.Environment{
+			{Name: "prod", Variables: map[string]string{"host": "new"}},
+			{Name: "dev", Variables: map[string]string{"host": "localhost"}},
+		},
+	})
+	assert.NoError(t, err)
+
+	collection, ok := GetCollection("api")
+	assert.True(t, ok)
+	assert.Len(t, collection.Requests, 1)
+	assert.Equal(t, map[string]string{"keep": "1", "host": "new"}, saved["prod"].Variables)
+	assert.Equal(t, "t", saved["prod"].Auth.Token, "the saved auth should be kept")
+	assert.Equal(t, "localhost", saved["dev"].Variables["host"])
+}
@@ -0,0 +1,149 @@
+package collection_module
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"strings"
+
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	app_path_util "github.com/diogopereiradev/httpzen/internal/utils/app_path"
+)
+
+// SavedRequest is a request kept to be sent again, Folder groups it inside
+// its collection, like "users/admin".
+type SavedRequest struct {
+	Name    string                        `json:"name"`
+	Folder  string                        `json:"folder,omitempty"`
+	Request request_module.RequestOptions `json:"request"`
+	// Extract sets session variables from the response, for the requests
+	// sent after this one.
+	Extract []Extractor `json:"extract,omitempty"`
+	// PreScript and PostScript are JavaScript run before the request and on
+	// its response, kept with the collection.
+	PreScript  string `json:"pre_script,omitempty"`
+	PostScript string `json:"post_script,omitempty"`
+}
+
+type Collection struct {
+	Name      string            `json:"name"`
+	Requests  []SavedRequest    `json:"requests"`
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+// Collections are stored like environments, as plain JSON so names and
+// variables keep their case.
+var COLLECTIONS_FILE_NAME = "collections.json"
+
+var mkdirAll = os.MkdirAll
+var readFile = os.ReadFile
+var writeFile = os.WriteFile
+
+func GetCollectionsFilePath() string {
+	return app_path_util.GetConfigPath() + "/" + COLLECTIONS_FILE_NAME
+}
+
+func loadCollections() (map[string]Collection, error) {
+	collections := map[string]Collection{}
+
+	data, err := readFile(GetCollectionsFilePath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return collections, nil
+		}
+		return nil, err
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return collections, nil
+	}
+	if err := json.Unmarshal(data, &collections); err != nil {
+		return nil, err
+	}
+	return collections, nil
+}
+
+func saveCollections(collections map[string]Collection) error {
+	if err := mkdirAll(app_path_util.GetConfigPath(), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(collections, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Saved requests carry their auth, keep the file private.
+	return writeFile(GetCollectionsFilePath(), data, 0600)
+}
+
+// GetCollection returns the named collection, ok is false when it was never
+// saved.
+func GetCollection(name string) (Collection, bool) {
+	collections, err := loadCollections()
+	if err != nil {
+		return Collection{Name: name}, false
+	}
+	collection, ok := collections[name]
+	collection.Name = name
+	return collection, ok
+}
+
+func ListCollections() []string {
+	collections, err := loadCollections()
+	if err != nil {
+		return []string{}
+	}
+
+	names := make([]string, 0, len(collections))
+	for name := range collections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func SaveCollection(collection Collection) error {
+	if strings.TrimSpace(collection.Name) == "" {
+		return errors.New("collection name cannot be empty")
+	}
+	collections, err := loadCollections()
+	if err != nil {
+		return err
+	}
+	collections[collection.Name] = collection
+	return saveCollections(collections)
+}
+
+// SaveRequest adds the request to the collection, creating it if needed. A
+// request with the same name in the same folder is replaced.
+func SaveRequest(collectionName string, request SavedRequest) error {
+	if strings.TrimSpace(request.Name) == "" {
+		return errors.New("request name cannot be empty")
+	}
+	collection, _ := GetCollection(collectionName)
+	for i, saved := range collection.Requests {
+		if saved.Name == request.Name && saved.Folder == request.Folder {
+			collection.Requests[i] = request
+			return SaveCollection(collection)
+		}
+	}
+	collection.Requests = append(collection.Requests, request)
+	return SaveCollection(collection)
+}
+
+func DeleteCollection(name string) error {
+	collections, err := loadCollections()
+	if err != nil {
+		return err
+	}
+	delete(collections, name)
+	return saveCollections(collections)
+}
+
+// Path is the folder and the name of the request, to tell apart requests
+// with the same name.
+func (r SavedRequest) Path() string {
+	if r.Folder == "" {
+		return r.Name
+	}
+	return r.Folder + "/" + r.Name
+}
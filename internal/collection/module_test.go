@@ -0,0 +1,65 @@
+package collection_module
+
+import (
+	"os"
+	"testing"
+
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupTestCollections() func() {
+	COLLECTIONS_FILE_NAME = "collections_test.json"
+	_ = os.Remove(GetCollectionsFilePath())
+	return func() {
+		_ = os.Remove(GetCollectionsFilePath())
+		COLLECTIONS_FILE_NAME = "collections.json"
+	}
+}
+
+func TestGetCollection_Missing(t *testing.T) {
+	teardown := setupTestCollections()
+	defer teardown()
+
+	collection, ok := GetCollection("api")
+	assert.False(t, ok)
+	assert.Equal(t, "api", collection.Name)
+	assert.Empty(t, ListCollections())
+}
+
+func TestSaveRequest_CreatesAndReplaces(t *testing.T) {
+	teardown := setupTestCollections()
+	defer teardown()
+
+	get := request_module.RequestOptions{Method: "GET", Url: "https://api.example.com/users"}
+	assert.NoError(t, SaveRequest("api", SavedRequest{Name: "List users", Folder: "users", Request: get}))
+	assert.NoError(t, SaveRequest("api", SavedRequest{Name: "List users", Request: get}))
+
+	get.Url = "https://api.example.com/v2/users"
+	assert.NoError(t, SaveRequest("api", SavedRequest{Name: "List users", Folder: "users", Request: get}))
+
+	collection, ok := GetCollection("api")
+	assert.True(t, ok)
+	assert.Len(t, collection.Requests, 2, "a request with the same name in another folder should be kept")
+	assert.Equal(t, "https://api.example.com/v2/users", collection.Requests[0].Request.Url)
+	assert.Equal(t, "users/List users", collection.Requests[0].Path())
+	assert.Equal(t, []string{"api"}, ListCollections())
+
+	assert.Error(t, SaveRequest("api", SavedRequest{Name: " "}))
+}
+
+func TestSaveCollection_KeepsVariables(t *testing.T) {
+	teardown := setupTestCollections()
+	defer teardown()
+
+	assert.Error(t, SaveCollection(Collection{}))
+	assert.NoError(t, SaveCollection(Collection{Name: "api", Variables: map[string]string{"BaseUrl": "https://api.example.com"}}))
+	assert.NoError(t, SaveRequest("api", SavedRequest{Name: "Ping"}))
+
+	collection, _ := GetCollection("api")
+	assert.Equal(t, "https://api.example.com", collection.Variables["BaseUrl"])
+
+	assert.NoError(t, DeleteCollection("api"))
+	_, ok := GetCollection("api")
+	assert.False(t, ok)
+}
@@ -0,0 +1,73 @@
+package collection_module
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/diogopereiradev/httpzen/internal/utils/http_utility"
+)
+
+// variablePattern matches {{name}}, spaces around the name are allowed like
+// in Postman and Insomnia.
+var variablePattern = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*\}\}`)
+
+// Expand replaces the {{name}} references of value. Later maps win over
+// earlier ones, unknown names are left as they are.
+func Expand(value string, variables ...map[string]string) string {
+	if !strings.Contains(value, "{{") {
+		return value
+	}
+	return variablePattern.ReplaceAllStringFunc(value, func(match string) string {
+		name := variablePattern.FindStringSubmatch(match)[1]
+		for i := len(variables) - 1; i >= 0; i-- {
+			if v, ok := variables[i][name]; ok {
+				return v
+			}
+		}
+		return match
+	})
+}
+
+// Resolve expands the variables in the URL, headers, body and auth of a
+// saved request. The given maps, like the environment then the session,
+// win over the collection variables, later ones over earlier ones.
+func (c Collection) Resolve(request SavedRequest, variables ...map[string]string) request_module.RequestOptions {
+	maps := append([]map[string]string{c.Variables}, variables...)
+	expand := func(value string) string {
+		return Expand(value, maps...)
+	}
+
+	options := request.Request
+	options.Url = expand(options.Url)
+
+	if options.Headers != nil {
+		headers := http.Header{}
+		for name, values := range options.Headers {
+			for _, value := range values {
+				headers.Add(expand(name), expand(value))
+			}
+		}
+		options.Headers = headers
+	}
+
+	if options.Body != nil {
+		body := make([]http_utility.HttpContentData, len(options.Body))
+		for i, part := range options.Body {
+			part.Key = expand(part.Key)
+			part.Value = expand(part.Value)
+			body[i] = part
+		}
+		options.Body = body
+	}
+
+	options.Auth.Username = expand(options.Auth.Username)
+	options.Auth.Password = expand(options.Auth.Password)
+	options.Auth.Token = expand(options.Auth.Token)
+	options.Auth.TokenUrl = expand(options.Auth.TokenUrl)
+	options.Auth.ClientId = expand(options.Auth.ClientId)
+	options.Auth.ClientSecret = expand(options.Auth.ClientSecret)
+	options.Auth.Scopes = expand(options.Auth.Scopes)
+	return options
+}
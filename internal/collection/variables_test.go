@@ -0,0 +1,39 @@
+package collection_module
+
+import (
+	"net/http"
+	"testing"
+
+	auth_module "github.com/diogopereiradev/httpzen/internal/auth"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/diogopereiradev/httpzen/internal/utils/http_utility"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpand(t *testing.T) {
+	collection := map[string]string{"host": "https://api.example.com", "version": "v1"}
+	environment := map[string]string{"host": "https://staging.example.com"}
+
+	assert.Equal(t, "https://staging.example.com/v1/users", Expand("{{host}}/{{ version }}/users", collection, environment))
+	assert.Equal(t, "{{missing}}", Expand("{{missing}}", collection))
+	assert.Equal(t, "plain", Expand("plain"))
+}
+
+func TestResolve(t *testing.T) {
+	collection := Collection{Name: "api", Variables: map[string]string{"host": "https://api.example.com", "token": "abc"}}
+	saved := SavedRequest{Name: "Create", Request: request_module.RequestOptions{
+		Method:  "POST",
+		Url:     "{{host}}/users",
+		Headers: http.Header{"X-Tenant": {"{{tenant}}"}},
+		Body:    []http_utility.HttpContentData{{ContentType: "application/json", Value: `{"tenant":"{{tenant}}"}`}},
+		Auth:    auth_module.AuthOptions{Type: auth_module.AuthBearer, Token: "{{token}}"},
+	}}
+
+	options := collection.Resolve(saved, map[string]string{"tenant": "acme"})
+	assert.Equal(t, "https://api.example.com/users", options.Url)
+	assert.Equal(t, "acme", options.Headers.Get("X-Tenant"))
+	assert.Equal(t, `{"tenant":"acme"}`, options.Body[0].Value)
+	assert.Equal(t, "abc", options.Auth.Token)
+	assert.Equal(t, "{{host}}/users", saved.Request.Url, "the saved request should be left as it was")
+	assert.Equal(t, `{"tenant":"{{tenant}}"}`, saved.Request.Body[0].Value)
+}
@@ -2,14 +2,18 @@ package config_editor
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	auth_module "github.com/diogopereiradev/httpzen/internal/auth"
 	blink_cursor_component "github.com/diogopereiradev/httpzen/internal/components/blink_cursor"
 	timed_message_component "github.com/diogopereiradev/httpzen/internal/components/timed_message"
 	config_module "github.com/diogopereiradev/httpzen/internal/config"
+	environment_module "github.com/diogopereiradev/httpzen/internal/environment"
 	logoascii "github.com/diogopereiradev/httpzen/internal/utils/logo_ascii"
 	"github.com/diogopereiradev/httpzen/internal/utils/terminal_utility"
 	"github.com/diogopereiradev/httpzen/internal/utils/theme"
@@ -20,6 +24,9 @@ type configType int
 const (
 	optionTypeNumber configType = iota
 	optionTypeBool
+	optionTypeText
+	optionTypeSecret
+	optionTypeSelect
 )
 
 type configOption struct {
@@ -27,11 +34,13 @@ type configOption struct {
 	ConfigKey string
 	Label     string
 	Value     any
+	Choices   []string
 }
 
 type model struct {
-	config  config_module.Config
-	options []configOption
+	config      config_module.Config
+	environment environment_module.Environment
+	options     []configOption
 
 	choice int
 
@@ -42,7 +51,21 @@ type model struct {
 	blinkCursor       *blink_cursor_component.BlinkCursor
 }
 
-func getConfigOptions(config *config_module.Config) []configOption {
+func getConfigOptions(config *config_module.Config, env *environment_module.Environment) []configOption {
+	authTypes := make([]string, 0, len(auth_module.AuthTypes))
+	for _, authType := range auth_module.AuthTypes {
+		if authType == auth_module.AuthNone {
+			authTypes = append(authTypes, "none")
+		} else {
+			authTypes = append(authTypes, string(authType))
+		}
+	}
+
+	authType := string(env.Auth.Type)
+	if authType == "" {
+		authType = "none"
+	}
+
 	return []configOption{
 		{
 			Type:      optionTypeNumber,
@@ -50,20 +73,116 @@ func getConfigOptions(config *config_module.Config) []configOption {
 			Label:     "Slow response threshold(ms)",
 			Value:     config.SlowResponseThreshold,
 		},
+		{
+			Type:      optionTypeNumber,
+			ConfigKey: "Timeout",
+			Label:     "Request timeout(ms)",
+			Value:     config.Timeout,
+		},
+		{
+			Type:      optionTypeNumber,
+			ConfigKey: "ConnectTimeout",
+			Label:     "Connect timeout(ms)",
+			Value:     config.ConnectTimeout,
+		},
+		{
+			Type:      optionTypeNumber,
+			ConfigKey: "TlsTimeout",
+			Label:     "TLS handshake timeout(ms)",
+			Value:     config.TlsTimeout,
+		},
+		{
+			Type:      optionTypeNumber,
+			ConfigKey: "ResponseHeaderTimeout",
+			Label:     "Response header timeout(ms)",
+			Value:     config.ResponseHeaderTimeout,
+		},
+		{
+			Type:      optionTypeNumber,
+			ConfigKey: "IdleTimeout",
+			Label:     "Idle body timeout(ms)",
+			Value:     config.IdleTimeout,
+		},
 		{
 			Type:      optionTypeBool,
 			ConfigKey: "HideLogomark",
 			Label:     "Hide logomark",
 			Value:     config.HideLogomark,
 		},
+		{
+			Type:      optionTypeText,
+			ConfigKey: "ActiveEnvironment",
+			Label:     "Active environment",
+			Value:     config.ActiveEnvironment,
+		},
+		{
+			Type:      optionTypeSelect,
+			ConfigKey: "Auth.Type",
+			Label:     "Auth type",
+			Value:     authType,
+			Choices:   authTypes,
+		},
+		{
+			Type:      optionTypeText,
+			ConfigKey: "Auth.Username",
+			Label:     "Auth username",
+			Value:     env.Auth.Username,
+		},
+		{
+			Type:      optionTypeSecret,
+			ConfigKey: "Auth.Password",
+			Label:     "Auth password",
+			Value:     env.Auth.Password,
+		},
+		{
+			Type:      optionTypeSecret,
+			ConfigKey: "Auth.Token",
+			Label:     "Bearer token",
+			Value:     env.Auth.Token,
+		},
+		{
+			Type:      optionTypeText,
+			ConfigKey: "Auth.TokenUrl",
+			Label:     "OAuth2 token URL",
+			Value:     env.Auth.TokenUrl,
+		},
+		{
+			Type:      optionTypeText,
+			ConfigKey: "Auth.ClientId",
+			Label:     "OAuth2 client ID",
+			Value:     env.Auth.ClientId,
+		},
+		{
+			Type:      optionTypeSecret,
+			ConfigKey: "Auth.ClientSecret",
+			Label:     "OAuth2 client secret",
+			Value:     env.Auth.ClientSecret,
+		},
+		{
+			Type:      optionTypeText,
+			ConfigKey: "Auth.Scopes",
+			Label:     "OAuth2 scopes",
+			Value:     env.Auth.Scopes,
+		},
 	}
 }
 
+func (o configOption) displayValue() string {
+	if o.Type == optionTypeSecret {
+		if value, _ := o.Value.(string); value != "" {
+			return strings.Repeat("*", 8)
+		}
+	}
+	return fmt.Sprintf("%v", o.Value)
+}
+
 func initialModel() model {
 	config := config_module.GetConfig()
+	env := environment_module.GetEnvironment(config.ActiveEnvironment)
 	return model{
 		config:            config,
-		options:           getConfigOptions(&config),
+		environment:       env,
+		options:           getConfigOptions(&config, &env),
 		savedTimedMessage: timed_message_component.New(),
 		blinkCursor:       blink_cursor_component.New(),
 		choice:            0,
@@ -116,7 +235,10 @@ func (m model) View() string {
 	view += titleStyle.Render("Configuration:") + "\n\n"
 
 	for i, opt := range m.options {
-		view += fmt.Sprintf("%s "+fieldStyle.Render(opt.Label+":")+" %v\n", cursor(i), opt.Value)
+		if opt.ConfigKey == "Auth.Type" {
+			view += "\n" + titleStyle.Render("Auth for environment '"+m.environment.Name+"':") + "\n\n"
+		}
+		view += fmt.Sprintf("%s "+fieldStyle.Render(opt.Label+":")+" %s\n", cursor(i), opt.displayValue())
 	}
 
 	blinkCursor := m.blinkCursor.Render()
@@ -128,6 +250,12 @@ func (m model) View() string {
 			view += "\n" + selectedShortcutStyle.Render("Press 'enter' to toggle('n' to cancel)")
 		case optionTypeNumber:
 			view += "\n" + selectedShortcutStyle.Render("Enter new value and press 'enter' to save('n' to cancel): ") + valueStyle.Render(m.editingValue+string(blinkCursor))
+		case optionTypeText:
+			view += "\n" + selectedShortcutStyle.Render("Enter new value and press 'enter' to save('esc' to cancel): ") + valueStyle.Render(m.editingValue+string(blinkCursor))
+		case optionTypeSecret:
+			view += "\n" + selectedShortcutStyle.Render("Enter new value and press 'enter' to save('esc' to cancel): ") + valueStyle.Render(strings.Repeat("*", len(m.editingValue))+string(blinkCursor))
+		case optionTypeSelect:
+			view += "\n" + selectedShortcutStyle.Render("Use ←/→ to choose and press 'enter' to save('n' to cancel): ") + valueStyle.Render(m.editingValue)
 		}
 	}
 
@@ -159,8 +287,24 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.Type {
-		case tea.KeyCtrlC, tea.KeyEsc:
+		case tea.KeyCtrlC:
+			return m.quit()
+		case tea.KeyEsc:
+			if m.editing {
+				m.editing = false
+				m.editingValue = ""
+				return m, nil
+			}
 			return m.quit()
+		case tea.KeySpace:
+			if m.editing && m.isTextEditing() {
+				m.editingValue += " "
+			}
+			return m, nil
+		case tea.KeyLeft:
+			return m.cycleChoice(-1)
+		case tea.KeyRight:
+			return m.cycleChoice(1)
 		case tea.KeyRunes:
 			return m.keyRunes(&msg)
 		case tea.KeyBackspace:
@@ -182,7 +326,27 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+func (m *model) isTextEditing() bool {
+	choice := m.options[m.choice]
+	return choice.Type == optionTypeText || choice.Type == optionTypeSecret
+}
+
+func (m *model) cycleChoice(step int) (tea.Model, tea.Cmd) {
+	choice := m.options[m.choice]
+	if !m.editing || choice.Type != optionTypeSelect || len(choice.Choices) == 0 {
+		return m, nil
+	}
+	index := max(slices.Index(choice.Choices, m.editingValue), 0)
+	m.editingValue = choice.Choices[(index+step+len(choice.Choices))%len(choice.Choices)]
+	return m, nil
+}
+
 func (m *model) keyRunes(msg *tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.editing && m.isTextEditing() {
+		m.editingValue += msg.String()
+		return m, nil
+	}
+
 	switch msg.String() {
 	case "q":
 		return m.quit()
@@ -223,7 +387,7 @@ func (m *model) navigateDown() (tea.Model, tea.Cmd) {
 func (m *model) backspace() (tea.Model, tea.Cmd) {
 	choice := m.options[m.choice]
 
-	if !m.editing || choice.Type != optionTypeNumber {
+	if !m.editing || (choice.Type != optionTypeNumber && !m.isTextEditing()) {
 		return m, nil
 	}
 	if m.editing && len(m.editingValue) > 0 {
@@ -240,26 +404,66 @@ func (m *model) saveChanges() (tea.Model, tea.Cmd) {
 	choice := m.options[m.choice]
 	newConfig := m.config
 
+	setNumber := func(field *int) {
+		if m.editingValue == "" {
+			*field = 0
+			return
+		}
+		if newValue, err := strconv.Atoi(m.editingValue); err == nil {
+			*field = newValue
+		}
+	}
+
 	var setters = map[string]func(*config_module.Config){
-		"HideLogomark": func(cfg *config_module.Config) { cfg.HideLogomark = !cfg.HideLogomark },
-		"SlowResponseThreshold": func(cfg *config_module.Config) {
-			if m.editingValue == "" {
-				cfg.SlowResponseThreshold = 0
-				return
-			}
-			if newValue, err := strconv.Atoi(m.editingValue); err == nil {
-				cfg.SlowResponseThreshold = newValue
+		"HideLogomark":          func(cfg *config_module.Config) { cfg.HideLogomark = !cfg.HideLogomark },
+		"SlowResponseThreshold": func(cfg *config_module.Config) { setNumber(&cfg.SlowResponseThreshold) },
+		"Timeout":               func(cfg *config_module.Config) { setNumber(&cfg.Timeout) },
+		"ConnectTimeout":        func(cfg *config_module.Config) { setNumber(&cfg.ConnectTimeout) },
+		"TlsTimeout":            func(cfg *config_module.Config) { setNumber(&cfg.TlsTimeout) },
+		"ResponseHeaderTimeout": func(cfg *config_module.Config) { setNumber(&cfg.ResponseHeaderTimeout) },
+		"IdleTimeout":           func(cfg *config_module.Config) { setNumber(&cfg.IdleTimeout) },
+	}
+
+	newEnvironment := m.environment
+	newAuth := &newEnvironment.Auth
+
+	var environmentSetters = map[string]func(){
+		"Auth.Type": func() {
+			if authType, err := auth_module.ParseAuthType(m.editingValue); err == nil {
+				newAuth.Type = authType
 			}
 		},
+		"Auth.Username":     func() { newAuth.Username = m.editingValue },
+		"Auth.Password":     func() { newAuth.Password = m.editingValue },
+		"Auth.Token":        func() { newAuth.Token = m.editingValue },
+		"Auth.TokenUrl":     func() { newAuth.TokenUrl = m.editingValue },
+		"Auth.ClientId":     func() { newAuth.ClientId = m.editingValue },
+		"Auth.ClientSecret": func() { newAuth.ClientSecret = m.editingValue },
+		"Auth.Scopes":       func() { newAuth.Scopes = m.editingValue },
+	}
+
+	if choice.ConfigKey == "ActiveEnvironment" {
+		name := strings.TrimSpace(m.editingValue)
+		if name == "" {
+			name = environment_module.DEFAULT_ENVIRONMENT
+		}
+		newConfig.ActiveEnvironment = name
+		newEnvironment = environment_module.GetEnvironment(name)
 	}
 
 	if setter, ok := setters[choice.ConfigKey]; ok {
 		setter(&newConfig)
+		config_module.UpdateConfig(newConfig)
+	} else if setter, ok := environmentSetters[choice.ConfigKey]; ok {
+		setter()
+		environment_module.SaveEnvironment(newEnvironment)
+	} else if choice.ConfigKey == "ActiveEnvironment" {
+		config_module.UpdateConfig(newConfig)
 	}
 
 	m.config = newConfig
-	m.options = getConfigOptions(&newConfig)
-	config_module.UpdateConfig(newConfig)
+	m.environment = newEnvironment
+	m.options = getConfigOptions(&newConfig, &newEnvironment)
 
 	m.editing = false
 	m.editingValue = ""
@@ -0,0 +1,34 @@
+package progress_bar_component
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/diogopereiradev/httpzen/internal/utils/theme"
+)
+
+// Render draws a bar of width cells followed by the percentage, total must
+// be greater than zero.
+func Render(current int64, total int64, width int) string {
+	ratio := min(max(float64(current)/float64(total), 0), 1)
+	filled := int(ratio * float64(width))
+
+	bar := lipgloss.NewStyle().Foreground(theme.Primary).Render(strings.Repeat("█", filled)) +
+		lipgloss.NewStyle().Foreground(theme.DarkenText).Render(strings.Repeat("░", width-filled))
+	return bar + fmt.Sprintf(" %3.0f%%", ratio*100)
+}
+
+func FormatBytes(size int64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB"}
+	value := float64(size)
+	unit := 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%d B", size)
+	}
+	return fmt.Sprintf("%.1f %s", value, units[unit])
+}
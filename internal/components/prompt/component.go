@@ -22,10 +22,15 @@ type PromptImpl struct {
 	Events                PromptEvents
 	Boolean               bool
 	BooleanDefault        bool
+	Password              bool
 	MaxLength             int
-	input                 textinput.Model
-	invalid               bool
-	submited              bool
+	// Value prefills the input, so a default can be accepted with enter.
+	Value string
+	// KeepCase submits the value as typed, it is lowercased otherwise.
+	KeepCase bool
+	input    textinput.Model
+	invalid  bool
+	submited bool
 }
 
 type PromptEvents struct {
@@ -38,6 +43,11 @@ func NewComponent(options PromptImpl) {
 	input.CharLimit = options.MaxLength
 	input.Focus()
 	input.Prompt = ""
+	input.SetValue(options.Value)
+	if options.Password {
+		input.EchoMode = textinput.EchoPassword
+		input.EchoCharacter = '*'
+	}
 
 	impl := PromptImpl{
 		Title:                 options.Title,
@@ -45,7 +55,9 @@ func NewComponent(options PromptImpl) {
 		Events:                options.Events,
 		Boolean:               options.Boolean,
 		BooleanDefault:        options.BooleanDefault,
+		Password:              options.Password,
 		MaxLength:             options.MaxLength,
+		KeepCase:              options.KeepCase,
 		input:                 input,
 		invalid:               false,
 		submited:              false,
@@ -61,7 +73,11 @@ func NewComponent(options PromptImpl) {
 		p := model.(PromptImpl)
 		if p.submited {
 			if p.Events.OnSubmit != nil {
-				p.Events.OnSubmit(strings.ToLower(p.input.Value()))
+				if p.Password || p.KeepCase {
+					p.Events.OnSubmit(p.input.Value())
+				} else {
+					p.Events.OnSubmit(strings.ToLower(p.input.Value()))
+				}
 			}
 		} else {
 			exitStyle := lipgloss.NewStyle().Foreground(theme.Error).Bold(true)
@@ -126,7 +142,11 @@ func (p PromptImpl) View() string {
 	}
 
 	if p.submited {
-		output += checkIcon + " " + p.Title + " [" + vlen + "/" + maxlen + "]" + ": " + selectedStyle.Render(p.input.Value()) + "\n"
+		submitedValue := p.input.Value()
+		if p.Password {
+			submitedValue = strings.Repeat("*", len(submitedValue))
+		}
+		output += checkIcon + " " + p.Title + " [" + vlen + "/" + maxlen + "]" + ": " + selectedStyle.Render(submitedValue) + "\n"
 	}
 	output += questionIcon + " " + p.Title + " [" + vlen + "/" + maxlen + "]" + ": " + p.input.View() + "\n"
 	return output
@@ -0,0 +1,31 @@
+package sparkline_component
+
+import "strings"
+
+var levels = []rune("▁▂▃▄▅▆▇█")
+
+// Render draws the last width values as a line of bars, scaled between the
+// lowest and the highest of them.
+func Render(values []float64, width int) string {
+	if width <= 0 || len(values) == 0 {
+		return ""
+	}
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+
+	low, high := values[0], values[0]
+	for _, value := range values {
+		low, high = min(low, value), max(high, value)
+	}
+
+	var b strings.Builder
+	for _, value := range values {
+		level := 0
+		if high > low {
+			level = int((value - low) / (high - low) * float64(len(levels)-1))
+		}
+		b.WriteRune(levels[level])
+	}
+	return b.String()
+}
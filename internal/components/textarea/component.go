@@ -3,6 +3,8 @@ package textarea_component
 import (
 	"fmt"
 	"os"
+	"strings"
+	"unicode"
 
 	"github.com/charmbracelet/bubbles/textarea"
 	tea "github.com/charmbracelet/bubbletea"
@@ -18,28 +20,39 @@ type TextareaImpl struct {
 	Title     string
 	Events    TextareaEvents
 	MaxLength int
-	input     textarea.Model
-	submited  bool
+	// Suggest, when set, receives the text before the cursor and returns the
+	// words that can complete it. The first one is inserted with TAB.
+	Suggest  func(before string) []string
+	input    textarea.Model
+	submited bool
 }
 
 type TextareaEvents struct {
 	OnSubmit func(result string)
 }
 
-func NewComponent(options TextareaImpl) {
+// NewInput returns a focused textarea styled like this component, for menus
+// that embed the input in their own view instead of running a prompt.
+func NewInput(maxLength int, width int) textarea.Model {
 	input := textarea.New()
-	input.CharLimit = options.MaxLength
+	input.CharLimit = maxLength
 	input.FocusedStyle.LineNumber = lipgloss.NewStyle().Foreground(theme.DarkenText)
 	input.FocusedStyle.CursorLine = lipgloss.NewStyle().Background(theme.DarkenText)
 	input.Focus()
 	input.Prompt = ""
 	input.ShowLineNumbers = true
-	input.SetWidth(60)
+	input.SetWidth(width)
+	return input
+}
+
+func NewComponent(options TextareaImpl) {
+	input := NewInput(options.MaxLength, 60)
 
 	impl := TextareaImpl{
 		Title:     options.Title,
 		Events:    options.Events,
 		MaxLength: options.MaxLength,
+		Suggest:   options.Suggest,
 		input:     input,
 		submited:  false,
 	}
@@ -110,6 +123,16 @@ func (p TextareaImpl) View() string {
 	output += optionalStyle.Render("(You can use \"CTRL + S\" to submit, \"Enter\" to add a new line)") + "\n"
 	output += borderStyle.Render(p.input.View()) + "\n"
 
+	if p.Suggest != nil {
+		if suggestions := p.Suggest(textBeforeCursor(p.input)); len(suggestions) > 0 {
+			suggestionStyle := lipgloss.NewStyle().Foreground(theme.Secondary)
+			if len(suggestions) > maxSuggestions {
+				suggestions = append(suggestions[:maxSuggestions], "...")
+			}
+			output += suggestionStyle.Render(strings.Join(suggestions, "  ")) + optionalStyle.Render("  (TAB to complete)") + "\n"
+		}
+	}
+
 	return output
 }
 
@@ -118,6 +141,11 @@ func (p TextareaImpl) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return p, tea.Quit
 	}
 
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.Type == tea.KeyTab && p.Suggest != nil {
+		p.complete()
+		return p, nil
+	}
+
 	var cmd tea.Cmd
 	p.input, cmd = p.input.Update(msg)
 
@@ -132,3 +160,39 @@ func (p TextareaImpl) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 	return p, cmd
 }
+
+const maxSuggestions = 8
+
+// textBeforeCursor returns the input value up to the cursor position.
+func textBeforeCursor(input textarea.Model) string {
+	lines := strings.Split(input.Value(), "\n")
+	row := min(input.Line(), len(lines)-1)
+	info := input.LineInfo()
+
+	current := []rune(lines[row])
+	col := min(info.StartColumn+info.ColumnOffset, len(current))
+
+	before := strings.Join(lines[:row], "\n")
+	if row > 0 {
+		before += "\n"
+	}
+	return before + string(current[:col])
+}
+
+// complete inserts the rest of the first suggestion for the word under the
+// cursor.
+func (p *TextareaImpl) complete() {
+	before := textBeforeCursor(p.input)
+	suggestions := p.Suggest(before)
+	if len(suggestions) == 0 {
+		return
+	}
+
+	word := []rune(before)
+	start := len(word)
+	for start > 0 && (word[start-1] == '_' || unicode.IsLetter(word[start-1]) || unicode.IsDigit(word[start-1])) {
+		start--
+	}
+	prefix := string(word[start:])
+	p.input.InsertString(strings.TrimPrefix(suggestions[0], prefix))
+}
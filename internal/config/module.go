@@ -8,10 +8,20 @@ import (
 )
 
 type Config struct {
-	SlowResponseThreshold int  `json:"slow_response_threshold"`
-	HideLogomark          bool `json:"hide_logomark"`
+	SlowResponseThreshold int    `json:"slow_response_threshold"`
+	HideLogomark          bool   `json:"hide_logomark"`
+	ActiveEnvironment     string `json:"active_environment"`
+	// Request timeouts in milliseconds, 0 means no limit for Timeout and the
+	// transport default for the others.
+	Timeout               int `json:"timeout"`
+	ConnectTimeout        int `json:"connect_timeout"`
+	TlsTimeout            int `json:"tls_timeout"`
+	ResponseHeaderTimeout int `json:"response_header_timeout"`
+	IdleTimeout           int `json:"idle_timeout"`
 }
 
+const DefaultTimeout = 30000
+
 var CONFIG_NAME string = "config"
 var CONFIG_EXTENSION string = "json"
 
@@ -23,6 +33,8 @@ func GetConfig() Config {
 	v.SetConfigName(CONFIG_NAME)
 	v.SetConfigType(CONFIG_EXTENSION)
 	v.AddConfigPath(configPath)
+	// Config files written before the timeout existed keep the old 30s.
+	v.SetDefault("timeout", DefaultTimeout)
 
 	if err := v.ReadInConfig(); err != nil {
 		return InitConfig()
@@ -30,6 +42,12 @@ func GetConfig() Config {
 	return Config{
 		SlowResponseThreshold: v.GetInt("slow_response_threshold"),
 		HideLogomark:          v.GetBool("hide_logomark"),
+		ActiveEnvironment:     v.GetString("active_environment"),
+		Timeout:               v.GetInt("timeout"),
+		ConnectTimeout:        v.GetInt("connect_timeout"),
+		TlsTimeout:            v.GetInt("tls_timeout"),
+		ResponseHeaderTimeout: v.GetInt("response_header_timeout"),
+		IdleTimeout:           v.GetInt("idle_timeout"),
 	}
 }
 
@@ -37,6 +55,12 @@ func UpdateConfig(newConfig Config) error {
 	v := viper.New()
 	v.Set("slow_response_threshold", newConfig.SlowResponseThreshold)
 	v.Set("hide_logomark", newConfig.HideLogomark)
+	v.Set("active_environment", newConfig.ActiveEnvironment)
+	v.Set("timeout", newConfig.Timeout)
+	v.Set("connect_timeout", newConfig.ConnectTimeout)
+	v.Set("tls_timeout", newConfig.TlsTimeout)
+	v.Set("response_header_timeout", newConfig.ResponseHeaderTimeout)
+	v.Set("idle_timeout", newConfig.IdleTimeout)
 
 	configPath := app_path_util.GetConfigPath()
 	if err := mkdirAll(configPath, 0755); err != nil {
@@ -57,6 +81,8 @@ func InitConfig() Config {
 	config := Config{
 		SlowResponseThreshold: 500,
 		HideLogomark:          false,
+		ActiveEnvironment:     "default",
+		Timeout:               DefaultTimeout,
 	}
 
 	configPath := app_path_util.GetConfigPath()
@@ -67,6 +93,12 @@ func InitConfig() Config {
 	v := viper.New()
 	v.SetDefault("slow_response_threshold", config.SlowResponseThreshold)
 	v.SetDefault("hide_logomark", config.HideLogomark)
+	v.SetDefault("active_environment", config.ActiveEnvironment)
+	v.SetDefault("timeout", config.Timeout)
+	v.SetDefault("connect_timeout", config.ConnectTimeout)
+	v.SetDefault("tls_timeout", config.TlsTimeout)
+	v.SetDefault("response_header_timeout", config.ResponseHeaderTimeout)
+	v.SetDefault("idle_timeout", config.IdleTimeout)
 
 	v.SetConfigName(CONFIG_NAME)
 	v.SetConfigType(CONFIG_EXTENSION)
@@ -53,6 +53,8 @@ func TestInitConfig(t *testing.T) {
 	_, err := os.Stat(configFile)
 	assert.NoError(t, err, "should config file be created by InitConfig")
 	assert.Equal(t, 500, config.SlowResponseThreshold, "should have default SlowResponseThreshold")
+	assert.Equal(t, "default", config.ActiveEnvironment, "should have default ActiveEnvironment")
+	assert.Equal(t, DefaultTimeout, config.Timeout, "should have default Timeout")
 
 	removeErr := os.Remove(configFile)
 	assert.NoError(t, removeErr, "should not fail to remove test config file")
@@ -110,12 +112,16 @@ func TestUpdateConfig(t *testing.T) {
 	InitConfig()
 	configData := Config{
 		SlowResponseThreshold: 1000,
+		Timeout:               5000,
+		IdleTimeout:           2000,
 	}
 	err := UpdateConfig(configData)
 	assert.NoError(t, err, "should not fail to update config")
 
 	updatedConfig := GetConfig()
 	assert.Equal(t, 1000, updatedConfig.SlowResponseThreshold, "should have SlowResponseThreshold updated")
+	assert.Equal(t, 5000, updatedConfig.Timeout, "should have Timeout updated")
+	assert.Equal(t, 2000, updatedConfig.IdleTimeout, "should have IdleTimeout updated")
 
 	removeErr := os.Remove(configFile)
 	assert.NoError(t, removeErr, "should not fail to remove test config file")
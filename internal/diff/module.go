@@ -0,0 +1,343 @@
+package diff_module
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+)
+
+// DifferentExitCode is returned by headless diffs that found changes, like
+// diff(1) does.
+const DifferentExitCode = 1
+
+// MaxLines caps the text bodies compared line by line, longer ones are only
+// told apart.
+const MaxLines = 1000
+
+type ChangeKind string
+
+const (
+	Added   ChangeKind = "added"
+	Removed ChangeKind = "removed"
+	Changed ChangeKind = "changed"
+)
+
+// Change is a difference at a path of the body, like $.items[0].id, a header
+// name or a line of a text body. The values of JSON bodies are JSON.
+type Change struct {
+	Kind   ChangeKind
+	Path   string
+	Before string
+	After  string
+}
+
+type Result struct {
+	StatusBefore  int
+	StatusAfter   int
+	LatencyBefore float64
+	LatencyAfter  float64
+	Headers       []Change
+	Body          []Change
+	// BodyJson tells whether the bodies were compared as JSON, or line by
+	// line.
+	BodyJson bool
+}
+
+// Same tells whether the responses have the same status, headers and body.
+// The latency is not compared.
+func (r Result) Same() bool {
+	return r.StatusBefore == r.StatusAfter && len(r.Headers) == 0 && len(r.Body) == 0
+}
+
+// Compare diffs two responses. ignore leaves out the body paths starting with
+// $, and the headers named otherwise, with what is below them.
+func Compare(before request_module.RequestResponse, after request_module.RequestResponse, ignore []string) Result {
+	result := Result{
+		StatusBefore:  before.StatusCode,
+		StatusAfter:   after.StatusCode,
+		LatencyBefore: before.ExecutionTime,
+		LatencyAfter:  after.ExecutionTime,
+		Headers:       Headers(before.Headers, after.Headers),
+	}
+	result.Body, result.BodyJson = Body(before.Result, after.Result)
+
+	result.Headers = filter(result.Headers, ignore, func(path string, pattern string) bool {
+		return !strings.HasPrefix(pattern, "$") && strings.EqualFold(path, pattern)
+	})
+	result.Body = filter(result.Body, ignore, func(path string, pattern string) bool {
+		return strings.HasPrefix(pattern, "$") &&
+			(path == pattern || strings.HasPrefix(path, pattern+".") || strings.HasPrefix(path, pattern+"["))
+	})
+	return result
+}
+
+func filter(changes []Change, ignore []string, matches func(path string, pattern string) bool) []Change {
+	if len(ignore) == 0 {
+		return changes
+	}
+	kept := changes[:0:0]
+	for _, change := range changes {
+		ignored := false
+		for _, pattern := range ignore {
+			if matches(change.Path, pattern) {
+				ignored = true
+				break
+			}
+		}
+		if !ignored {
+			kept = append(kept, change)
+		}
+	}
+	return kept
+}
+
+// Headers diffs the headers by name, the values of a header are compared
+// together.
+func Headers(before http.Header, after http.Header) []Change {
+	names := map[string]bool{}
+	for name := range before {
+		names[http.CanonicalHeaderKey(name)] = true
+	}
+	for name := range after {
+		names[http.CanonicalHeaderKey(name)] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var changes []Change
+	for _, name := range sorted {
+		beforeValues, afterValues := before.Values(name), after.Values(name)
+		beforeValue, afterValue := strings.Join(beforeValues, ", "), strings.Join(afterValues, ", ")
+		switch {
+		case len(beforeValues) == 0:
+			changes = append(changes, Change{Kind: Added, Path: name, After: afterValue})
+		case len(afterValues) == 0:
+			changes = append(changes, Change{Kind: Removed, Path: name, Before: beforeValue})
+		case beforeValue != afterValue:
+			changes = append(changes, Change{Kind: Changed, Path: name, Before: beforeValue, After: afterValue})
+		}
+	}
+	return changes
+}
+
+// Body diffs two bodies as JSON when both are, and line by line otherwise.
+func Body(before string, after string) ([]Change, bool) {
+	beforeValue, beforeOk := decode(before)
+	afterValue, afterOk := decode(after)
+	if beforeOk && afterOk {
+		var changes []Change
+		Json(beforeValue, afterValue, "$", &changes)
+		return changes, true
+	}
+	return Text(before, after), false
+}
+
+func decode(body string) (any, bool) {
+	if strings.TrimSpace(body) == "" {
+		return nil, false
+	}
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return nil, false
+	}
+	return value, true
+}
+
+// Json diffs two decoded JSON values, objects by key and arrays by index.
+func Json(before any, after any, path string, changes *[]Change) {
+	switch beforeValue := before.(type) {
+	case map[string]any:
+		if afterValue, ok := after.(map[string]any); ok {
+			keys := map[string]bool{}
+			for key := range beforeValue {
+				keys[key] = true
+			}
+			for key := range afterValue {
+				keys[key] = true
+			}
+			sorted := make([]string, 0, len(keys))
+			for key := range keys {
+				sorted = append(sorted, key)
+			}
+			sort.Strings(sorted)
+
+			for _, key := range sorted {
+				child := path + keySegment(key)
+				beforeChild, inBefore := beforeValue[key]
+				afterChild, inAfter := afterValue[key]
+				switch {
+				case !inBefore:
+					*changes = append(*changes, Change{Kind: Added, Path: child, After: encode(afterChild)})
+				case !inAfter:
+					*changes = append(*changes, Change{Kind: Removed, Path: child, Before: encode(beforeChild)})
+				default:
+					Json(beforeChild, afterChild, child, changes)
+				}
+			}
+			return
+		}
+	case []any:
+		if afterValue, ok := after.([]any); ok {
+			for i := 0; i < max(len(beforeValue), len(afterValue)); i++ {
+				child := path + "[" + strconv.Itoa(i) + "]"
+				switch {
+				case i >= len(beforeValue):
+					*changes = append(*changes, Change{Kind: Added, Path: child, After: encode(afterValue[i])})
+				case i >= len(afterValue):
+					*changes = append(*changes, Change{Kind: Removed, Path: child, Before: encode(beforeValue[i])})
+				default:
+					Json(beforeValue[i], afterValue[i], child, changes)
+				}
+			}
+			return
+		}
+	}
+
+	if beforeEncoded, afterEncoded := encode(before), encode(after); beforeEncoded != afterEncoded {
+		*changes = append(*changes, Change{Kind: Changed, Path: path, Before: beforeEncoded, After: afterEncoded})
+	}
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// keySegment writes a key as jsonpath_utility reads it.
+func keySegment(key string) string {
+	if identifier.MatchString(key) {
+		return "." + key
+	}
+	return "['" + strings.ReplaceAll(key, "'", `\'`) + "']"
+}
+
+func encode(value any) string {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Sprint(value)
+	}
+	return strings.TrimSuffix(buffer.String(), "\n")
+}
+
+// Text diffs two bodies line by line, the paths are the line numbers of the
+// body they come from.
+func Text(before string, after string) []Change {
+	if before == after {
+		return nil
+	}
+	beforeLines, afterLines := strings.Split(before, "\n"), strings.Split(after, "\n")
+	if len(beforeLines) > MaxLines || len(afterLines) > MaxLines {
+		return []Change{{
+			Kind:   Changed,
+			Path:   "body",
+			Before: strconv.Itoa(len(before)) + " bytes",
+			After:  strconv.Itoa(len(after)) + " bytes",
+		}}
+	}
+
+	// lengths[i][j] is the longest common subsequence of the lines from i
+	// and j on.
+	lengths := make([][]int, len(beforeLines)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(afterLines)+1)
+	}
+	for i := len(beforeLines) - 1; i >= 0; i-- {
+		for j := len(afterLines) - 1; j >= 0; j-- {
+			if beforeLines[i] == afterLines[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	var changes []Change
+	i, j := 0, 0
+	for i < len(beforeLines) || j < len(afterLines) {
+		switch {
+		case i < len(beforeLines) && j < len(afterLines) && beforeLines[i] == afterLines[j]:
+			i, j = i+1, j+1
+		case i < len(beforeLines) && (j == len(afterLines) || lengths[i+1][j] >= lengths[i][j+1]):
+			changes = append(changes, Change{Kind: Removed, Path: "line " + strconv.Itoa(i+1), Before: beforeLines[i]})
+			i++
+		default:
+			changes = append(changes, Change{Kind: Added, Path: "line " + strconv.Itoa(j+1), After: afterLines[j]})
+			j++
+		}
+	}
+	return changes
+}
+
+// maxValueLength keeps a large value from taking over the diff.
+const maxValueLength = 200
+
+func shorten(value string) string {
+	value = strings.ReplaceAll(value, "\n", "\\n")
+	if len(value) > maxValueLength {
+		return value[:maxValueLength] + "…"
+	}
+	return value
+}
+
+// FormatChange writes a change on one line: + for added, - for removed and
+// ~ for changed.
+func FormatChange(change Change) string {
+	switch change.Kind {
+	case Added:
+		return "+ " + change.Path + ": " + shorten(change.After)
+	case Removed:
+		return "- " + change.Path + ": " + shorten(change.Before)
+	}
+	return "~ " + change.Path + ": " + shorten(change.Before) + " → " + shorten(change.After)
+}
+
+func FormatStatus(r Result) string {
+	if r.StatusBefore == r.StatusAfter {
+		return strconv.Itoa(r.StatusAfter) + " (same)"
+	}
+	return strconv.Itoa(r.StatusBefore) + " → " + strconv.Itoa(r.StatusAfter)
+}
+
+func FormatLatency(r Result) string {
+	delta := math.Round(r.LatencyAfter - r.LatencyBefore)
+	if delta == 0 {
+		// Rounding a small drop leaves -0.
+		delta = 0
+	}
+	return fmt.Sprintf("%.0fms → %.0fms (%+.0fms)", r.LatencyBefore, r.LatencyAfter, delta)
+}
+
+// Format writes the whole diff, for headless runs.
+func Format(r Result) string {
+	lines := []string{"Status: " + FormatStatus(r), "Latency: " + FormatLatency(r)}
+
+	section := func(title string, changes []Change) {
+		if len(changes) == 0 {
+			lines = append(lines, title+": no changes")
+			return
+		}
+		lines = append(lines, title+":")
+		for _, change := range changes {
+			lines = append(lines, "  "+FormatChange(change))
+		}
+	}
+	section("Headers", r.Headers)
+	if r.BodyJson {
+		section("Body (JSON)", r.Body)
+	} else {
+		section("Body", r.Body)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,105 @@
+package diff_module
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBody_Json(t *testing.T) {
+	changes, isJson := Body(
+		`{"count": 2, "items": [{"id": 1}, {"id": 2}], "debug": true, "a b": "x", "big": 12345678901234567890}`,
+		`{"count": 3, "items": [{"id": 1}, {"id": 5, "tag": "<new>"}, {"id": 3}], "a b": "y", "big": 12345678901234567890}`,
+	)
+
+	assert.True(t, isJson)
+	assert.Equal(t, []Change{
+		{Kind: Changed, Path: "$['a b']", Before: `"x"`, After: `"y"`},
+		{Kind: Changed, Path: "$.count", Before: "2", After: "3"},
+		{Kind: Removed, Path: "$.debug", Before: "true"},
+		{Kind: Changed, Path: "$.items[1].id", Before: "2", After: "5"},
+		{Kind: Added, Path: "$.items[1].tag", After: `"<new>"`},
+		{Kind: Added, Path: "$.items[2]", After: `{"id":3}`},
+	}, changes)
+}
+
+func TestBody_JsonTypeChange(t *testing.T) {
+	changes, _ := Body(`{"data": [1]}`, `{"data": {"0": 1}}`)
+	assert.Equal(t, []Change{{Kind: Changed, Path: "$.data", Before: "[1]", After: `{"0":1}`}}, changes)
+
+	changes, _ = Body(`[1, 2]`, `[1, 2]`)
+	assert.Empty(t, changes)
+}
+
+func TestBody_Text(t *testing.T) {
+	changes, isJson := Body("a\nb\nc\nd", "a\nc\nd\ne")
+
+	assert.False(t, isJson)
+	assert.Equal(t, []Change{
+		{Kind: Removed, Path: "line 2", Before: "b"},
+		{Kind: Added, Path: "line 4", After: "e"},
+	}, changes)
+
+	changes, _ = Body("<html>", `{"ok": true}`)
+	assert.Equal(t, []Change{
+		{Kind: Removed, Path: "line 1", Before: "<html>"},
+		{Kind: Added, Path: "line 1", After: `{"ok": true}`},
+	}, changes)
+}
+
+func TestText_TooLong(t *testing.T) {
+	long := strings.Repeat("a\n", MaxLines+1)
+	assert.Equal(t, []Change{{Kind: Changed, Path: "body", Before: "2002 bytes", After: "1 bytes"}}, Text(long, "b"))
+	assert.Empty(t, Text(long, long))
+}
+
+func TestHeaders(t *testing.T) {
+	changes := Headers(
+		http.Header{"Content-Length": {"4"}, "X-Old": {"a"}, "Set-Cookie": {"a=1", "b=2"}},
+		http.Header{"Content-Length": {"5"}, "X-New": {"b"}, "Set-Cookie": {"a=1", "b=2"}},
+	)
+	assert.Equal(t, []Change{
+		{Kind: Changed, Path: "Content-Length", Before: "4", After: "5"},
+		{Kind: Added, Path: "X-New", After: "b"},
+		{Kind: Removed, Path: "X-Old", Before: "a"},
+	}, changes)
+}
+
+func TestCompare(t *testing.T) {
+	before := request_module.RequestResponse{StatusCode: 503, ExecutionTime: 120, Headers: http.Header{"Date": {"1"}}, Result: `{"status": "down", "at": {"ms": 1}}`}
+	after := request_module.RequestResponse{StatusCode: 200, ExecutionTime: 35, Headers: http.Header{"Date": {"2"}}, Result: `{"status": "up", "at": {"ms": 2}}`}
+
+	result := Compare(before, after, nil)
+	assert.False(t, result.Same())
+	assert.Len(t, result.Headers, 1)
+	assert.Len(t, result.Body, 2)
+
+	result = Compare(before, after, []string{"date", "$.at", "$.stat"})
+	assert.Empty(t, result.Headers)
+	assert.Equal(t, []Change{{Kind: Changed, Path: "$.status", Before: `"down"`, After: `"up"`}}, result.Body)
+
+	assert.True(t, Compare(after, after, nil).Same())
+}
+
+func TestFormat(t *testing.T) {
+	result := Result{
+		StatusBefore: 503, StatusAfter: 200, LatencyBefore: 120, LatencyAfter: 35, BodyJson: true,
+		Body: []Change{
+			{Kind: Changed, Path: "$.count", Before: "2", After: "3"},
+			{Kind: Added, Path: "$.tag", After: strings.Repeat("a", 250)},
+		},
+	}
+
+	assert.Equal(t, "Status: 503 → 200\n"+
+		"Latency: 120ms → 35ms (-85ms)\n"+
+		"Headers: no changes\n"+
+		"Body (JSON):\n"+
+		"  ~ $.count: 2 → 3\n"+
+		"  + $.tag: "+strings.Repeat("a", 200)+"…", Format(result))
+	assert.Equal(t, "200 (same)", FormatStatus(Result{StatusBefore: 200, StatusAfter: 200}))
+	assert.Equal(t, "1ms → 1ms (+0ms)", FormatLatency(Result{LatencyBefore: 1.4, LatencyAfter: 1.1}))
+	assert.Equal(t, "- line 2: a\\nb", FormatChange(Change{Kind: Removed, Path: "line 2", Before: "a\nb"}))
+}
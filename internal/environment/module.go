@@ -0,0 +1,120 @@
+package environment_module
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"strings"
+
+	auth_module "github.com/diogopereiradev/httpzen/internal/auth"
+	app_path_util "github.com/diogopereiradev/httpzen/internal/utils/app_path"
+)
+
+type Environment struct {
+	Name      string                  `json:"name"`
+	Auth      auth_module.AuthOptions `json:"auth"`
+	Variables map[string]string       `json:"variables,omitempty"`
+}
+
+const DEFAULT_ENVIRONMENT = "default"
+
+// Environments are stored as plain JSON instead of going through viper, since
+// viper lowercases map keys and variable names must keep their case.
+var ENVIRONMENTS_FILE_NAME = "environments.json"
+
+var mkdirAll = os.MkdirAll
+var readFile = os.ReadFile
+var writeFile = os.WriteFile
+
+func GetEnvironmentsFilePath() string {
+	return app_path_util.GetConfigPath() + "/" + ENVIRONMENTS_FILE_NAME
+}
+
+func loadEnvironments() (map[string]Environment, error) {
+	environments := map[string]Environment{}
+
+	data, err := readFile(GetEnvironmentsFilePath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return environments, nil
+		}
+		return nil, err
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return environments, nil
+	}
+	if err := json.Unmarshal(data, &environments); err != nil {
+		return nil, err
+	}
+	return environments, nil
+}
+
+func saveEnvironments(environments map[string]Environment) error {
+	if err := mkdirAll(app_path_util.GetConfigPath(), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(environments, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(GetEnvironmentsFilePath(), data, 0600)
+}
+
+// GetEnvironment returns the named environment, or an empty one carrying the
+// name when it was never saved.
+func GetEnvironment(name string) Environment {
+	if name == "" {
+		name = DEFAULT_ENVIRONMENT
+	}
+	environments, err := loadEnvironments()
+	if err != nil {
+		return Environment{Name: name}
+	}
+	if env, ok := environments[name]; ok {
+		env.Name = name
+		return env
+	}
+	return Environment{Name: name}
+}
+
+func ListEnvironments() []string {
+	environments, err := loadEnvironments()
+	if err != nil {
+		return []string{}
+	}
+
+	names := make([]string, 0, len(environments))
+	for name := range environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func SaveEnvironment(env Environment) error {
+	if strings.TrimSpace(env.Name) == "" {
+		return errors.New("environment name cannot be empty")
+	}
+	environments, err := loadEnvironments()
+	if err != nil {
+		return err
+	}
+	environments[env.Name] = env
+	return saveEnvironments(environments)
+}
+
+func SaveEnvironmentAuth(name string, auth auth_module.AuthOptions) error {
+	env := GetEnvironment(name)
+	env.Auth = auth
+	return SaveEnvironment(env)
+}
+
+func DeleteEnvironment(name string) error {
+	environments, err := loadEnvironments()
+	if err != nil {
+		return err
+	}
+	delete(environments, name)
+	return saveEnvironments(environments)
+}
@@ -0,0 +1,80 @@
+package environment_module
+
+import (
+	"os"
+	"testing"
+
+	auth_module "github.com/diogopereiradev/httpzen/internal/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupTestEnvironments() func() {
+	ENVIRONMENTS_FILE_NAME = "environments_test.json"
+	_ = os.Remove(GetEnvironmentsFilePath())
+	return func() {
+		_ = os.Remove(GetEnvironmentsFilePath())
+		ENVIRONMENTS_FILE_NAME = "environments.json"
+	}
+}
+
+func TestGetEnvironment_Missing(t *testing.T) {
+	teardown := setupTestEnvironments()
+	defer teardown()
+
+	env := GetEnvironment("")
+	assert.Equal(t, DEFAULT_ENVIRONMENT, env.Name)
+	assert.True(t, env.Auth.IsEmpty())
+}
+
+func TestSaveEnvironment_RoundTrip(t *testing.T) {
+	teardown := setupTestEnvironments()
+	defer teardown()
+
+	err := SaveEnvironment(Environment{
+		Name:      "staging",
+		Auth:      auth_module.AuthOptions{Type: auth_module.AuthBearer, Token: "abc"},
+		Variables: map[string]string{"BaseUrl": "https://staging.example.com"},
+	})
+	assert.NoError(t, err)
+
+	env := GetEnvironment("staging")
+	assert.Equal(t, auth_module.AuthBearer, env.Auth.Type)
+	assert.Equal(t, "abc", env.Auth.Token)
+	assert.Equal(t, "https://staging.example.com", env.Variables["BaseUrl"], "variable names should keep their case")
+	assert.Equal(t, []string{"staging"}, ListEnvironments())
+}
+
+func TestSaveEnvironmentAuth_KeepsVariables(t *testing.T) {
+	teardown := setupTestEnvironments()
+	defer teardown()
+
+	_ = SaveEnvironment(Environment{Name: "dev", Variables: map[string]string{"a": "1"}})
+	err := SaveEnvironmentAuth("dev", auth_module.AuthOptions{Type: auth_module.AuthBasic, Username: "user"})
+	assert.NoError(t, err)
+
+	env := GetEnvironment("dev")
+	assert.Equal(t, "user", env.Auth.Username)
+	assert.Equal(t, "1", env.Variables["a"])
+}
+
+func TestSaveEnvironment_EmptyName(t *testing.T) {
+	assert.Error(t, SaveEnvironment(Environment{}))
+}
+
+func TestDeleteEnvironment(t *testing.T) {
+	teardown := setupTestEnvironments()
+	defer teardown()
+
+	_ = SaveEnvironment(Environment{Name: "dev"})
+	assert.NoError(t, DeleteEnvironment("dev"))
+	assert.Empty(t, ListEnvironments())
+}
+
+func TestLoadEnvironments_InvalidJson(t *testing.T) {
+	readFile = func(string) ([]byte, error) { return []byte("{invalid"), nil }
+	defer func() { readFile = os.ReadFile }()
+
+	env := GetEnvironment("dev")
+	assert.Equal(t, "dev", env.Name)
+	assert.Error(t, SaveEnvironment(Environment{Name: "dev"}))
+}
@@ -0,0 +1,271 @@
+package graphql_module
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+type completionToken struct {
+	kind  byte // 'n' for names, 'v' for other values, the character itself for punctuators
+	value string
+}
+
+// tokenize splits the query into names and punctuators, skipping comments,
+// strings and numbers, which only matter to completion as "a value".
+func tokenize(query string) []completionToken {
+	var tokens []completionToken
+	runes := []rune(query)
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '#':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '"':
+			if i+2 < len(runes) && runes[i+1] == '"' && runes[i+2] == '"' {
+				i += 3
+				for i+2 < len(runes) && !(runes[i] == '"' && runes[i+1] == '"' && runes[i+2] == '"') {
+					i++
+				}
+				i += 2
+			} else {
+				i++
+				for i < len(runes) && runes[i] != '"' && runes[i] != '\n' {
+					if runes[i] == '\\' {
+						i++
+					}
+					i++
+				}
+			}
+			tokens = append(tokens, completionToken{kind: 'v'})
+		case r == '_' || unicode.IsLetter(r):
+			start := i
+			for i+1 < len(runes) && (runes[i+1] == '_' || unicode.IsLetter(runes[i+1]) || unicode.IsDigit(runes[i+1])) {
+				i++
+			}
+			tokens = append(tokens, completionToken{kind: 'n', value: string(runes[start : i+1])})
+		case r == '-' || unicode.IsDigit(r):
+			for i+1 < len(runes) && (unicode.IsDigit(runes[i+1]) || strings.ContainsRune(".eE+-", runes[i+1])) {
+				i++
+			}
+			tokens = append(tokens, completionToken{kind: 'v'})
+		case r == '.':
+			if i+2 < len(runes) && runes[i+1] == '.' && runes[i+2] == '.' {
+				i += 2
+				tokens = append(tokens, completionToken{kind: '.', value: "..."})
+			}
+		case strings.ContainsRune("{}()[]:$@=!|&", r):
+			tokens = append(tokens, completionToken{kind: byte(r), value: string(r)})
+		}
+	}
+	return tokens
+}
+
+type completionScope struct {
+	typeName string
+	// lastField is the last field selected in this selection set, the one a
+	// following "{" or "(" belongs to.
+	lastField string
+}
+
+// Complete suggests what can be typed at the end of before, the text of the
+// query up to the cursor: fields of the enclosing selection set, arguments
+// inside parentheses, type names after "on", or operation keywords.
+func Complete(schema *ast.Schema, before string) []string {
+	if schema == nil {
+		return nil
+	}
+
+	prefixStart := len(before)
+	for prefixStart > 0 {
+		c := before[prefixStart-1]
+		if c != '_' && !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') && !(c >= '0' && c <= '9') {
+			break
+		}
+		prefixStart--
+	}
+	prefix := before[prefixStart:]
+	tokens := tokenize(before[:prefixStart])
+
+	var scopes []completionScope
+	operation := ""
+	fragmentType := ""
+	afterOn := false
+
+	// Argument state, only tracked while inside the parentheses of a field.
+	argsDepth := 0
+	argsField := ""
+	argsNesting := 0
+	expectArgName := false
+
+	for i, token := range tokens {
+		if argsDepth > 0 {
+			switch token.kind {
+			case '(':
+				argsDepth++
+			case ')':
+				argsDepth--
+			case '[', '{':
+				argsNesting++
+			case ']', '}':
+				argsNesting--
+				if argsNesting == 0 {
+					expectArgName = true
+				}
+			case ':':
+				expectArgName = false
+			case 'n', 'v':
+				if argsNesting == 0 {
+					// A name right after "(" or a complete value is the next
+					// argument, anything after ':' is its value.
+					if expectArgName && token.kind == 'n' {
+						expectArgName = false
+					} else if i > 0 && (tokens[i-1].kind == ':' || tokens[i-1].kind == '$') {
+						expectArgName = true
+					}
+				}
+			}
+			continue
+		}
+
+		switch token.kind {
+		case 'n':
+			if afterOn {
+				afterOn = false
+				if len(scopes) == 0 {
+					fragmentType = token.value
+				} else {
+					scopes[len(scopes)-1].lastField = "on " + token.value
+				}
+				continue
+			}
+			if token.value == "on" && (len(scopes) == 0 || (i > 0 && tokens[i-1].kind == '.')) {
+				afterOn = true
+				continue
+			}
+			if len(scopes) == 0 {
+				switch token.value {
+				case "query", "mutation", "subscription":
+					operation = token.value
+					fragmentType = ""
+				case "fragment":
+					operation = "fragment"
+				}
+				continue
+			}
+			if i > 0 && (tokens[i-1].kind == '.' || tokens[i-1].kind == '@') {
+				continue // fragment spread or directive
+			}
+			scopes[len(scopes)-1].lastField = token.value
+		case '(':
+			if len(scopes) > 0 {
+				argsDepth = 1
+				argsField = scopes[len(scopes)-1].lastField
+				argsNesting = 0
+				expectArgName = true
+			} else {
+				// Variable definitions of the operation, skip them.
+				argsDepth = 1
+				argsField = ""
+			}
+		case '{':
+			if len(scopes) == 0 {
+				scopes = append(scopes, completionScope{typeName: rootTypeName(schema, operation, fragmentType)})
+				continue
+			}
+			scopes = append(scopes, completionScope{typeName: fieldTypeName(schema, scopes[len(scopes)-1])})
+		case '}':
+			if len(scopes) > 0 {
+				scopes = scopes[:len(scopes)-1]
+			}
+			if len(scopes) == 0 {
+				operation = ""
+				fragmentType = ""
+			}
+		}
+	}
+
+	var candidates []string
+	switch {
+	case afterOn:
+		for name, def := range schema.Types {
+			if !strings.HasPrefix(name, "__") && (def.Kind == ast.Object || def.Kind == ast.Interface || def.Kind == ast.Union) {
+				candidates = append(candidates, name)
+			}
+		}
+		sort.Strings(candidates)
+	case argsDepth > 0:
+		if !expectArgName || argsField == "" || len(scopes) == 0 {
+			return nil
+		}
+		if def := schema.Types[scopes[len(scopes)-1].typeName]; def != nil {
+			if field := def.Fields.ForName(argsField); field != nil {
+				for _, arg := range field.Arguments {
+					candidates = append(candidates, arg.Name)
+				}
+			}
+		}
+	case len(scopes) > 0:
+		def := schema.Types[scopes[len(scopes)-1].typeName]
+		if def == nil {
+			return nil
+		}
+		for _, field := range def.Fields {
+			if !strings.HasPrefix(field.Name, "__") {
+				candidates = append(candidates, field.Name)
+			}
+		}
+		candidates = append(candidates, "__typename")
+	default:
+		candidates = []string{"query", "mutation", "subscription", "fragment"}
+	}
+
+	var result []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) && candidate != prefix {
+			result = append(result, candidate)
+		}
+	}
+	return result
+}
+
+func rootTypeName(schema *ast.Schema, operation string, fragmentType string) string {
+	if fragmentType != "" {
+		return fragmentType
+	}
+	switch operation {
+	case "mutation":
+		if schema.Mutation != nil {
+			return schema.Mutation.Name
+		}
+	case "subscription":
+		if schema.Subscription != nil {
+			return schema.Subscription.Name
+		}
+	default:
+		if schema.Query != nil {
+			return schema.Query.Name
+		}
+	}
+	return ""
+}
+
+func fieldTypeName(schema *ast.Schema, scope completionScope) string {
+	if typeName, ok := strings.CutPrefix(scope.lastField, "on "); ok {
+		return typeName
+	}
+	def := schema.Types[scope.typeName]
+	if def == nil {
+		return ""
+	}
+	field := def.Fields.ForName(scope.lastField)
+	if field == nil {
+		return ""
+	}
+	return field.Type.Name()
+}
@@ -0,0 +1,46 @@
+package graphql_module
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComplete(t *testing.T) {
+	schema, err := BuildSchema(testIntrospection)
+	assert.NoError(t, err)
+
+	cases := []struct {
+		name   string
+		before string
+		want   []string
+	}{
+		{"top level keywords", "mu", []string{"mutation"}},
+		{"query root fields", "{ ", []string{"user", "search", "__typename"}},
+		{"prefix", "query Q($id: ID!) { us", []string{"user"}},
+		{"nested selection", "{ user(id: 1) { ", []string{"id", "name", "role", "posts", "__typename"}},
+		{"list of objects", "{ user(id: 1) { posts { t", []string{"title"}},
+		{"after closing a selection", "{ user(id: 1) { posts { title } n", []string{"name"}},
+		{"alias", "{ me: user(id: 1) { na", []string{"name"}},
+		{"mutation root", "mutation { cr", []string{"createUser"}},
+		{"arguments", "{ user(", []string{"id", "withPosts"}},
+		{"next argument", "{ user(id: 1 ", []string{"id", "withPosts"}},
+		{"next argument after variable", "query($x: ID!) { user(id: $x, w", []string{"withPosts"}},
+		{"argument value", "{ user(id: ", nil},
+		{"object argument", "mutation { createUser(input: {name: ", nil},
+		{"inline fragment type", "{ search { ... on ", []string{"Mutation", "Node", "Post", "Query", "SearchResult", "User"}},
+		{"inline fragment fields", "{ search { ... on Post { ", []string{"title", "published", "__typename"}},
+		{"fragment definition", "fragment F on User { po", []string{"posts"}},
+		{"directive arguments are skipped", "{ user(id: 1) @include(if: true) { na", []string{"name"}},
+		{"strings and comments", "{ user(id: \"{ }\") { # { \n na", []string{"name"}},
+		{"unknown field", "{ nope { ", nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.want, Complete(schema, c.before))
+		})
+	}
+
+	assert.Nil(t, Complete(nil, "{ "))
+}
@@ -0,0 +1,339 @@
+package graphql_module
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	ip_cache_module "github.com/diogopereiradev/httpzen/internal/cache"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/diogopereiradev/httpzen/internal/utils/http_utility"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// SchemaCacheDuration is how long an introspected schema is reused before it
+// is fetched again.
+const SchemaCacheDuration = 24 * time.Hour
+
+const IntrospectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types { ...FullType }
+    directives { name locations args { ...InputValue } }
+  }
+}
+
+fragment FullType on __Type {
+  kind
+  name
+  fields(includeDeprecated: true) { name args { ...InputValue } type { ...TypeRef } }
+  inputFields { ...InputValue }
+  interfaces { ...TypeRef }
+  enumValues(includeDeprecated: true) { name }
+  possibleTypes { ...TypeRef }
+}
+
+fragment InputValue on __InputValue {
+  name
+  type { ...TypeRef }
+  defaultValue
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name } } } } } } }
+}`
+
+type introspectionTypeRef struct {
+	Kind   string                `json:"kind"`
+	Name   string                `json:"name"`
+	OfType *introspectionTypeRef `json:"ofType"`
+}
+
+type introspectionInputValue struct {
+	Name         string               `json:"name"`
+	Type         introspectionTypeRef `json:"type"`
+	DefaultValue *string              `json:"defaultValue"`
+}
+
+type introspectionField struct {
+	Name string                    `json:"name"`
+	Args []introspectionInputValue `json:"args"`
+	Type introspectionTypeRef      `json:"type"`
+}
+
+type introspectionType struct {
+	Kind          string                    `json:"kind"`
+	Name          string                    `json:"name"`
+	Fields        []introspectionField      `json:"fields"`
+	InputFields   []introspectionInputValue `json:"inputFields"`
+	Interfaces    []introspectionTypeRef    `json:"interfaces"`
+	EnumValues    []struct{ Name string }   `json:"enumValues"`
+	PossibleTypes []introspectionTypeRef    `json:"possibleTypes"`
+}
+
+type introspectionSchema struct {
+	QueryType        *struct{ Name string } `json:"queryType"`
+	MutationType     *struct{ Name string } `json:"mutationType"`
+	SubscriptionType *struct{ Name string } `json:"subscriptionType"`
+	Types            []introspectionType    `json:"types"`
+	Directives       []struct {
+		Name      string                    `json:"name"`
+		Locations []string                  `json:"locations"`
+		Args      []introspectionInputValue `json:"args"`
+	} `json:"directives"`
+}
+
+var runRequest = request_module.RunRequest
+var now = time.Now
+var getSchemaFromCache = ip_cache_module.GetGraphQLSchemaFromCache
+var setSchemaToCache = ip_cache_module.SetGraphQLSchemaToCache
+
+// GetSchema returns the schema of the endpoint the request points to. The
+// introspection result is cached per URL, refresh skips the cache.
+func GetSchema(options request_module.RequestOptions, refresh bool) (*ast.Schema, error) {
+	key := schemaCacheKey(options.Url)
+
+	if !refresh {
+		if cached, fetchedAt, ok := getSchemaFromCache(key); ok && now().Sub(time.Unix(fetchedAt, 0)) < SchemaCacheDuration {
+			if schema, err := BuildSchema(cached); err == nil {
+				return schema, nil
+			}
+		}
+	}
+
+	introspection, err := FetchIntrospection(options)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := BuildSchema(introspection)
+	if err != nil {
+		return nil, err
+	}
+	setSchemaToCache(key, introspection, now().Unix())
+	return schema, nil
+}
+
+func schemaCacheKey(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return "schema_" + hex.EncodeToString(sum[:16])
+}
+
+// FetchIntrospection sends the introspection query with the headers and auth
+// of the given request and returns the raw "__schema" object.
+func FetchIntrospection(options request_module.RequestOptions) (string, error) {
+	headers := http.Header{}
+	for k, v := range options.Headers {
+		if !strings.EqualFold(k, "Content-Type") && !strings.EqualFold(k, "Content-Length") {
+			headers[k] = v
+		}
+	}
+
+	res, err := runRequest(request_module.RequestOptions{
+		Url:     options.Url,
+		Method:  "POST",
+		Headers: headers,
+		Timeout: options.Timeout,
+		Auth:    options.Auth,
+		Body:    http_utility.NewGraphQLBody(IntrospectionQuery, "", "IntrospectionQuery"),
+	})
+	if err != nil {
+		return "", err
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return "", fmt.Errorf("introspection failed with status %s", res.StatusMessage)
+	}
+
+	var document struct {
+		Data struct {
+			Schema json.RawMessage `json:"__schema"`
+		} `json:"data"`
+		Errors []ResponseError `json:"errors"`
+	}
+	if err := json.Unmarshal([]byte(res.Result), &document); err != nil {
+		return "", errors.New("introspection response is not valid JSON: " + err.Error())
+	}
+	if len(document.Errors) > 0 {
+		return "", errors.New("introspection failed: " + document.Errors[0].Message)
+	}
+	if len(document.Data.Schema) == 0 || string(document.Data.Schema) == "null" {
+		return "", errors.New("introspection is disabled on this server")
+	}
+	return string(document.Data.Schema), nil
+}
+
+// BuildSchema turns an introspection "__schema" object into a schema the
+// query validator understands, by printing it back as SDL.
+func BuildSchema(introspection string) (*ast.Schema, error) {
+	var schema introspectionSchema
+	if err := json.Unmarshal([]byte(introspection), &schema); err != nil {
+		return nil, err
+	}
+
+	schemaAst, gqlErr := gqlparser.LoadSchema(&ast.Source{Name: "introspection", Input: printSchema(schema)})
+	if gqlErr != nil {
+		return nil, gqlErr
+	}
+	return schemaAst, nil
+}
+
+var builtinScalars = map[string]bool{"String": true, "Int": true, "Float": true, "Boolean": true, "ID": true}
+var builtinDirectives = map[string]bool{"include": true, "skip": true, "deprecated": true, "specifiedBy": true, "defer": true, "oneOf": true}
+
+func printTypeRef(ref introspectionTypeRef) string {
+	switch ref.Kind {
+	case "NON_NULL":
+		if ref.OfType != nil {
+			return printTypeRef(*ref.OfType) + "!"
+		}
+	case "LIST":
+		if ref.OfType != nil {
+			return "[" + printTypeRef(*ref.OfType) + "]"
+		}
+	}
+	return ref.Name
+}
+
+func printInputValues(values []introspectionInputValue, separator string) string {
+	parts := make([]string, 0, len(values))
+	for _, value := range values {
+		part := value.Name + ": " + printTypeRef(value.Type)
+		if value.DefaultValue != nil {
+			part += " = " + *value.DefaultValue
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, separator)
+}
+
+func printSchema(schema introspectionSchema) string {
+	var b strings.Builder
+
+	b.WriteString("schema {\n")
+	if schema.QueryType != nil {
+		b.WriteString("  query: " + schema.QueryType.Name + "\n")
+	}
+	if schema.MutationType != nil {
+		b.WriteString("  mutation: " + schema.MutationType.Name + "\n")
+	}
+	if schema.SubscriptionType != nil {
+		b.WriteString("  subscription: " + schema.SubscriptionType.Name + "\n")
+	}
+	b.WriteString("}\n\n")
+
+	for _, t := range schema.Types {
+		if strings.HasPrefix(t.Name, "__") || builtinScalars[t.Name] {
+			continue
+		}
+
+		switch t.Kind {
+		case "SCALAR":
+			b.WriteString("scalar " + t.Name + "\n\n")
+		case "OBJECT", "INTERFACE":
+			keyword := "type "
+			if t.Kind == "INTERFACE" {
+				keyword = "interface "
+			}
+			b.WriteString(keyword + t.Name)
+			if len(t.Interfaces) > 0 {
+				names := make([]string, 0, len(t.Interfaces))
+				for _, i := range t.Interfaces {
+					names = append(names, i.Name)
+				}
+				b.WriteString(" implements " + strings.Join(names, " & "))
+			}
+			b.WriteString(" {\n")
+			for _, f := range t.Fields {
+				b.WriteString("  " + f.Name)
+				if len(f.Args) > 0 {
+					b.WriteString("(" + printInputValues(f.Args, ", ") + ")")
+				}
+				b.WriteString(": " + printTypeRef(f.Type) + "\n")
+			}
+			b.WriteString("}\n\n")
+		case "UNION":
+			names := make([]string, 0, len(t.PossibleTypes))
+			for _, p := range t.PossibleTypes {
+				names = append(names, p.Name)
+			}
+			b.WriteString("union " + t.Name + " = " + strings.Join(names, " | ") + "\n\n")
+		case "ENUM":
+			b.WriteString("enum " + t.Name + " {\n")
+			for _, v := range t.EnumValues {
+				b.WriteString("  " + v.Name + "\n")
+			}
+			b.WriteString("}\n\n")
+		case "INPUT_OBJECT":
+			b.WriteString("input " + t.Name + " {\n  " + printInputValues(t.InputFields, "\n  ") + "\n}\n\n")
+		}
+	}
+
+	for _, d := range schema.Directives {
+		if builtinDirectives[d.Name] {
+			continue
+		}
+		b.WriteString("directive @" + d.Name)
+		if len(d.Args) > 0 {
+			b.WriteString("(" + printInputValues(d.Args, ", ") + ")")
+		}
+		b.WriteString(" on " + strings.Join(d.Locations, " | ") + "\n\n")
+	}
+	return b.String()
+}
+
+// Validate checks the query against the schema and returns one message per
+// problem, with its line and column.
+func Validate(schema *ast.Schema, query string, operationName string) []string {
+	document, gqlErr := gqlparser.LoadQuery(schema, query)
+	if gqlErr != nil {
+		return formatGqlErrors(gqlErr)
+	}
+
+	if operationName != "" && document.Operations.ForName(operationName) == nil {
+		return []string{"Operation \"" + operationName + "\" is not defined in the query"}
+	}
+	if operationName == "" && len(document.Operations) > 1 {
+		return []string{"The query defines several operations, pick one with an operation name"}
+	}
+	return nil
+}
+
+// OperationNames lists the named operations of a query, in order. Queries
+// that can't be parsed have none.
+func OperationNames(query string) []string {
+	document, err := parser.ParseQuery(&ast.Source{Input: query})
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, op := range document.Operations {
+		if op.Name != "" {
+			names = append(names, op.Name)
+		}
+	}
+	return names
+}
+
+func formatGqlErrors(list gqlerror.List) []string {
+	messages := make([]string, 0, len(list))
+	for _, err := range list {
+		message := err.Message
+		if len(err.Locations) > 0 {
+			message = fmt.Sprintf("%d:%d %s", err.Locations[0].Line, err.Locations[0].Column, message)
+		}
+		messages = append(messages, message)
+	}
+	return messages
+}
@@ -0,0 +1,226 @@
+package graphql_module
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	ip_cache_module "github.com/diogopereiradev/httpzen/internal/cache"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/diogopereiradev/httpzen/internal/utils/http_utility"
+	"github.com/stretchr/testify/assert"
+)
+
+func named(kind string, name string) string {
+	return `{"kind":"` + kind + `","name":"` + name + `","ofType":null}`
+}
+
+func nonNull(inner string) string {
+	return `{"kind":"NON_NULL","name":null,"ofType":` + inner + `}`
+}
+
+func list(inner string) string {
+	return `{"kind":"LIST","name":null,"ofType":` + inner + `}`
+}
+
+var testIntrospection = `{
+  "queryType": {"name": "Query"},
+  "mutationType": {"name": "Mutation"},
+  "subscriptionType": null,
+  "types": [
+    {"kind": "OBJECT", "name": "Query", "fields": [
+      {"name": "user", "args": [
+        {"name": "id", "type": ` + nonNull(named("SCALAR", "ID")) + `, "defaultValue": null},
+        {"name": "withPosts", "type": ` + named("SCALAR", "Boolean") + `, "defaultValue": "false"}
+      ], "type": ` + named("OBJECT", "User") + `},
+      {"name": "search", "args": [], "type": ` + list(named("UNION", "SearchResult")) + `}
+    ], "interfaces": []},
+    {"kind": "OBJECT", "name": "Mutation", "fields": [
+      {"name": "createUser", "args": [
+        {"name": "input", "type": ` + nonNull(named("INPUT_OBJECT", "UserInput")) + `, "defaultValue": null}
+      ], "type": ` + named("OBJECT", "User") + `}
+    ], "interfaces": []},
+    {"kind": "INTERFACE", "name": "Node", "fields": [
+      {"name": "id", "args": [], "type": ` + nonNull(named("SCALAR", "ID")) + `}
+    ], "possibleTypes": [` + named("OBJECT", "User") + `]},
+    {"kind": "OBJECT", "name": "User", "fields": [
+      {"name": "id", "args": [], "type": ` + nonNull(named("SCALAR", "ID")) + `},
+      {"name": "name", "args": [], "type": ` + named("SCALAR", "String") + `},
+      {"name": "role", "args": [], "type": ` + named("ENUM", "Role") + `},
+      {"name": "posts", "args": [], "type": ` + nonNull(list(nonNull(named("OBJECT", "Post")))) + `}
+    ], "interfaces": [` + named("INTERFACE", "Node") + `]},
+    {"kind": "OBJECT", "name": "Post", "fields": [
+      {"name": "title", "args": [], "type": ` + named("SCALAR", "String") + `},
+      {"name": "published", "args": [], "type": ` + named("SCALAR", "DateTime") + `}
+    ], "interfaces": []},
+    {"kind": "UNION", "name": "SearchResult", "possibleTypes": [` + named("OBJECT", "User") + `, ` + named("OBJECT", "Post") + `]},
+    {"kind": "ENUM", "name": "Role", "enumValues": [{"name": "ADMIN"}, {"name": "MEMBER"}]},
+    {"kind": "INPUT_OBJECT", "name": "UserInput", "inputFields": [
+      {"name": "name", "type": ` + nonNull(named("SCALAR", "String")) + `, "defaultValue": null},
+      {"name": "role", "type": ` + named("ENUM", "Role") + `, "defaultValue": "MEMBER"}
+    ]},
+    {"kind": "SCALAR", "name": "DateTime"},
+    {"kind": "SCALAR", "name": "String"},
+    {"kind": "SCALAR", "name": "ID"},
+    {"kind": "SCALAR", "name": "Boolean"},
+    {"kind": "OBJECT", "name": "__Type", "fields": [], "interfaces": []}
+  ],
+  "directives": [
+    {"name": "include", "locations": ["FIELD"], "args": [{"name": "if", "type": ` + nonNull(named("SCALAR", "Boolean")) + `, "defaultValue": null}]},
+    {"name": "cached", "locations": ["FIELD", "QUERY"], "args": [{"name": "ttl", "type": ` + named("SCALAR", "Int") + `, "defaultValue": "60"}]}
+  ]
+}`
+
+func TestBuildSchema(t *testing.T) {
+	schema, err := BuildSchema(testIntrospection)
+	assert.NoError(t, err)
+	assert.Equal(t, "Query", schema.Query.Name)
+	assert.Equal(t, "Mutation", schema.Mutation.Name)
+	assert.NotNil(t, schema.Types["UserInput"])
+	assert.NotNil(t, schema.Directives["cached"])
+	assert.Equal(t, "[Post!]!", schema.Types["User"].Fields.ForName("posts").Type.String())
+
+	_, err = BuildSchema("not json")
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	schema, err := BuildSchema(testIntrospection)
+	assert.NoError(t, err)
+
+	assert.Empty(t, Validate(schema, `query Q($id: ID!) { user(id: $id) { id name posts { title } } }`, ""))
+	assert.Empty(t, Validate(schema, `{ search { ... on User { name } ... on Post { title } } }`, ""))
+	assert.Empty(t, Validate(schema, `mutation { createUser(input: {name: "a", role: ADMIN}) { id } }`, ""))
+
+	errs := Validate(schema, "{\n  user(id: 1) { email }\n}", "")
+	assert.Len(t, errs, 1)
+	assert.True(t, strings.HasPrefix(errs[0], "2:17"), errs[0])
+	assert.Contains(t, errs[0], "email")
+
+	errs = Validate(schema, `query A { user(id: 1) { id } } query B { user(id: 2) { id } }`, "")
+	assert.Len(t, errs, 1)
+	assert.Empty(t, Validate(schema, `query A { user(id: 1) { id } } query B { user(id: 2) { id } }`, "B"))
+	assert.Len(t, Validate(schema, `query A { user(id: 1) { id } }`, "C"), 1)
+
+	assert.NotEmpty(t, Validate(schema, `{ user(id: 1) { id }`, ""))
+}
+
+func TestOperationNames(t *testing.T) {
+	assert.Equal(t, []string{"A", "B"}, OperationNames(`query A { a } mutation B { b } { c }`))
+	assert.Nil(t, OperationNames(`query {`))
+}
+
+func TestFetchIntrospection(t *testing.T) {
+	var sent request_module.RequestOptions
+	runRequest = func(options request_module.RequestOptions) (request_module.RequestResponse, error) {
+		sent = options
+		return request_module.RequestResponse{StatusCode: 200, Result: `{"data":{"__schema":` + testIntrospection + `}}`}, nil
+	}
+	defer func() { runRequest = request_module.RunRequest }()
+
+	introspection, err := FetchIntrospection(request_module.RequestOptions{
+		Url:     "http://api/graphql",
+		Method:  "GET",
+		Headers: map[string][]string{"Authorization": {"Bearer x"}, "Content-Type": {"text/plain"}},
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, introspection, `"queryType"`)
+
+	assert.Equal(t, "POST", sent.Method)
+	assert.Equal(t, "Bearer x", sent.Headers.Get("Authorization"))
+	assert.Empty(t, sent.Headers.Get("Content-Type"))
+	query, _, operation, ok := http_utility.GetGraphQLBody(sent.Body)
+	assert.True(t, ok)
+	assert.Equal(t, IntrospectionQuery, query)
+	assert.Equal(t, "IntrospectionQuery", operation)
+}
+
+func TestFetchIntrospection_Errors(t *testing.T) {
+	defer func() { runRequest = request_module.RunRequest }()
+
+	cases := map[string]request_module.RequestResponse{
+		"status":   {StatusCode: 400, StatusMessage: "400 Bad Request"},
+		"json":     {StatusCode: 200, Result: "<html>"},
+		"errors":   {StatusCode: 200, Result: `{"errors":[{"message":"introspection not allowed"}]}`},
+		"disabled": {StatusCode: 200, Result: `{"data":{"__schema":null}}`},
+	}
+	for name, response := range cases {
+		runRequest = func(options request_module.RequestOptions) (request_module.RequestResponse, error) {
+			return response, nil
+		}
+		_, err := FetchIntrospection(request_module.RequestOptions{Url: "http://api"})
+		assert.Error(t, err, name)
+	}
+
+	runRequest = func(options request_module.RequestOptions) (request_module.RequestResponse, error) {
+		return request_module.RequestResponse{}, errors.New("refused")
+	}
+	_, err := FetchIntrospection(request_module.RequestOptions{Url: "http://api"})
+	assert.EqualError(t, err, "refused")
+}
+
+func TestGetSchema_UsesCache(t *testing.T) {
+	cache := map[string]string{}
+	cacheTimes := map[string]int64{}
+	fetches := 0
+
+	getSchemaFromCache = func(key string) (string, int64, bool) {
+		schema, ok := cache[key]
+		return schema, cacheTimes[key], ok
+	}
+	setSchemaToCache = func(key string, schema string, fetchedAt int64) {
+		cache[key] = schema
+		cacheTimes[key] = fetchedAt
+	}
+	runRequest = func(options request_module.RequestOptions) (request_module.RequestResponse, error) {
+		fetches++
+		return request_module.RequestResponse{StatusCode: 200, Result: `{"data":{"__schema":` + testIntrospection + `}}`}, nil
+	}
+	current := time.Unix(1700000000, 0)
+	now = func() time.Time { return current }
+	defer func() {
+		runRequest = request_module.RunRequest
+		now = time.Now
+		getSchemaFromCache = ip_cache_module.GetGraphQLSchemaFromCache
+		setSchemaToCache = ip_cache_module.SetGraphQLSchemaToCache
+	}()
+
+	options := request_module.RequestOptions{Url: "http://api/graphql"}
+
+	_, err := GetSchema(options, false)
+	assert.NoError(t, err)
+	_, err = GetSchema(options, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, fetches)
+	for key := range cache {
+		assert.NotContains(t, key, ".")
+	}
+
+	_, err = GetSchema(options, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, fetches)
+
+	current = current.Add(SchemaCacheDuration + time.Minute)
+	_, err = GetSchema(options, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, fetches)
+}
+
+func TestParseResponse(t *testing.T) {
+	res, ok := ParseResponse(`{"data":{"user":null},"errors":[{"message":"not found","path":["user",0,"name"],"locations":[{"line":3,"column":5}],"extensions":{"code":"NOT_FOUND"}}]}`)
+	assert.True(t, ok)
+	assert.Equal(t, `{"user":null}`, res.Data)
+	assert.Len(t, res.Errors, 1)
+	assert.Equal(t, "user.0.name (3:5): [NOT_FOUND] not found", res.Errors[0].Format())
+
+	res, ok = ParseResponse(`{"errors":[{"message":"bad"}]}`)
+	assert.True(t, ok)
+	assert.Equal(t, "null", res.Data)
+	assert.Equal(t, "bad", res.Errors[0].Format())
+
+	_, ok = ParseResponse(`{"id":1}`)
+	assert.False(t, ok)
+	_, ok = ParseResponse(`nope`)
+	assert.False(t, ok)
+}
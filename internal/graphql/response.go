@@ -0,0 +1,70 @@
+package graphql_module
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type ResponseErrorLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type ResponseError struct {
+	Message    string                  `json:"message"`
+	Path       []any                   `json:"path,omitempty"`
+	Locations  []ResponseErrorLocation `json:"locations,omitempty"`
+	Extensions map[string]any          `json:"extensions,omitempty"`
+}
+
+type Response struct {
+	// Data is the raw "data" member, "null" when the server sent none.
+	Data   string
+	Errors []ResponseError
+}
+
+// ParseResponse splits a GraphQL response body into its data and errors. ok
+// is false when the body is not a GraphQL response document.
+func ParseResponse(body string) (Response, bool) {
+	var document struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []ResponseError `json:"errors"`
+	}
+	if err := json.Unmarshal([]byte(body), &document); err != nil {
+		return Response{}, false
+	}
+	if document.Data == nil && document.Errors == nil {
+		return Response{}, false
+	}
+
+	data := string(document.Data)
+	if data == "" {
+		data = "null"
+	}
+	return Response{Data: data, Errors: document.Errors}, true
+}
+
+// Format renders the error on one line, like "users.0.name (3:5): message".
+func (e ResponseError) Format() string {
+	var where []string
+	if len(e.Path) > 0 {
+		parts := make([]string, 0, len(e.Path))
+		for _, p := range e.Path {
+			parts = append(parts, fmt.Sprint(p))
+		}
+		where = append(where, strings.Join(parts, "."))
+	}
+	if len(e.Locations) > 0 {
+		where = append(where, fmt.Sprintf("(%d:%d)", e.Locations[0].Line, e.Locations[0].Column))
+	}
+
+	message := e.Message
+	if code, ok := e.Extensions["code"].(string); ok && code != "" {
+		message = "[" + code + "] " + message
+	}
+	if len(where) == 0 {
+		return message
+	}
+	return strings.Join(where, " ") + ": " + message
+}
@@ -0,0 +1,285 @@
+package grpc_module
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	reflection "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+type MethodInfo struct {
+	Name            string
+	Input           string
+	Output          string
+	ClientStreaming bool
+	ServerStreaming bool
+}
+
+type ServiceInfo struct {
+	Name    string
+	Methods []MethodInfo
+}
+
+// ParseMethodName splits "pkg.Service/Method", or "pkg.Service.Method", into
+// the full service name and the method name.
+func ParseMethodName(name string) (string, string, bool) {
+	name = strings.TrimPrefix(name, "/")
+	separator := strings.LastIndex(name, "/")
+	if separator < 0 {
+		separator = strings.LastIndex(name, ".")
+	}
+	if separator <= 0 || separator == len(name)-1 {
+		return "", "", false
+	}
+	return name[:separator], name[separator+1:], true
+}
+
+// loadDescriptors returns the files describing the given services, compiled
+// from the local .proto files when there are any or fetched through server
+// reflection otherwise. With no services and reflection, every service the
+// server advertises is loaded.
+func loadDescriptors(ctx context.Context, conn grpc.ClientConnInterface, options CallOptions, services []string) (*protoregistry.Files, []string, error) {
+	if len(options.ProtoFiles) > 0 {
+		files, err := compileProtoFiles(ctx, options.ProtoFiles, options.ImportPaths)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(services) == 0 {
+			files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+				for i := 0; i < fd.Services().Len(); i++ {
+					services = append(services, string(fd.Services().Get(i).FullName()))
+				}
+				return true
+			})
+		}
+		return files, services, nil
+	}
+
+	client, err := newReflectionClient(ctx, conn)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer client.close()
+
+	if len(services) == 0 {
+		if services, err = client.listServices(); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, service := range services {
+		if err := client.fileContainingSymbol(service); err != nil {
+			return nil, nil, err
+		}
+	}
+	files, err := client.registry()
+	if err != nil {
+		return nil, nil, err
+	}
+	return files, services, nil
+}
+
+func findService(files *protoregistry.Files, name string) (protoreflect.ServiceDescriptor, error) {
+	descriptor, err := files.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, errors.New("service \"" + name + "\" not found")
+	}
+	service, ok := descriptor.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, errors.New("\"" + name + "\" is not a service")
+	}
+	return service, nil
+}
+
+func describeService(service protoreflect.ServiceDescriptor) ServiceInfo {
+	info := ServiceInfo{Name: string(service.FullName())}
+	for i := 0; i < service.Methods().Len(); i++ {
+		method := service.Methods().Get(i)
+		info.Methods = append(info.Methods, MethodInfo{
+			Name:            string(method.Name()),
+			Input:           string(method.Input().FullName()),
+			Output:          string(method.Output().FullName()),
+			ClientStreaming: method.IsStreamingClient(),
+			ServerStreaming: method.IsStreamingServer(),
+		})
+	}
+	return info
+}
+
+func compileProtoFiles(ctx context.Context, paths []string, importPaths []string) (*protoregistry.Files, error) {
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{ImportPaths: importPaths}),
+	}
+	compiled, err := compiler.Compile(ctx, paths...)
+	if err != nil {
+		return nil, err
+	}
+
+	files := &protoregistry.Files{}
+	for _, fd := range compiled {
+		if err := registerFile(files, fd); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func registerFile(files *protoregistry.Files, fd protoreflect.FileDescriptor) error {
+	if _, err := files.FindFileByPath(fd.Path()); err == nil {
+		return nil
+	}
+	imports := fd.Imports()
+	for i := 0; i < imports.Len(); i++ {
+		if err := registerFile(files, imports.Get(i).FileDescriptor); err != nil {
+			return err
+		}
+	}
+	return files.RegisterFile(fd)
+}
+
+// reflectionClient asks the server for its file descriptors over a single
+// reflection stream and collects them, with their dependencies.
+type reflectionClient struct {
+	stream reflection.ServerReflection_ServerReflectionInfoClient
+	cancel context.CancelFunc
+	files  map[string]*descriptorpb.FileDescriptorProto
+}
+
+func newReflectionClient(ctx context.Context, conn grpc.ClientConnInterface) (*reflectionClient, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := reflection.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &reflectionClient{stream: stream, cancel: cancel, files: map[string]*descriptorpb.FileDescriptorProto{}}, nil
+}
+
+func (c *reflectionClient) close() {
+	c.stream.CloseSend()
+	c.cancel()
+}
+
+func (c *reflectionClient) send(req *reflection.ServerReflectionRequest) (*reflection.ServerReflectionResponse, error) {
+	if err := c.stream.Send(req); err != nil {
+		return nil, reflectionError(err)
+	}
+	res, err := c.stream.Recv()
+	if err != nil {
+		return nil, reflectionError(err)
+	}
+	if failure := res.GetErrorResponse(); failure != nil {
+		return nil, status.Error(codes.Code(failure.ErrorCode), failure.ErrorMessage)
+	}
+	return res, nil
+}
+
+func reflectionError(err error) error {
+	if status.Code(err) == codes.Unimplemented {
+		return errors.New("the server does not support reflection, pass its .proto files with --proto")
+	}
+	return err
+}
+
+func (c *reflectionClient) listServices() ([]string, error) {
+	res, err := c.send(&reflection.ServerReflectionRequest{
+		MessageRequest: &reflection.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var services []string
+	for _, service := range res.GetListServicesResponse().GetService() {
+		if !strings.HasPrefix(service.Name, "grpc.reflection.") {
+			services = append(services, service.Name)
+		}
+	}
+	sort.Strings(services)
+	return services, nil
+}
+
+func (c *reflectionClient) fileContainingSymbol(symbol string) error {
+	res, err := c.send(&reflection.ServerReflectionRequest{
+		MessageRequest: &reflection.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: symbol},
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return errors.New("service \"" + symbol + "\" not found")
+		}
+		return err
+	}
+	return c.addFiles(res.GetFileDescriptorResponse().GetFileDescriptorProto())
+}
+
+func (c *reflectionClient) fileByName(name string) error {
+	res, err := c.send(&reflection.ServerReflectionRequest{
+		MessageRequest: &reflection.ServerReflectionRequest_FileByFilename{FileByFilename: name},
+	})
+	if err != nil {
+		return err
+	}
+	return c.addFiles(res.GetFileDescriptorResponse().GetFileDescriptorProto())
+}
+
+func (c *reflectionClient) addFiles(raw [][]byte) error {
+	for _, data := range raw {
+		file := &descriptorpb.FileDescriptorProto{}
+		if err := proto.Unmarshal(data, file); err != nil {
+			return err
+		}
+		c.files[file.GetName()] = file
+	}
+	return nil
+}
+
+// registry fetches the dependencies the server did not send along and builds
+// the files. Well-known types the server can't provide are taken from the
+// ones compiled into httpzen.
+func (c *reflectionClient) registry() (*protoregistry.Files, error) {
+	for {
+		var missing []string
+		for _, file := range c.files {
+			for _, dependency := range file.GetDependency() {
+				if _, ok := c.files[dependency]; !ok {
+					missing = append(missing, dependency)
+				}
+			}
+		}
+		if len(missing) == 0 {
+			break
+		}
+
+		for _, dependency := range missing {
+			if _, ok := c.files[dependency]; ok {
+				continue
+			}
+			if err := c.fileByName(dependency); err != nil {
+				fd, globalErr := protoregistry.GlobalFiles.FindFileByPath(dependency)
+				if globalErr != nil {
+					return nil, err
+				}
+				c.files[dependency] = protodesc.ToFileDescriptorProto(fd)
+			}
+			if _, ok := c.files[dependency]; !ok {
+				return nil, errors.New("the server did not send \"" + dependency + "\"")
+			}
+		}
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, file := range c.files {
+		set.File = append(set.File, file)
+	}
+	return protodesc.NewFiles(set)
+}
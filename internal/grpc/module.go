@@ -0,0 +1,302 @@
+package grpc_module
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/diogopereiradev/httpzen/internal/utils/http_utility"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+type CallOptions struct {
+	// Target is the address to dial, see http_utility.ParseGrpcTarget.
+	Target string
+	// Method is "pkg.Service/Method", it is only needed to make a call.
+	Method string
+	// Data is the request message as JSON, an empty message when blank.
+	Data string
+	// Headers are sent as request metadata.
+	Headers     http.Header
+	ProtoFiles  []string
+	ImportPaths []string
+	Plaintext   bool
+	Insecure    bool
+	// Timeout bounds the reflection and unary calls, 0 means no limit.
+	Timeout time.Duration
+}
+
+var Call = call
+var ListServices = listServices
+var newClient = grpc.NewClient
+var now = time.Now
+
+func dial(options CallOptions) (*grpc.ClientConn, error) {
+	address, plaintext := http_utility.ParseGrpcTarget(options.Target, options.Plaintext)
+	if address == "" {
+		return nil, &request_module.RequestError{
+			Class: request_module.ErrorClassInvalidUrl,
+			Err:   errors.New("expected host:port, got \"" + options.Target + "\""),
+		}
+	}
+
+	creds := insecure.NewCredentials()
+	if !plaintext {
+		creds = credentials.NewTLS(&tls.Config{InsecureSkipVerify: options.Insecure})
+	}
+	return newClient(address, grpc.WithTransportCredentials(creds))
+}
+
+func (options CallOptions) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if options.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, options.Timeout)
+}
+
+// listServices describes every service of the server, or of the local .proto
+// files when there are any.
+func listServices(options CallOptions) ([]ServiceInfo, error) {
+	conn, err := dial(options)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	ctx, cancel := options.withTimeout(context.Background())
+	defer cancel()
+
+	files, services, err := loadDescriptors(ctx, conn, options, nil)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+
+	var result []ServiceInfo
+	for _, name := range services {
+		service, err := findService(files, name)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, describeService(service))
+	}
+	return result, nil
+}
+
+// call makes a unary or server-streaming call and reports every received
+// message as a stream event, the same way streamed HTTP responses are. A
+// failed call is not an error: its status shows up in the response like an
+// HTTP status code would, only transport failures are returned as errors.
+func call(ctx context.Context, options CallOptions, handlers request_module.StreamHandlers) (request_module.RequestResponse, error) {
+	failed := request_module.RequestResponse{Request: options.RequestOptions()}
+
+	serviceName, methodName, ok := ParseMethodName(options.Method)
+	if !ok {
+		return failed, errors.New("expected SERVICE/METHOD, got \"" + options.Method + "\"")
+	}
+
+	conn, err := dial(options)
+	if err != nil {
+		return failed, err
+	}
+	defer conn.Close()
+
+	resolveCtx, cancel := options.withTimeout(ctx)
+	files, _, err := loadDescriptors(resolveCtx, conn, options, []string{serviceName})
+	cancel()
+	if err != nil {
+		return failed, classifyError(err)
+	}
+	service, err := findService(files, serviceName)
+	if err != nil {
+		return failed, err
+	}
+	method := service.Methods().ByName(protoreflect.Name(methodName))
+	if method == nil {
+		return failed, errors.New("method \"" + methodName + "\" not found in " + serviceName)
+	}
+	if method.IsStreamingClient() {
+		return failed, errors.New("client and bidirectional streaming methods are not supported")
+	}
+
+	types := dynamicpb.NewTypes(files)
+	input := dynamicpb.NewMessage(method.Input())
+	data := options.Data
+	if strings.TrimSpace(data) == "" {
+		data = "{}"
+	}
+	if err := (protojson.UnmarshalOptions{Resolver: types}).Unmarshal([]byte(data), input); err != nil {
+		return failed, fmt.Errorf("invalid %s message: %w", method.Input().FullName(), err)
+	}
+
+	md := metadata.MD{}
+	for key, values := range options.Headers {
+		md.Append(strings.ToLower(key), values...)
+	}
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	response := failed
+	response.HttpVersion = "gRPC"
+	response.Method = string(service.FullName()) + "/" + methodName
+	response.Path = "/" + response.Method
+	response.Host, _ = http_utility.ParseGrpcTarget(options.Target, options.Plaintext)
+	response.Body = failed.Request.Body
+
+	var result strings.Builder
+	marshal := protojson.MarshalOptions{Resolver: types}
+	onMessage := func(message *dynamicpb.Message) {
+		encoded, err := marshal.Marshal(message)
+		if err != nil {
+			encoded = []byte(err.Error())
+		}
+		event := request_module.StreamEvent{Data: string(encoded), Time: now()}
+		result.WriteString(event.Data + "\n")
+		if handlers.OnEvent != nil {
+			handlers.OnEvent(event)
+		}
+	}
+
+	startTime := now()
+	var header, trailer metadata.MD
+	var callErr error
+
+	started := false
+	start := func() {
+		if started {
+			return
+		}
+		started = true
+		response.Headers = metadataToHeader(header)
+		response.ExecutionTime = http_utility.ParseExecutionTimeInMilliseconds(startTime)
+		if handlers.OnStart != nil {
+			handlers.OnStart(response)
+		}
+	}
+
+	if method.IsStreamingServer() {
+		var stream grpc.ClientStream
+		stream, callErr = conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, response.Path)
+		if callErr == nil {
+			callErr = stream.SendMsg(input)
+		}
+		if callErr == nil {
+			callErr = stream.CloseSend()
+		}
+		if callErr == nil {
+			header, callErr = stream.Header()
+		}
+		for callErr == nil {
+			start()
+			output := dynamicpb.NewMessage(method.Output())
+			if callErr = stream.RecvMsg(output); callErr == nil {
+				onMessage(output)
+			}
+		}
+		if errors.Is(callErr, io.EOF) {
+			callErr = nil
+		}
+		if stream != nil {
+			trailer = stream.Trailer()
+		}
+	} else {
+		callCtx, cancel := options.withTimeout(ctx)
+		output := dynamicpb.NewMessage(method.Output())
+		callErr = conn.Invoke(callCtx, response.Path, input, output, grpc.Header(&header), grpc.Trailer(&trailer))
+		cancel()
+		if callErr == nil {
+			start()
+			onMessage(output)
+		}
+	}
+
+	if callErr != nil && !started && isTransportError(callErr) {
+		return failed, classifyError(callErr)
+	}
+	start()
+
+	st := status.Convert(callErr)
+	response.StatusCode = int(st.Code())
+	response.StatusMessage = fmt.Sprintf("%d %s", st.Code(), st.Code().String())
+	response.Trailers = metadataToHeader(trailer)
+	response.ExecutionTime = http_utility.ParseExecutionTimeInMilliseconds(startTime)
+	if st.Code() != codes.OK {
+		encoded, _ := json.Marshal(map[string]string{"code": st.Code().String(), "message": st.Message()})
+		event := request_module.StreamEvent{Event: "error", Data: string(encoded), Time: now()}
+		result.WriteString(request_module.FormatStreamEvent(event))
+		if handlers.OnEvent != nil {
+			handlers.OnEvent(event)
+		}
+	}
+	response.Result = strings.TrimSuffix(result.String(), "\n")
+	return response, nil
+}
+
+// RequestOptions describes the call in the shape the response viewer shows
+// for HTTP requests.
+func (options CallOptions) RequestOptions() request_module.RequestOptions {
+	var body []http_utility.HttpContentData
+	if strings.TrimSpace(options.Data) != "" {
+		body = []http_utility.HttpContentData{{ContentType: "application/json", Value: options.Data}}
+	}
+	return request_module.RequestOptions{
+		Url:     options.Target + "/" + strings.TrimPrefix(options.Method, "/"),
+		Method:  "gRPC",
+		Headers: options.Headers,
+		Timeout: options.Timeout,
+		Body:    body,
+	}
+}
+
+func metadataToHeader(md metadata.MD) http.Header {
+	header := http.Header{}
+	for key, values := range md {
+		header[key] = values
+	}
+	return header
+}
+
+// isTransportError tells a call that never reached the server apart from one
+// the server answered with an error status.
+func isTransportError(err error) bool {
+	code := status.Code(err)
+	return code == codes.Unavailable || code == codes.DeadlineExceeded
+}
+
+// classifyError maps the status of a failed connection to the failure classes
+// used for HTTP requests. grpc only reports the cause as text.
+func classifyError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() == codes.Unknown {
+		return request_module.ClassifyError(err)
+	}
+
+	message := st.Message()
+	class := request_module.ErrorClassUnknown
+	switch {
+	case st.Code() == codes.DeadlineExceeded:
+		class = request_module.ErrorClassTimeout
+	case strings.Contains(message, "no such host"), strings.Contains(message, "produced zero addresses"):
+		class = request_module.ErrorClassDns
+	case strings.Contains(message, "connection refused"):
+		class = request_module.ErrorClassConnectionRefused
+	case strings.Contains(message, "tls:"), strings.Contains(message, "x509:"), strings.Contains(message, "authentication handshake failed"):
+		class = request_module.ErrorClassTls
+	case st.Code() == codes.Unavailable:
+		class = request_module.ErrorClassProtocol
+	}
+	return &request_module.RequestError{Class: class, Err: errors.New(message)}
+}
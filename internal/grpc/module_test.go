@@ -0,0 +1,325 @@
+package grpc_module
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	reflection_v1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const greeterProto = `syntax = "proto3";
+package test.v1;
+
+import "google/protobuf/timestamp.proto";
+
+message HelloRequest {
+  string name = 1;
+  int32 count = 2;
+}
+
+message HelloReply {
+  string message = 1;
+  string authorization = 2;
+  google.protobuf.Timestamp at = 3;
+}
+
+service Greeter {
+  rpc SayHello(HelloRequest) returns (HelloReply);
+  rpc StreamHello(HelloRequest) returns (stream HelloReply);
+  rpc Chat(stream HelloRequest) returns (stream HelloReply);
+}
+`
+
+func writeProto(t *testing.T) string {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "greeter.proto"), []byte(greeterProto), 0644))
+	return dir
+}
+
+// startGreeter serves the test service with dynamic messages, so no generated
+// code is needed, and registers reflection when asked to.
+func startGreeter(t *testing.T, withReflection bool) string {
+	files, err := compileProtoFiles(context.Background(), []string{"greeter.proto"}, []string{writeProto(t)})
+	require.NoError(t, err)
+	service, err := findService(files, "test.v1.Greeter")
+	require.NoError(t, err)
+
+	methods := service.Methods()
+	input := methods.ByName("SayHello").Input()
+	output := methods.ByName("SayHello").Output()
+
+	reply := func(message string, authorization string) *dynamicpb.Message {
+		res := dynamicpb.NewMessage(output)
+		res.Set(output.Fields().ByName("message"), protoreflect.ValueOfString(message))
+		res.Set(output.Fields().ByName("authorization"), protoreflect.ValueOfString(authorization))
+		return res
+	}
+
+	server := grpc.NewServer()
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "test.v1.Greeter",
+		HandlerType: (*any)(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "SayHello",
+			Handler: func(srv any, ctx context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+				req := dynamicpb.NewMessage(input)
+				if err := dec(req); err != nil {
+					return nil, err
+				}
+				name := req.Get(input.Fields().ByName("name")).String()
+				if name == "" {
+					return nil, status.Error(codes.InvalidArgument, "name is required")
+				}
+
+				md, _ := metadata.FromIncomingContext(ctx)
+				grpc.SetHeader(ctx, metadata.Pairs("x-served-by", "greeter"))
+				grpc.SetTrailer(ctx, metadata.Pairs("x-trailer", "done"))
+				return reply("hello "+name, strings.Join(md.Get("authorization"), ",")), nil
+			},
+		}},
+		Streams: []grpc.StreamDesc{
+			{
+				StreamName:    "StreamHello",
+				ServerStreams: true,
+				Handler: func(srv any, stream grpc.ServerStream) error {
+					req := dynamicpb.NewMessage(input)
+					if err := stream.RecvMsg(req); err != nil {
+						return err
+					}
+					count := int(req.Get(input.Fields().ByName("count")).Int())
+					for i := range count {
+						if err := stream.SendMsg(reply(strings.Repeat("!", i+1), "")); err != nil {
+							return err
+						}
+					}
+					return nil
+				},
+			},
+			{
+				StreamName:    "Chat",
+				ServerStreams: true,
+				ClientStreams: true,
+				Handler:       func(srv any, stream grpc.ServerStream) error { return nil },
+			},
+		},
+		Metadata: "greeter.proto",
+	}, struct{}{})
+
+	if withReflection {
+		reflection_v1.RegisterServerReflectionServer(server, reflection.NewServerV1(reflection.ServerOptions{
+			Services:           server,
+			DescriptorResolver: files,
+		}))
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	return listener.Addr().String()
+}
+
+func testOptions(target string) CallOptions {
+	return CallOptions{Target: target, Plaintext: true, Timeout: 5 * time.Second}
+}
+
+func TestParseMethodName(t *testing.T) {
+	cases := map[string][2]string{
+		"test.v1.Greeter/SayHello":  {"test.v1.Greeter", "SayHello"},
+		"/test.v1.Greeter/SayHello": {"test.v1.Greeter", "SayHello"},
+		"test.v1.Greeter.SayHello":  {"test.v1.Greeter", "SayHello"},
+	}
+	for input, want := range cases {
+		service, method, ok := ParseMethodName(input)
+		assert.True(t, ok, input)
+		assert.Equal(t, want, [2]string{service, method}, input)
+	}
+
+	for _, input := range []string{"", "Greeter", "Greeter/", "/SayHello"} {
+		_, _, ok := ParseMethodName(input)
+		assert.False(t, ok, input)
+	}
+}
+
+func TestListServices(t *testing.T) {
+	target := startGreeter(t, true)
+
+	check := func(services []ServiceInfo, err error) {
+		require.NoError(t, err)
+		require.Len(t, services, 1)
+		assert.Equal(t, "test.v1.Greeter", services[0].Name)
+		assert.Equal(t, []MethodInfo{
+			{Name: "SayHello", Input: "test.v1.HelloRequest", Output: "test.v1.HelloReply"},
+			{Name: "StreamHello", Input: "test.v1.HelloRequest", Output: "test.v1.HelloReply", ServerStreaming: true},
+			{Name: "Chat", Input: "test.v1.HelloRequest", Output: "test.v1.HelloReply", ClientStreaming: true, ServerStreaming: true},
+		}, services[0].Methods)
+	}
+
+	check(ListServices(testOptions(target)))
+
+	options := testOptions(target)
+	options.ProtoFiles = []string{"greeter.proto"}
+	options.ImportPaths = []string{writeProto(t)}
+	check(ListServices(options))
+}
+
+func TestCall_Unary(t *testing.T) {
+	target := startGreeter(t, true)
+
+	options := testOptions(target)
+	options.Method = "test.v1.Greeter/SayHello"
+	options.Data = `{"name": "zen"}`
+	options.Headers = map[string][]string{"Authorization": {"Bearer abc"}}
+
+	var started int
+	var events []request_module.StreamEvent
+	res, err := Call(context.Background(), options, request_module.StreamHandlers{
+		OnStart: func(request_module.RequestResponse) { started++ },
+		OnEvent: func(event request_module.StreamEvent) { events = append(events, event) },
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, started)
+	assert.Len(t, events, 1)
+	assert.Equal(t, 0, res.StatusCode)
+	assert.Equal(t, "0 OK", res.StatusMessage)
+	assert.Equal(t, "gRPC", res.HttpVersion)
+	assert.Equal(t, "test.v1.Greeter/SayHello", res.Method)
+	assert.Equal(t, []string{"greeter"}, res.Headers["x-served-by"])
+	assert.Equal(t, []string{"done"}, res.Trailers["x-trailer"])
+	assert.Equal(t, target+"/test.v1.Greeter/SayHello", res.Request.Url)
+
+	var message map[string]string
+	require.NoError(t, json.Unmarshal([]byte(res.Result), &message))
+	assert.Equal(t, "hello zen", message["message"])
+	assert.Equal(t, "Bearer abc", message["authorization"])
+}
+
+func TestCall_NoTimeout(t *testing.T) {
+	target := startGreeter(t, true)
+
+	options := testOptions(target)
+	options.Method = "test.v1.Greeter/SayHello"
+	options.Timeout = 0
+	_, err := Call(context.Background(), options, request_module.StreamHandlers{})
+	assert.NoError(t, err, "0 should mean no limit")
+}
+
+func TestCall_ServerStreaming(t *testing.T) {
+	target := startGreeter(t, false)
+
+	options := testOptions(target)
+	options.Method = "test.v1.Greeter.StreamHello"
+	options.Data = `{"count": 3}`
+	options.ProtoFiles = []string{"greeter.proto"}
+	options.ImportPaths = []string{writeProto(t)}
+
+	var events []string
+	res, err := Call(context.Background(), options, request_module.StreamHandlers{
+		OnEvent: func(event request_module.StreamEvent) { events = append(events, event.Data) },
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{`{"message":"!"}`, `{"message":"!!"}`, `{"message":"!!!"}`}, normalize(events))
+	assert.Len(t, strings.Split(res.Result, "\n"), 3)
+	assert.Equal(t, 0, res.StatusCode)
+}
+
+// normalize removes the whitespace protojson randomly adds to its output.
+func normalize(messages []string) []string {
+	result := make([]string, 0, len(messages))
+	for _, message := range messages {
+		result = append(result, strings.ReplaceAll(message, " ", ""))
+	}
+	return result
+}
+
+func TestCall_ErrorStatus(t *testing.T) {
+	target := startGreeter(t, true)
+
+	options := testOptions(target)
+	options.Method = "test.v1.Greeter/SayHello"
+
+	var started bool
+	res, err := Call(context.Background(), options, request_module.StreamHandlers{
+		OnStart: func(request_module.RequestResponse) { started = true },
+	})
+	require.NoError(t, err)
+	assert.True(t, started)
+	assert.Equal(t, int(codes.InvalidArgument), res.StatusCode)
+	assert.Equal(t, "3 InvalidArgument", res.StatusMessage)
+	assert.Contains(t, res.Result, "event: error")
+	assert.Contains(t, res.Result, "name is required")
+}
+
+func TestCall_Errors(t *testing.T) {
+	target := startGreeter(t, true)
+	withoutReflection := startGreeter(t, false)
+
+	cases := map[string]CallOptions{
+		"bad method":       {Target: target, Method: "Greeter"},
+		"unknown service":  {Target: target, Method: "test.v1.Nope/SayHello"},
+		"unknown method":   {Target: target, Method: "test.v1.Greeter/Nope"},
+		"client streaming": {Target: target, Method: "test.v1.Greeter/Chat"},
+		"invalid message":  {Target: target, Method: "test.v1.Greeter/SayHello", Data: `{"nope": 1}`},
+		"no reflection":    {Target: withoutReflection, Method: "test.v1.Greeter/SayHello"},
+	}
+	for name, options := range cases {
+		options.Plaintext = true
+		options.Timeout = 5 * time.Second
+		_, err := Call(context.Background(), options, request_module.StreamHandlers{})
+		assert.Error(t, err, name)
+	}
+
+	_, err := Call(context.Background(), CallOptions{Target: "", Method: "a.B/C"}, request_module.StreamHandlers{})
+	assert.Equal(t, request_module.ErrorClassInvalidUrl, request_module.ClassifyError(err).Class)
+}
+
+func TestCall_ConnectionRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	target := listener.Addr().String()
+	listener.Close()
+
+	options := testOptions(target)
+	options.Method = "test.v1.Greeter/SayHello"
+	_, err = Call(context.Background(), options, request_module.StreamHandlers{})
+	require.Error(t, err)
+	assert.Equal(t, request_module.ErrorClassConnectionRefused, request_module.ClassifyError(err).Class)
+	assert.Equal(t, 7, request_module.ExitCode(err))
+}
+
+func TestClassifyError(t *testing.T) {
+	cases := map[string]request_module.ErrorClass{
+		"dial tcp: lookup nope: no such host": request_module.ErrorClassDns,
+		"connection error: desc = \"transport: authentication handshake failed: tls: first record does not look like a TLS handshake\"": request_module.ErrorClassTls,
+		"error reading server preface: EOF": request_module.ErrorClassProtocol,
+	}
+	for message, class := range cases {
+		err := classifyError(status.Error(codes.Unavailable, message))
+		assert.Equal(t, class, request_module.ClassifyError(err).Class, message)
+	}
+
+	err := classifyError(status.Error(codes.DeadlineExceeded, "context deadline exceeded"))
+	assert.Equal(t, request_module.ErrorClassTimeout, request_module.ClassifyError(err).Class)
+
+	err = classifyError(errors.New("plain"))
+	assert.Equal(t, request_module.ErrorClassUnknown, request_module.ClassifyError(err).Class)
+}
@@ -0,0 +1,441 @@
+package har_module
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/diogopereiradev/httpzen/internal/utils/http_utility"
+)
+
+// Har is a HAR 1.2 document, the format browser devtools export network
+// traffic in. See http://www.softwareishard.com/blog/har-12-spec/.
+type Har struct {
+	Log Log `json:"log"`
+}
+
+type Log struct {
+	Version string  `json:"version"`
+	Creator Creator `json:"creator"`
+	Entries []Entry `json:"entries"`
+}
+
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type Entry struct {
+	StartedDateTime string   `json:"startedDateTime"`
+	Time            float64  `json:"time"`
+	Request         Request  `json:"request"`
+	Response        Response `json:"response"`
+	Cache           struct{} `json:"cache"`
+	Timings         Timings  `json:"timings"`
+	ServerIPAddress string   `json:"serverIPAddress,omitempty"`
+	Comment         string   `json:"comment,omitempty"`
+}
+
+type Request struct {
+	Method      string      `json:"method"`
+	Url         string      `json:"url"`
+	HttpVersion string      `json:"httpVersion"`
+	Cookies     []Cookie    `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	QueryString []NameValue `json:"queryString"`
+	PostData    *PostData   `json:"postData,omitempty"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+type Response struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HttpVersion string      `json:"httpVersion"`
+	Cookies     []Cookie    `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	Content     Content     `json:"content"`
+	RedirectUrl string      `json:"redirectURL"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type Cookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Path     string `json:"path,omitempty"`
+	Domain   string `json:"domain,omitempty"`
+	Expires  string `json:"expires,omitempty"`
+	HttpOnly bool   `json:"httpOnly,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+}
+
+type PostData struct {
+	MimeType string  `json:"mimeType"`
+	Text     string  `json:"text"`
+	Params   []Param `json:"params,omitempty"`
+	Comment  string  `json:"comment,omitempty"`
+}
+
+type Param struct {
+	Name        string `json:"name"`
+	Value       string `json:"value,omitempty"`
+	FileName    string `json:"fileName,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+}
+
+type Content struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// Timings are in milliseconds, -1 for the phases that didn't happen.
+// Connect includes Ssl.
+type Timings struct {
+	Blocked float64 `json:"blocked"`
+	Dns     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	Ssl     float64 `json:"ssl"`
+}
+
+// Version is written as the creator version, main sets it with the build
+// version.
+var Version = "unknown"
+
+var writeFile = os.WriteFile
+var readFile = os.ReadFile
+
+// Export builds a HAR document with one entry per response.
+func Export(responses []request_module.RequestResponse) Har {
+	entries := make([]Entry, 0, len(responses))
+	for _, res := range responses {
+		entries = append(entries, FromResponse(res))
+	}
+	return Har{Log: Log{
+		Version: "1.2",
+		Creator: Creator{Name: "httpzen", Version: Version},
+		Entries: entries,
+	}}
+}
+
+func Write(path string, har Har) error {
+	data, err := json.MarshalIndent(har, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(path, data, 0644)
+}
+
+func Read(path string) (Har, error) {
+	var har Har
+	data, err := readFile(path)
+	if err != nil {
+		return har, err
+	}
+	err = json.Unmarshal(data, &har)
+	return har, err
+}
+
+func FromResponse(res request_module.RequestResponse) Entry {
+	started := res.StartedAt
+	if started.IsZero() {
+		started = time.Now()
+	}
+
+	entry := Entry{
+		StartedDateTime: started.Format("2006-01-02T15:04:05.000Z07:00"),
+		Time:            res.ExecutionTime,
+		Request:         fromRequest(res),
+		Response:        fromResponse(res),
+		Timings: Timings{
+			Blocked: res.Timings.Blocked,
+			Dns:     res.Timings.Dns,
+			Connect: res.Timings.Connect,
+			Send:    res.Timings.Send,
+			Wait:    res.Timings.Wait,
+			Receive: res.Timings.Receive,
+			Ssl:     res.Timings.Tls,
+		},
+	}
+	if res.Timings == (request_module.Timings{}) {
+		// Without a trace, like a gRPC call, all the time is spent waiting.
+		entry.Timings = Timings{Blocked: -1, Dns: -1, Connect: -1, Ssl: -1, Wait: res.ExecutionTime}
+	}
+	if len(res.IpInfos) > 0 && res.IpInfos[0].Type != "Unix socket" {
+		entry.ServerIPAddress = res.IpInfos[0].Ip
+	}
+	return entry
+}
+
+func fromRequest(res request_module.RequestResponse) Request {
+	request := Request{
+		Method:      res.Request.Method,
+		Url:         res.Request.Url,
+		HttpVersion: res.HttpVersion,
+		Cookies:     []Cookie{},
+		Headers:     nameValues(res.Request.Headers),
+		QueryString: []NameValue{},
+		HeadersSize: -1,
+	}
+	if request.Method == "" {
+		request.Method = res.Method
+	}
+	if parsed, err := neturl.Parse(res.Request.Url); err == nil {
+		for _, pair := range strings.Split(parsed.RawQuery, "&") {
+			if pair == "" {
+				continue
+			}
+			name, value, _ := strings.Cut(pair, "=")
+			name, _ = neturl.QueryUnescape(name)
+			value, _ = neturl.QueryUnescape(value)
+			request.QueryString = append(request.QueryString, NameValue{Name: name, Value: value})
+		}
+	}
+
+	if postData := fromBody(res.Request.Body); postData != nil {
+		request.PostData = postData
+		request.BodySize = len(postData.Text)
+	}
+	return request
+}
+
+// fromBody describes the body as HAR post data. Files are not read, the
+// entry only names them.
+func fromBody(body []http_utility.HttpContentData) *PostData {
+	if len(body) == 0 {
+		return nil
+	}
+
+	switch {
+	case body[0].Key == http_utility.FileBodyKey:
+		return &PostData{
+			MimeType: body[0].ContentType,
+			Params:   []Param{{FileName: body[0].Value, ContentType: body[0].ContentType}},
+			Comment:  "The body was sent from the file " + body[0].Value,
+		}
+	case body[0].ContentType == "multipart/form-data":
+		postData := &PostData{MimeType: "multipart/form-data"}
+		for _, part := range body {
+			if _, err := http_utility.GetFileByPath(part.Value); err == nil {
+				postData.Params = append(postData.Params, Param{Name: part.Key, FileName: part.Value})
+			} else {
+				postData.Params = append(postData.Params, Param{Name: part.Key, Value: part.Value})
+			}
+		}
+		return postData
+	}
+
+	parsed := request_module.HandleBody(body)
+	postData := &PostData{MimeType: parsed.ContentTypeHeader}
+	if body[0].ContentType == "application/x-www-form-urlencoded" {
+		for _, part := range body {
+			postData.Params = append(postData.Params, Param{Name: part.Key, Value: part.Value})
+		}
+	}
+	switch result := parsed.Result.(type) {
+	case string:
+		postData.Text = result
+	case nil:
+	default:
+		data, _ := json.Marshal(result)
+		postData.Text = string(data)
+	}
+	return postData
+}
+
+func fromResponse(res request_module.RequestResponse) Response {
+	_, statusText, _ := strings.Cut(res.StatusMessage, " ")
+	if statusText == "" {
+		statusText = http.StatusText(res.StatusCode)
+	}
+
+	response := Response{
+		Status:      res.StatusCode,
+		StatusText:  statusText,
+		HttpVersion: res.HttpVersion,
+		Cookies:     []Cookie{},
+		Headers:     nameValues(res.Headers),
+		RedirectUrl: res.Headers.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    len(res.Result),
+		Content: Content{
+			Size:     len(res.Result),
+			MimeType: res.Headers.Get("Content-Type"),
+		},
+	}
+	for _, cookie := range res.Cookies {
+		c := Cookie{Name: cookie.Name, Value: cookie.Value, Path: cookie.Path, Domain: cookie.Domain, HttpOnly: cookie.HttpOnly, Secure: cookie.Secure}
+		if !cookie.Expires.IsZero() {
+			c.Expires = cookie.Expires.Format(time.RFC3339)
+		}
+		response.Cookies = append(response.Cookies, c)
+	}
+
+	switch {
+	case res.Download != nil:
+		response.Content.Size = int(res.Download.Size)
+		response.BodySize = int(res.Download.Size)
+		response.Content.Comment = "The body was saved to " + res.Download.Path
+	case utf8.ValidString(res.Result):
+		response.Content.Text = res.Result
+	default:
+		response.Content.Text = base64.StdEncoding.EncodeToString([]byte(res.Result))
+		response.Content.Encoding = "base64"
+	}
+	return response
+}
+
+// nameValues lists the headers sorted by name, HAR keeps one entry per
+// value.
+func nameValues(header http.Header) []NameValue {
+	values := []NameValue{}
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range header[name] {
+			values = append(values, NameValue{Name: name, Value: value})
+		}
+	}
+	return values
+}
+
+// skippedHeaders are not replayed. HTTP/2 pseudo headers and the length are
+// set by the transport, and sending Accept-Encoding ourselves would turn off
+// the transparent decompression.
+var skippedHeaders = []string{"Content-Length", "Host", "Accept-Encoding", "Connection"}
+
+// ToRequestOptions turns an entry back into a request that can be sent or
+// saved. File parts of a form can't be restored, they are sent as their
+// file name.
+func (e Entry) ToRequestOptions() request_module.RequestOptions {
+	options := request_module.RequestOptions{
+		Method:  strings.ToUpper(e.Request.Method),
+		Url:     e.Request.Url,
+		Headers: http.Header{},
+	}
+	for _, header := range e.Request.Headers {
+		if strings.HasPrefix(header.Name, ":") || containsFold(skippedHeaders, header.Name) {
+			continue
+		}
+		options.Headers.Add(header.Name, header.Value)
+	}
+
+	postData := e.Request.PostData
+	if postData == nil {
+		return options
+	}
+	mimeType := postData.MimeType
+	if mimeType == "" {
+		mimeType = options.Headers.Get("Content-Type")
+	}
+
+	switch {
+	case strings.HasPrefix(mimeType, "application/x-www-form-urlencoded"):
+		params := postData.Params
+		if len(params) == 0 {
+			values, _ := neturl.ParseQuery(postData.Text)
+			for name, list := range values {
+				for _, value := range list {
+					params = append(params, Param{Name: name, Value: value})
+				}
+			}
+			sort.Slice(params, func(i, j int) bool { return params[i].Name < params[j].Name })
+		}
+		for _, param := range params {
+			options.Body = append(options.Body, http_utility.HttpContentData{ContentType: "application/x-www-form-urlencoded", Key: param.Name, Value: param.Value})
+		}
+	case strings.HasPrefix(mimeType, "multipart/form-data") && len(postData.Params) > 0:
+		// The boundary in the recorded header doesn't match the new body.
+		options.Headers.Del("Content-Type")
+		for _, param := range postData.Params {
+			value := param.Value
+			if param.FileName != "" {
+				value = param.FileName
+			}
+			options.Body = append(options.Body, http_utility.HttpContentData{ContentType: "multipart/form-data", Key: param.Name, Value: value})
+		}
+	case postData.Text != "":
+		if mimeType == "" {
+			mimeType = "text/plain"
+		}
+		options.Body = []http_utility.HttpContentData{{ContentType: mimeType, Value: postData.Text}}
+	}
+	return options
+}
+
+// Host is the host of the request URL, used to filter the entries.
+func (e Entry) Host() string {
+	parsed, err := neturl.Parse(e.Request.Url)
+	if err != nil {
+		return ""
+	}
+	return parsed.Host
+}
+
+// Filter keeps the entries matching the host and the method, an empty
+// value matches any.
+func Filter(entries []Entry, host string, method string) []Entry {
+	var filtered []Entry
+	for _, entry := range entries {
+		if host != "" && !strings.EqualFold(entry.Host(), host) {
+			continue
+		}
+		if method != "" && !strings.EqualFold(entry.Request.Method, method) {
+			continue
+		}
+		filtered = append(filtered, entry)
+	}
+	return filtered
+}
+
+// Hosts lists the hosts of the entries, sorted, for the host filter.
+func Hosts(entries []Entry) []string {
+	return unique(entries, Entry.Host)
+}
+
+func Methods(entries []Entry) []string {
+	return unique(entries, func(e Entry) string { return strings.ToUpper(e.Request.Method) })
+}
+
+func unique(entries []Entry, value func(Entry) string) []string {
+	seen := map[string]bool{}
+	var values []string
+	for _, entry := range entries {
+		if v := value(entry); v != "" && !seen[v] {
+			seen[v] = true
+			values = append(values, v)
+		}
+	}
+	sort.Strings(values)
+	return values
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
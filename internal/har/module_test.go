@@ -0,0 +1,151 @@
+package har_module
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/diogopereiradev/httpzen/internal/utils/http_utility"
+	"github.com/diogopereiradev/httpzen/internal/utils/ip_utility"
+	"github.com/stretchr/testify/assert"
+)
+
+func testResponse() request_module.RequestResponse {
+	return request_module.RequestResponse{
+		HttpVersion:   "HTTP/1.1",
+		StatusMessage: "201 Created",
+		StatusCode:    201,
+		ExecutionTime: 42,
+		Headers:       http.Header{"Content-Type": {"application/json"}, "Location": {"/items/1"}, "Set-Cookie": {"a=1"}},
+		Cookies:       []*http.Cookie{{Name: "a", Value: "1", Path: "/"}},
+		Request: request_module.RequestOptions{
+			Method:  "POST",
+			Url:     "https://example.com/items?page=2&q=a%20b",
+			Headers: http.Header{"X-Token": {"abc"}, "Accept": {"*/*"}},
+			Body:    []http_utility.HttpContentData{{ContentType: "text/plain", Value: "hello"}},
+		},
+		IpInfos:   []ip_utility.LookupIpInfo{{Ip: "93.184.216.34", Type: "IPv4"}},
+		Result:    `{"id":1}`,
+		StartedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Timings:   request_module.Timings{Blocked: -1, Dns: 2, Connect: 10, Tls: 6, Send: 1, Wait: 25, Receive: 4},
+	}
+}
+
+func TestFromResponse(t *testing.T) {
+	entry := FromResponse(testResponse())
+
+	assert.Equal(t, "2025-01-02T03:04:05.000Z", entry.StartedDateTime)
+	assert.Equal(t, 42.0, entry.Time)
+	assert.Equal(t, "93.184.216.34", entry.ServerIPAddress)
+	assert.Equal(t, Timings{Blocked: -1, Dns: 2, Connect: 10, Ssl: 6, Send: 1, Wait: 25, Receive: 4}, entry.Timings)
+
+	assert.Equal(t, "POST", entry.Request.Method)
+	assert.Equal(t, []NameValue{{Name: "Accept", Value: "*/*"}, {Name: "X-Token", Value: "abc"}}, entry.Request.Headers)
+	assert.Equal(t, []NameValue{{Name: "page", Value: "2"}, {Name: "q", Value: "a b"}}, entry.Request.QueryString)
+	assert.Equal(t, "hello", entry.Request.PostData.Text)
+
+	assert.Equal(t, 201, entry.Response.Status)
+	assert.Equal(t, "Created", entry.Response.StatusText)
+	assert.Equal(t, "/items/1", entry.Response.RedirectUrl)
+	assert.Equal(t, `{"id":1}`, entry.Response.Content.Text)
+	assert.Equal(t, "application/json", entry.Response.Content.MimeType)
+	assert.Equal(t, []Cookie{{Name: "a", Value: "1", Path: "/"}}, entry.Response.Cookies)
+}
+
+func TestFromResponse_BinaryAndNoTimings(t *testing.T) {
+	res := testResponse()
+	res.Result = "\xff\xfe"
+	res.StatusMessage = ""
+	res.Timings = request_module.Timings{}
+	res.IpInfos = []ip_utility.LookupIpInfo{{Ip: "/tmp/app.sock", Type: "Unix socket"}}
+
+	entry := FromResponse(res)
+	assert.Equal(t, "base64", entry.Response.Content.Encoding)
+	assert.Equal(t, "//4=", entry.Response.Content.Text)
+	assert.Equal(t, "Created", entry.Response.StatusText)
+	assert.Equal(t, 42.0, entry.Timings.Wait)
+	assert.Equal(t, -1.0, entry.Timings.Dns)
+	assert.Empty(t, entry.ServerIPAddress)
+}
+
+func TestFromBody(t *testing.T) {
+	form := fromBody([]http_utility.HttpContentData{
+		{ContentType: "application/x-www-form-urlencoded", Key: "a", Value: "1"},
+		{ContentType: "application/x-www-form-urlencoded", Key: "b", Value: "2"},
+	})
+	assert.Equal(t, []Param{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}}, form.Params)
+
+	file := fromBody(http_utility.NewFileBody("data.bin", "application/octet-stream"))
+	assert.Equal(t, "data.bin", file.Params[0].FileName)
+	assert.Contains(t, file.Comment, "data.bin")
+
+	assert.Nil(t, fromBody(nil))
+}
+
+func TestWriteAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.har")
+	har := Export([]request_module.RequestResponse{testResponse()})
+	assert.Equal(t, "1.2", har.Log.Version)
+	assert.Equal(t, "httpzen", har.Log.Creator.Name)
+
+	assert.NoError(t, Write(path, har))
+	read, err := Read(path)
+	assert.NoError(t, err)
+	assert.Len(t, read.Log.Entries, 1)
+	assert.Equal(t, har.Log.Entries[0].Request.Url, read.Log.Entries[0].Request.Url)
+
+	_, err = Read(filepath.Join(t.TempDir(), "missing.har"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestToRequestOptions(t *testing.T) {
+	entry := Entry{Request: Request{
+		Method: "post",
+		Url:    "https://example.com/login",
+		Headers: []NameValue{
+			{Name: ":authority", Value: "example.com"},
+			{Name: "Content-Length", Value: "7"},
+			{Name: "Accept-Encoding", Value: "gzip"},
+			{Name: "Content-Type", Value: "application/x-www-form-urlencoded"},
+			{Name: "X-Token", Value: "abc"},
+		},
+		PostData: &PostData{MimeType: "application/x-www-form-urlencoded", Text: "b=2&a=1"},
+	}}
+
+	options := entry.ToRequestOptions()
+	assert.Equal(t, "POST", options.Method)
+	assert.Equal(t, "abc", options.Headers.Get("X-Token"))
+	assert.Empty(t, options.Headers.Get("Content-Length"))
+	assert.Empty(t, options.Headers.Get("Accept-Encoding"))
+	assert.Len(t, options.Headers, 2)
+	assert.Equal(t, []http_utility.HttpContentData{
+		{ContentType: "application/x-www-form-urlencoded", Key: "a", Value: "1"},
+		{ContentType: "application/x-www-form-urlencoded", Key: "b", Value: "2"},
+	}, options.Body)
+
+	entry.Request.PostData = &PostData{MimeType: "application/json", Text: `{"a":1}`}
+	options = entry.ToRequestOptions()
+	assert.Equal(t, []http_utility.HttpContentData{{ContentType: "application/json", Value: `{"a":1}`}}, options.Body)
+
+	entry.Request.PostData = &PostData{MimeType: "multipart/form-data; boundary=x", Params: []Param{{Name: "f", FileName: "a.txt"}}}
+	options = entry.ToRequestOptions()
+	assert.Empty(t, options.Headers.Get("Content-Type"))
+	assert.Equal(t, "a.txt", options.Body[0].Value)
+}
+
+func TestFilters(t *testing.T) {
+	entries := []Entry{
+		{Request: Request{Method: "GET", Url: "https://a.com/1"}},
+		{Request: Request{Method: "POST", Url: "https://b.com/2"}},
+		{Request: Request{Method: "get", Url: "https://b.com/3"}},
+	}
+
+	assert.Equal(t, []string{"a.com", "b.com"}, Hosts(entries))
+	assert.Equal(t, []string{"GET", "POST"}, Methods(entries))
+	assert.Len(t, Filter(entries, "b.com", ""), 2)
+	assert.Len(t, Filter(entries, "b.com", "GET"), 1)
+	assert.Len(t, Filter(entries, "", ""), 3)
+}
@@ -0,0 +1,95 @@
+package headless_module
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+
+	openapi_module "github.com/diogopereiradev/httpzen/internal/openapi"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	script_module "github.com/diogopereiradev/httpzen/internal/script"
+)
+
+// Output gets the response body, ErrorOutput the status line, the headers,
+// the failures and the validation, so the body can be piped on its own.
+var Output io.Writer = os.Stdout
+var ErrorOutput io.Writer = os.Stderr
+
+// Print writes the response, or the error, without the TUI and returns the
+// exit code of the run. validation is nil when the response wasn't checked.
+func Print(res request_module.RequestResponse, err error, validation *openapi_module.Validation) int {
+	if err != nil {
+		requestErr := request_module.ClassifyError(err)
+		fmt.Fprintln(ErrorOutput, requestErr.Error())
+		fmt.Fprintln(ErrorOutput, requestErr.Suggestion())
+		return request_module.ExitCode(err)
+	}
+
+	status := res.StatusMessage
+	if status == "" {
+		status = strconv.Itoa(res.StatusCode)
+	}
+	fmt.Fprintln(ErrorOutput, res.HttpVersion+" "+status)
+
+	names := make([]string, 0, len(res.Headers))
+	for name := range res.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range res.Headers[name] {
+			fmt.Fprintln(ErrorOutput, name+": "+value)
+		}
+	}
+	fmt.Fprintln(ErrorOutput)
+	if res.Result != "" {
+		fmt.Fprintln(Output, res.Result)
+	}
+
+	if validation == nil {
+		return 0
+	}
+	return PrintValidation(*validation)
+}
+
+// PrintValidation writes the problems found and returns the exit code.
+func PrintValidation(validation openapi_module.Validation) int {
+	if validation.Passed() {
+		fmt.Fprintln(ErrorOutput, "Validation passed against "+validation.Against)
+		return 0
+	}
+	fmt.Fprintf(ErrorOutput, "Validation failed against %s, %d problems:\n", validation.Against, len(validation.Problems))
+	for _, problem := range validation.Problems {
+		fmt.Fprintln(ErrorOutput, "  "+problem.String())
+	}
+	return openapi_module.ValidationExitCode
+}
+
+// PrintScript writes the console output and the tests of the scripts, and
+// returns the exit code.
+func PrintScript(result script_module.Result) int {
+	for _, entry := range result.Logs {
+		fmt.Fprintf(ErrorOutput, "[%s] %s: %s\n", entry.Phase, entry.Level, entry.Message)
+	}
+	failed := 0
+	for _, test := range result.Tests {
+		if test.Passed {
+			fmt.Fprintln(ErrorOutput, "✓ "+test.Name)
+			continue
+		}
+		failed++
+		fmt.Fprintln(ErrorOutput, "✗ "+test.Name+": "+test.Message)
+	}
+	for _, message := range result.Errors {
+		fmt.Fprintln(ErrorOutput, message)
+	}
+	if len(result.Tests) > 0 {
+		fmt.Fprintf(ErrorOutput, "Tests: %d passed, %d failed\n", len(result.Tests)-failed, failed)
+	}
+	if !result.Passed() {
+		return script_module.TestsFailedExitCode
+	}
+	return 0
+}
@@ -0,0 +1,84 @@
+package headless_module
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+
+	openapi_module "github.com/diogopereiradev/httpzen/internal/openapi"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	script_module "github.com/diogopereiradev/httpzen/internal/script"
+	"github.com/stretchr/testify/assert"
+)
+
+func capture(t *testing.T) (*bytes.Buffer, *bytes.Buffer) {
+	oldOutput, oldErrorOutput := Output, ErrorOutput
+	t.Cleanup(func() { Output, ErrorOutput = oldOutput, oldErrorOutput })
+
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	Output, ErrorOutput = out, errOut
+	return out, errOut
+}
+
+func TestPrint(t *testing.T) {
+	out, errOut := capture(t)
+
+	code := Print(request_module.RequestResponse{
+		HttpVersion:   "HTTP/1.1",
+		StatusMessage: "200 OK",
+		Headers:       http.Header{"X-B": {"2"}, "Content-Type": {"application/json"}},
+		Result:        `{"ok": true}`,
+	}, nil, nil)
+
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "{\"ok\": true}\n", out.String())
+	assert.Equal(t, "HTTP/1.1 200 OK\nContent-Type: application/json\nX-B: 2\n\n", errOut.String())
+}
+
+func TestPrint_Error(t *testing.T) {
+	out, errOut := capture(t)
+
+	err := &request_module.RequestError{Class: request_module.ErrorClassTimeout, Err: errors.New("deadline")}
+	code := Print(request_module.RequestResponse{}, err, nil)
+
+	assert.Equal(t, 28, code)
+	assert.Empty(t, out.String())
+	assert.Contains(t, errOut.String(), "Request timed out: deadline")
+}
+
+func TestPrint_Validation(t *testing.T) {
+	_, errOut := capture(t)
+
+	code := Print(request_module.RequestResponse{StatusCode: 204}, nil, &openapi_module.Validation{
+		Against:  "GET /users",
+		Problems: []openapi_module.Problem{{In: "body", Pointer: "/0/id", Message: "expected integer, got string"}},
+	})
+	assert.Equal(t, openapi_module.ValidationExitCode, code)
+	assert.Equal(t, " 204\n\nValidation failed against GET /users, 1 problems:\n  body /0/id: expected integer, got string\n", errOut.String())
+
+	errOut.Reset()
+	code = Print(request_module.RequestResponse{StatusCode: 204}, nil, &openapi_module.Validation{Against: "GET /users"})
+	assert.Equal(t, 0, code)
+	assert.Equal(t, " 204\n\nValidation passed against GET /users\n", errOut.String())
+}
+
+func TestPrintScript(t *testing.T) {
+	out, errOut := capture(t)
+
+	code := PrintScript(script_module.Result{
+		Logs:  []script_module.LogEntry{{Phase: script_module.PhasePre, Level: "log", Message: "signed"}},
+		Tests: []script_module.TestResult{{Name: "status", Passed: true}, {Name: "id", Message: "expected 2, got 1"}},
+	})
+	assert.Equal(t, script_module.TestsFailedExitCode, code)
+	assert.Empty(t, out.String())
+	assert.Equal(t, "[pre-request] log: signed\n✓ status\n✗ id: expected 2, got 1\nTests: 1 passed, 1 failed\n", errOut.String())
+
+	errOut.Reset()
+	assert.Equal(t, 0, PrintScript(script_module.Result{}))
+	assert.Empty(t, errOut.String())
+
+	code = PrintScript(script_module.Result{Errors: []string{"post-response script: boom"}})
+	assert.Equal(t, script_module.TestsFailedExitCode, code)
+	assert.Equal(t, "post-response script: boom\n", errOut.String())
+}
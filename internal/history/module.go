@@ -0,0 +1,112 @@
+package history_module
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	app_path_util "github.com/diogopereiradev/httpzen/internal/utils/app_path"
+)
+
+var HISTORY_FILE_NAME = "history.json"
+
+// MaxEntries is how many responses are kept, the oldest go first.
+var MaxEntries = 100
+
+// MaxBodySize caps the body kept for each response, so the history stays
+// quick to load.
+var MaxBodySize = 256 * 1024
+
+var mkdirAll = os.MkdirAll
+var readFile = os.ReadFile
+var writeFile = os.WriteFile
+
+func GetHistoryFilePath() string {
+	return app_path_util.GetConfigPath() + "/" + HISTORY_FILE_NAME
+}
+
+// List returns the saved responses, oldest first.
+func List() ([]request_module.RequestResponse, error) {
+	var entries []request_module.RequestResponse
+
+	data, err := readFile(GetHistoryFilePath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return entries, nil
+		}
+		return nil, err
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return entries, nil
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Send runs the request and adds a successful response to the history. A
+// history that can't be written is only reported to warn, it shouldn't hide
+// the response.
+func Send(options request_module.RequestOptions, run func(request_module.RequestOptions) (request_module.RequestResponse, error), add func(request_module.RequestResponse) error, warn func(string, int)) (request_module.RequestResponse, error) {
+	res, err := run(options)
+	if err == nil {
+		if historyErr := add(res); historyErr != nil {
+			warn("Could not save the request to the history: "+historyErr.Error(), 70)
+		}
+	}
+	return res, err
+}
+
+// Add saves a response to the history.
+func Add(res request_module.RequestResponse) error {
+	entries, err := List()
+	if err != nil {
+		// A broken history file shouldn't block new requests, start over.
+		entries = nil
+	}
+
+	if len(res.Result) > MaxBodySize {
+		res.Result = res.Result[:MaxBodySize]
+	}
+	// Passwords, tokens and client secrets never reach the file, only the
+	// auth type is kept.
+	res.Request.Auth = res.Request.Auth.Redacted()
+	entries = append(entries, res)
+	if len(entries) > MaxEntries {
+		entries = entries[len(entries)-MaxEntries:]
+	}
+
+	if err := mkdirAll(app_path_util.GetConfigPath(), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	// Requests can still carry secrets in their headers or URL, keep the
+	// file private.
+	return writeFile(GetHistoryFilePath(), data, 0600)
+}
+
+// Last returns the count most recent responses, or all of them when count is
+// zero or more than there are.
+func Last(count int) ([]request_module.RequestResponse, error) {
+	entries, err := List()
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 && count < len(entries) {
+		entries = entries[len(entries)-count:]
+	}
+	return entries, nil
+}
+
+func Clear() error {
+	if err := os.Remove(GetHistoryFilePath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
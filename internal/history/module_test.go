@@ -0,0 +1,137 @@
+package history_module
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	auth_module "github.com/diogopereiradev/httpzen/internal/auth"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupTestHistory() func() {
+	HISTORY_FILE_NAME = "history_test.json"
+	_ = os.Remove(GetHistoryFilePath())
+	return func() {
+		_ = os.Remove(GetHistoryFilePath())
+		HISTORY_FILE_NAME = "history.json"
+	}
+}
+
+func TestList_Empty(t *testing.T) {
+	teardown := setupTestHistory()
+	defer teardown()
+
+	entries, err := List()
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestAdd_KeepsTheLatestEntries(t *testing.T) {
+	teardown := setupTestHistory()
+	defer teardown()
+	oldMax := MaxEntries
+	MaxEntries = 3
+	defer func() { MaxEntries = oldMax }()
+
+	for i := range 5 {
+		assert.NoError(t, Add(request_module.RequestResponse{StatusCode: 200 + i}))
+	}
+
+	entries, err := List()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+	assert.Equal(t, 202, entries[0].StatusCode)
+	assert.Equal(t, 204, entries[2].StatusCode)
+
+	last, _ := Last(2)
+	assert.Equal(t, []int{203, 204}, []int{last[0].StatusCode, last[1].StatusCode})
+	all, _ := Last(0)
+	assert.Len(t, all, 3)
+
+	assert.NoError(t, Clear())
+	entries, _ = List()
+	assert.Empty(t, entries)
+	assert.NoError(t, Clear(), "clearing an empty history should not fail")
+}
+
+func TestAdd_RedactsTheAuth(t *testing.T) {
+	teardown := setupTestHistory()
+	defer teardown()
+
+	for _, auth := range []auth_module.AuthOptions{
+		{Type: auth_module.AuthBasic, Username: "user", Password: "basic-secret"},
+		{Type: auth_module.AuthBearer, Token: "bearer-secret"},
+		{Type: auth_module.AuthOAuth2, TokenUrl: "http://a/token", ClientId: "id", ClientSecret: "client-secret"},
+	} {
+		assert.NoError(t, Add(request_module.RequestResponse{Request: request_module.RequestOptions{Url: "http://a", Auth: auth}}))
+	}
+
+	data, err := os.ReadFile(GetHistoryFilePath())
+	assert.NoError(t, err)
+	for _, secret := range []string{"basic-secret", "bearer-secret", "client-secret"} {
+		assert.NotContains(t, string(data), secret)
+	}
+	entries, _ := List()
+	assert.Equal(t, auth_module.AuthBearer, entries[1].Request.Auth.Type)
+}
+
+func TestAdd_TruncatesTheBody(t *testing.T) {
+	teardown := setupTestHistory()
+	defer teardown()
+	oldMax := MaxBodySize
+	MaxBodySize = 10
+	defer func() { MaxBodySize = oldMax }()
+
+	assert.NoError(t, Add(request_module.RequestResponse{Result: strings.Repeat("a", 50)}))
+	entries, _ := List()
+	assert.Equal(t, 10, len(entries[0].Result))
+}
+
+func TestAdd_RecoversFromABrokenFile(t *testing.T) {
+	teardown := setupTestHistory()
+	defer teardown()
+
+	os.WriteFile(GetHistoryFilePath(), []byte("{broken"), 0600)
+	_, err := List()
+	assert.Error(t, err)
+
+	assert.NoError(t, Add(request_module.RequestResponse{StatusMessage: "200 OK"}))
+	entries, err := List()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestSend(t *testing.T) {
+	var added []request_module.RequestResponse
+	var warnings []string
+	add := func(res request_module.RequestResponse) error {
+		added = append(added, res)
+		return nil
+	}
+	warn := func(message string, _ int) { warnings = append(warnings, message) }
+	run := func(options request_module.RequestOptions) (request_module.RequestResponse, error) {
+		if options.Url == "http://down" {
+			return request_module.RequestResponse{Request: options}, errors.New("connection refused")
+		}
+		return request_module.RequestResponse{StatusCode: 200, Request: options}, nil
+	}
+
+	res, err := Send(request_module.RequestOptions{Url: "http://up"}, run, add, warn)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Len(t, added, 1)
+
+	_, err = Send(request_module.RequestOptions{Url: "http://down"}, run, add, warn)
+	assert.Error(t, err)
+	assert.Len(t, added, 1, "a failed request has no response to record")
+
+	res, err = Send(request_module.RequestOptions{Url: "http://up"}, run, func(request_module.RequestResponse) error {
+		return errors.New("read-only")
+	}, warn)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, []string{"Could not save the request to the history: read-only"}, warnings)
+}
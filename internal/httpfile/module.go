@@ -0,0 +1,276 @@
+package httpfile_module
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	collection_module "github.com/diogopereiradev/httpzen/internal/collection"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/diogopereiradev/httpzen/internal/utils/http_utility"
+)
+
+// File is a .http or .rest file of the VS Code REST Client or the JetBrains
+// HTTP Client: requests separated by ### lines, with @name = value variables.
+type File struct {
+	Path      string
+	Variables map[string]string
+	Requests  []Request
+	// Skipped lists what httpzen doesn't run, like response handler scripts.
+	Skipped []string
+}
+
+type Request struct {
+	Name    string
+	Line    int
+	Method  string
+	Url     string
+	Headers []Header
+	Body    string
+	// BodyFile is set by a "< ./file" body, relative to the .http file.
+	// "<@ ./file" sets ExpandBodyFile too, the variables of the file are
+	// replaced then.
+	BodyFile       string
+	ExpandBodyFile bool
+}
+
+// Header keeps the name as written, it may hold {{variables}}.
+type Header struct {
+	Name  string
+	Value string
+}
+
+var readFile = os.ReadFile
+
+var variablePattern = regexp.MustCompile(`^@([\w.-]+)\s*=\s*(.*)$`)
+var namePattern = regexp.MustCompile(`^(#|//)\s*@name\s+(.+)$`)
+
+func Load(path string) (File, error) {
+	data, err := readFile(path)
+	if err != nil {
+		return File{}, err
+	}
+	return Parse(data, path)
+}
+
+// Parse reads the requests of a file, path is used for the bodies read from
+// other files.
+func Parse(data []byte, path string) (File, error) {
+	file := File{Path: path, Variables: map[string]string{}}
+
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	start, name := 0, ""
+	for i := 0; i <= len(lines); i++ {
+		if i < len(lines) && !strings.HasPrefix(lines[i], "###") {
+			continue
+		}
+		if request, ok := file.parseBlock(lines[start:i], start+1, name); ok {
+			file.Requests = append(file.Requests, request)
+		}
+		if i < len(lines) {
+			start, name = i+1, strings.TrimSpace(strings.TrimLeft(lines[i], "#"))
+		}
+	}
+
+	if len(file.Requests) == 0 {
+		return File{}, errors.New("the file has no requests")
+	}
+	return file, nil
+}
+
+// parseBlock reads the request between two ### lines. first is the line
+// number of the block, for the names of unnamed requests.
+func (f *File) parseBlock(lines []string, first int, name string) (Request, bool) {
+	request := Request{Name: name}
+
+	i := 0
+	for ; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if match := variablePattern.FindStringSubmatch(line); match != nil {
+			f.Variables[match[1]] = strings.TrimSpace(match[2])
+			continue
+		}
+		if match := namePattern.FindStringSubmatch(line); match != nil {
+			request.Name = strings.TrimSpace(match[2])
+			continue
+		}
+		if line == "" || isComment(line) {
+			continue
+		}
+		break
+	}
+	if i == len(lines) {
+		return Request{}, false
+	}
+
+	request.Line = first + i
+	request.Method, request.Url = requestLine(strings.TrimSpace(lines[i]))
+	i++
+	// Query parameters can continue the URL on the next lines.
+	for ; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(line, "?") && !strings.HasPrefix(line, "&") {
+			break
+		}
+		request.Url += line
+	}
+
+	for ; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			i++
+			break
+		}
+		if isComment(line) {
+			continue
+		}
+		headerName, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		request.Headers = append(request.Headers, Header{Name: strings.TrimSpace(headerName), Value: strings.TrimSpace(value)})
+	}
+
+	var body []string
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		// "> {% script %}" and "> ./handler.js" handle the response, ">> file"
+		// and "<> file" save or compare it.
+		if strings.HasPrefix(trimmed, ">") || strings.HasPrefix(trimmed, "<>") {
+			f.Skipped = append(f.Skipped, request.Title()+": the response handler on line "+strconv.Itoa(first+i))
+			if strings.HasPrefix(trimmed, "> {%") && !strings.Contains(trimmed, "%}") {
+				for i+1 < len(lines) && !strings.Contains(lines[i], "%}") {
+					i++
+				}
+			}
+			continue
+		}
+		body = append(body, lines[i])
+	}
+	text := strings.TrimSpace(strings.Join(body, "\n"))
+
+	if path, ok := strings.CutPrefix(text, "<@"); ok && !strings.Contains(path, "\n") {
+		request.BodyFile, request.ExpandBodyFile = strings.TrimSpace(path), true
+	} else if path, ok := strings.CutPrefix(text, "<"); ok && !strings.Contains(path, "\n") && strings.HasPrefix(path, " ") {
+		request.BodyFile = strings.TrimSpace(path)
+	} else {
+		request.Body = text
+	}
+	return request, true
+}
+
+func isComment(line string) bool {
+	return strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//")
+}
+
+// requestLine reads "METHOD URL HTTP/1.1", the method and the version are
+// optional.
+func requestLine(line string) (string, string) {
+	method := "GET"
+	if first, rest, ok := strings.Cut(line, " "); ok {
+		if parsed := http_utility.ParseHttpMethod(first); parsed != "" {
+			method, line = parsed, strings.TrimSpace(rest)
+		} else if parsed := http_utility.ParseCustomHttpMethod(first); parsed != "" {
+			method, line = parsed, strings.TrimSpace(rest)
+		}
+	}
+	if index := strings.LastIndex(line, " HTTP/"); index > 0 {
+		line = strings.TrimSpace(line[:index])
+	}
+	return method, line
+}
+
+// Title is the name of the request, or its method and URL.
+func (r Request) Title() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return r.Method + " " + r.Url
+}
+
+// ResolveVariables expands the file variables, which may use each other and
+// the environment. The file variables win over the environment ones.
+func (f File) ResolveVariables(environment map[string]string) map[string]string {
+	variables := map[string]string{}
+	for name, value := range f.Variables {
+		variables[name] = value
+	}
+	// A few passes resolve chains like @url = {{host}}/api.
+	for range 5 {
+		changed := false
+		for name, value := range variables {
+			if expanded := collection_module.Expand(value, environment, variables); expanded != value {
+				variables[name], changed = expanded, true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+	return variables
+}
+
+// RequestOptions builds a request with the variables expanded. Bodies from
+// other files are streamed from disk, unless their variables are expanded.
+func (f File) RequestOptions(request Request, environment map[string]string) (request_module.RequestOptions, error) {
+	variables := f.ResolveVariables(environment)
+	expand := func(value string) string {
+		return collection_module.Expand(value, environment, variables)
+	}
+
+	// A custom method written in the file is taken as asked for, the
+	// parser only reads uppercase tokens.
+	options := request_module.RequestOptions{
+		Method:       request.Method,
+		CustomMethod: http_utility.ParseHttpMethod(request.Method) == "",
+		Url:          expand(request.Url),
+		Headers:      http.Header{},
+	}
+	for _, header := range request.Headers {
+		options.Headers.Add(expand(header.Name), expand(header.Value))
+	}
+	contentType := options.Headers.Get("Content-Type")
+
+	body := expand(request.Body)
+	if request.BodyFile != "" {
+		path := expand(request.BodyFile)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(f.Path), path)
+		}
+		if !request.ExpandBodyFile {
+			options.Body = http_utility.NewFileBody(path, contentType)
+			return options, nil
+		}
+		content, err := readFile(path)
+		if err != nil {
+			return request_module.RequestOptions{}, errors.New("could not read the body of " + request.Title() + ": " + err.Error())
+		}
+		body = expand(string(content))
+	}
+	if body == "" {
+		return options, nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case contentType == "":
+		options.Body = []http_utility.HttpContentData{{ContentType: "text/plain", Value: body}}
+	case mediaType == "application/x-www-form-urlencoded":
+		// The body is already encoded, the fields are kept as written.
+		for _, field := range strings.Split(strings.ReplaceAll(body, "\n", ""), "&") {
+			if field == "" {
+				continue
+			}
+			key, value, _ := strings.Cut(field, "=")
+			options.Body = append(options.Body, http_utility.HttpContentData{ContentType: mediaType, Key: key, Value: value})
+		}
+	default:
+		options.Body = []http_utility.HttpContentData{{ContentType: contentType, Value: body}}
+	}
+	return options, nil
+}
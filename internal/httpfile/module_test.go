@@ -0,0 +1,143 @@
+package httpfile_module
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/diogopereiradev/httpzen/internal/utils/http_utility"
+	"github.com/stretchr/testify/assert"
+)
+
+const testFile = `@host = api.example.com
+@baseUrl = https://{{host}}/v1
+# A comment before the first request
+
+### List users
+GET {{baseUrl}}/users
+    ?page=1
+    &limit={{limit}}
+Accept: application/json
+// X-Debug: 1
+
+###
+# @name createUser
+POST {{baseUrl}}/users HTTP/1.1
+Content-Type: application/json
+
+{
+  "name": "{{name}}"
+}
+
+> {%
+  client.test("created", function() {});
+%}
+
+### Login
+POST {{baseUrl}}/login
+Content-Type: application/x-www-form-urlencoded
+
+user=ada
+&password=s%26cret
+
+### Upload
+PUT {{baseUrl}}/avatar
+Content-Type: image/png
+
+< ./avatar.png
+
+### Template
+POST {{baseUrl}}/template
+
+<@ ./template.json
+
+###
+https://example.com/health
+`
+
+func TestParse(t *testing.T) {
+	file, err := Parse([]byte(testFile), "api/requests.http")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"host": "api.example.com", "baseUrl": "https://{{host}}/v1"}, file.Variables)
+	assert.Len(t, file.Requests, 6)
+
+	list := file.Requests[0]
+	assert.Equal(t, "List users", list.Name)
+	assert.Equal(t, 6, list.Line)
+	assert.Equal(t, "GET", list.Method)
+	assert.Equal(t, "{{baseUrl}}/users?page=1&limit={{limit}}", list.Url)
+	assert.Equal(t, []Header{{Name: "Accept", Value: "application/json"}}, list.Headers)
+	assert.Empty(t, list.Body)
+
+	create := file.Requests[1]
+	assert.Equal(t, "createUser", create.Name)
+	assert.Equal(t, "{{baseUrl}}/users", create.Url)
+	assert.Equal(t, "{\n  \"name\": \"{{name}}\"\n}", create.Body)
+	assert.Equal(t, []string{"createUser: the response handler on line 21"}, file.Skipped)
+
+	assert.Equal(t, "./avatar.png", file.Requests[3].BodyFile)
+	assert.False(t, file.Requests[3].ExpandBodyFile)
+	assert.Equal(t, "./template.json", file.Requests[4].BodyFile)
+	assert.True(t, file.Requests[4].ExpandBodyFile)
+
+	health := file.Requests[5]
+	assert.Equal(t, "GET https://example.com/health", health.Title())
+}
+
+func TestParse_Empty(t *testing.T) {
+	_, err := Parse([]byte("@host = a\n# only comments\n###\n"), "empty.http")
+	assert.Error(t, err)
+}
+
+func TestRequestOptions(t *testing.T) {
+	file, _ := Parse([]byte(testFile), "api/requests.http")
+	environment := map[string]string{"host": "staging", "limit": "10", "name": "Ada"}
+
+	options, err := file.RequestOptions(file.Requests[0], environment)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://api.example.com/v1/users?page=1&limit=10", options.Url, "the file variables should win")
+	assert.Equal(t, "application/json", options.Headers.Get("Accept"))
+
+	options, _ = file.RequestOptions(file.Requests[1], environment)
+	assert.Equal(t, []http_utility.HttpContentData{{ContentType: "application/json", Value: "{\n  \"name\": \"Ada\"\n}"}}, options.Body)
+
+	options, _ = file.RequestOptions(file.Requests[2], environment)
+	assert.Equal(t, []http_utility.HttpContentData{
+		{ContentType: "application/x-www-form-urlencoded", Key: "user", Value: "ada"},
+		{ContentType: "application/x-www-form-urlencoded", Key: "password", Value: "s%26cret"},
+	}, options.Body)
+
+	options, _ = file.RequestOptions(file.Requests[3], environment)
+	assert.Equal(t, http_utility.NewFileBody(filepath.Join("api", "avatar.png"), "image/png"), options.Body)
+}
+
+func TestRequestOptions_CustomMethod(t *testing.T) {
+	file, _ := Parse([]byte("PURGE https://cdn.example.com/a\n###\nGET https://cdn.example.com/b\n"), "cdn.http")
+
+	options, _ := file.RequestOptions(file.Requests[0], nil)
+	assert.Equal(t, "PURGE", options.Method)
+	assert.True(t, options.CustomMethod)
+
+	options, _ = file.RequestOptions(file.Requests[1], nil)
+	assert.False(t, options.CustomMethod)
+}
+
+func TestRequestOptions_ExpandedBodyFile(t *testing.T) {
+	oldRead := readFile
+	defer func() { readFile = oldRead }()
+	readFile = func(path string) ([]byte, error) {
+		if path == filepath.Join("api", "template.json") {
+			return []byte(`{"host": "{{host}}"}`), nil
+		}
+		return nil, os.ErrNotExist
+	}
+
+	file, _ := Parse([]byte(testFile), "api/requests.http")
+	options, err := file.RequestOptions(file.Requests[4], nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"host": "api.example.com"}`, options.Body[0].Value)
+
+	file.Path = "elsewhere/requests.http"
+	_, err = file.RequestOptions(file.Requests[4], nil)
+	assert.ErrorContains(t, err, "could not read the body of Template")
+}
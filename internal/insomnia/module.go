@@ -0,0 +1,346 @@
+package insomnia_module
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"regexp"
+	"strings"
+
+	auth_module "github.com/diogopereiradev/httpzen/internal/auth"
+	collection_module "github.com/diogopereiradev/httpzen/internal/collection"
+	environment_module "github.com/diogopereiradev/httpzen/internal/environment"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/diogopereiradev/httpzen/internal/utils/http_utility"
+)
+
+// export is an Insomnia v4 JSON export, a flat list of resources pointing to
+// their parent through parentId.
+type export struct {
+	Type      string     `json:"_type"`
+	Format    int        `json:"__export_format"`
+	Resources []resource `json:"resources"`
+}
+
+type resource struct {
+	Id       string `json:"_id"`
+	Type     string `json:"_type"`
+	ParentId string `json:"parentId"`
+	Name     string `json:"name"`
+
+	Method         string          `json:"method"`
+	Url            string          `json:"url"`
+	Body           body            `json:"body"`
+	Headers        []param         `json:"headers"`
+	Parameters     []param         `json:"parameters"`
+	Authentication authentication  `json:"authentication"`
+	Data           json.RawMessage `json:"data"`
+
+	PreRequestScript    string `json:"preRequestScript"`
+	AfterResponseScript string `json:"afterResponseScript"`
+}
+
+type body struct {
+	MimeType string  `json:"mimeType"`
+	Text     string  `json:"text"`
+	Params   []param `json:"params"`
+	FileName string  `json:"fileName"`
+}
+
+type param struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Type     string `json:"type"`
+	FileName string `json:"fileName"`
+	Disabled bool   `json:"disabled"`
+}
+
+type authentication struct {
+	Type           string `json:"type"`
+	Disabled       bool   `json:"disabled"`
+	Username       string `json:"username"`
+	Password       string `json:"password"`
+	Token          string `json:"token"`
+	GrantType      string `json:"grantType"`
+	AccessTokenUrl string `json:"accessTokenUrl"`
+	ClientId       string `json:"clientId"`
+	ClientSecret   string `json:"clientSecret"`
+	Scope          string `json:"scope"`
+	Key            string `json:"key"`
+	Value          string `json:"value"`
+	AddTo          string `json:"addTo"`
+}
+
+// templatePattern matches Insomnia variables, {{ _.name }} or {{ name }}.
+var templatePattern = regexp.MustCompile(`\{\{\s*(?:_\.)?([^{}\s]+)\s*\}\}`)
+
+type importer struct {
+	resources map[string]resource
+	result    collection_module.ImportResult
+}
+
+// Parse reads an Insomnia v4 JSON export. The base environment becomes the
+// collection variables and its sub environments httpzen environments.
+// Template tags, scripts and gRPC or WebSocket requests are listed in
+// Skipped.
+func Parse(data []byte) (collection_module.ImportResult, error) {
+	var e export
+	if err := json.Unmarshal(data, &e); err != nil {
+		return collection_module.ImportResult{}, errors.New("not an Insomnia export, only the JSON format is supported: " + err.Error())
+	}
+	if e.Type != "export" || e.Format != 4 {
+		return collection_module.ImportResult{}, errors.New("not an Insomnia v4 export")
+	}
+
+	im := &importer{
+		resources: map[string]resource{},
+		result: collection_module.ImportResult{
+			Collection: collection_module.Collection{Variables: map[string]string{}},
+		},
+	}
+	for _, r := range e.Resources {
+		im.resources[r.Id] = r
+	}
+
+	for _, r := range e.Resources {
+		switch r.Type {
+		case "workspace":
+			if im.result.Collection.Name == "" {
+				im.result.Collection.Name = r.Name
+			}
+		case "environment":
+			im.environment(r)
+		case "request_group":
+			for name, value := range im.variables(r.Data) {
+				im.result.Collection.Variables[name] = value
+			}
+		case "request":
+			im.request(r)
+		case "grpc_request", "websocket_request":
+			im.skip(im.path(r) + ": " + strings.TrimSuffix(r.Type, "_request") + " requests are not supported")
+		}
+	}
+	if im.result.Collection.Name == "" {
+		im.result.Collection.Name = "insomnia"
+	}
+	return im.result, nil
+}
+
+// environment sorts the environments out, the base one is the child of the
+// workspace.
+func (im *importer) environment(r resource) {
+	variables := im.variables(r.Data)
+	if parent, ok := im.resources[r.ParentId]; ok && parent.Type == "environment" {
+		im.result.Environments = append(im.result.Environments, environment_module.Environment{Name: r.Name, Variables: variables})
+		return
+	}
+	for name, value := range variables {
+		im.result.Collection.Variables[name] = value
+	}
+}
+
+// variables flattens the environment data, nested objects are read with
+// dots like {{ _.api.url }}.
+func (im *importer) variables(data json.RawMessage) map[string]string {
+	variables := map[string]string{}
+	var values map[string]any
+	if len(data) == 0 || json.Unmarshal(data, &values) != nil {
+		return variables
+	}
+
+	var flatten func(prefix string, value any)
+	flatten = func(prefix string, value any) {
+		switch value := value.(type) {
+		case map[string]any:
+			for key, nested := range value {
+				flatten(prefix+"."+key, nested)
+			}
+		case string:
+			variables[prefix] = im.template(prefix, value)
+		case nil:
+		default:
+			encoded, _ := json.Marshal(value)
+			variables[prefix] = string(encoded)
+		}
+	}
+	for key, value := range values {
+		flatten(key, value)
+	}
+	return variables
+}
+
+func (im *importer) request(r resource) {
+	path := im.path(r)
+	options := request_module.RequestOptions{
+		Method:  strings.ToUpper(r.Method),
+		Url:     im.template(path, r.Url),
+		Headers: http.Header{},
+	}
+	if options.Method == "" {
+		options.Method = "GET"
+	}
+
+	query := neturl.Values{}
+	for _, p := range r.Parameters {
+		if !p.Disabled && p.Name != "" {
+			query.Add(im.template(path, p.Name), im.template(path, p.Value))
+		}
+	}
+	options.Url = http_utility.AddQuery(options.Url, query)
+
+	for _, header := range r.Headers {
+		if !header.Disabled && header.Name != "" {
+			options.Headers.Add(im.template(path, header.Name), im.template(path, header.Value))
+		}
+	}
+	options.Body = im.body(path, r.Body)
+	im.auth(path, im.inheritedAuth(r), &options)
+
+	if strings.TrimSpace(r.PreRequestScript) != "" {
+		im.skip(path + ": the pre-request script")
+	}
+	if strings.TrimSpace(r.AfterResponseScript) != "" {
+		im.skip(path + ": the after-response script")
+	}
+
+	folder, _ := strings.CutSuffix(path, r.Name)
+	im.result.Collection.Requests = append(im.result.Collection.Requests, collection_module.SavedRequest{
+		Name:    r.Name,
+		Folder:  strings.TrimSuffix(folder, "/"),
+		Request: options,
+	})
+}
+
+// path joins the names of the folders above the resource.
+func (im *importer) path(r resource) string {
+	names := []string{r.Name}
+	for parent, ok := im.resources[r.ParentId]; ok && parent.Type == "request_group"; parent, ok = im.resources[parent.ParentId] {
+		names = append([]string{parent.Name}, names...)
+	}
+	return strings.Join(names, "/")
+}
+
+func (im *importer) body(path string, b body) []http_utility.HttpContentData {
+	mimeType := b.MimeType
+	switch {
+	case mimeType == "application/x-www-form-urlencoded":
+		var data []http_utility.HttpContentData
+		for _, p := range b.Params {
+			if !p.Disabled {
+				data = append(data, http_utility.HttpContentData{ContentType: mimeType, Key: im.template(path, p.Name), Value: im.template(path, p.Value)})
+			}
+		}
+		return data
+	case mimeType == "multipart/form-data":
+		var data []http_utility.HttpContentData
+		for _, p := range b.Params {
+			if p.Disabled {
+				continue
+			}
+			value := im.template(path, p.Value)
+			if p.Type == "file" {
+				if p.FileName == "" {
+					im.skip(path + ": the form file \"" + p.Name + "\" has no path")
+					continue
+				}
+				value = p.FileName
+			}
+			data = append(data, http_utility.HttpContentData{ContentType: mimeType, Key: im.template(path, p.Name), Value: value})
+		}
+		return data
+	case b.FileName != "":
+		return http_utility.NewFileBody(b.FileName, mimeType)
+	case mimeType == "application/graphql":
+		var graphql struct {
+			Query         string          `json:"query"`
+			Variables     json.RawMessage `json:"variables"`
+			OperationName string          `json:"operationName"`
+		}
+		if json.Unmarshal([]byte(b.Text), &graphql) != nil {
+			im.skip(path + ": the GraphQL body is not valid JSON")
+			return nil
+		}
+		variables := ""
+		if len(graphql.Variables) > 0 && string(graphql.Variables) != "null" {
+			variables = string(graphql.Variables)
+		}
+		return http_utility.NewGraphQLBody(im.template(path, graphql.Query), im.template(path, variables), graphql.OperationName)
+	case b.Text != "":
+		if mimeType == "" {
+			mimeType = "text/plain"
+		}
+		return []http_utility.HttpContentData{{ContentType: mimeType, Value: im.template(path, b.Text)}}
+	}
+	return nil
+}
+
+// inheritedAuth walks up the folders until one sets an auth, Insomnia
+// requests without one inherit it.
+func (im *importer) inheritedAuth(r resource) authentication {
+	for current, ok := r, true; ok; current, ok = im.resources[current.ParentId] {
+		if current.Authentication.Type != "" && current.Authentication.Type != "inherit" {
+			return current.Authentication
+		}
+		if current.ParentId == "" {
+			break
+		}
+	}
+	return authentication{}
+}
+
+func (im *importer) auth(path string, a authentication, options *request_module.RequestOptions) {
+	if a.Disabled {
+		return
+	}
+	t := func(value string) string { return im.template(path, value) }
+
+	switch a.Type {
+	case "", "none":
+	case "basic":
+		options.Auth = auth_module.AuthOptions{Type: auth_module.AuthBasic, Username: t(a.Username), Password: t(a.Password)}
+	case "digest":
+		options.Auth = auth_module.AuthOptions{Type: auth_module.AuthDigest, Username: t(a.Username), Password: t(a.Password)}
+	case "bearer":
+		options.Auth = auth_module.AuthOptions{Type: auth_module.AuthBearer, Token: t(a.Token)}
+	case "oauth2":
+		if a.GrantType != "" && a.GrantType != "client_credentials" {
+			im.skip(path + ": OAuth2 with the " + a.GrantType + " grant, only client credentials are supported")
+			return
+		}
+		options.Auth = auth_module.AuthOptions{
+			Type:         auth_module.AuthOAuth2,
+			TokenUrl:     t(a.AccessTokenUrl),
+			ClientId:     t(a.ClientId),
+			ClientSecret: t(a.ClientSecret),
+			Scopes:       t(a.Scope),
+		}
+	case "apikey":
+		if a.AddTo == "queryParams" {
+			options.Url = http_utility.AddQuery(options.Url, neturl.Values{t(a.Key): {t(a.Value)}})
+		} else {
+			options.Headers.Set(t(a.Key), t(a.Value))
+		}
+	default:
+		im.skip(path + ": " + a.Type + " auth is not supported")
+	}
+}
+
+// template rewrites Insomnia variables to {{name}}. Template tags, like
+// {% uuid %}, have no equivalent and are reported.
+func (im *importer) template(path string, value string) string {
+	if strings.Contains(value, "{%") {
+		im.skip(fmt.Sprintf("%s: the template tag in %q is sent as it is", path, value))
+	}
+	return templatePattern.ReplaceAllString(value, "{{$1}}")
+}
+
+func (im *importer) skip(message string) {
+	for _, skipped := range im.result.Skipped {
+		if skipped == message {
+			return
+		}
+	}
+	im.result.Skipped = append(im.result.Skipped, message)
+}
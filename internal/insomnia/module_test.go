@@ -0,0 +1,97 @@
+package insomnia_module
+
+import (
+	"testing"
+
+	auth_module "github.com/diogopereiradev/httpzen/internal/auth"
+	"github.com/diogopereiradev/httpzen/internal/utils/http_utility"
+	"github.com/stretchr/testify/assert"
+)
+
+const testExport = `{
+	"_type": "export",
+	"__export_format": 4,
+	"resources": [
+		{"_id": "wrk_1", "_type": "workspace", "name": "Shop"},
+		{"_id": "env_base", "_type": "environment", "parentId": "wrk_1", "name": "Base Environment", "data": {"baseUrl": "https://api.example.com", "api": {"version": 2}}},
+		{"_id": "env_dev", "_type": "environment", "parentId": "env_base", "name": "Dev", "data": {"baseUrl": "http://localhost:3000"}},
+		{"_id": "fld_1", "_type": "request_group", "parentId": "wrk_1", "name": "Users", "authentication": {"type": "bearer", "token": "{{ _.token }}"}},
+		{"_id": "fld_2", "_type": "request_group", "parentId": "fld_1", "name": "Admin"},
+		{
+			"_id": "req_1", "_type": "request", "parentId": "fld_2", "name": "List",
+			"method": "get", "url": "{{ _.baseUrl }}/v{{ _.api.version }}/users",
+			"parameters": [{"name": "page", "value": "2"}, {"name": "off", "value": "1", "disabled": true}],
+			"headers": [{"name": "Accept", "value": "application/json"}],
+			"body": {}, "authentication": {}
+		},
+		{
+			"_id": "req_2", "_type": "request", "parentId": "wrk_1", "name": "Login",
+			"method": "POST", "url": "{{ baseUrl }}/login",
+			"body": {"mimeType": "application/x-www-form-urlencoded", "params": [{"name": "user", "value": "alice"}]},
+			"authentication": {"type": "basic", "username": "alice", "password": "secret"},
+			"preRequestScript": "insomnia.environment.set('a', 1)"
+		},
+		{
+			"_id": "req_3", "_type": "request", "parentId": "wrk_1", "name": "Upload",
+			"method": "POST", "url": "{{ _.baseUrl }}/files",
+			"body": {"mimeType": "multipart/form-data", "params": [{"name": "title", "value": "{% uuid 'v4' %}"}, {"name": "file", "type": "file", "fileName": "/tmp/a.pdf"}]},
+			"authentication": {"type": "hawk"}
+		},
+		{
+			"_id": "req_4", "_type": "request", "parentId": "wrk_1", "name": "Query",
+			"method": "POST", "url": "{{ _.baseUrl }}/graphql",
+			"body": {"mimeType": "application/graphql", "text": "{\"query\": \"{ me { id } }\", \"variables\": {\"a\": 1}}"}
+		},
+		{"_id": "greq_1", "_type": "grpc_request", "parentId": "wrk_1", "name": "Greeter"}
+	]
+}`
+
+func TestParse(t *testing.T) {
+	result, err := Parse([]byte(testExport))
+	assert.NoError(t, err)
+
+	collection := result.Collection
+	assert.Equal(t, "Shop", collection.Name)
+	assert.Equal(t, map[string]string{"baseUrl": "https://api.example.com", "api.version": "2"}, collection.Variables)
+	assert.Len(t, collection.Requests, 4)
+
+	list := collection.Requests[0]
+	assert.Equal(t, "Users/Admin", list.Folder)
+	assert.Equal(t, "GET", list.Request.Method)
+	assert.Equal(t, "{{baseUrl}}/v{{api.version}}/users?page=2", list.Request.Url)
+	assert.Equal(t, "application/json", list.Request.Headers.Get("Accept"))
+	assert.Nil(t, list.Request.Body)
+	assert.Equal(t, auth_module.AuthOptions{Type: auth_module.AuthBearer, Token: "{{token}}"}, list.Request.Auth, "the folder auth should be inherited")
+
+	login := collection.Requests[1]
+	assert.Empty(t, login.Folder)
+	assert.Equal(t, auth_module.AuthOptions{Type: auth_module.AuthBasic, Username: "alice", Password: "secret"}, login.Request.Auth)
+	assert.Equal(t, []http_utility.HttpContentData{{ContentType: "application/x-www-form-urlencoded", Key: "user", Value: "alice"}}, login.Request.Body)
+
+	upload := collection.Requests[2]
+	assert.Equal(t, "/tmp/a.pdf", upload.Request.Body[1].Value)
+
+	query, variables, _, ok := http_utility.GetGraphQLBody(collection.Requests[3].Request.Body)
+	assert.True(t, ok)
+	assert.Equal(t, "{ me { id } }", query)
+	assert.JSONEq(t, `{"a": 1}`, variables)
+
+	assert.Len(t, result.Environments, 1)
+	assert.Equal(t, "Dev", result.Environments[0].Name)
+	assert.Equal(t, map[string]string{"baseUrl": "http://localhost:3000"}, result.Environments[0].Variables)
+
+	assert.Equal(t, []string{
+		"Login: the pre-request script",
+		`Upload: the template tag in "{% uuid 'v4' %}" is sent as it is`,
+		"Upload: hawk auth is not supported",
+		"Greeter: grpc requests are not supported",
+	}, result.Skipped)
+}
+
+func TestParse_Errors(t *testing.T) {
+	_, err := Parse([]byte(`_type: export`))
+	assert.ErrorContains(t, err, "JSON")
+
+	_, err = Parse([]byte(`{"_type": "export", "__export_format": 3, "resources": []}`))
+	assert.ErrorContains(t, err, "v4")
+}
@@ -1,11 +1,13 @@
 package body_menu
 
 import (
+	"encoding/json"
 	"os"
 
 	keyvalue_menu_component "github.com/diogopereiradev/httpzen/internal/components/keyvalue_menu"
 	select_menu_component "github.com/diogopereiradev/httpzen/internal/components/select_menu"
 	textarea_component "github.com/diogopereiradev/httpzen/internal/components/textarea"
+	graphql_module "github.com/diogopereiradev/httpzen/internal/graphql"
 	logger_module "github.com/diogopereiradev/httpzen/internal/logger"
 	request_module "github.com/diogopereiradev/httpzen/internal/request"
 	"github.com/diogopereiradev/httpzen/internal/utils/http_utility"
@@ -14,12 +16,14 @@ import (
 
 var Exit = os.Exit
 var ErrorLogger = logger_module.Error
+var WarnLogger = logger_module.Warn
 var TermClear = terminal_utility.Clear
+var GetGraphQLSchema = graphql_module.GetSchema
 
 func New(req *request_module.RequestOptions, bodyPointer *[]http_utility.HttpContentData) {
 	TermClear()
 
-	choices := []string{"application/json", "application/x-www-form-urlencoded", "text/plain", "multipart/form-data"}
+	choices := []string{"application/json", "application/x-www-form-urlencoded", "text/plain", "multipart/form-data", http_utility.GraphQLContentType}
 	var contentType string
 
 	select_menu_component.New(select_menu_component.MenuImpl{
@@ -52,6 +56,8 @@ func New(req *request_module.RequestOptions, bodyPointer *[]http_utility.HttpCon
 		bodyResult = plainText()
 	case "multipart/form-data":
 		bodyResult = multipartFormDataMenu()
+	case http_utility.GraphQLContentType:
+		bodyResult = graphQLMenu(req)
 	default:
 		ErrorLogger("Invalid Content-Type selected.", 50)
 		Exit(1)
@@ -145,3 +151,70 @@ func multipartFormDataMenu() []http_utility.HttpContentData {
 	}
 	return bodyResult
 }
+
+func graphQLMenu(req *request_module.RequestOptions) []http_utility.HttpContentData {
+	// The schema only powers completion here, the request is validated again
+	// before it is sent.
+	var suggest func(before string) []string
+	if schema, err := GetGraphQLSchema(*req, false); err != nil {
+		WarnLogger("Could not load the GraphQL schema, field completion is disabled: "+err.Error(), 70)
+	} else {
+		suggest = func(before string) []string {
+			return graphql_module.Complete(schema, before)
+		}
+	}
+
+	var query string
+	textarea_component.New(textarea_component.TextareaImpl{
+		Title:     "Enter GraphQL query",
+		MaxLength: 50000,
+		Suggest:   suggest,
+		Events: textarea_component.TextareaEvents{
+			OnSubmit: func(value string) {
+				query = value
+			},
+		},
+	})
+	if query == "" {
+		ErrorLogger("GraphQL query cannot be empty.", 50)
+		Exit(1)
+		return nil
+	}
+
+	var variables string
+	textarea_component.New(textarea_component.TextareaImpl{
+		Title:     "Enter GraphQL variables (JSON)",
+		MaxLength: 50000,
+		Events: textarea_component.TextareaEvents{
+			OnSubmit: func(value string) {
+				variables = value
+			},
+		},
+	})
+	if variables != "" && !json.Valid([]byte(variables)) {
+		ErrorLogger("GraphQL variables must be a JSON object.", 50)
+		Exit(1)
+		return nil
+	}
+
+	var operationName string
+	if operations := graphql_module.OperationNames(query); len(operations) > 1 {
+		select_menu_component.New(select_menu_component.MenuImpl{
+			Choices: operations,
+			Messages: select_menu_component.MenuMessages{
+				Title:        "Select the operation to run",
+				EmptyOptions: "No operations available.",
+			},
+			PerPage: 5,
+			Events: select_menu_component.MenuEvents{
+				OnSelect: func(choice int) {
+					if choice >= 0 && choice < len(operations) {
+						operationName = operations[choice]
+					}
+				},
+			},
+		})
+	}
+
+	return http_utility.NewGraphQLBody(query, variables, operationName)
+}
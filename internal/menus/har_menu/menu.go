@@ -0,0 +1,327 @@
+package har_menu
+
+import (
+	"fmt"
+	neturl "net/url"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	collection_module "github.com/diogopereiradev/httpzen/internal/collection"
+	"github.com/diogopereiradev/httpzen/internal/components/prompt"
+	timed_message_component "github.com/diogopereiradev/httpzen/internal/components/timed_message"
+	config_module "github.com/diogopereiradev/httpzen/internal/config"
+	har_module "github.com/diogopereiradev/httpzen/internal/har"
+	logger_module "github.com/diogopereiradev/httpzen/internal/logger"
+	"github.com/diogopereiradev/httpzen/internal/menus/request_menu"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	logoascii "github.com/diogopereiradev/httpzen/internal/utils/logo_ascii"
+	"github.com/diogopereiradev/httpzen/internal/utils/terminal_utility"
+	"github.com/diogopereiradev/httpzen/internal/utils/theme"
+)
+
+type action int
+
+const (
+	action_None action = iota
+	action_Replay
+	action_Save
+)
+
+type Model struct {
+	config  *config_module.Config
+	entries []har_module.Entry
+	hosts   []string
+	methods []string
+
+	// host and method index hosts and methods, -1 shows every entry.
+	host   int
+	method int
+
+	cursor  int
+	action  action
+	message *timed_message_component.TimedMessage
+}
+
+var Exit = os.Exit
+var LoggerError = logger_module.Error
+var TeaNewProgram = tea.NewProgram
+var TermClear = terminal_utility.Clear
+var RunRequestFunc = request_module.RunRequest
+var RequestMenuNewFunc = request_menu.New
+var PromptNewFunc = prompt.New
+var SaveRequestFunc = collection_module.SaveRequest
+var RunProgram = func(p *tea.Program) (tea.Model, error) {
+	return p.Run()
+}
+
+const perPage = 15
+
+// New lists the entries of a HAR file. Enter replays the selected entry and
+// shows the response, 's' saves it as a request of a collection; both come
+// back to the list. host and method preselect the filters.
+func New(entries []har_module.Entry, host string, method string) {
+	config := config_module.GetConfig()
+	m := &Model{
+		config:  &config,
+		entries: entries,
+		hosts:   har_module.Hosts(entries),
+		methods: har_module.Methods(entries),
+		host:    -1,
+		method:  -1,
+		message: timed_message_component.New(),
+	}
+	m.host = indexFold(m.hosts, host)
+	m.method = indexFold(m.methods, method)
+
+	for {
+		m.action = action_None
+		TermClear()
+		if _, err := RunProgram(TeaNewProgram(m)); err != nil {
+			LoggerError("Error on rendering the program: "+err.Error(), 70)
+			Exit(1)
+			return
+		}
+
+		filtered := m.filtered()
+		if m.action == action_None || len(filtered) == 0 {
+			return
+		}
+		entry := filtered[m.cursor]
+
+		switch m.action {
+		case action_Replay:
+			replay(m, entry)
+		case action_Save:
+			save(m, entry)
+		}
+	}
+}
+
+func replay(m *Model, entry har_module.Entry) {
+	options := entry.ToRequestOptions()
+	options.Timeout, options.Timeouts = request_module.ConfigTimeouts(*m.config)
+
+	res, err := RunRequestFunc(options)
+	// The error is shown by the request menu, the list stays open.
+	_ = RequestMenuNewFunc(&res, err, request_menu.Options{})
+}
+
+func save(m *Model, entry har_module.Entry) {
+	collection := "har"
+	PromptNewFunc(prompt.PromptImpl{
+		Title:     "Collection name",
+		Value:     collection,
+		KeepCase:  true,
+		MaxLength: 100,
+		Events: prompt.PromptEvents{
+			OnSubmit: func(result string) {
+				if result = strings.TrimSpace(result); result != "" {
+					collection = result
+				}
+			},
+		},
+	})
+
+	name := strings.ToUpper(entry.Request.Method) + " " + entryPath(entry)
+	PromptNewFunc(prompt.PromptImpl{
+		Title:     "Request name",
+		Value:     name,
+		KeepCase:  true,
+		MaxLength: 200,
+		Events: prompt.PromptEvents{
+			OnSubmit: func(result string) {
+				if result = strings.TrimSpace(result); result != "" {
+					name = result
+				}
+			},
+		},
+	})
+
+	err := SaveRequestFunc(collection, collection_module.SavedRequest{Name: name, Request: entry.ToRequestOptions()})
+	if err != nil {
+		m.message.Show("Could not save the request: "+err.Error(), 2*time.Second)
+		return
+	}
+	m.message.Show("Saved \""+name+"\" to the collection "+collection, 2*time.Second)
+}
+
+func (m *Model) filtered() []har_module.Entry {
+	host, method := "", ""
+	if m.host >= 0 {
+		host = m.hosts[m.host]
+	}
+	if m.method >= 0 {
+		method = m.methods[m.method]
+	}
+	return har_module.Filter(m.entries, host, method)
+}
+
+func (m *Model) Init() tea.Cmd {
+	if m.message.Visible {
+		return func() tea.Msg {
+			time.Sleep(m.message.Duration)
+			m.message.Visible = false
+			return timed_message_component.TimedMessageExpiredMsg{}
+		}
+	}
+	return nil
+}
+
+func (m *Model) View() string {
+	var content string
+
+	titleStyle := lipgloss.NewStyle().Foreground(theme.Primary).Bold(true)
+	headerStyle := lipgloss.NewStyle().Foreground(theme.Secondary)
+	selectedStyle := lipgloss.NewStyle().Foreground(theme.Secondary).Bold(true)
+	errorStyle := lipgloss.NewStyle().Foreground(theme.Error)
+	greyTextStyle := lipgloss.NewStyle().Foreground(theme.DarkenText)
+
+	if !m.config.HideLogomark {
+		content += lipgloss.NewStyle().Foreground(theme.Primary).Render(logoascii.GetLogo(".har")) + "\n"
+	}
+
+	filtered := m.filtered()
+	content += titleStyle.Render(fmt.Sprintf("HAR entries (%d of %d)", len(filtered), len(m.entries)))
+	content += greyTextStyle.Render("  host: "+filterLabel(m.hosts, m.host)+"  method: "+filterLabel(m.methods, m.method)) + "\n\n"
+
+	if len(filtered) == 0 {
+		content += errorStyle.Render("No entries match the filters.") + "\n"
+	} else {
+		start := 0
+		if m.cursor >= perPage {
+			start = m.cursor - perPage + 1
+		}
+		end := min(start+perPage, len(filtered))
+
+		content += headerStyle.Render("  "+row("Method", "Status", "Host", "Path", "Time")) + "\n"
+		for i := start; i < end; i++ {
+			entry := filtered[i]
+			line := row(
+				strings.ToUpper(entry.Request.Method),
+				fmt.Sprintf("%d", entry.Response.Status),
+				entry.Host(),
+				entryPath(entry),
+				fmt.Sprintf("%.0fms", entry.Time),
+			)
+			if i == m.cursor {
+				content += selectedStyle.Render("> "+line) + "\n"
+			} else if entry.Response.Status >= 400 || entry.Response.Status == 0 {
+				content += errorStyle.Render("  "+line) + "\n"
+			} else {
+				content += "  " + line + "\n"
+			}
+		}
+		if len(filtered) > perPage {
+			content += greyTextStyle.Render(fmt.Sprintf("[%d-%d/%d entries]", start+1, end, len(filtered))) + "\n"
+		}
+	}
+
+	content += greyTextStyle.Render("\nUse up/down to move, enter to replay, 's' to save as a request, 'q' to quit.")
+	content += greyTextStyle.Render("\n'h' to filter by host and 'm' by method.\n")
+
+	if m.message.Visible {
+		content += "\n" + m.message.Render()
+	}
+	return content
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	filtered := m.filtered()
+	switch keyMsg.Type {
+	case tea.KeyRunes:
+		switch keyMsg.String() {
+		case "q":
+			return m, tea.Quit
+		case "h":
+			m.host = nextFilter(m.host, len(m.hosts))
+			m.cursor = 0
+		case "m":
+			m.method = nextFilter(m.method, len(m.methods))
+			m.cursor = 0
+		case "s":
+			if len(filtered) > 0 {
+				m.action = action_Save
+				return m, tea.Quit
+			}
+		}
+	case tea.KeyCtrlC, tea.KeyEsc:
+		return m, tea.Quit
+	case tea.KeyUp:
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case tea.KeyDown:
+		if m.cursor < len(filtered)-1 {
+			m.cursor++
+		}
+	case tea.KeyPgUp:
+		m.cursor = max(m.cursor-perPage, 0)
+	case tea.KeyPgDown:
+		m.cursor = max(min(m.cursor+perPage, len(filtered)-1), 0)
+	case tea.KeyEnter:
+		if len(filtered) > 0 {
+			m.action = action_Replay
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+func row(method, status, host, path, time string) string {
+	return fmt.Sprintf("%-7s %-6s %-28s %-40s %s", method, status, truncate(host, 28), truncate(path, 40), time)
+}
+
+func entryPath(entry har_module.Entry) string {
+	parsed, err := neturl.Parse(entry.Request.Url)
+	if err != nil {
+		return entry.Request.Url
+	}
+	path := parsed.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if parsed.RawQuery != "" {
+		path += "?" + parsed.RawQuery
+	}
+	return path
+}
+
+func truncate(value string, width int) string {
+	runes := []rune(value)
+	if len(runes) <= width {
+		return value
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// nextFilter cycles through the values and back to showing everything.
+func nextFilter(current int, count int) int {
+	if current+1 >= count {
+		return -1
+	}
+	return current + 1
+}
+
+func filterLabel(values []string, index int) string {
+	if index < 0 {
+		return "all"
+	}
+	return values[index]
+}
+
+func indexFold(values []string, value string) int {
+	for i, v := range values {
+		if value != "" && strings.EqualFold(v, value) {
+			return i
+		}
+	}
+	return -1
+}
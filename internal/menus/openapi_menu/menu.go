@@ -0,0 +1,232 @@
+package openapi_menu
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	collection_module "github.com/diogopereiradev/httpzen/internal/collection"
+	"github.com/diogopereiradev/httpzen/internal/components/prompt"
+	config_module "github.com/diogopereiradev/httpzen/internal/config"
+	environment_module "github.com/diogopereiradev/httpzen/internal/environment"
+	logger_module "github.com/diogopereiradev/httpzen/internal/logger"
+	"github.com/diogopereiradev/httpzen/internal/menus/request_menu"
+	openapi_module "github.com/diogopereiradev/httpzen/internal/openapi"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	logoascii "github.com/diogopereiradev/httpzen/internal/utils/logo_ascii"
+	"github.com/diogopereiradev/httpzen/internal/utils/terminal_utility"
+	"github.com/diogopereiradev/httpzen/internal/utils/theme"
+)
+
+type Model struct {
+	config     *config_module.Config
+	spec       *openapi_module.Spec
+	operations []openapi_module.Operation
+
+	cursor int
+	send   bool
+}
+
+var Exit = os.Exit
+var LoggerError = logger_module.Error
+var TeaNewProgram = tea.NewProgram
+var TermClear = terminal_utility.Clear
+var RunRequestFunc = request_module.RunRequest
+var RequestMenuNewFunc = request_menu.New
+var PromptNewFunc = prompt.New
+var GetCollectionFunc = collection_module.GetCollection
+var GetEnvironmentFunc = environment_module.GetEnvironment
+var RunProgram = func(p *tea.Program) (tea.Model, error) {
+	return p.Run()
+}
+
+const perPage = 15
+
+// New lists the operations of a spec grouped by tag. Enter asks for the
+// required parameters, sends the request and shows the response, then
+// comes back to the list. collection is the name the spec was imported
+// as, its variables and the active environment fill the {{variables}}.
+func New(spec *openapi_module.Spec, collection string) {
+	config := config_module.GetConfig()
+	m := &Model{
+		config:     &config,
+		spec:       spec,
+		operations: groupByTag(spec.Operations()),
+	}
+
+	for {
+		m.send = false
+		TermClear()
+		if _, err := RunProgram(TeaNewProgram(m)); err != nil {
+			LoggerError("Error on rendering the program: "+err.Error(), 70)
+			Exit(1)
+			return
+		}
+		if !m.send {
+			return
+		}
+		send(m, m.operations[m.cursor], collection)
+	}
+}
+
+func send(m *Model, operation openapi_module.Operation, collectionName string) {
+	values := map[string]string{}
+	for _, parameter := range operation.Parameters {
+		if (!parameter.Required && parameter.In != "path") || parameter.Ignored() {
+			continue
+		}
+		PromptNewFunc(prompt.PromptImpl{
+			Title:     "Value of the " + parameter.In + " parameter " + parameter.Name,
+			Value:     parameter.ParameterExample(),
+			KeepCase:  true,
+			MaxLength: 500,
+			Events: prompt.PromptEvents{
+				OnSubmit: func(result string) {
+					values[parameter.Name] = result
+				},
+			},
+		})
+	}
+
+	options, _ := m.spec.RequestOptions(operation, values)
+
+	collection, _ := GetCollectionFunc(collectionName)
+	variables := map[string]string{"baseUrl": m.spec.ServerUrl()}
+	for name, value := range collection.Variables {
+		if value != "" {
+			variables[name] = value
+		}
+	}
+	collection.Variables = variables
+	environment := GetEnvironmentFunc(m.config.ActiveEnvironment)
+	options = collection.Resolve(collection_module.SavedRequest{Request: options}, environment.Variables)
+
+	options.Timeout, options.Timeouts = request_module.ConfigTimeouts(*m.config)
+
+	res, err := RunRequestFunc(options)
+	// The error is shown by the request menu, the list stays open.
+	_ = RequestMenuNewFunc(&res, err, request_menu.Options{})
+}
+
+// groupByTag sorts the operations by tag, keeping the path order inside a
+// tag, with the untagged ones last.
+func groupByTag(operations []openapi_module.Operation) []openapi_module.Operation {
+	sorted := append([]openapi_module.Operation{}, operations...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].Tag(), sorted[j].Tag()
+		if (a == "default") != (b == "default") {
+			return b == "default"
+		}
+		return a < b
+	})
+	return sorted
+}
+
+func (m *Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m *Model) View() string {
+	var content string
+
+	titleStyle := lipgloss.NewStyle().Foreground(theme.Primary).Bold(true)
+	tagStyle := lipgloss.NewStyle().Foreground(theme.Secondary).Bold(true)
+	selectedStyle := lipgloss.NewStyle().Foreground(theme.Secondary).Bold(true)
+	errorStyle := lipgloss.NewStyle().Foreground(theme.Error)
+	greyTextStyle := lipgloss.NewStyle().Foreground(theme.DarkenText)
+
+	if !m.config.HideLogomark {
+		content += lipgloss.NewStyle().Foreground(theme.Primary).Render(logoascii.GetLogo("openapi")) + "\n"
+	}
+
+	title := m.spec.Info.Title
+	if title == "" {
+		title = "OpenAPI"
+	}
+	content += titleStyle.Render(fmt.Sprintf("%s (%d operations)", title, len(m.operations)))
+	if server := m.spec.ServerUrl(); server != "" {
+		content += greyTextStyle.Render("  " + server)
+	}
+	content += "\n\n"
+
+	if len(m.operations) == 0 {
+		content += errorStyle.Render("The spec has no operations.") + "\n"
+	} else {
+		start := 0
+		if m.cursor >= perPage {
+			start = m.cursor - perPage + 1
+		}
+		end := min(start+perPage, len(m.operations))
+
+		for i := start; i < end; i++ {
+			operation := m.operations[i]
+			if i == start || operation.Tag() != m.operations[i-1].Tag() {
+				content += tagStyle.Render(operation.Tag()) + "\n"
+			}
+			line := fmt.Sprintf("%-7s %-40s %s", operation.Method, truncate(operation.Path, 40), operationName(operation))
+			if i == m.cursor {
+				content += selectedStyle.Render("> "+line) + "\n"
+			} else {
+				content += "  " + line + "\n"
+			}
+		}
+		if len(m.operations) > perPage {
+			content += greyTextStyle.Render(fmt.Sprintf("[%d-%d/%d operations]", start+1, end, len(m.operations))) + "\n"
+		}
+	}
+
+	content += greyTextStyle.Render("\nUse up/down to move, enter to fill in the parameters and send, 'q' to quit.\n")
+	return content
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.Type {
+	case tea.KeyRunes:
+		if keyMsg.String() == "q" {
+			return m, tea.Quit
+		}
+	case tea.KeyCtrlC, tea.KeyEsc:
+		return m, tea.Quit
+	case tea.KeyUp:
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case tea.KeyDown:
+		if m.cursor < len(m.operations)-1 {
+			m.cursor++
+		}
+	case tea.KeyPgUp:
+		m.cursor = max(m.cursor-perPage, 0)
+	case tea.KeyPgDown:
+		m.cursor = max(min(m.cursor+perPage, len(m.operations)-1), 0)
+	case tea.KeyEnter:
+		if len(m.operations) > 0 {
+			m.send = true
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+// operationName hides the name when it is only the method and path.
+func operationName(operation openapi_module.Operation) string {
+	if name := operation.Name(); name != operation.Method+" "+operation.Path {
+		return name
+	}
+	return ""
+}
+
+func truncate(value string, width int) string {
+	runes := []rune(value)
+	if len(runes) <= width {
+		return value
+	}
+	return string(runes[:width-1]) + "…"
+}
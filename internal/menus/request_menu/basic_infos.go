@@ -3,9 +3,11 @@ package request_menu
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/x/ansi"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
 	"github.com/diogopereiradev/httpzen/internal/utils/html_formatter"
 	"github.com/diogopereiradev/httpzen/internal/utils/http_utility"
 	"github.com/diogopereiradev/httpzen/internal/utils/json_formatter"
@@ -34,13 +36,88 @@ func basic_infos_Render(m *Model) string {
 
 	content += greyTextStyle.Render(fmt.Sprint(m.response.HttpVersion)+" "+m.response.Method+" "+m.response.StatusMessage) + "\n\n"
 	content += fieldTextStyle.Render("URL: ") + m.response.Request.Url + "\n"
+	content += basic_infos_protocol_Render(m)
 	content += fieldTextStyle.Render("Response Time: ") + executionTime + "\n"
 	content += fieldTextStyle.Render("Response Size: ") + fmt.Sprintf("%d bytes", len(m.response.Result))
+	content += basic_infos_attempts_Render(m.response.Attempts)
 	content += basic_infos_body_Render(m)
 
 	return content
 }
 
+// basic_infos_attempts_Render shows the retry timeline, so a clean success
+// can be told apart from one that needed several tries.
+func basic_infos_attempts_Render(attempts []request_module.RetryAttempt) string {
+	if len(attempts) == 0 {
+		return ""
+	}
+
+	fieldTextStyle := lipgloss.NewStyle().Foreground(theme.Secondary)
+	greyTextStyle := lipgloss.NewStyle().Foreground(theme.DarkenText)
+	redFieldTextStyle := lipgloss.NewStyle().Foreground(theme.Error)
+
+	summary := "first try"
+	if len(attempts) > 1 {
+		summary = fmt.Sprintf("%d retries", len(attempts)-1)
+	}
+	content := "\n" + fieldTextStyle.Render("Attempts: ") + fmt.Sprintf("%d ", len(attempts)) + greyTextStyle.Render("("+summary+")")
+	for i, attempt := range attempts {
+		result := fmt.Sprint(attempt.StatusCode)
+		if attempt.Error != "" {
+			result = redFieldTextStyle.Render(attempt.Error)
+		} else if attempt.StatusCode >= 400 {
+			result = redFieldTextStyle.Render(result)
+		}
+		line := fmt.Sprintf("\n  %d. %s %s", i+1, result, greyTextStyle.Render(fmt.Sprintf("%.2fms", attempt.ExecutionTime)))
+		if attempt.Wait > 0 {
+			wait := "waited " + attempt.Wait.Round(time.Millisecond).String()
+			if attempt.RetryAfter {
+				wait += " (Retry-After)"
+			}
+			line += greyTextStyle.Render(", " + wait)
+		}
+		content += line
+	}
+	return content
+}
+
+func basic_infos_protocol_Render(m *Model) string {
+	info := m.response.Protocol
+	if info.Negotiated == "" {
+		return ""
+	}
+
+	fieldTextStyle := lipgloss.NewStyle().Foreground(theme.Secondary)
+	greyTextStyle := lipgloss.NewStyle().Foreground(theme.DarkenText)
+
+	var details []string
+	if info.Alpn != "" {
+		details = append(details, "ALPN "+info.Alpn)
+	}
+	if info.TlsVersion != "" {
+		details = append(details, info.TlsVersion)
+	}
+	if info.Requested != "" {
+		details = append(details, "forced with --protocol "+info.Requested)
+	}
+
+	content := fieldTextStyle.Render("Protocol: ") + info.Negotiated
+	if len(details) > 0 {
+		content += greyTextStyle.Render(" (" + strings.Join(details, ", ") + ")")
+	}
+	content += "\n"
+
+	if info.Reused {
+		content += fieldTextStyle.Render("Connection: ") + "reused\n"
+	} else {
+		content += fieldTextStyle.Render("Connection: ") + "new\n"
+	}
+	if strings.HasPrefix(info.Negotiated, "HTTP/2") {
+		content += fieldTextStyle.Render("Server Push: ") + greyTextStyle.Render("disabled, the client does not accept pushed streams") + "\n"
+	}
+	return content
+}
+
 func basic_infos_Render_Paged(m *Model) string {
 	content := basic_infos_Render(m)
 	lines := strings.Split(content, "\n")
@@ -0,0 +1,152 @@
+package request_menu
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	diff_module "github.com/diogopereiradev/httpzen/internal/diff"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/diogopereiradev/httpzen/internal/utils/terminal_utility"
+	"github.com/diogopereiradev/httpzen/internal/utils/theme"
+)
+
+// Baseline is a response the Diff tab compares every response to, instead
+// of the one a refetch replaced. Label says what it is, Ignore leaves body
+// paths and headers out of the diff.
+type Baseline struct {
+	Label    string
+	Response request_module.RequestResponse
+	Ignore   []string
+}
+
+// diff_Set compares the response shown to previous, a failed response has
+// nothing to compare.
+func diff_Set(m *Model, previous *request_module.RequestResponse, label string) {
+	m.previous, m.previousLabel, m.diff = previous, label, nil
+	if previous == nil || m.response == nil || m.err != nil {
+		return
+	}
+	var ignore []string
+	if m.options.Baseline != nil {
+		ignore = m.options.Baseline.Ignore
+	}
+	result := diff_module.Compare(*previous, *m.response, ignore)
+	m.diff = &result
+}
+
+// diff_Next compares a new response to the one it replaces, or keeps the
+// previous comparison when the replaced one failed. A baseline stays the
+// base.
+func diff_Next(m *Model, next *Model) {
+	switch {
+	case m.options.Baseline != nil:
+		diff_Set(next, &m.options.Baseline.Response, m.options.Baseline.Label)
+	case m.err == nil && m.response != nil:
+		diff_Set(next, m.response, "the previous response")
+	default:
+		diff_Set(next, m.previous, m.previousLabel)
+	}
+}
+
+func diff_Change_Render(change diff_module.Change, width int) string {
+	color := theme.Warn
+	switch change.Kind {
+	case diff_module.Added:
+		color = theme.Success
+	case diff_module.Removed:
+		color = theme.Error
+	}
+	return "  " + lipgloss.NewStyle().Foreground(color).Render(ansi.Truncate(diff_module.FormatChange(change), width-2, "…"))
+}
+
+func diff_Render(m *Model) string {
+	fieldTextStyle := lipgloss.NewStyle().Foreground(theme.Secondary)
+	titleTextStyle := lipgloss.NewStyle().Foreground(theme.Primary).Bold(true)
+	greyTextStyle := lipgloss.NewStyle().Foreground(theme.DarkenText)
+	width := terminal_utility.GetTerminalWidth(9999)
+	diff := m.diff
+
+	content := greyTextStyle.Render("Compared to "+m.previousLabel) + "\n\n"
+
+	statusStyle := greyTextStyle
+	if diff.StatusBefore != diff.StatusAfter {
+		statusStyle = lipgloss.NewStyle().Foreground(theme.Warn)
+	}
+	content += fieldTextStyle.Render("Status: ") + statusStyle.Render(diff_module.FormatStatus(*diff)) + "\n"
+
+	latencyStyle := lipgloss.NewStyle().Foreground(theme.Success)
+	if diff.LatencyAfter > diff.LatencyBefore {
+		latencyStyle = lipgloss.NewStyle().Foreground(theme.Error)
+	}
+	content += fieldTextStyle.Render("Latency: ") + latencyStyle.Render(diff_module.FormatLatency(*diff))
+
+	if diff.Same() {
+		return content + "\n\n" + lipgloss.NewStyle().Foreground(theme.Success).Bold(true).Render("✓ The status, headers and body are the same")
+	}
+
+	section := func(title string, changes []diff_module.Change) {
+		content += "\n\n" + titleTextStyle.Render(title)
+		if len(changes) == 0 {
+			content += "\n" + greyTextStyle.Render("  No changes")
+			return
+		}
+		for _, change := range changes {
+			content += "\n" + diff_Change_Render(change, width)
+		}
+	}
+	section("Headers", diff.Headers)
+	if diff.BodyJson {
+		section("Body (JSON)", diff.Body)
+	} else {
+		section("Body", diff.Body)
+	}
+	return content
+}
+
+func diff_Render_Paged(m *Model) string {
+	content := diff_Render(m)
+	lines := strings.Split(content, "\n")
+
+	m.diffLinesAmount = len(lines)
+
+	maxLines := terminal_utility.GetTerminalHeight(9999) - 16
+	start := min(m.diffScrollOffset, len(lines))
+	end := min(start+maxLines, len(lines))
+
+	result := strings.Join(lines[start:end], "\n")
+
+	if len(lines) > maxLines {
+		keyTextStyle := lipgloss.NewStyle().Foreground(theme.Secondary)
+		result += keyTextStyle.Render(fmt.Sprintf("\n[%d-%d/%d lines] Use ↑/↓ or PgUp/PgDown to scroll.", start+1, end, len(lines)))
+	}
+
+	return result
+}
+
+func diff_ScrollUp(m *Model) {
+	if m.diffScrollOffset > 0 {
+		m.diffScrollOffset--
+	}
+}
+
+func diff_ScrollDown(m *Model) {
+	maxLines := terminal_utility.GetTerminalHeight(9999) - 16
+	if m.diffLinesAmount <= maxLines || m.diffScrollOffset+maxLines >= m.diffLinesAmount {
+		return
+	}
+	m.diffScrollOffset++
+}
+
+func diff_ScrollPgUp(m *Model) {
+	m.diffScrollOffset = max(m.diffScrollOffset-5, 0)
+}
+
+func diff_ScrollPgDown(m *Model) {
+	maxLines := terminal_utility.GetTerminalHeight(9999) - 16
+	if m.diffLinesAmount <= maxLines {
+		return
+	}
+	m.diffScrollOffset = min(m.diffScrollOffset+5, m.diffLinesAmount-maxLines)
+}
@@ -0,0 +1,88 @@
+package request_menu
+
+import (
+	"context"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	progress_bar_component "github.com/diogopereiradev/httpzen/internal/components/progress_bar"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/diogopereiradev/httpzen/internal/utils/terminal_utility"
+	"github.com/diogopereiradev/httpzen/internal/utils/theme"
+)
+
+var RunDownloadFunc = request_module.RunDownload
+
+// NewDownload saves the response body to disk with a progress bar in the
+// Response tab, 'r' resumes or repeats the download.
+func NewDownload(options request_module.RequestOptions) error {
+	run := func(ctx context.Context, handlers request_module.StreamHandlers) (request_module.RequestResponse, error) {
+		return RunDownloadFunc(ctx, options, handlers)
+	}
+	return stream_Open(options, run, "bytes", Options{})
+}
+
+func download_Status_Render(m *Model) string {
+	s := m.stream
+	fieldTextStyle := lipgloss.NewStyle().Foreground(theme.Secondary)
+
+	var state string
+	switch {
+	case s.err != nil:
+		state = lipgloss.NewStyle().Foreground(theme.Error).Render("● Download failed: " + s.err.Error())
+	case s.done && m.response.Download != nil:
+		state = lipgloss.NewStyle().Foreground(theme.Success).Render("● Download complete")
+	case s.done:
+		state = lipgloss.NewStyle().Foreground(theme.DarkenText).Render("● Download stopped")
+	default:
+		state = lipgloss.NewStyle().Foreground(theme.Primary).Render("● Downloading")
+	}
+
+	if s.total <= 0 {
+		return state + fieldTextStyle.Render("  "+progress_bar_component.FormatBytes(s.received)) + "\n\n"
+	}
+
+	width := max(10, min(40, terminal_utility.GetTerminalWidth(9999)-60))
+	bar := progress_bar_component.Render(s.received, s.total, width)
+	return state + "  " + bar + fieldTextStyle.Render("  "+progress_bar_component.FormatBytes(s.received)+" / "+progress_bar_component.FormatBytes(s.total)) + "\n\n"
+}
+
+// download_Summary_Render replaces the body of a saved download, it is on
+// disk and not in memory.
+func download_Summary_Render(m *Model) string {
+	download := m.response.Download
+	fieldTextStyle := lipgloss.NewStyle().Foreground(theme.Primary)
+
+	content := fieldTextStyle.Render("Saved to: ") + download.Path + "\n"
+	content += fieldTextStyle.Render("Size: ") + progress_bar_component.FormatBytes(download.Size) + "\n"
+	if download.ContentType != "" {
+		content += fieldTextStyle.Render("Content-Type: ") + download.ContentType + "\n"
+	}
+	if download.ResumedFrom > 0 {
+		content += fieldTextStyle.Render("Resumed from: ") + progress_bar_component.FormatBytes(download.ResumedFrom) + "\n"
+	}
+	if download.Checksum != "" {
+		verified := lipgloss.NewStyle().Foreground(theme.DarkenText).Render(" (not verified)")
+		if download.Verified {
+			verified = lipgloss.NewStyle().Foreground(theme.Success).Render(" (verified)")
+		}
+		content += fieldTextStyle.Render("Checksum: ") + download.Checksum + verified + "\n"
+	}
+	return strings.TrimRight(content, "\n")
+}
+
+// binary_Summary_Render stands in for a body that would only garble the
+// terminal.
+func binary_Summary_Render(m *Model, contentType string) string {
+	fieldTextStyle := lipgloss.NewStyle().Foreground(theme.Primary)
+	greyTextStyle := lipgloss.NewStyle().Foreground(theme.DarkenText)
+
+	if contentType == "" {
+		contentType = "unknown"
+	}
+	content := fieldTextStyle.Render("Binary response") + "\n"
+	content += fieldTextStyle.Render("Content-Type: ") + contentType + "\n"
+	content += fieldTextStyle.Render("Size: ") + progress_bar_component.FormatBytes(int64(len(m.response.Result))) + "\n\n"
+	content += greyTextStyle.Render("Use --download to save it to a file.")
+	return content
+}
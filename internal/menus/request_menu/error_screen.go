@@ -0,0 +1,49 @@
+package request_menu
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	logoascii "github.com/diogopereiradev/httpzen/internal/utils/logo_ascii"
+	"github.com/diogopereiradev/httpzen/internal/utils/terminal_utility"
+	"github.com/diogopereiradev/httpzen/internal/utils/theme"
+)
+
+func error_screen_Render(m *Model) string {
+	var content string
+
+	width := terminal_utility.GetTerminalWidth(9999)
+
+	titleStyle := lipgloss.NewStyle().Foreground(theme.LightText).Background(theme.Error).Bold(true).Padding(0, 2)
+	fieldTextStyle := lipgloss.NewStyle().Foreground(theme.Secondary)
+	greyTextStyle := lipgloss.NewStyle().Foreground(theme.DarkenText)
+	messageStyle := lipgloss.NewStyle().Width(width).Background(theme.CodeBlock).Padding(1, 1)
+
+	if !m.config.HideLogomark {
+		content += lipgloss.NewStyle().Foreground(theme.Error).Render(logoascii.GetLogo(".request")) + "\n"
+	}
+
+	if m.watch != nil {
+		content += watch_Render(m) + "\n\n"
+	}
+	content += titleStyle.Render(m.err.Title()) + "\n\n"
+	content += fieldTextStyle.Render("Request: ") + m.response.Request.Method + " " + m.response.Request.Url + "\n"
+	content += fieldTextStyle.Render("Failure class: ") + string(m.err.Class) + "\n"
+	content += fieldTextStyle.Render("Exit code: ") + fmt.Sprintf("%d", request_module.ExitCode(m.err))
+	content += basic_infos_attempts_Render(m.response.Attempts) + "\n\n"
+
+	if m.err.Err != nil {
+		content += messageStyle.Render(ansi.Wrap(m.err.Err.Error(), width-2, "")) + "\n\n"
+	}
+
+	content += fieldTextStyle.Render("Suggested fix: ") + m.err.Suggestion()
+	if m.watch != nil {
+		content += greyTextStyle.Render("\n\nPress 'r' to retry now, 'p' to pause/resume the watch, 'q' to quit.\n")
+	} else {
+		content += greyTextStyle.Render("\n\nPress 'r' to retry the request, 'q' to quit.\n")
+	}
+
+	return content
+}
@@ -0,0 +1,24 @@
+package request_menu
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	har_module "github.com/diogopereiradev/httpzen/internal/har"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+)
+
+var ExportHarFunc = func(path string, res request_module.RequestResponse) error {
+	return har_module.Write(path, har_module.Export([]request_module.RequestResponse{res}))
+}
+var Now = time.Now
+
+// export_Har writes the response to a HAR file in the working directory,
+// named after the time so repeated exports don't overwrite each other.
+func export_Har(m *Model) tea.Cmd {
+	path := "httpzen-" + Now().Format("20060102-150405") + ".har"
+	if err := ExportHarFunc(path, *m.response); err != nil {
+		return m.clipboardTimedMessage.Show("Could not export the HAR file: "+err.Error(), 2*time.Second)
+	}
+	return m.clipboardTimedMessage.Show("Exported to "+path, 2*time.Second)
+}
@@ -0,0 +1,20 @@
+package request_menu
+
+import (
+	"context"
+
+	grpc_module "github.com/diogopereiradev/httpzen/internal/grpc"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+)
+
+var GrpcCallFunc = grpc_module.Call
+
+// NewGrpc makes a gRPC call in the response viewer. Messages of a
+// server-streaming call are appended as they arrive, like the events of a
+// streamed HTTP response, and 'r' makes the call again.
+func NewGrpc(options grpc_module.CallOptions) error {
+	run := func(ctx context.Context, handlers request_module.StreamHandlers) (request_module.RequestResponse, error) {
+		return GrpcCallFunc(ctx, options, handlers)
+	}
+	return stream_Open(options.RequestOptions(), run, "messages", Options{})
+}
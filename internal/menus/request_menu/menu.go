@@ -12,23 +12,52 @@ import (
 	"github.com/diogopereiradev/httpzen/internal/components/number_prompt"
 	timed_message_component "github.com/diogopereiradev/httpzen/internal/components/timed_message"
 	config_module "github.com/diogopereiradev/httpzen/internal/config"
+	diff_module "github.com/diogopereiradev/httpzen/internal/diff"
 	logger_module "github.com/diogopereiradev/httpzen/internal/logger"
 	"github.com/diogopereiradev/httpzen/internal/menus/benchmark_menu"
+	openapi_module "github.com/diogopereiradev/httpzen/internal/openapi"
 	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	script_module "github.com/diogopereiradev/httpzen/internal/script"
 	logoascii "github.com/diogopereiradev/httpzen/internal/utils/logo_ascii"
 	"github.com/diogopereiradev/httpzen/internal/utils/terminal_utility"
 	"github.com/diogopereiradev/httpzen/internal/utils/theme"
 )
 
+// Options add to what the viewer shows around the response, the zero value
+// shows the response alone.
+type Options struct {
+	// Validate checks every response shown, refetched ones included, and
+	// adds the Validation tab. It is set by --validate.
+	Validate func(res request_module.RequestResponse) openapi_module.Validation
+	// Script runs the post-response script on every response shown,
+	// refetched ones included, and adds the Script Log tab.
+	Script func(res request_module.RequestResponse) script_module.Result
+	// Variables returns the session variables of a collection run, shown in
+	// the Variables tab.
+	Variables func() map[string]string
+	// Watch is set by --watch.
+	Watch *WatchOptions
+	// Baseline is set by `snapshot diff`, the viewer then opens on the Diff
+	// tab.
+	Baseline *Baseline
+}
+
 type Model struct {
 	activeTab tab
 
 	config   *config_module.Config
+	options  Options
 	response *request_module.RequestResponse
+	err      *request_module.RequestError
+	// validation is nil when no validator is set.
+	validation *openapi_module.Validation
+	// script is nil when the request has no scripts.
+	script *script_module.Result
 
 	clipboardTimedMessage *timed_message_component.TimedMessage
 
 	isRefetching bool
+	stream       *streamState
 
 	resultScrollOffset int
 	resultLinesAmount  int
@@ -44,6 +73,27 @@ type Model struct {
 
 	respHeadersScrollOffset int
 	respHeadersLinesAmount  int
+
+	validationScrollOffset int
+	validationLinesAmount  int
+
+	variablesScrollOffset int
+	variablesLinesAmount  int
+
+	scriptScrollOffset int
+	scriptLinesAmount  int
+
+	// previous is the response the Diff tab compares to, diff is nil until
+	// there is one.
+	previous      *request_module.RequestResponse
+	previousLabel string
+	diff          *diff_module.Result
+
+	diffScrollOffset int
+	diffLinesAmount  int
+
+	// watch is nil unless options.Watch is set.
+	watch *watchState
 }
 
 var Exit = os.Exit
@@ -60,25 +110,49 @@ var RunProgram = func(p *tea.Program) (tea.Model, error) {
 	return p.Run()
 }
 
-func initialModel(res *request_module.RequestResponse, config *config_module.Config) Model {
-	return Model{
+func initialModel(res *request_module.RequestResponse, err error, config *config_module.Config, options Options) Model {
+	model := Model{
 		config:                config,
+		options:               options,
 		activeTab:             tab_Result,
 		response:              res,
+		err:                   request_module.ClassifyError(err),
 		isRefetching:          false,
 		clipboardTimedMessage: timed_message_component.New(),
 	}
+	if options.Validate != nil && res != nil && err == nil {
+		validation := options.Validate(*res)
+		model.validation = &validation
+	}
+	if options.Script != nil && res != nil && err == nil {
+		script := options.Script(*res)
+		model.script = &script
+	}
+	return model
 }
 
-func New(res *request_module.RequestResponse) {
+// New opens the response viewer, or the error screen when err is set, and
+// returns the error of the last attempt once the user quits so the caller
+// can pick an exit code.
+func New(res *request_module.RequestResponse, err error, options Options) error {
 	config := config_module.GetConfig()
-	model := initialModel(res, &config)
+	model := initialModel(res, err, &config, options)
+	if options.Watch != nil {
+		model.watch = watch_New(options.Watch, res, err)
+	}
+	if options.Baseline != nil {
+		diff_Set(&model, &options.Baseline.Response, options.Baseline.Label)
+		if model.diff != nil {
+			model.activeTab = tab_Diff
+		}
+	}
 
 	p := TeaNewProgram(&model)
 	TermClear()
 
-	if _, err := RunProgram(p); err != nil {
-		LoggerError("Error on rendering the program: "+err.Error(), 70)
+	finalModel, runErr := RunProgram(p)
+	if runErr != nil {
+		LoggerError("Error on rendering the program: "+runErr.Error(), 70)
 		Exit(1)
 	}
 
@@ -86,9 +160,23 @@ func New(res *request_module.RequestResponse) {
 		StartBenchmark(*BenchmarkRequestToRun)
 		BenchmarkRequestToRun = nil
 	}
+
+	if m, ok := finalModel.(*Model); ok && m.err != nil {
+		return m.err
+	}
+	if finalModel == nil && model.err != nil {
+		return model.err
+	}
+	return nil
 }
 
 func (m *Model) Init() tea.Cmd {
+	if m.stream != nil {
+		return stream_Start(m)
+	}
+	if m.watch != nil {
+		return watch_Tick(m)
+	}
 	return nil
 }
 
@@ -100,10 +188,21 @@ func (m *Model) View() string {
 		return content
 	}
 
+	if m.err != nil {
+		return error_screen_Render(m)
+	}
+
+	if m.stream != nil && !m.stream.started {
+		return stream_Waiting_Render(m)
+	}
+
 	if !m.config.HideLogomark {
 		content += lipgloss.NewStyle().Foreground(theme.Primary).Render(logoascii.GetLogo(".request")) + "\n"
 	}
 
+	if m.watch != nil {
+		content += watch_Render(m) + "\n\n"
+	}
 	content += tab_Render(m)
 	content += "\n\n"
 
@@ -118,8 +217,16 @@ func (m *Model) View() string {
 		content += request_headers_Render_Paged(m)
 	case tab_ResponseHeaders:
 		content += response_headers_Render_Paged(m)
+	case tab_Validation:
+		content += validation_Render_Paged(m)
+	case tab_Variables:
+		content += variables_Render_Paged(m)
+	case tab_ScriptLog:
+		content += script_log_Render_Paged(m)
+	case tab_Diff:
+		content += diff_Render_Paged(m)
 	}
-	content += navigation_options_Render()
+	content += navigation_options_Render(m)
 
 	if m.clipboardTimedMessage != nil && m.clipboardTimedMessage.Visible {
 		dialogMsg := m.clipboardTimedMessage.Render()
@@ -134,12 +241,32 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// Events
 	switch ev := msg.(type) {
 	case RefetchEvent:
-		model := initialModel(&ev.Response, m.config)
-		model.activeTab = m.activeTab
+		model := initialModel(&ev.Response, ev.Err, m.config, m.options)
+		diff_Next(m, &model)
+		for _, t := range model.tabs() {
+			if t == m.activeTab {
+				model.activeTab = m.activeTab
+			}
+		}
 		model.isRefetching = false
 		m.isRefetching = false
 		m = &model
 		return m, nil
+	case watchTickEvent:
+		if m.watch == nil {
+			return m, nil
+		}
+		return m, watch_OnTick(m, ev)
+	case watchEvent:
+		if m.watch == nil {
+			return m, nil
+		}
+		return m, watch_Apply(m, ev)
+	case streamStartEvent, streamDataEvent, streamProgressEvent, streamDoneEvent:
+		if m.stream == nil {
+			return m, nil
+		}
+		return m, stream_Update(m, msg)
 	}
 
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
@@ -149,19 +276,37 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if keyMsg.String() == "q" {
 				return m, tea.Quit
 			}
+			if keyMsg.String() == "r" && m.stream != nil {
+				return m, stream_Start(m)
+			}
+			if keyMsg.String() == "r" && m.watch != nil {
+				return m, watch_Fetch(m)
+			}
+			if keyMsg.String() == "p" && m.watch != nil {
+				return m, watch_TogglePause(m)
+			}
 			if keyMsg.String() == "r" {
 				m.isRefetching = true
+				options := refetch_Options(m)
 				return m, func() tea.Msg {
-					res := RunRequestFunc(request_module.RequestOptions{
-						Url:     m.response.Request.Url,
-						Headers: m.response.Request.Headers,
-						Method:  m.response.Request.Method,
-						Timeout: m.response.Request.Timeout,
-						Body:    m.response.Request.Body,
-					})
-					return RefetchEvent{Response: res}
+					res, err := RunRequestFunc(options)
+					return RefetchEvent{Response: res, Err: err}
 				}
 			}
+			if m.err != nil {
+				return m, nil
+			}
+			if m.stream != nil && !m.stream.started {
+				return m, nil
+			}
+			if keyMsg.String() == "p" && m.stream != nil && !m.stream.done {
+				stream_TogglePause(m)
+				return m, nil
+			}
+			if keyMsg.String() == "f" && m.stream != nil {
+				stream_ToggleFollow(m)
+				return m, nil
+			}
 			if keyMsg.String() == "c" {
 				err := ClipboardWriteAll(m.response.Result)
 				if err != nil {
@@ -170,7 +315,10 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, m.clipboardTimedMessage.Show("Request response copied", 1*time.Second)
 				}
 			}
-			if keyMsg.String() == "b" {
+			if keyMsg.String() == "e" && (m.stream == nil || m.stream.done) {
+				return m, export_Har(m)
+			}
+			if keyMsg.String() == "b" && m.stream == nil {
 				BenchmarkRequestToRun = &m.response.Request
 				return m, tea.Quit
 			}
@@ -187,6 +335,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			switch m.activeTab {
 			case tab_Result:
 				result_viewport_ScrollUp(m)
+				stream_StopFollowing(m)
 			case tab_RequestInfos:
 				basic_infos_ScrollUp(m)
 			case tab_NetworkInfos:
@@ -195,6 +344,14 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				request_headers_ScrollUp(m)
 			case tab_ResponseHeaders:
 				response_headers_ScrollUp(m)
+			case tab_Validation:
+				validation_ScrollUp(m)
+			case tab_Variables:
+				variables_ScrollUp(m)
+			case tab_ScriptLog:
+				script_log_ScrollUp(m)
+			case tab_Diff:
+				diff_ScrollUp(m)
 			}
 		case tea.KeyDown:
 			switch m.activeTab {
@@ -208,11 +365,20 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				request_headers_ScrollDown(m)
 			case tab_ResponseHeaders:
 				response_headers_ScrollDown(m)
+			case tab_Validation:
+				validation_ScrollDown(m)
+			case tab_Variables:
+				variables_ScrollDown(m)
+			case tab_ScriptLog:
+				script_log_ScrollDown(m)
+			case tab_Diff:
+				diff_ScrollDown(m)
 			}
 		case tea.KeyPgUp:
 			switch m.activeTab {
 			case tab_Result:
 				result_viewport_ScrollPgUp(m)
+				stream_StopFollowing(m)
 			case tab_RequestInfos:
 				basic_infos_ScrollPgUp(m)
 			case tab_NetworkInfos:
@@ -221,6 +387,14 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				request_headers_ScrollPgUp(m)
 			case tab_ResponseHeaders:
 				response_headers_ScrollPgUp(m)
+			case tab_Validation:
+				validation_ScrollPgUp(m)
+			case tab_Variables:
+				variables_ScrollPgUp(m)
+			case tab_ScriptLog:
+				script_log_ScrollPgUp(m)
+			case tab_Diff:
+				diff_ScrollPgUp(m)
 			}
 		case tea.KeyPgDown:
 			switch m.activeTab {
@@ -234,6 +408,14 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				request_headers_ScrollPgDown(m)
 			case tab_ResponseHeaders:
 				response_headers_ScrollPgDown(m)
+			case tab_Validation:
+				validation_ScrollPgDown(m)
+			case tab_Variables:
+				variables_ScrollPgDown(m)
+			case tab_ScriptLog:
+				script_log_ScrollPgDown(m)
+			case tab_Diff:
+				diff_ScrollPgDown(m)
 			}
 		}
 	}
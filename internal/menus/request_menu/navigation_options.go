@@ -5,12 +5,18 @@ import (
 	"github.com/diogopereiradev/httpzen/internal/utils/theme"
 )
 
-func navigation_options_Render() string {
+func navigation_options_Render(m *Model) string {
 	var content string
 	greyTextStyle := lipgloss.NewStyle().Foreground(theme.DarkenText)
 
 	content += greyTextStyle.Render("\n\nUse left/right arrows to navigate between tabs, 'q' to quit.")
-	content += greyTextStyle.Render("\n'c' to copy response, 'b' to benchmark, and 'r' to resend request.\n")
+	if m.watch != nil {
+		content += greyTextStyle.Render("\n'p' to pause/resume the watch, 'r' to resend now, 'c' to copy response and 'e' to export as HAR.\n")
+	} else if m.stream != nil {
+		content += greyTextStyle.Render("\n'p' to pause/resume, 'f' to follow new output, 'c' to copy, 'e' to export as HAR once done and 'r' to restart the stream.\n")
+	} else {
+		content += greyTextStyle.Render("\n'c' to copy response, 'e' to export as HAR, 'b' to benchmark, and 'r' to resend request.\n")
+	}
 
 	return content
 }
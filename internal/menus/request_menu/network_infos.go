@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
 	"github.com/diogopereiradev/httpzen/internal/utils/terminal_utility"
 	"github.com/diogopereiradev/httpzen/internal/utils/theme"
 )
@@ -18,7 +19,11 @@ func network_infos_Render(m *Model) string {
 	for _, info := range m.response.IpInfos {
 		var block string
 		block += fieldTextStyle.Render("Protocol: ") + info.Type + "\n"
-		block += fieldTextStyle.Render("IP Address: ") + info.Ip + "\n"
+		if info.Type == "Unix socket" {
+			block += fieldTextStyle.Render("Path: ") + info.Ip + "\n"
+		} else {
+			block += fieldTextStyle.Render("IP Address: ") + info.Ip + "\n"
+		}
 
 		if info.Country != "" {
 			block += fieldTextStyle.Render("Country: ") + info.Country + "\n"
@@ -48,10 +53,71 @@ func network_infos_Render(m *Model) string {
 		blocks = append(blocks, block)
 	}
 
+	if len(m.response.IpResults) > 0 {
+		content += ip_results_Render(m) + "\n\n"
+	}
 	content += strings.TrimRight(strings.Join(blocks, "\n\n"), "\n ")
 	return content
 }
 
+// ip_results_Render lays the per address results of --all-ips side by side.
+// Rows whose status or body differ from the most common one are highlighted,
+// that's the node to look at.
+func ip_results_Render(m *Model) string {
+	titleStyle := lipgloss.NewStyle().Foreground(theme.Primary).Bold(true)
+	headerStyle := lipgloss.NewStyle().Foreground(theme.Secondary)
+	errorStyle := lipgloss.NewStyle().Foreground(theme.Error)
+
+	results := m.response.IpResults
+	counts := map[string]int{}
+	common := ""
+	for _, result := range results {
+		key := ip_results_Key(result)
+		counts[key]++
+		if counts[key] > counts[common] {
+			common = key
+		}
+	}
+
+	ipWidth := len("IP Address")
+	for _, result := range results {
+		ipWidth = max(ipWidth, len(result.Ip))
+	}
+	row := func(ip, status, time, size, hash string) string {
+		return fmt.Sprintf("%-*s  %-8s %-10s %-10s %s", ipWidth, ip, status, time, size, hash)
+	}
+
+	lines := []string{
+		titleStyle.Render(fmt.Sprintf("All addresses (%d)", len(results))),
+		headerStyle.Render(row("IP Address", "Status", "Time", "Size", "Body hash")),
+	}
+	for _, result := range results {
+		if result.Error != "" {
+			lines = append(lines, errorStyle.Render(fmt.Sprintf("%-*s  %s", ipWidth, result.Ip, result.Error)))
+			continue
+		}
+		line := row(
+			result.Ip,
+			fmt.Sprintf("%d", result.StatusCode),
+			fmt.Sprintf("%.0fms", result.ExecutionTime),
+			fmt.Sprintf("%d B", result.Size),
+			result.BodyHash,
+		)
+		if len(counts) > 1 && ip_results_Key(result) != common {
+			line = errorStyle.Render(line + "  (differs)")
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func ip_results_Key(result request_module.IpResult) string {
+	if result.Error != "" {
+		return "error"
+	}
+	return fmt.Sprintf("%d %s", result.StatusCode, result.BodyHash)
+}
+
 func network_infos_Render_Paged(m *Model) string {
 	content := network_infos_Render(m)
 	lines := strings.Split(content, "\n")
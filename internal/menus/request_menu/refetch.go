@@ -10,6 +10,27 @@ import (
 
 type RefetchEvent struct {
 	Response request_module.RequestResponse
+	Err      error
+}
+
+// refetch_Options is the request of the response shown, to send it again.
+func refetch_Options(m *Model) request_module.RequestOptions {
+	return request_module.RequestOptions{
+		Url:          m.response.Request.Url,
+		Headers:      m.response.Request.Headers,
+		Method:       m.response.Request.Method,
+		CustomMethod: m.response.Request.CustomMethod,
+		Timeout:      m.response.Request.Timeout,
+		Body:         m.response.Request.Body,
+		Auth:         m.response.Request.Auth,
+		Protocol:     m.response.Request.Protocol,
+		UnixSocket:   m.response.Request.UnixSocket,
+		Resolve:      m.response.Request.Resolve,
+		ConnectTo:    m.response.Request.ConnectTo,
+		AllIps:       m.response.Request.AllIps,
+		Retry:        m.response.Request.Retry,
+		Timeouts:     m.response.Request.Timeouts,
+	}
 }
 
 func refetch_Render(m *Model) string {
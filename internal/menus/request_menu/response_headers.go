@@ -32,6 +32,30 @@ func response_headers_Render(m *Model) string {
 			content += "\n"
 		}
 	}
+	content += response_trailers_Render(m)
+	return content
+}
+
+func response_trailers_Render(m *Model) string {
+	keys := make([]string, 0, len(m.response.Trailers))
+	for key, value := range m.response.Trailers {
+		if len(value) > 0 {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+
+	sort.Strings(keys)
+
+	keyTextStyle := lipgloss.NewStyle().Foreground(theme.Primary)
+	fieldTextStyle := lipgloss.NewStyle().Foreground(theme.Secondary)
+
+	content := "\n\n" + fieldTextStyle.Render("Trailers:")
+	for _, key := range keys {
+		content += "\n" + ansi.Wrap(keyTextStyle.Render(key)+": "+m.response.Trailers[key][0], terminal_utility.GetTerminalWidth(9999), "")
+	}
 	return content
 }
 
@@ -3,9 +3,11 @@ package request_menu
 import (
 	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/x/ansi"
+	graphql_module "github.com/diogopereiradev/httpzen/internal/graphql"
 	"github.com/diogopereiradev/httpzen/internal/utils/html_formatter"
 	"github.com/diogopereiradev/httpzen/internal/utils/http_utility"
 	"github.com/diogopereiradev/httpzen/internal/utils/json_formatter"
@@ -19,19 +21,42 @@ func result_viewport_Render(m *Model) string {
 	fieldTextStyle := lipgloss.NewStyle().Foreground(theme.Secondary)
 	maxLines := terminal_utility.GetTerminalHeight(9999) - 16
 
-	if m.response.Result != "" {
+	if m.stream != nil {
+		result += stream_Status_Render(m)
+		maxLines -= 2
+	}
+
+	responseType := m.response.Headers.Get("Content-Type")
+
+	if m.response.Download != nil {
+		result += download_Summary_Render(m)
+	} else if m.stream == nil && m.response.Result != "" &&
+		(http_utility.IsBinaryContentType(responseType) || !utf8.ValidString(m.response.Result)) {
+		result += binary_Summary_Render(m, responseType)
+	} else if m.response.Result != "" {
 		var formatted string
-		contentType := http_utility.DetectContentType(m.response.Result)
+		body := m.response.Result
+		contentType := http_utility.DetectContentType(body)
+
+		// GraphQL answers 200 with an "errors" member, show those apart so
+		// they are not buried in the data.
+		if _, _, _, isGraphQL := http_utility.GetGraphQLBody(m.response.Request.Body); isGraphQL {
+			if gqlResponse, ok := graphql_module.ParseResponse(body); ok && len(gqlResponse.Errors) > 0 {
+				formatted = graphql_errors_Render(gqlResponse.Errors) + "\n"
+				body = gqlResponse.Data
+				contentType = "json"
+			}
+		}
 
 		switch contentType {
 		case "json":
-			formatted = ansi.Wrap(json_formatter.FormatJSON(m.response.Result), terminal_utility.GetTerminalWidth(9999), "")
+			formatted += ansi.Wrap(json_formatter.FormatJSON(body), terminal_utility.GetTerminalWidth(9999), "")
 		case "html":
-			formatted = ansi.Wrap(html_formatter.FormatHTML(m.response.Result), terminal_utility.GetTerminalWidth(9999), "")
+			formatted += ansi.Wrap(html_formatter.FormatHTML(body), terminal_utility.GetTerminalWidth(9999), "")
 		case "xml":
-			formatted = ansi.Wrap(html_formatter.FormatHTML(m.response.Result), terminal_utility.GetTerminalWidth(9999), "")
+			formatted += ansi.Wrap(html_formatter.FormatHTML(body), terminal_utility.GetTerminalWidth(9999), "")
 		default:
-			formatted = ansi.Wrap(m.response.Result, terminal_utility.GetTerminalWidth(9999), "")
+			formatted += ansi.Wrap(body, terminal_utility.GetTerminalWidth(9999), "")
 		}
 
 		lines := strings.Split(formatted, "\n")
@@ -50,12 +75,28 @@ func result_viewport_Render(m *Model) string {
 		if total > maxLines {
 			result += fieldTextStyle.Render(fmt.Sprintf("\n[%d-%d/%d lines] Use ↑/↓ or PgUp/PgDown to scroll.", m.resultScrollOffset+1, end, total))
 		}
+	} else if m.response.Request.Download != nil {
+		result += fieldTextStyle.Render("The body is being saved to disk.")
+	} else if m.stream != nil {
+		result += fieldTextStyle.Render("No events received yet.")
 	} else {
 		result += fieldTextStyle.Render("No response available.")
 	}
 	return result
 }
 
+func graphql_errors_Render(errors []graphql_module.ResponseError) string {
+	titleStyle := lipgloss.NewStyle().Foreground(theme.Error).Bold(true)
+	errorStyle := lipgloss.NewStyle().Foreground(theme.Error)
+
+	lines := []string{titleStyle.Render(fmt.Sprintf("Errors (%d)", len(errors)))}
+	for _, e := range errors {
+		lines = append(lines, ansi.Wrap(errorStyle.Render("• "+e.Format()), terminal_utility.GetTerminalWidth(9999), ""))
+	}
+	lines = append(lines, titleStyle.Render("Data"))
+	return strings.Join(lines, "\n")
+}
+
 func result_viewport_ScrollUp(m *Model) {
 	if m.resultScrollOffset > 0 {
 		m.resultScrollOffset--
@@ -0,0 +1,99 @@
+package request_menu
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/diogopereiradev/httpzen/internal/utils/terminal_utility"
+	"github.com/diogopereiradev/httpzen/internal/utils/theme"
+)
+
+func script_log_Render(m *Model) string {
+	successStyle := lipgloss.NewStyle().Foreground(theme.Success).Bold(true)
+	errorStyle := lipgloss.NewStyle().Foreground(theme.Error).Bold(true)
+	warnStyle := lipgloss.NewStyle().Foreground(theme.Warn)
+	keyTextStyle := lipgloss.NewStyle().Foreground(theme.Primary)
+	greyTextStyle := lipgloss.NewStyle().Foreground(theme.DarkenText)
+	width := terminal_utility.GetTerminalWidth(9999)
+
+	var lines []string
+	if len(m.script.Tests) > 0 {
+		passed := 0
+		for _, test := range m.script.Tests {
+			if test.Passed {
+				passed++
+				lines = append(lines, successStyle.Render("✓ ")+test.Name)
+			} else {
+				lines = append(lines, ansi.Wrap(errorStyle.Render("✗ ")+test.Name+": "+test.Message, width, ""))
+			}
+		}
+		lines = append(lines, greyTextStyle.Render(fmt.Sprintf("%d/%d tests passed", passed, len(m.script.Tests))), "")
+	}
+	for _, message := range m.script.Errors {
+		lines = append(lines, ansi.Wrap(errorStyle.Render(message), width, ""))
+	}
+
+	if len(m.script.Logs) == 0 {
+		lines = append(lines, greyTextStyle.Render("The scripts printed nothing."))
+	}
+	for _, entry := range m.script.Logs {
+		prefix := keyTextStyle.Render("[" + string(entry.Phase) + "]")
+		message := entry.Message
+		switch entry.Level {
+		case "warn":
+			message = warnStyle.Render(message)
+		case "error":
+			message = errorStyle.Render(message)
+		}
+		lines = append(lines, ansi.Wrap(prefix+" "+message, width, ""))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func script_log_Render_Paged(m *Model) string {
+	content := script_log_Render(m)
+	lines := strings.Split(content, "\n")
+
+	m.scriptLinesAmount = len(lines)
+
+	maxLines := terminal_utility.GetTerminalHeight(9999) - 16
+	start := min(m.scriptScrollOffset, len(lines))
+	end := min(start+maxLines, len(lines))
+
+	result := strings.Join(lines[start:end], "\n")
+
+	if len(lines) > maxLines {
+		keyTextStyle := lipgloss.NewStyle().Foreground(theme.Secondary)
+		result += keyTextStyle.Render(fmt.Sprintf("\n[%d-%d/%d lines] Use ↑/↓ or PgUp/PgDown to scroll.", start+1, end, len(lines)))
+	}
+
+	return result
+}
+
+func script_log_ScrollUp(m *Model) {
+	if m.scriptScrollOffset > 0 {
+		m.scriptScrollOffset--
+	}
+}
+
+func script_log_ScrollDown(m *Model) {
+	maxLines := terminal_utility.GetTerminalHeight(9999) - 16
+	if m.scriptLinesAmount <= maxLines || m.scriptScrollOffset+maxLines >= m.scriptLinesAmount {
+		return
+	}
+	m.scriptScrollOffset++
+}
+
+func script_log_ScrollPgUp(m *Model) {
+	m.scriptScrollOffset = max(m.scriptScrollOffset-5, 0)
+}
+
+func script_log_ScrollPgDown(m *Model) {
+	maxLines := terminal_utility.GetTerminalHeight(9999) - 16
+	if m.scriptLinesAmount <= maxLines {
+		return
+	}
+	m.scriptScrollOffset = min(m.scriptScrollOffset+5, m.scriptLinesAmount-maxLines)
+}
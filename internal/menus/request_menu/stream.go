@@ -0,0 +1,338 @@
+package request_menu
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	config_module "github.com/diogopereiradev/httpzen/internal/config"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	logoascii "github.com/diogopereiradev/httpzen/internal/utils/logo_ascii"
+	"github.com/diogopereiradev/httpzen/internal/utils/terminal_utility"
+	"github.com/diogopereiradev/httpzen/internal/utils/theme"
+)
+
+// streamRunner sends the request and reports its progress through handlers
+// until it ends or ctx is canceled.
+type streamRunner func(ctx context.Context, handlers request_module.StreamHandlers) (request_module.RequestResponse, error)
+
+type streamState struct {
+	run  streamRunner
+	unit string
+
+	id       int
+	messages chan tea.Msg
+	cancel   context.CancelFunc
+
+	started  bool
+	done     bool
+	paused   bool
+	follow   bool
+	count    int
+	buffered []request_module.StreamEvent
+	err      *request_module.RequestError
+
+	// lines are the formatted events shown, dropped counts the ones left
+	// out by request_module.StreamEventLimit.
+	lines   []string
+	dropped int
+
+	// received and total are the bytes of a download, total is -1 when the
+	// server didn't send a length.
+	received int64
+	total    int64
+}
+
+type streamStartEvent struct {
+	id       int
+	Response request_module.RequestResponse
+}
+
+type streamDataEvent struct {
+	id    int
+	Event request_module.StreamEvent
+}
+
+type streamProgressEvent struct {
+	id       int
+	Received int64
+	Total    int64
+}
+
+type streamDoneEvent struct {
+	id       int
+	Response request_module.RequestResponse
+	Err      error
+}
+
+var RunStreamRequestFunc = request_module.RunStreamRequest
+
+// NewStream opens the response viewer before the response arrives and
+// appends every event or line to the Response tab as it is received.
+func NewStream(options request_module.RequestOptions, viewer Options) error {
+	run := func(ctx context.Context, handlers request_module.StreamHandlers) (request_module.RequestResponse, error) {
+		return RunStreamRequestFunc(ctx, options, handlers)
+	}
+	return stream_Open(options, run, "events", viewer)
+}
+
+func stream_Open(options request_module.RequestOptions, run streamRunner, unit string, viewer Options) error {
+	config := config_module.GetConfig()
+	res := request_module.RequestResponse{Request: options}
+	model := initialModel(&res, nil, &config, viewer)
+	model.stream = &streamState{run: run, unit: unit, follow: true}
+
+	p := TeaNewProgram(&model)
+	TermClear()
+
+	finalModel, runErr := RunProgram(p)
+	if runErr != nil {
+		LoggerError("Error on rendering the program: "+runErr.Error(), 70)
+		Exit(1)
+	}
+
+	m, ok := finalModel.(*Model)
+	if !ok {
+		m = &model
+	}
+	stream_Stop(m)
+
+	if BenchmarkRequestToRun != nil {
+		StartBenchmark(*BenchmarkRequestToRun)
+		BenchmarkRequestToRun = nil
+	}
+
+	if m.err != nil {
+		return m.err
+	}
+	if m.stream != nil && m.stream.err != nil {
+		return m.stream.err
+	}
+	return nil
+}
+
+// stream_Start cancels the running stream, if any, and sends the request
+// again. Messages of an old stream carry its id and are dropped once a new
+// one starts.
+func stream_Start(m *Model) tea.Cmd {
+	stream_Stop(m)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	messages := make(chan tea.Msg, 64)
+	id := m.stream.id + 1
+	run := m.stream.run
+
+	m.stream = &streamState{run: run, unit: m.stream.unit, id: id, messages: messages, cancel: cancel, follow: m.stream.follow, total: -1}
+	m.err = nil
+	m.response.Result = ""
+	m.resultScrollOffset = 0
+
+	send := func(msg tea.Msg) {
+		select {
+		case messages <- msg:
+		case <-ctx.Done():
+		}
+	}
+
+	go func() {
+		res, err := run(ctx, request_module.StreamHandlers{
+			OnStart: func(res request_module.RequestResponse) {
+				send(streamStartEvent{id: id, Response: res})
+			},
+			OnEvent: func(event request_module.StreamEvent) {
+				send(streamDataEvent{id: id, Event: event})
+			},
+			OnProgress: func(received int64, total int64) {
+				send(streamProgressEvent{id: id, Received: received, Total: total})
+			},
+		})
+		send(streamDoneEvent{id: id, Response: res, Err: err})
+		close(messages)
+	}()
+
+	return stream_Listen(messages)
+}
+
+func stream_Stop(m *Model) {
+	if m.stream != nil && m.stream.cancel != nil {
+		m.stream.cancel()
+	}
+}
+
+func stream_Listen(messages chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-messages
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+func stream_Update(m *Model, msg tea.Msg) tea.Cmd {
+	s := m.stream
+
+	switch ev := msg.(type) {
+	case streamStartEvent:
+		if ev.id != s.id {
+			return nil
+		}
+		s.started = true
+		request := m.response.Request
+		*m.response = ev.Response
+		m.response.Request = request
+
+	case streamDataEvent:
+		if ev.id != s.id {
+			return nil
+		}
+		s.count++
+		if s.paused {
+			s.buffered = append(s.buffered, ev.Event)
+			if len(s.buffered) > request_module.StreamEventLimit {
+				s.dropped += len(s.buffered) - request_module.StreamEventLimit
+				s.buffered = s.buffered[len(s.buffered)-request_module.StreamEventLimit:]
+			}
+		} else {
+			stream_Append(m, ev.Event)
+		}
+
+	case streamProgressEvent:
+		if ev.id != s.id {
+			return nil
+		}
+		s.received = ev.Received
+		s.total = ev.Total
+
+	case streamDoneEvent:
+		if ev.id != s.id {
+			return nil
+		}
+		s.done = true
+		stream_Flush(m)
+		if ev.Err != nil {
+			if s.started {
+				s.err = request_module.ClassifyError(ev.Err)
+			} else {
+				m.err = request_module.ClassifyError(ev.Err)
+			}
+		} else {
+			// A gRPC status and trailers are only known once the call ends.
+			m.response.ExecutionTime = ev.Response.ExecutionTime
+			m.response.StatusCode = ev.Response.StatusCode
+			m.response.StatusMessage = ev.Response.StatusMessage
+			m.response.Trailers = ev.Response.Trailers
+			// A download only has a body when the server answered an error.
+			if m.response.Request.Download != nil {
+				m.response.Download = ev.Response.Download
+				m.response.Result = ev.Response.Result
+			}
+		}
+		return nil
+	}
+	return stream_Listen(s.messages)
+}
+
+func stream_Append(m *Model, event request_module.StreamEvent) {
+	s := m.stream
+	s.lines = append(s.lines, request_module.FormatStreamEvent(event))
+	if len(s.lines) > request_module.StreamEventLimit {
+		s.dropped += len(s.lines) - request_module.StreamEventLimit
+		s.lines = s.lines[len(s.lines)-request_module.StreamEventLimit:]
+	}
+	m.response.Result = strings.Join(s.lines, "\n") + "\n"
+	if m.stream.follow {
+		stream_FollowBottom(m)
+	}
+}
+
+func stream_Flush(m *Model) {
+	for _, event := range m.stream.buffered {
+		stream_Append(m, event)
+	}
+	m.stream.buffered = nil
+}
+
+// stream_FollowBottom moves the viewport past the end, result_viewport_Render
+// clamps it back to the last page.
+func stream_FollowBottom(m *Model) {
+	m.resultScrollOffset = m.resultLinesAmount + len(m.response.Result)
+}
+
+func stream_TogglePause(m *Model) {
+	m.stream.paused = !m.stream.paused
+	if !m.stream.paused {
+		stream_Flush(m)
+	}
+}
+
+func stream_ToggleFollow(m *Model) {
+	m.stream.follow = !m.stream.follow
+	if m.stream.follow {
+		stream_FollowBottom(m)
+	}
+}
+
+// stream_StopFollowing lets the user scroll back through the output without
+// being pulled to the bottom by every new event.
+func stream_StopFollowing(m *Model) {
+	if m.stream != nil {
+		m.stream.follow = false
+	}
+}
+
+func stream_Status_Render(m *Model) string {
+	s := m.stream
+	fieldTextStyle := lipgloss.NewStyle().Foreground(theme.Secondary)
+	greyTextStyle := lipgloss.NewStyle().Foreground(theme.DarkenText)
+
+	if m.response.Request.Download != nil {
+		return download_Status_Render(m)
+	}
+
+	var state string
+	switch {
+	case s.err != nil:
+		state = lipgloss.NewStyle().Foreground(theme.Error).Render("● Stream failed: " + s.err.Error())
+	case s.done:
+		state = greyTextStyle.Render("● Stream closed")
+	case s.paused:
+		state = lipgloss.NewStyle().Foreground(theme.Warn).Render(fmt.Sprintf("● Paused (%d buffered)", len(s.buffered)))
+	default:
+		state = lipgloss.NewStyle().Foreground(theme.Success).Render("● Streaming")
+	}
+
+	follow := "off"
+	if s.follow {
+		follow = "on"
+	}
+
+	shown := ""
+	if s.dropped > 0 {
+		shown = fmt.Sprintf("  showing the last %d", request_module.StreamEventLimit)
+	}
+
+	return state + fieldTextStyle.Render(fmt.Sprintf("  %d %s", s.count, s.unit)) + greyTextStyle.Render(shown+"  follow: "+follow) + "\n\n"
+}
+
+func stream_Waiting_Render(m *Model) string {
+	content := lipgloss.
+		NewStyle().
+		Width(terminal_utility.GetTerminalWidth(9999)).
+		Foreground(theme.Primary).
+		Render(logoascii.GetLogo(".request"))
+
+	content += "\n\n"
+	content += lipgloss.
+		NewStyle().
+		Width(terminal_utility.GetTerminalWidth(9999)).
+		Foreground(theme.LightText).
+		Background(theme.Primary).
+		Align(lipgloss.Center).
+		Render("Waiting for the stream to start...")
+
+	content += lipgloss.NewStyle().Foreground(theme.DarkenText).Render("\n\nPress 'q' to cancel.\n")
+	return content
+}
@@ -16,6 +16,10 @@ const (
 	tab_NetworkInfos
 	tab_RequestHeaders
 	tab_ResponseHeaders
+	tab_Validation
+	tab_Variables
+	tab_ScriptLog
+	tab_Diff
 )
 
 var tabNames = []string{
@@ -24,6 +28,40 @@ var tabNames = []string{
 	"Network Infos",
 	"Request Headers",
 	"Response Headers",
+	"Validation",
+	"Variables",
+	"Script Log",
+	"Diff",
+}
+
+// tabs lists the tabs shown, the validation one only when the response was
+// validated, the variables one only during a collection run, the script log
+// one only when the request has scripts and the diff one only once there is
+// a response to compare to.
+func (m *Model) tabs() []tab {
+	tabs := []tab{tab_Result, tab_RequestInfos, tab_NetworkInfos, tab_RequestHeaders, tab_ResponseHeaders}
+	if m.validation != nil {
+		tabs = append(tabs, tab_Validation)
+	}
+	if m.options.Variables != nil {
+		tabs = append(tabs, tab_Variables)
+	}
+	if m.script != nil {
+		tabs = append(tabs, tab_ScriptLog)
+	}
+	if m.diff != nil {
+		tabs = append(tabs, tab_Diff)
+	}
+	return tabs
+}
+
+func (m *Model) tabIndex() int {
+	for i, t := range m.tabs() {
+		if t == m.activeTab {
+			return i
+		}
+	}
+	return 0
 }
 
 var activeTabBorder = lipgloss.Border{
@@ -54,8 +92,9 @@ func tab_Render(m *Model) string {
 		Padding(0, 1)
 
 	var tabLabels []string
-	for i, name := range tabNames {
-		if tab(i) == m.activeTab {
+	for _, t := range m.tabs() {
+		name := tabNames[t]
+		if t == m.activeTab {
 			tabLabels = append(tabLabels, tabStyle.Border(activeTabBorder).Foreground(theme.Primary).Render(name))
 		} else {
 			tabLabels = append(tabLabels, tabStyle.Border(tabBorder).Render(name))
@@ -79,13 +118,15 @@ func tab_Render(m *Model) string {
 }
 
 func tab_MoveLeft(m *Model) Model {
-	m.activeTab = tab((int(m.activeTab) - 1 + len(tabNames)) % len(tabNames))
+	tabs := m.tabs()
+	m.activeTab = tabs[(m.tabIndex()-1+len(tabs))%len(tabs)]
 	m.resultScrollOffset = 0
 	return *m
 }
 
 func tab_MoveRight(m *Model) Model {
-	m.activeTab = tab((int(m.activeTab) + 1) % len(tabNames))
+	tabs := m.tabs()
+	m.activeTab = tabs[(m.tabIndex()+1)%len(tabs)]
 	m.resultScrollOffset = 0
 	return *m
 }
@@ -0,0 +1,79 @@
+package request_menu
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/diogopereiradev/httpzen/internal/utils/terminal_utility"
+	"github.com/diogopereiradev/httpzen/internal/utils/theme"
+)
+
+func validation_Render(m *Model) string {
+	successStyle := lipgloss.NewStyle().Foreground(theme.Success).Bold(true)
+	errorStyle := lipgloss.NewStyle().Foreground(theme.Error).Bold(true)
+	keyTextStyle := lipgloss.NewStyle().Foreground(theme.Primary)
+	greyTextStyle := lipgloss.NewStyle().Foreground(theme.DarkenText)
+
+	if m.validation.Passed() {
+		return successStyle.Render("✓ The response matches "+m.validation.Against) + "\n" +
+			greyTextStyle.Render("Status, headers and body were checked.")
+	}
+
+	content := errorStyle.Render(fmt.Sprintf("✗ %d problems against %s", len(m.validation.Problems), m.validation.Against))
+	for _, problem := range m.validation.Problems {
+		location := problem.In
+		if problem.Pointer != "" {
+			location += " " + problem.Pointer
+		}
+		content += "\n" + ansi.Wrap(keyTextStyle.Render(location)+": "+problem.Message, terminal_utility.GetTerminalWidth(9999), "")
+	}
+	return content
+}
+
+func validation_Render_Paged(m *Model) string {
+	content := validation_Render(m)
+	lines := strings.Split(content, "\n")
+
+	m.validationLinesAmount = len(lines)
+
+	maxLines := terminal_utility.GetTerminalHeight(9999) - 16
+	start := min(m.validationScrollOffset, len(lines))
+	end := min(start+maxLines, len(lines))
+
+	result := strings.Join(lines[start:end], "\n")
+
+	if len(lines) > maxLines {
+		keyTextStyle := lipgloss.NewStyle().Foreground(theme.Secondary)
+		result += keyTextStyle.Render(fmt.Sprintf("\n[%d-%d/%d lines] Use ↑/↓ or PgUp/PgDown to scroll.", start+1, end, len(lines)))
+	}
+
+	return result
+}
+
+func validation_ScrollUp(m *Model) {
+	if m.validationScrollOffset > 0 {
+		m.validationScrollOffset--
+	}
+}
+
+func validation_ScrollDown(m *Model) {
+	maxLines := terminal_utility.GetTerminalHeight(9999) - 16
+	if m.validationLinesAmount <= maxLines || m.validationScrollOffset+maxLines >= m.validationLinesAmount {
+		return
+	}
+	m.validationScrollOffset++
+}
+
+func validation_ScrollPgUp(m *Model) {
+	m.validationScrollOffset = max(m.validationScrollOffset-5, 0)
+}
+
+func validation_ScrollPgDown(m *Model) {
+	maxLines := terminal_utility.GetTerminalHeight(9999) - 16
+	if m.validationLinesAmount <= maxLines {
+		return
+	}
+	m.validationScrollOffset = min(m.validationScrollOffset+5, m.validationLinesAmount-maxLines)
+}
@@ -0,0 +1,80 @@
+package request_menu
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/diogopereiradev/httpzen/internal/utils/terminal_utility"
+	"github.com/diogopereiradev/httpzen/internal/utils/theme"
+)
+
+func variables_Render(m *Model) string {
+	keyTextStyle := lipgloss.NewStyle().Foreground(theme.Primary)
+	greyTextStyle := lipgloss.NewStyle().Foreground(theme.DarkenText)
+
+	variables := m.options.Variables()
+	if len(variables) == 0 {
+		return greyTextStyle.Render("No variables were extracted yet.")
+	}
+
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var lines []string
+	for _, name := range names {
+		lines = append(lines, ansi.Wrap(keyTextStyle.Render(name)+" = "+variables[name], terminal_utility.GetTerminalWidth(9999), ""))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func variables_Render_Paged(m *Model) string {
+	content := variables_Render(m)
+	lines := strings.Split(content, "\n")
+
+	m.variablesLinesAmount = len(lines)
+
+	maxLines := terminal_utility.GetTerminalHeight(9999) - 16
+	start := min(m.variablesScrollOffset, len(lines))
+	end := min(start+maxLines, len(lines))
+
+	result := strings.Join(lines[start:end], "\n")
+
+	if len(lines) > maxLines {
+		keyTextStyle := lipgloss.NewStyle().Foreground(theme.Secondary)
+		result += keyTextStyle.Render(fmt.Sprintf("\n[%d-%d/%d lines] Use ↑/↓ or PgUp/PgDown to scroll.", start+1, end, len(lines)))
+	}
+
+	return result
+}
+
+func variables_ScrollUp(m *Model) {
+	if m.variablesScrollOffset > 0 {
+		m.variablesScrollOffset--
+	}
+}
+
+func variables_ScrollDown(m *Model) {
+	maxLines := terminal_utility.GetTerminalHeight(9999) - 16
+	if m.variablesLinesAmount <= maxLines || m.variablesScrollOffset+maxLines >= m.variablesLinesAmount {
+		return
+	}
+	m.variablesScrollOffset++
+}
+
+func variables_ScrollPgUp(m *Model) {
+	m.variablesScrollOffset = max(m.variablesScrollOffset-5, 0)
+}
+
+func variables_ScrollPgDown(m *Model) {
+	maxLines := terminal_utility.GetTerminalHeight(9999) - 16
+	if m.variablesLinesAmount <= maxLines {
+		return
+	}
+	m.variablesScrollOffset = min(m.variablesScrollOffset+5, m.variablesLinesAmount-maxLines)
+}
@@ -0,0 +1,193 @@
+package request_menu
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	sparkline_component "github.com/diogopereiradev/httpzen/internal/components/sparkline"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/diogopereiradev/httpzen/internal/utils/terminal_utility"
+	"github.com/diogopereiradev/httpzen/internal/utils/theme"
+	watch_module "github.com/diogopereiradev/httpzen/internal/watch"
+)
+
+// WatchOptions sends the request again every Interval, with the status
+// timeline and the latencies above the tabs. Met is set once the Until
+// conditions are met, the viewer then quits.
+type WatchOptions struct {
+	Interval time.Duration
+	Until    []watch_module.Condition
+	Met      bool
+}
+
+type watchState struct {
+	options  *WatchOptions
+	history  *watch_module.History
+	paused   bool
+	fetching bool
+	// generation changes on every resend and pause, the ticks scheduled
+	// before are then ignored.
+	generation int
+}
+
+type watchTickEvent struct {
+	generation int
+}
+
+type watchEvent struct {
+	Response request_module.RequestResponse
+	Err      error
+}
+
+func watch_New(options *WatchOptions, res *request_module.RequestResponse, err error) *watchState {
+	state := &watchState{options: options, history: watch_module.NewHistory()}
+	if res != nil {
+		state.history.Add(*res, err, time.Now())
+	}
+	return state
+}
+
+func watch_Tick(m *Model) tea.Cmd {
+	generation := m.watch.generation
+	return tea.Tick(m.watch.options.Interval, func(time.Time) tea.Msg {
+		return watchTickEvent{generation: generation}
+	})
+}
+
+// watch_Fetch sends the request now, the next one is scheduled once it is
+// back.
+func watch_Fetch(m *Model) tea.Cmd {
+	if m.watch.fetching {
+		return nil
+	}
+	m.watch.fetching = true
+	m.watch.generation++
+	options := refetch_Options(m)
+	return func() tea.Msg {
+		res, err := RunRequestFunc(options)
+		return watchEvent{Response: res, Err: err}
+	}
+}
+
+func watch_OnTick(m *Model, ev watchTickEvent) tea.Cmd {
+	if ev.generation != m.watch.generation || m.watch.paused {
+		return nil
+	}
+	return watch_Fetch(m)
+}
+
+// watch_Apply shows a new response in place, keeping the tab and the scroll
+// of the viewer.
+func watch_Apply(m *Model, ev watchEvent) tea.Cmd {
+	m.watch.fetching = false
+	m.watch.history.Add(ev.Response, ev.Err, time.Now())
+
+	model := initialModel(&ev.Response, ev.Err, m.config, m.options)
+	diff_Next(m, &model)
+	m.response, m.err, m.validation, m.script = model.response, model.err, model.validation, model.script
+	m.previous, m.previousLabel, m.diff = model.previous, model.previousLabel, model.diff
+	if m.tabIndex() == 0 {
+		m.activeTab = tab_Result
+	}
+
+	if watch_module.Met(m.watch.options.Until, ev.Response, ev.Err) {
+		m.watch.options.Met = true
+		return tea.Quit
+	}
+	if m.watch.paused {
+		return nil
+	}
+	return watch_Tick(m)
+}
+
+func watch_TogglePause(m *Model) tea.Cmd {
+	m.watch.paused = !m.watch.paused
+	m.watch.generation++
+	if m.watch.paused || m.watch.fetching {
+		return nil
+	}
+	return watch_Tick(m)
+}
+
+func watch_StatusColor(sample watch_module.Sample) lipgloss.AdaptiveColor {
+	switch {
+	case sample.Error != "" || sample.StatusCode >= 500:
+		return theme.Error
+	case sample.StatusCode >= 400:
+		return theme.Warn
+	case sample.StatusCode >= 300:
+		return theme.Secondary
+	}
+	return theme.Success
+}
+
+// watch_Render draws the state of the watch, the status of every request
+// as a timeline, with the changes as ◆, and the latencies as a sparkline.
+func watch_Render(m *Model) string {
+	fieldTextStyle := lipgloss.NewStyle().Foreground(theme.Secondary)
+	greyTextStyle := lipgloss.NewStyle().Foreground(theme.DarkenText)
+	width := max(10, terminal_utility.GetTerminalWidth(9999)-24)
+	history := m.watch.history
+
+	state := lipgloss.NewStyle().Foreground(theme.Primary).Render("● Watching every " + m.watch.options.Interval.String())
+	if m.watch.paused {
+		state = greyTextStyle.Render("❚❚ Paused")
+	}
+	if len(m.watch.options.Until) > 0 {
+		until := make([]string, 0, len(m.watch.options.Until))
+		for _, condition := range m.watch.options.Until {
+			until = append(until, condition.String())
+		}
+		state += greyTextStyle.Render(" until " + strings.Join(until, " and "))
+	}
+	last, ok := history.Last()
+	if !ok {
+		return state
+	}
+	state += greyTextStyle.Render(fmt.Sprintf(" · %d requests · last at %s", last.Attempt, last.At.Format("15:04:05")))
+	if m.watch.fetching {
+		state += greyTextStyle.Render(" · sending...")
+	}
+
+	samples := history.Samples[max(0, len(history.Samples)-width):]
+	var timeline strings.Builder
+	for _, sample := range samples {
+		mark := "█"
+		if sample.Changed() {
+			mark = "◆"
+		}
+		timeline.WriteString(lipgloss.NewStyle().Foreground(watch_StatusColor(sample)).Render(mark))
+	}
+	status := fieldTextStyle.Render("Status   ") + timeline.String() + " " +
+		lipgloss.NewStyle().Foreground(watch_StatusColor(last)).Render(last.Status())
+
+	latencies := history.Latencies()
+	lowest, highest := latencies[0], latencies[0]
+	for _, latency := range latencies {
+		lowest, highest = min(lowest, latency), max(highest, latency)
+	}
+	latency := fieldTextStyle.Render("Latency  ") + lipgloss.NewStyle().Foreground(theme.Primary).Render(sparkline_component.Render(latencies, width)) +
+		fmt.Sprintf(" %.0fms", last.Latency) + greyTextStyle.Render(fmt.Sprintf(" (min %.0fms, max %.0fms)", lowest, highest))
+
+	content := state + "\n" + status + "\n" + latency
+	if last.Changed() {
+		content += "\n" + watch_Changes_Render(m, last)
+	}
+	return content
+}
+
+// watch_Changes_Render highlights what the last request changed.
+func watch_Changes_Render(m *Model, last watch_module.Sample) string {
+	var changes []string
+	if previous, ok := m.watch.history.Previous(); ok && last.StatusChanged {
+		changes = append(changes, "status "+previous.Status()+" → "+last.Status())
+	}
+	if last.BodyChanged {
+		changes = append(changes, "body "+last.BodyHash)
+	}
+	return lipgloss.NewStyle().Foreground(theme.LightText).Background(theme.Warn).Bold(true).Padding(0, 1).
+		Render("Changed: " + strings.Join(changes, ", "))
+}
@@ -0,0 +1,144 @@
+package test_menu
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	progress_bar_component "github.com/diogopereiradev/httpzen/internal/components/progress_bar"
+	testrunner_module "github.com/diogopereiradev/httpzen/internal/testrunner"
+	"github.com/diogopereiradev/httpzen/internal/utils/terminal_utility"
+	"github.com/diogopereiradev/httpzen/internal/utils/theme"
+)
+
+func cases_Set(m *Model, index int, c testrunner_module.Case) {
+	if index < 0 || index >= len(m.cases) {
+		return
+	}
+	m.cases[index] = c
+}
+
+func cases_MaxLines(m *Model) int {
+	reserved := 8
+	if !m.config.HideLogomark {
+		reserved += 7
+	}
+	return max(3, terminal_utility.GetTerminalHeight(9999)-reserved)
+}
+
+func cases_Counts(m *Model) (finished int, passed int, failed int, skipped int) {
+	run := testrunner_module.Run{Cases: m.cases}
+	passed, failed, skipped = run.Counts()
+	return passed + failed + skipped, passed, failed, skipped
+}
+
+func progress_Render(m *Model) string {
+	fieldTextStyle := lipgloss.NewStyle().Foreground(theme.Secondary)
+	labeledStyle := lipgloss.NewStyle().Foreground(theme.LightText).Padding(0, 1).Bold(true)
+
+	finished, passed, failed, skipped := cases_Counts(m)
+	var label string
+	switch {
+	case !m.done:
+		label = labeledStyle.Background(theme.Primary).Render("Running " + m.name)
+	case failed > 0:
+		label = labeledStyle.Background(theme.Error).Render("Failed")
+	default:
+		label = labeledStyle.Background(theme.Success).Render("Passed")
+	}
+
+	content := label + "  "
+	if len(m.cases) > 0 {
+		width := max(10, min(40, terminal_utility.GetTerminalWidth(9999)-60))
+		content += progress_bar_component.Render(int64(finished), int64(len(m.cases)), width)
+	}
+	content += fieldTextStyle.Render(fmt.Sprintf("  %d/%d", finished, len(m.cases))) + "\n"
+
+	content += lipgloss.NewStyle().Foreground(theme.Success).Render(strconv.Itoa(passed)+" passed") + ", "
+	content += lipgloss.NewStyle().Foreground(theme.Error).Render(strconv.Itoa(failed)+" failed") + ", "
+	content += lipgloss.NewStyle().Foreground(theme.DarkenText).Render(strconv.Itoa(skipped) + " skipped")
+	return content
+}
+
+func case_Icon(c testrunner_module.Case) string {
+	switch c.Status {
+	case testrunner_module.StatusPassed:
+		return lipgloss.NewStyle().Foreground(theme.Success).Render("✓")
+	case testrunner_module.StatusFailed:
+		return lipgloss.NewStyle().Foreground(theme.Error).Render("✗")
+	case testrunner_module.StatusRunning:
+		return lipgloss.NewStyle().Foreground(theme.Primary).Render("●")
+	case testrunner_module.StatusSkipped:
+		return lipgloss.NewStyle().Foreground(theme.DarkenText).Render("-")
+	}
+	return lipgloss.NewStyle().Foreground(theme.DarkenText).Render("○")
+}
+
+// case_Lines draws a case and, when it failed, the reasons below it.
+func case_Lines(m *Model, c testrunner_module.Case, width int) []string {
+	greyTextStyle := lipgloss.NewStyle().Foreground(theme.DarkenText)
+	errorTextStyle := lipgloss.NewStyle().Foreground(theme.Error)
+
+	line := case_Icon(c) + " "
+	if m.cases[len(m.cases)-1].Iteration > 1 {
+		line += greyTextStyle.Render("#"+strconv.Itoa(c.Iteration)) + " "
+	}
+	line += c.Name()
+	if c.Response != nil {
+		line += "  " + lipgloss.NewStyle().Foreground(theme.Secondary).Render(strconv.Itoa(c.Response.StatusCode))
+	}
+	if c.Status == testrunner_module.StatusPassed || c.Status == testrunner_module.StatusFailed {
+		line += "  " + greyTextStyle.Render(c.Duration.Round(time.Millisecond).String())
+	}
+
+	lines := []string{ansi.Truncate(line, width, "…")}
+	for _, failure := range c.Failures {
+		for _, wrapped := range strings.Split(ansi.Wrap(failure, width-4, ""), "\n") {
+			lines = append(lines, "    "+errorTextStyle.Render(wrapped))
+		}
+	}
+	return lines
+}
+
+func cases_viewport_Render(m *Model) string {
+	if len(m.cases) == 0 {
+		return lipgloss.NewStyle().Foreground(theme.Secondary).Render("The collection has no requests.")
+	}
+
+	width := terminal_utility.GetTerminalWidth(9999)
+	maxLines := cases_MaxLines(m)
+
+	var lines []string
+	running := -1
+	for _, c := range m.cases {
+		if c.Status == testrunner_module.StatusRunning && running == -1 {
+			running = len(lines)
+		}
+		lines = append(lines, case_Lines(m, c, width)...)
+	}
+
+	// Following keeps the first running case in view, the list moves along
+	// with the run.
+	if m.follow && running != -1 {
+		m.casesScrollOffset = max(0, running-maxLines/2)
+	}
+	m.casesScrollOffset = min(m.casesScrollOffset, max(0, len(lines)-maxLines))
+
+	end := min(len(lines), m.casesScrollOffset+maxLines)
+	return strings.Join(lines[m.casesScrollOffset:end], "\n")
+}
+
+func cases_viewport_Scroll(m *Model, amount int) {
+	m.casesScrollOffset = max(0, m.casesScrollOffset+amount)
+}
+
+func navigation_options_Render(m *Model) string {
+	greyTextStyle := lipgloss.NewStyle().Foreground(theme.DarkenText)
+	if m.done {
+		return greyTextStyle.Render("\n↑/↓ scroll • q quit")
+	}
+	return greyTextStyle.Render("\n↑/↓ scroll • q stop the run")
+}
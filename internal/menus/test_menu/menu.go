@@ -0,0 +1,153 @@
+package test_menu
+
+import (
+	"context"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	config_module "github.com/diogopereiradev/httpzen/internal/config"
+	logger_module "github.com/diogopereiradev/httpzen/internal/logger"
+	testrunner_module "github.com/diogopereiradev/httpzen/internal/testrunner"
+	logoascii "github.com/diogopereiradev/httpzen/internal/utils/logo_ascii"
+	"github.com/diogopereiradev/httpzen/internal/utils/terminal_utility"
+	"github.com/diogopereiradev/httpzen/internal/utils/theme"
+)
+
+type Model struct {
+	config *config_module.Config
+	name   string
+	cases  []testrunner_module.Case
+
+	messages chan tea.Msg
+	done     bool
+
+	casesScrollOffset int
+	follow            bool
+}
+
+type caseEvent struct {
+	Index int
+	Case  testrunner_module.Case
+}
+
+type doneEvent struct{}
+
+var Exit = os.Exit
+var LoggerError = logger_module.Error
+var TeaNewProgram = tea.NewProgram
+var ExecuteFunc = testrunner_module.Execute
+var TermClear = terminal_utility.Clear
+var RunProgram = func(p *tea.Program) (tea.Model, error) {
+	return p.Run()
+}
+
+// New runs the collection with a live list of its cases. Quitting before the
+// end stops the run, the cases left are skipped, and the run is returned once
+// the requests in flight are over.
+func New(options testrunner_module.Options) testrunner_module.Run {
+	config := config_module.GetConfig()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	messages := make(chan tea.Msg, 256)
+	quit := make(chan struct{})
+	finished := make(chan testrunner_module.Run, 1)
+
+	go func() {
+		run := ExecuteFunc(ctx, options, func(index int, c testrunner_module.Case) {
+			select {
+			case messages <- caseEvent{Index: index, Case: c}:
+			case <-quit:
+			}
+		})
+		finished <- run
+		select {
+		case messages <- doneEvent{}:
+		case <-quit:
+		}
+	}()
+
+	model := initialModel(options, messages, &config)
+	p := TeaNewProgram(&model)
+	TermClear()
+
+	_, err := RunProgram(p)
+	close(quit)
+	cancel()
+	if err != nil {
+		LoggerError("Error on rendering the program: "+err.Error(), 70)
+		Exit(1)
+	}
+	return <-finished
+}
+
+func initialModel(options testrunner_module.Options, messages chan tea.Msg, config *config_module.Config) Model {
+	return Model{
+		config:   config,
+		name:     options.Collection.Name,
+		cases:    testrunner_module.Plan(options),
+		messages: messages,
+		follow:   true,
+	}
+}
+
+func (m *Model) Init() tea.Cmd {
+	return listen(m.messages)
+}
+
+func listen(messages chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-messages
+	}
+}
+
+func (m *Model) View() string {
+	var content string
+
+	if !m.config.HideLogomark {
+		content += lipgloss.NewStyle().Foreground(theme.Primary).Render(logoascii.GetLogo(".test")) + "\n"
+	}
+
+	content += progress_Render(m)
+	content += "\n\n"
+	content += cases_viewport_Render(m)
+	content += "\n"
+	content += navigation_options_Render(m)
+	return content
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// Events
+	switch ev := msg.(type) {
+	case caseEvent:
+		cases_Set(m, ev.Index, ev.Case)
+		return m, listen(m.messages)
+	case doneEvent:
+		m.done = true
+		return m, nil
+	}
+
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		// Shortcuts
+		switch keyMsg.Type {
+		case tea.KeyCtrlC, tea.KeyEsc:
+			return m, tea.Quit
+		case tea.KeyRunes:
+			if keyMsg.String() == "q" {
+				return m, tea.Quit
+			}
+		case tea.KeyUp:
+			m.follow = false
+			cases_viewport_Scroll(m, -1)
+		case tea.KeyDown:
+			cases_viewport_Scroll(m, 1)
+		case tea.KeyPgUp:
+			m.follow = false
+			cases_viewport_Scroll(m, -cases_MaxLines(m))
+		case tea.KeyPgDown:
+			cases_viewport_Scroll(m, cases_MaxLines(m))
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,155 @@
+package websocket_menu
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/diogopereiradev/httpzen/internal/utils/terminal_utility"
+	"github.com/diogopereiradev/httpzen/internal/utils/theme"
+	websocket_module "github.com/diogopereiradev/httpzen/internal/websocket"
+	"github.com/gorilla/websocket"
+)
+
+func frames_Append(m *Model, frame websocket_module.Frame) {
+	m.frames = append(m.frames, frame)
+
+	switch frame.Direction {
+	case websocket_module.DirectionSent:
+		m.sentAmount++
+	case websocket_module.DirectionReceived:
+		m.receivedAmount++
+	}
+	if frame.Opcode == websocket.CloseMessage && frame.Direction == websocket_module.DirectionReceived {
+		m.closeCode = frame.CloseCode
+	}
+
+	if m.follow {
+		frames_FollowBottom(m)
+	}
+}
+
+func frames_MaxLines(m *Model) int {
+	reserved := 14
+	if !m.config.HideLogomark {
+		reserved += 7
+	}
+	return max(3, terminal_utility.GetTerminalHeight(9999)-reserved)
+}
+
+func frame_Style(frame websocket_module.Frame) lipgloss.Style {
+	switch {
+	case frame.Direction == websocket_module.DirectionInfo:
+		return lipgloss.NewStyle().Foreground(theme.DarkenText)
+	case frame.Opcode == websocket.CloseMessage:
+		return lipgloss.NewStyle().Foreground(theme.Error)
+	case frame.Opcode == websocket.PingMessage, frame.Opcode == websocket.PongMessage:
+		return lipgloss.NewStyle().Foreground(theme.Warn)
+	case frame.Direction == websocket_module.DirectionSent:
+		return lipgloss.NewStyle().Foreground(theme.Secondary)
+	}
+	return lipgloss.NewStyle().Foreground(theme.LightText)
+}
+
+func frames_viewport_Render(m *Model) string {
+	var result string
+
+	fieldTextStyle := lipgloss.NewStyle().Foreground(theme.Secondary)
+	maxLines := frames_MaxLines(m)
+	width := terminal_utility.GetTerminalWidth(9999)
+
+	if len(m.frames) == 0 {
+		return fieldTextStyle.Render("No frames yet, type a message below and press CTRL + S to send it.")
+	}
+
+	var lines []string
+	for _, frame := range m.frames {
+		style := frame_Style(frame)
+		wrapped := ansi.Wrap(websocket_module.FormatFrame(frame), width, "")
+		for _, line := range strings.Split(wrapped, "\n") {
+			lines = append(lines, style.Render(line))
+		}
+	}
+	total := len(lines)
+
+	if m.framesScrollOffset > total-maxLines {
+		m.framesScrollOffset = max(0, total-maxLines)
+	}
+	m.framesLinesAmount = total
+
+	end := min(m.framesScrollOffset+maxLines, total)
+	result += strings.Join(lines[m.framesScrollOffset:end], "\n")
+
+	if total > maxLines {
+		result += fieldTextStyle.Render(fmt.Sprintf("\n[%d-%d/%d lines] Use PgUp/PgDown to scroll.", m.framesScrollOffset+1, end, total))
+	}
+	return result
+}
+
+// frames_FollowBottom moves the viewport past the end, frames_viewport_Render
+// clamps it back to the last page.
+func frames_FollowBottom(m *Model) {
+	m.framesScrollOffset = m.framesLinesAmount + len(m.frames)
+}
+
+func frames_viewport_ScrollPgUp(m *Model) {
+	m.follow = false
+	m.framesScrollOffset -= 5
+	if m.framesScrollOffset < 0 {
+		m.framesScrollOffset = 0
+	}
+}
+
+func frames_viewport_ScrollPgDown(m *Model) {
+	if m.framesLinesAmount <= frames_MaxLines(m) {
+		return
+	}
+	m.framesScrollOffset += 5
+	if m.framesScrollOffset >= m.framesLinesAmount-frames_MaxLines(m) {
+		m.follow = true
+	}
+}
+
+func status_Render(m *Model) string {
+	fieldTextStyle := lipgloss.NewStyle().Foreground(theme.Secondary)
+	greyTextStyle := lipgloss.NewStyle().Foreground(theme.DarkenText)
+
+	var state string
+	if m.closed {
+		closeText := "● Closed"
+		if m.closeCode != 0 {
+			closeText += fmt.Sprintf(" %d", m.closeCode)
+			if name := websocket_module.CloseCodeName(m.closeCode); name != "" {
+				closeText += " (" + name + ")"
+			}
+		}
+		state = lipgloss.NewStyle().Foreground(theme.Error).Render(closeText)
+	} else {
+		state = lipgloss.NewStyle().Foreground(theme.Success).Render("● Connected")
+	}
+
+	content := state + " " + fieldTextStyle.Render(m.session.Url)
+	content += greyTextStyle.Render(fmt.Sprintf("  sent %d, received %d", m.sentAmount, m.receivedAmount))
+
+	if len(m.options.Script) > 0 {
+		content += greyTextStyle.Render(fmt.Sprintf("  script %d/%d", m.scriptIndex, len(m.options.Script)))
+	}
+	if !m.follow {
+		content += greyTextStyle.Render("  (paused scrolling, CTRL + L to follow)")
+	}
+	return content
+}
+
+func input_Render(m *Model) string {
+	borderStyle := lipgloss.NewStyle().
+		Padding(0, 1).
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Primary).
+		UnsetBackground()
+
+	if m.closed {
+		borderStyle = borderStyle.BorderForeground(theme.DarkenText)
+	}
+	return borderStyle.Render(m.input.View())
+}
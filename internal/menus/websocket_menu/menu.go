@@ -0,0 +1,299 @@
+package websocket_menu
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	textarea_component "github.com/diogopereiradev/httpzen/internal/components/textarea"
+	timed_message_component "github.com/diogopereiradev/httpzen/internal/components/timed_message"
+	config_module "github.com/diogopereiradev/httpzen/internal/config"
+	logger_module "github.com/diogopereiradev/httpzen/internal/logger"
+	logoascii "github.com/diogopereiradev/httpzen/internal/utils/logo_ascii"
+	"github.com/diogopereiradev/httpzen/internal/utils/terminal_utility"
+	"github.com/diogopereiradev/httpzen/internal/utils/theme"
+	websocket_module "github.com/diogopereiradev/httpzen/internal/websocket"
+	"github.com/gorilla/websocket"
+)
+
+type Options struct {
+	Connect websocket_module.ConnectOptions
+	// Script lines are sent one by one once connected, Interval apart.
+	Script   []string
+	Interval time.Duration
+	// ExportPath receives the session log when the console is closed, and
+	// when the user exports it with ctrl+o.
+	ExportPath string
+}
+
+type Model struct {
+	config  *config_module.Config
+	options Options
+	session *websocket_module.Session
+
+	messages chan tea.Msg
+	frames   []websocket_module.Frame
+	input    textarea.Model
+
+	sentAmount     int
+	receivedAmount int
+	closed         bool
+	closeCode      int
+	scriptIndex    int
+
+	framesScrollOffset int
+	framesLinesAmount  int
+	follow             bool
+
+	timedMessage *timed_message_component.TimedMessage
+}
+
+type frameEvent struct {
+	Frame websocket_module.Frame
+}
+
+type closedEvent struct{}
+
+type scriptTickEvent struct{}
+
+var Exit = os.Exit
+var LoggerError = logger_module.Error
+var TeaNewProgram = tea.NewProgram
+var ConnectFunc = websocket_module.Connect
+var ExportLogFunc = websocket_module.ExportLog
+var TermClear = terminal_utility.Clear
+var RunProgram = func(p *tea.Program) (tea.Model, error) {
+	return p.Run()
+}
+
+const inputMaxLength = 64 * 1024
+
+// New connects to the server and opens the console. The handshake happens
+// before the TUI starts so a failed connection returns its error right away
+// and the caller can pick an exit code.
+func New(options Options) error {
+	config := config_module.GetConfig()
+
+	var (
+		recordedMu sync.Mutex
+		recorded   []websocket_module.Frame
+	)
+	messages := make(chan tea.Msg, 256)
+	done := make(chan struct{})
+
+	// Every frame is also kept here, the console stops listening when it
+	// quits but the closing handshake still belongs in the exported log.
+	session, err := ConnectFunc(options.Connect, func(frame websocket_module.Frame) {
+		recordedMu.Lock()
+		recorded = append(recorded, frame)
+		recordedMu.Unlock()
+
+		select {
+		case messages <- frameEvent{Frame: frame}:
+		case <-done:
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	model := initialModel(session, messages, options, &config)
+
+	p := TeaNewProgram(&model)
+	TermClear()
+
+	_, runErr := RunProgram(p)
+	close(done)
+	if runErr != nil {
+		LoggerError("Error on rendering the program: "+runErr.Error(), 70)
+		Exit(1)
+	}
+
+	session.Close(websocket.CloseNormalClosure, "")
+
+	if options.ExportPath != "" {
+		recordedMu.Lock()
+		defer recordedMu.Unlock()
+		if err := ExportLogFunc(options.ExportPath, session.Url, recorded); err != nil {
+			LoggerError("Error on exporting the session log: "+err.Error(), 70)
+		}
+	}
+	return nil
+}
+
+func initialModel(session *websocket_module.Session, messages chan tea.Msg, options Options, config *config_module.Config) Model {
+	return Model{
+		config:       config,
+		options:      options,
+		session:      session,
+		messages:     messages,
+		input:        textarea_component.NewInput(inputMaxLength, 80),
+		follow:       true,
+		timedMessage: timed_message_component.New(),
+	}
+}
+
+func (m *Model) Init() tea.Cmd {
+	m.input.SetHeight(3)
+
+	cmds := []tea.Cmd{textarea.Blink, listen(m.messages), waitClosed(m.session)}
+	if len(m.options.Script) > 0 {
+		cmds = append(cmds, scriptTick(m.options.Interval))
+	}
+	return tea.Batch(cmds...)
+}
+
+func listen(messages chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-messages
+	}
+}
+
+func waitClosed(session *websocket_module.Session) tea.Cmd {
+	return func() tea.Msg {
+		<-session.Done()
+		return closedEvent{}
+	}
+}
+
+func scriptTick(interval time.Duration) tea.Cmd {
+	return tea.Tick(interval, func(time.Time) tea.Msg {
+		return scriptTickEvent{}
+	})
+}
+
+func (m *Model) View() string {
+	var content string
+
+	if !m.config.HideLogomark {
+		content += lipgloss.NewStyle().Foreground(theme.Primary).Render(logoascii.GetLogo(".websocket")) + "\n"
+	}
+
+	content += status_Render(m)
+	content += "\n\n"
+	content += frames_viewport_Render(m)
+	content += "\n\n"
+	content += input_Render(m)
+	content += navigation_options_Render()
+
+	if m.timedMessage != nil && m.timedMessage.Visible {
+		if dialogMsg := m.timedMessage.Render(); dialogMsg != "" {
+			content += "\n" + dialogMsg
+		}
+	}
+	return content
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// Events
+	switch ev := msg.(type) {
+	case frameEvent:
+		frames_Append(m, ev.Frame)
+		return m, listen(m.messages)
+	case closedEvent:
+		m.closed = true
+		return m, nil
+	case scriptTickEvent:
+		return m, script_SendNext(m)
+	}
+
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		// Shortcuts
+		switch keyMsg.Type {
+		case tea.KeyCtrlC, tea.KeyEsc:
+			return m, tea.Quit
+
+		case tea.KeyCtrlS:
+			return m, input_Send(m)
+		case tea.KeyCtrlG:
+			return m, send(m, func() error { return m.session.SendPing("") })
+		case tea.KeyCtrlX:
+			if m.closed {
+				return m, nil
+			}
+			// Close waits for the server to answer, keep the console responsive.
+			session := m.session
+			return m, func() tea.Msg {
+				session.Close(websocket.CloseNormalClosure, "closed by user")
+				return nil
+			}
+		case tea.KeyCtrlO:
+			return m, export(m)
+		case tea.KeyCtrlL:
+			m.follow = true
+			frames_FollowBottom(m)
+			return m, nil
+
+		case tea.KeyPgUp:
+			frames_viewport_ScrollPgUp(m)
+			return m, nil
+		case tea.KeyPgDown:
+			frames_viewport_ScrollPgDown(m)
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// send runs a write on the session and reports a failure in the console, the
+// frame itself shows up through the session callback when it succeeds.
+func send(m *Model, write func() error) tea.Cmd {
+	if m.closed {
+		return m.timedMessage.Show("The connection is closed", 2*time.Second)
+	}
+	if err := write(); err != nil {
+		return m.timedMessage.Show("Failed to send: "+err.Error(), 2*time.Second)
+	}
+	return nil
+}
+
+func input_Send(m *Model) tea.Cmd {
+	value := m.input.Value()
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+
+	cmd := send(m, func() error { return m.session.SendText(value) })
+	if cmd == nil {
+		m.input.Reset()
+		m.follow = true
+		frames_FollowBottom(m)
+	}
+	return cmd
+}
+
+func script_SendNext(m *Model) tea.Cmd {
+	if m.scriptIndex >= len(m.options.Script) || m.closed {
+		return nil
+	}
+
+	line := m.options.Script[m.scriptIndex]
+	m.scriptIndex++
+	if cmd := send(m, func() error { return m.session.SendText(line) }); cmd != nil {
+		return cmd
+	}
+
+	if m.scriptIndex < len(m.options.Script) {
+		return scriptTick(m.options.Interval)
+	}
+	return nil
+}
+
+func export(m *Model) tea.Cmd {
+	path := m.options.ExportPath
+	if path == "" {
+		path = "httpzen-ws-" + time.Now().Format("20060102-150405") + ".log"
+	}
+
+	if err := ExportLogFunc(path, m.session.Url, m.frames); err != nil {
+		return m.timedMessage.Show("Failed to export: "+err.Error(), 2*time.Second)
+	}
+	return m.timedMessage.Show("Session exported to "+path, 2*time.Second)
+}
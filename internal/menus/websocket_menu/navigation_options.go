@@ -0,0 +1,16 @@
+package websocket_menu
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/diogopereiradev/httpzen/internal/utils/theme"
+)
+
+func navigation_options_Render() string {
+	var content string
+	greyTextStyle := lipgloss.NewStyle().Foreground(theme.DarkenText)
+
+	content += greyTextStyle.Render("\n'CTRL + S' to send, 'CTRL + G' to ping, 'CTRL + X' to close the connection, 'ESC' to quit.")
+	content += greyTextStyle.Render("\n'CTRL + O' to export the session log, 'PgUp/PgDown' to scroll and 'CTRL + L' to follow new frames.\n")
+
+	return content
+}
@@ -0,0 +1,167 @@
+package openapi_module
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// maxExampleDepth stops the example of deeply nested schemas, the deepest
+// objects are left empty.
+const maxExampleDepth = 8
+
+// GenerateExample builds a value matching the schema, using the examples,
+// defaults and enums it declares before falling back to a value per type.
+func GenerateExample(schema *Schema) any {
+	return generateExample(schema, 0)
+}
+
+func generateExample(schema *Schema, depth int) any {
+	if schema == nil {
+		return nil
+	}
+	switch {
+	case schema.Example != nil:
+		return schema.Example
+	case schema.Examples != nil:
+		if list, ok := schema.Examples.([]any); ok && len(list) > 0 {
+			return list[0]
+		}
+	case schema.Default != nil:
+		return schema.Default
+	case schema.Const != nil:
+		return schema.Const
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	}
+
+	if len(schema.AllOf) > 0 {
+		merged := map[string]any{}
+		for _, part := range schema.AllOf {
+			if object, ok := generateExample(part, depth).(map[string]any); ok {
+				for key, value := range object {
+					merged[key] = value
+				}
+			}
+		}
+		return mergeObject(merged, schema, depth)
+	}
+	if len(schema.OneOf) > 0 {
+		return generateExample(schema.OneOf[0], depth)
+	}
+	if len(schema.AnyOf) > 0 {
+		return generateExample(schema.AnyOf[0], depth)
+	}
+
+	switch schema.Type.Main() {
+	case "object":
+		return mergeObject(map[string]any{}, schema, depth)
+	case "array":
+		if depth >= maxExampleDepth || schema.Items == nil {
+			return []any{}
+		}
+		return []any{generateExample(schema.Items, depth+1)}
+	case "string":
+		return stringExample(schema.Format)
+	case "integer":
+		if schema.Minimum != nil {
+			return int(*schema.Minimum)
+		}
+		return 0
+	case "number":
+		if schema.Minimum != nil {
+			return *schema.Minimum
+		}
+		return 0.0
+	case "boolean":
+		return false
+	case "":
+		if len(schema.Properties) > 0 {
+			return mergeObject(map[string]any{}, schema, depth)
+		}
+	}
+	return nil
+}
+
+func mergeObject(object map[string]any, schema *Schema, depth int) map[string]any {
+	if depth >= maxExampleDepth {
+		return object
+	}
+	for name, property := range schema.Properties {
+		object[name] = generateExample(property, depth+1)
+	}
+	return object
+}
+
+func stringExample(format string) string {
+	switch format {
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	case "date":
+		return "2024-01-01"
+	case "time":
+		return "00:00:00"
+	case "email":
+		return "user@example.com"
+	case "uuid":
+		return "00000000-0000-0000-0000-000000000000"
+	case "uri", "url":
+		return "https://example.com"
+	case "hostname":
+		return "example.com"
+	case "ipv4":
+		return "127.0.0.1"
+	case "ipv6":
+		return "::1"
+	case "binary", "byte":
+		return ""
+	}
+	return "string"
+}
+
+// MediaExample is the example of a request or response body: the media
+// example, its first named example, or one built from the schema.
+func (m MediaType) MediaExample() any {
+	if m.Example != nil {
+		return m.Example
+	}
+	if len(m.Examples) > 0 {
+		names := make([]string, 0, len(m.Examples))
+		for name := range m.Examples {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return m.Examples[names[0]].Value
+	}
+	return GenerateExample(m.Schema)
+}
+
+// ParameterExample is the value written for a parameter, "" when neither
+// the parameter nor its schema has one.
+func (p Parameter) ParameterExample() string {
+	var value any
+	switch {
+	case p.Example != nil:
+		value = p.Example
+	case p.Schema != nil && (p.Schema.Example != nil || p.Schema.Default != nil || len(p.Schema.Enum) > 0 || p.Schema.Examples != nil):
+		value = GenerateExample(p.Schema)
+	default:
+		return ""
+	}
+	return FormatValue(value)
+}
+
+// FormatValue writes a value as it goes in a URL or a header, JSON for
+// objects and lists.
+func FormatValue(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case map[string]any, []any:
+		encoded, _ := json.Marshal(value)
+		return string(encoded)
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,248 @@
+package openapi_module
+
+import (
+	"encoding/json"
+	"net/http"
+	neturl "net/url"
+	"sort"
+	"strings"
+
+	auth_module "github.com/diogopereiradev/httpzen/internal/auth"
+	collection_module "github.com/diogopereiradev/httpzen/internal/collection"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/diogopereiradev/httpzen/internal/utils/http_utility"
+)
+
+// ignoredHeaders are described by other fields of the spec, OpenAPI says
+// header parameters with these names are ignored.
+var ignoredHeaders = []string{"Accept", "Content-Type", "Authorization"}
+
+// Import creates one saved request per operation, in a folder per tag. The
+// server URL becomes the {{baseUrl}} variable of the collection.
+func (s *Spec) Import() collection_module.ImportResult {
+	name := s.Info.Title
+	if name == "" {
+		name = "openapi"
+	}
+	result := collection_module.ImportResult{Collection: collection_module.Collection{
+		Name:      name,
+		Variables: map[string]string{"baseUrl": s.ServerUrl()},
+	}}
+
+	for _, operation := range s.Operations() {
+		options, skipped := s.RequestOptions(operation, nil)
+		result.Collection.Requests = append(result.Collection.Requests, collection_module.SavedRequest{
+			Name:    operation.Name(),
+			Folder:  operation.Tag(),
+			Request: options,
+		})
+		for _, message := range skipped {
+			result.Skipped = append(result.Skipped, operation.Method+" "+operation.Path+": "+message)
+		}
+	}
+	return result
+}
+
+// RequestOptions builds the request of an operation. values sets the
+// parameters by name, the others take their example or become a {{name}}
+// variable when required. The URL starts with {{baseUrl}}.
+func (s *Spec) RequestOptions(operation Operation, values map[string]string) (request_module.RequestOptions, []string) {
+	var skipped []string
+	options := request_module.RequestOptions{
+		Method:  operation.Method,
+		Headers: http.Header{},
+	}
+
+	path := operation.Path
+	query := neturl.Values{}
+	var cookies []string
+	for _, parameter := range operation.Parameters {
+		value, ok := values[parameter.Name]
+		if !ok {
+			value = parameter.ParameterExample()
+		}
+		if value == "" {
+			if !parameter.Required && parameter.In != "path" {
+				continue
+			}
+			value = "{{" + parameter.Name + "}}"
+		}
+
+		switch parameter.In {
+		case "path":
+			if !strings.HasPrefix(value, "{{") {
+				value = neturl.PathEscape(value)
+			}
+			path = strings.ReplaceAll(path, "{"+parameter.Name+"}", value)
+		case "query":
+			query.Add(parameter.Name, value)
+		case "header":
+			if !parameter.Ignored() {
+				options.Headers.Set(parameter.Name, value)
+			}
+		case "cookie":
+			cookies = append(cookies, parameter.Name+"="+value)
+		}
+	}
+	if len(cookies) > 0 {
+		options.Headers.Add("Cookie", strings.Join(cookies, "; "))
+	}
+
+	options.Url = http_utility.AddQuery("{{baseUrl}}"+path, query)
+	if operation.RequestBody != nil {
+		body, message := requestBody(*operation.RequestBody)
+		options.Body = body
+		if message != "" {
+			skipped = append(skipped, message)
+		}
+	}
+	skipped = append(skipped, s.applySecurity(operation, &options)...)
+	return options, skipped
+}
+
+// Ignored tells if the parameter is a header OpenAPI says to ignore.
+func (p Parameter) Ignored() bool {
+	return p.In == "header" && containsFold(ignoredHeaders, p.Name)
+}
+
+// BodyContentType picks the media type a request body is sent as, JSON
+// first, then forms, then the first one declared.
+func BodyContentType(body RequestBody) string {
+	types := make([]string, 0, len(body.Content))
+	for contentType := range body.Content {
+		types = append(types, contentType)
+	}
+	sort.Strings(types)
+
+	for _, preferred := range []func(string) bool{
+		func(t string) bool { return t == "application/json" },
+		func(t string) bool { return strings.HasSuffix(t, "+json") },
+		func(t string) bool { return t == "application/x-www-form-urlencoded" },
+		func(t string) bool { return t == "multipart/form-data" },
+	} {
+		for _, t := range types {
+			if preferred(t) {
+				return t
+			}
+		}
+	}
+	if len(types) > 0 {
+		return types[0]
+	}
+	return ""
+}
+
+func requestBody(body RequestBody) ([]http_utility.HttpContentData, string) {
+	contentType := BodyContentType(body)
+	if contentType == "" {
+		return nil, ""
+	}
+	example := body.Content[contentType].MediaExample()
+
+	switch {
+	case contentType == "application/json" || strings.HasSuffix(contentType, "+json"):
+		encoded, _ := json.MarshalIndent(example, "", "  ")
+		return []http_utility.HttpContentData{{ContentType: contentType, Value: string(encoded)}}, ""
+	case contentType == "application/x-www-form-urlencoded", contentType == "multipart/form-data":
+		object, _ := example.(map[string]any)
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var data []http_utility.HttpContentData
+		message := ""
+		for _, name := range names {
+			value := FormatValue(object[name])
+			if value == "" && contentType == "multipart/form-data" {
+				message = "set the path of the file fields of the form"
+			}
+			data = append(data, http_utility.HttpContentData{ContentType: contentType, Key: name, Value: value})
+		}
+		return data, message
+	}
+
+	if text, ok := example.(string); ok && text != "" {
+		return []http_utility.HttpContentData{{ContentType: contentType, Value: text}}, ""
+	}
+	return nil, "no example for the " + contentType + " body"
+}
+
+// applySecurity sets the auth of the first security requirement httpzen
+// supports. The secrets are {{variables}} to be set in an environment.
+func (s *Spec) applySecurity(operation Operation, options *request_module.RequestOptions) []string {
+	requirements := s.Security
+	if operation.Security != nil {
+		requirements = *operation.Security
+	}
+
+	var skipped []string
+	for _, requirement := range requirements {
+		supported := true
+		names := make([]string, 0, len(requirement))
+		for name := range requirement {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		next := *options
+		next.Headers = options.Headers.Clone()
+		for _, name := range names {
+			scheme, ok := s.Components.SecuritySchemes[name]
+			if !ok || !applyScheme(scheme, requirement[name], &next) {
+				skipped = append(skipped, "the "+name+" security scheme is not supported")
+				supported = false
+				break
+			}
+		}
+		if supported {
+			*options = next
+			return nil
+		}
+	}
+	return skipped
+}
+
+func applyScheme(scheme SecurityScheme, scopes []string, options *request_module.RequestOptions) bool {
+	switch {
+	case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "basic"):
+		options.Auth = auth_module.AuthOptions{Type: auth_module.AuthBasic, Username: "{{username}}", Password: "{{password}}"}
+	case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "digest"):
+		options.Auth = auth_module.AuthOptions{Type: auth_module.AuthDigest, Username: "{{username}}", Password: "{{password}}"}
+	case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "bearer"):
+		options.Auth = auth_module.AuthOptions{Type: auth_module.AuthBearer, Token: "{{token}}"}
+	case scheme.Type == "apiKey":
+		value := "{{" + scheme.Name + "}}"
+		switch scheme.In {
+		case "header":
+			options.Headers.Set(scheme.Name, value)
+		case "query":
+			options.Url = http_utility.AddQuery(options.Url, neturl.Values{scheme.Name: {value}})
+		case "cookie":
+			options.Headers.Add("Cookie", scheme.Name+"="+value)
+		default:
+			return false
+		}
+	case scheme.Type == "oauth2" && scheme.Flows.ClientCredentials != nil:
+		options.Auth = auth_module.AuthOptions{
+			Type:         auth_module.AuthOAuth2,
+			TokenUrl:     scheme.Flows.ClientCredentials.TokenUrl,
+			ClientId:     "{{client_id}}",
+			ClientSecret: "{{client_secret}}",
+			Scopes:       strings.Join(scopes, " "),
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,417 @@
+package openapi_module
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is the part of an OpenAPI 3 document httpzen uses. Local $refs are
+// resolved when the document is loaded, so the types never hold one.
+type Spec struct {
+	OpenApi    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Servers    []Server              `json:"servers"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+	Security   []map[string][]string `json:"security"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Server struct {
+	Url       string                    `json:"url"`
+	Variables map[string]ServerVariable `json:"variables"`
+}
+
+type ServerVariable struct {
+	Default string `json:"default"`
+}
+
+type PathItem struct {
+	Parameters []Parameter `json:"parameters"`
+	Get        *Operation  `json:"get"`
+	Put        *Operation  `json:"put"`
+	Post       *Operation  `json:"post"`
+	Delete     *Operation  `json:"delete"`
+	Options    *Operation  `json:"options"`
+	Head       *Operation  `json:"head"`
+	Patch      *Operation  `json:"patch"`
+	Trace      *Operation  `json:"trace"`
+}
+
+type Operation struct {
+	OperationId string                 `json:"operationId"`
+	Summary     string                 `json:"summary"`
+	Tags        []string               `json:"tags"`
+	Parameters  []Parameter            `json:"parameters"`
+	RequestBody *RequestBody           `json:"requestBody"`
+	Responses   map[string]Response    `json:"responses"`
+	Security    *[]map[string][]string `json:"security"`
+
+	// Method and Path are set by Operations.
+	Method string `json:"-"`
+	Path   string `json:"-"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+	Example  any     `json:"example"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+type Header struct {
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema   *Schema            `json:"schema"`
+	Example  any                `json:"example"`
+	Examples map[string]Example `json:"examples"`
+}
+
+type Example struct {
+	Value any `json:"value"`
+}
+
+type Components struct {
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme"`
+	Name   string `json:"name"`
+	In     string `json:"in"`
+	Flows  struct {
+		ClientCredentials *struct {
+			TokenUrl string            `json:"tokenUrl"`
+			Scopes   map[string]string `json:"scopes"`
+		} `json:"clientCredentials"`
+	} `json:"flows"`
+}
+
+// Schema is a JSON Schema as written in OpenAPI 3.0 and 3.1.
+type Schema struct {
+	Type                 SchemaType         `json:"type"`
+	Format               string             `json:"format"`
+	Nullable             bool               `json:"nullable"`
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *Schema            `json:"-"`
+	NoAdditional         bool               `json:"-"`
+	Items                *Schema            `json:"items"`
+	AllOf                []*Schema          `json:"allOf"`
+	OneOf                []*Schema          `json:"oneOf"`
+	AnyOf                []*Schema          `json:"anyOf"`
+	Enum                 []any              `json:"enum"`
+	Const                any                `json:"const"`
+	Example              any                `json:"example"`
+	// Examples is a list in 3.1, it is read as any since some 3.0 documents
+	// write a map there.
+	Examples  any      `json:"examples"`
+	Default   any      `json:"default"`
+	Pattern   string   `json:"pattern"`
+	MinLength *int     `json:"minLength"`
+	MaxLength *int     `json:"maxLength"`
+	Minimum   *float64 `json:"minimum"`
+	Maximum   *float64 `json:"maximum"`
+	MinItems  *int     `json:"minItems"`
+	MaxItems  *int     `json:"maxItems"`
+}
+
+// UnmarshalJSON reads additionalProperties, a boolean or a schema.
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	type plain Schema
+	if err := json.Unmarshal(data, (*plain)(s)); err != nil {
+		return err
+	}
+	var raw struct {
+		AdditionalProperties json.RawMessage `json:"additionalProperties"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil || len(raw.AdditionalProperties) == 0 {
+		return nil
+	}
+	var allowed bool
+	if json.Unmarshal(raw.AdditionalProperties, &allowed) == nil {
+		s.NoAdditional = !allowed
+		return nil
+	}
+	s.AdditionalProperties = &Schema{}
+	return json.Unmarshal(raw.AdditionalProperties, s.AdditionalProperties)
+}
+
+// SchemaType is a single type in OpenAPI 3.0, a list of them in 3.1.
+type SchemaType []string
+
+func (t *SchemaType) UnmarshalJSON(data []byte) error {
+	var single string
+	if json.Unmarshal(data, &single) == nil {
+		*t = SchemaType{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*t = list
+	return nil
+}
+
+// Is tells if the schema allows the type, null included for 3.1 lists.
+func (t SchemaType) Is(name string) bool {
+	for _, v := range t {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Main is the first type that isn't null.
+func (t SchemaType) Main() string {
+	for _, v := range t {
+		if v != "null" {
+			return v
+		}
+	}
+	return ""
+}
+
+var readFile = os.ReadFile
+
+// Load reads a YAML or JSON document. References to other files are not
+// followed, they are left as empty schemas.
+func Load(path string) (*Spec, error) {
+	data, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+func Parse(data []byte) (*Spec, error) {
+	root, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	// An unquoted version, like openapi: 3.1, is decoded as a number.
+	version := fmt.Sprint(root["openapi"])
+	if !strings.HasPrefix(version, "3.") {
+		if _, ok := root["swagger"]; ok {
+			return nil, errors.New("Swagger 2.0 documents are not supported, convert them to OpenAPI 3 first")
+		}
+		return nil, errors.New("not an OpenAPI 3 document, the openapi field is missing")
+	}
+	root["openapi"] = version
+	if info, ok := root["info"].(map[string]any); ok && info["version"] != nil {
+		info["version"] = fmt.Sprint(info["version"])
+	}
+
+	var spec Spec
+	if err := convert(root, &spec); err != nil {
+		return nil, errors.New("invalid OpenAPI document: " + err.Error())
+	}
+	return &spec, nil
+}
+
+// decode reads a YAML or JSON document that must be an object.
+func decode(data []byte) (map[string]any, error) {
+	var document any
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, errors.New("not a YAML or JSON document: " + err.Error())
+	}
+	root, ok := normalize(document).(map[string]any)
+	if !ok {
+		return nil, errors.New("the document is not an object")
+	}
+	return root, nil
+}
+
+// convert resolves the $refs of a decoded document and reads it into target.
+func convert(root map[string]any, target any) error {
+	encoded, err := json.Marshal(resolveRefs(root, root, map[string]bool{}))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(encoded, target)
+}
+
+// normalize turns the maps YAML decodes with non string keys, like response
+// codes, into string keyed maps JSON can encode.
+func normalize(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, nested := range value {
+			value[key] = normalize(nested)
+		}
+		return value
+	case map[any]any:
+		converted := make(map[string]any, len(value))
+		for key, nested := range value {
+			converted[fmt.Sprint(key)] = normalize(nested)
+		}
+		return converted
+	case []any:
+		for i, nested := range value {
+			value[i] = normalize(nested)
+		}
+		return value
+	}
+	return value
+}
+
+// resolveRefs replaces the local $refs by a copy of their target. A schema
+// referencing itself, like a tree node, ends as an empty schema at the
+// second level.
+func resolveRefs(value any, root map[string]any, visiting map[string]bool) any {
+	switch value := value.(type) {
+	case map[string]any:
+		if ref, ok := value["$ref"].(string); ok {
+			if !strings.HasPrefix(ref, "#/") || visiting[ref] {
+				return map[string]any{}
+			}
+			target, ok := lookup(root, ref)
+			if !ok {
+				return map[string]any{}
+			}
+			visiting[ref] = true
+			resolved := resolveRefs(target, root, visiting)
+			delete(visiting, ref)
+			return resolved
+		}
+		copied := make(map[string]any, len(value))
+		for key, nested := range value {
+			copied[key] = resolveRefs(nested, root, visiting)
+		}
+		return copied
+	case []any:
+		copied := make([]any, len(value))
+		for i, nested := range value {
+			copied[i] = resolveRefs(nested, root, visiting)
+		}
+		return copied
+	}
+	return value
+}
+
+// lookup follows a JSON pointer like #/components/schemas/User.
+func lookup(root map[string]any, ref string) (any, bool) {
+	var current any = root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if current, ok = object[part]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// Operations lists the operations sorted by path and method, with the
+// parameters of their path merged in.
+func (s *Spec) Operations() []Operation {
+	paths := make([]string, 0, len(s.Paths))
+	for path := range s.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var operations []Operation
+	for _, path := range paths {
+		item := s.Paths[path]
+		for _, entry := range []struct {
+			method    string
+			operation *Operation
+		}{
+			{"GET", item.Get}, {"POST", item.Post}, {"PUT", item.Put}, {"PATCH", item.Patch},
+			{"DELETE", item.Delete}, {"HEAD", item.Head}, {"OPTIONS", item.Options}, {"TRACE", item.Trace},
+		} {
+			if entry.operation == nil {
+				continue
+			}
+			operation := *entry.operation
+			operation.Method = entry.method
+			operation.Path = path
+			operation.Parameters = mergeParameters(item.Parameters, operation.Parameters)
+			operations = append(operations, operation)
+		}
+	}
+	return operations
+}
+
+// mergeParameters lets the operation override a path parameter with the
+// same name and location.
+func mergeParameters(pathParameters []Parameter, operationParameters []Parameter) []Parameter {
+	merged := append([]Parameter{}, operationParameters...)
+	for _, parameter := range pathParameters {
+		overridden := false
+		for _, own := range operationParameters {
+			if own.Name == parameter.Name && own.In == parameter.In {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			merged = append(merged, parameter)
+		}
+	}
+	return merged
+}
+
+// Tag groups the operation, the first tag or "default".
+func (o Operation) Tag() string {
+	if len(o.Tags) > 0 && o.Tags[0] != "" {
+		return o.Tags[0]
+	}
+	return "default"
+}
+
+// Name is the summary, or the operation id, or the method and path.
+func (o Operation) Name() string {
+	switch {
+	case o.Summary != "":
+		return o.Summary
+	case o.OperationId != "":
+		return o.OperationId
+	}
+	return o.Method + " " + o.Path
+}
+
+// ServerUrl is the first server with its variables set to their defaults.
+// Relative server URLs are kept, the user sets the host through {{baseUrl}}.
+func (s *Spec) ServerUrl() string {
+	if len(s.Servers) == 0 {
+		return ""
+	}
+	url := s.Servers[0].Url
+	for name, variable := range s.Servers[0].Variables {
+		url = strings.ReplaceAll(url, "{"+name+"}", variable.Default)
+	}
+	return strings.TrimSuffix(url, "/")
+}
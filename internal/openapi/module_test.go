@@ -0,0 +1,227 @@
+package openapi_module
+
+import (
+	"testing"
+
+	auth_module "github.com/diogopereiradev/httpzen/internal/auth"
+	"github.com/diogopereiradev/httpzen/internal/utils/http_utility"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSpec = `
+openapi: 3.0.3
+info:
+  title: Pet Store
+  version: 1.0
+servers:
+  - url: https://{region}.example.com/v1/
+    variables:
+      region:
+        default: eu
+security:
+  - bearerAuth: []
+paths:
+  /pets:
+    get:
+      summary: List pets
+      tags: [pets]
+      parameters:
+        - name: limit
+          in: query
+          schema: {type: integer, default: 20}
+        - name: cursor
+          in: query
+          schema: {type: string}
+        - name: X-Request-Id
+          in: header
+          required: true
+          schema: {type: string}
+        - name: Accept
+          in: header
+          schema: {type: string, example: text/plain}
+      responses:
+        200:
+          description: The pets
+          content:
+            application/json:
+              schema:
+                type: array
+                items: {$ref: '#/components/schemas/Pet'}
+    post:
+      operationId: createPet
+      tags: [pets]
+      security:
+        - apiKey: []
+      requestBody:
+        content:
+          application/xml:
+            schema: {type: string}
+          application/json:
+            schema: {$ref: '#/components/schemas/Pet'}
+      responses:
+        201: {description: Created}
+  /pets/{petId}:
+    parameters:
+      - name: petId
+        in: path
+        required: true
+        schema: {type: string}
+    get:
+      tags: [pets]
+      security: []
+      responses:
+        200: {description: The pet}
+    delete:
+      security:
+        - oauth: [pets:write]
+      parameters:
+        - name: petId
+          in: path
+          required: true
+          example: 7
+      responses:
+        204: {description: Deleted}
+components:
+  securitySchemes:
+    bearerAuth: {type: http, scheme: bearer}
+    apiKey: {type: apiKey, in: query, name: api_key}
+    oauth:
+      type: oauth2
+      flows:
+        clientCredentials:
+          tokenUrl: https://auth.example.com/token
+          scopes: {pets:write: Write pets}
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        id: {type: integer, format: int64, minimum: 1}
+        name: {type: string, example: Rex}
+        born: {type: string, format: date}
+        tags: {type: array, items: {type: string, enum: [good, lazy]}}
+        parent: {$ref: '#/components/schemas/Pet'}
+`
+
+func TestParse(t *testing.T) {
+	spec, err := Parse([]byte(testSpec))
+	assert.NoError(t, err)
+	assert.Equal(t, "Pet Store", spec.Info.Title)
+	assert.Equal(t, "https://eu.example.com/v1", spec.ServerUrl())
+
+	operations := spec.Operations()
+	assert.Len(t, operations, 4)
+	assert.Equal(t, []string{"GET /pets", "POST /pets", "GET /pets/{petId}", "DELETE /pets/{petId}"}, []string{
+		operations[0].Method + " " + operations[0].Path,
+		operations[1].Method + " " + operations[1].Path,
+		operations[2].Method + " " + operations[2].Path,
+		operations[3].Method + " " + operations[3].Path,
+	})
+	assert.Equal(t, "petId", operations[2].Parameters[0].Name, "the path parameters should be merged in")
+	assert.Len(t, operations[3].Parameters, 1, "the operation should override the path parameter")
+	assert.Equal(t, "default", operations[3].Tag())
+	assert.Equal(t, "createPet", operations[1].Name())
+	assert.Equal(t, "GET /pets/{petId}", operations[2].Name())
+
+	response := operations[0].Responses["200"].Content["application/json"]
+	assert.Equal(t, "object", response.Schema.Items.Type.Main(), "the $ref should be resolved")
+	assert.Empty(t, response.Schema.Items.Properties["parent"].Properties, "the recursive $ref should stop")
+}
+
+func TestParse_Errors(t *testing.T) {
+	_, err := Parse([]byte(`swagger: "2.0"`))
+	assert.ErrorContains(t, err, "Swagger 2.0")
+
+	_, err = Parse([]byte(`{"info": {}}`))
+	assert.Error(t, err)
+
+	_, err = Parse([]byte(`: :`))
+	assert.Error(t, err)
+
+	spec, err := Parse([]byte(`{"openapi": 3.1, "info": {"title": "Numbered"}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "Numbered", spec.Info.Title)
+}
+
+func TestGenerateExample(t *testing.T) {
+	spec, _ := Parse([]byte(testSpec))
+	pet := spec.Operations()[1].RequestBody.Content["application/json"].Schema
+
+	example := GenerateExample(pet).(map[string]any)
+	assert.Equal(t, 1, example["id"])
+	assert.Equal(t, "Rex", example["name"])
+	assert.Equal(t, "2024-01-01", example["born"])
+	assert.Equal(t, []any{"good"}, example["tags"])
+
+	assert.Equal(t, map[string]any{"a": "string", "b": false}, GenerateExample(&Schema{AllOf: []*Schema{
+		{Type: SchemaType{"object"}, Properties: map[string]*Schema{"a": {Type: SchemaType{"string"}}}},
+		{Properties: map[string]*Schema{"b": {Type: SchemaType{"boolean", "null"}}}},
+	}}))
+	assert.Nil(t, GenerateExample(nil))
+}
+
+func TestImport(t *testing.T) {
+	spec, _ := Parse([]byte(testSpec))
+	result := spec.Import()
+
+	collection := result.Collection
+	assert.Equal(t, "Pet Store", collection.Name)
+	assert.Equal(t, "https://eu.example.com/v1", collection.Variables["baseUrl"])
+	assert.Len(t, collection.Requests, 4)
+
+	list := collection.Requests[0]
+	assert.Equal(t, "pets", list.Folder)
+	assert.Equal(t, "List pets", list.Name)
+	assert.Equal(t, "{{baseUrl}}/pets?limit=20", list.Request.Url)
+	assert.Equal(t, "{{X-Request-Id}}", list.Request.Headers.Get("X-Request-Id"))
+	assert.Empty(t, list.Request.Headers.Get("Accept"))
+	assert.Equal(t, auth_module.AuthOptions{Type: auth_module.AuthBearer, Token: "{{token}}"}, list.Request.Auth)
+
+	create := collection.Requests[1]
+	assert.Equal(t, "{{baseUrl}}/pets?api_key={{api_key}}", create.Request.Url)
+	assert.True(t, create.Request.Auth.IsEmpty())
+	assert.Equal(t, "application/json", create.Request.Body[0].ContentType)
+	assert.Contains(t, create.Request.Body[0].Value, `"name": "Rex"`)
+
+	get := collection.Requests[2]
+	assert.Equal(t, "{{baseUrl}}/pets/{{petId}}", get.Request.Url)
+	assert.True(t, get.Request.Auth.IsEmpty(), "an empty security list should turn the auth off")
+
+	remove := collection.Requests[3]
+	assert.Equal(t, "{{baseUrl}}/pets/7", remove.Request.Url)
+	assert.Equal(t, auth_module.AuthOptions{
+		Type:         auth_module.AuthOAuth2,
+		TokenUrl:     "https://auth.example.com/token",
+		ClientId:     "{{client_id}}",
+		ClientSecret: "{{client_secret}}",
+		Scopes:       "pets:write",
+	}, remove.Request.Auth)
+	assert.Empty(t, result.Skipped)
+}
+
+func TestRequestOptions_Values(t *testing.T) {
+	spec, _ := Parse([]byte(testSpec))
+	operation := spec.Operations()[2]
+
+	options, _ := spec.RequestOptions(operation, map[string]string{"petId": "a b"})
+	assert.Equal(t, "{{baseUrl}}/pets/a%20b", options.Url)
+}
+
+func TestRequestBody_Forms(t *testing.T) {
+	body := RequestBody{Content: map[string]MediaType{
+		"multipart/form-data": {Schema: &Schema{Type: SchemaType{"object"}, Properties: map[string]*Schema{
+			"title": {Type: SchemaType{"string"}},
+			"file":  {Type: SchemaType{"string"}, Format: "binary"},
+		}}},
+	}}
+
+	data, message := requestBody(body)
+	assert.Equal(t, []http_utility.HttpContentData{
+		{ContentType: "multipart/form-data", Key: "file", Value: ""},
+		{ContentType: "multipart/form-data", Key: "title", Value: "string"},
+	}, data)
+	assert.NotEmpty(t, message)
+
+	_, message = requestBody(RequestBody{Content: map[string]MediaType{"application/octet-stream": {}}})
+	assert.Contains(t, message, "no example")
+}
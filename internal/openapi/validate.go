@@ -0,0 +1,446 @@
+package openapi_module
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"mime"
+	neturl "net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+)
+
+// ValidationExitCode is the exit code of a headless run whose response
+// doesn't match the spec or the schema, like EX_DATAERR of sysexits.h.
+const ValidationExitCode = 65
+
+// Problem is a difference between a response and its contract. In is
+// "status", "header", "body" or "request", Pointer is a JSON pointer in the
+// body or the name of the header.
+type Problem struct {
+	In      string `json:"in"`
+	Pointer string `json:"pointer,omitempty"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	if p.Pointer == "" {
+		return p.In + ": " + p.Message
+	}
+	return p.In + " " + p.Pointer + ": " + p.Message
+}
+
+// Validation is the result of checking a response. Against is the operation,
+// like "GET /users/{id}", or the path of the schema file.
+type Validation struct {
+	Against  string    `json:"against"`
+	Problems []Problem `json:"problems"`
+}
+
+func (v Validation) Passed() bool {
+	return len(v.Problems) == 0
+}
+
+// Validator checks responses against an OpenAPI spec, with the operation
+// picked by method and path, or against a JSON Schema for the body.
+type Validator struct {
+	Source string
+	Spec   *Spec
+	Schema *Schema
+}
+
+// LoadValidator reads an OpenAPI 3 spec, or a JSON Schema when the document
+// has no openapi field.
+func LoadValidator(path string) (*Validator, error) {
+	data, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	root, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := root["openapi"]; ok {
+		spec, err := Parse(data)
+		if err != nil {
+			return nil, err
+		}
+		return &Validator{Source: path, Spec: spec}, nil
+	}
+	if _, ok := root["swagger"]; ok {
+		return nil, errors.New("Swagger 2.0 documents are not supported, convert them to OpenAPI 3 first")
+	}
+
+	var schema Schema
+	if err := convert(root, &schema); err != nil {
+		return nil, errors.New("invalid JSON Schema: " + err.Error())
+	}
+	return &Validator{Source: path, Schema: &schema}, nil
+}
+
+func (v *Validator) Validate(res request_module.RequestResponse) Validation {
+	if v.Spec == nil {
+		return Validation{Against: v.Source, Problems: validateBody(res, "application/json", v.Schema)}
+	}
+
+	operation, ok := v.Spec.FindOperation(res.Request.Method, res.Request.Url)
+	if !ok {
+		return Validation{Against: v.Source, Problems: []Problem{{
+			In:      "request",
+			Message: "no operation of the spec matches " + res.Request.Method + " " + urlPath(res.Request.Url),
+		}}}
+	}
+	return Validation{
+		Against:  operation.Method + " " + operation.Path,
+		Problems: ValidateResponse(operation, res),
+	}
+}
+
+// FindOperation matches a request to an operation. The URL may have the
+// base path of a server before the path of the spec, and literal segments
+// win over parameters, so /users/me is picked over /users/{id}.
+func (s *Spec) FindOperation(method string, rawUrl string) (Operation, bool) {
+	paths := []string{urlPath(rawUrl)}
+	for _, server := range s.Servers {
+		base := server.Url
+		for name, variable := range server.Variables {
+			base = strings.ReplaceAll(base, "{"+name+"}", variable.Default)
+		}
+		if basePath := strings.TrimSuffix(urlPath(base), "/"); basePath != "" {
+			if rest, ok := strings.CutPrefix(paths[0], basePath); ok && strings.HasPrefix(rest, "/") {
+				paths = append(paths, rest)
+			}
+		}
+	}
+
+	var best Operation
+	found, bestScore := false, 0
+	for _, operation := range s.Operations() {
+		if !strings.EqualFold(operation.Method, method) {
+			continue
+		}
+		pattern := regexp.MustCompile("^" + pathPattern(operation.Path) + "/?$")
+		for _, path := range paths {
+			if !pattern.MatchString(path) {
+				continue
+			}
+			score := len(operation.Path) - strings.Count(operation.Path, "{")*100
+			if !found || score > bestScore {
+				best, found, bestScore = operation, true, score
+			}
+		}
+	}
+	return best, found
+}
+
+// pathPattern turns /users/{id} into a regular expression with a segment
+// for each parameter.
+func pathPattern(template string) string {
+	var pattern strings.Builder
+	for rest := template; ; {
+		open := strings.Index(rest, "{")
+		if open < 0 {
+			pattern.WriteString(regexp.QuoteMeta(rest))
+			break
+		}
+		pattern.WriteString(regexp.QuoteMeta(rest[:open]))
+		end := strings.Index(rest[open:], "}")
+		if end < 0 {
+			pattern.WriteString(regexp.QuoteMeta(rest[open:]))
+			break
+		}
+		pattern.WriteString("[^/]+")
+		rest = rest[open+end+1:]
+	}
+	return pattern.String()
+}
+
+func urlPath(rawUrl string) string {
+	parsed, err := neturl.Parse(rawUrl)
+	if err != nil || parsed.Path == "" {
+		return "/"
+	}
+	return parsed.Path
+}
+
+// ValidateResponse checks the status, the headers and the body of a response
+// against an operation.
+func ValidateResponse(operation Operation, res request_module.RequestResponse) []Problem {
+	response, ok := findResponse(operation.Responses, res.StatusCode)
+	if !ok {
+		return []Problem{{In: "status", Message: fmt.Sprintf("the status %d is not documented", res.StatusCode)}}
+	}
+
+	var problems []Problem
+	names := make([]string, 0, len(response.Headers))
+	for name := range response.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		header := response.Headers[name]
+		values := res.Headers.Values(name)
+		if len(values) == 0 {
+			if header.Required {
+				problems = append(problems, Problem{In: "header", Pointer: name, Message: "the required header is missing"})
+			}
+			continue
+		}
+		for _, problem := range ValidateValue(header.Schema, coerce(header.Schema, values[0]), "") {
+			problems = append(problems, Problem{In: "header", Pointer: name, Message: problem.Message})
+		}
+	}
+
+	if len(response.Content) > 0 && res.Download == nil {
+		contentType, _, _ := mime.ParseMediaType(res.Headers.Get("Content-Type"))
+		media, ok := findMedia(response.Content, contentType)
+		if !ok {
+			problems = append(problems, Problem{In: "header", Pointer: "Content-Type", Message: "the content type \"" + contentType + "\" is not documented"})
+		} else {
+			problems = append(problems, validateBody(res, contentType, media.Schema)...)
+		}
+	}
+	return problems
+}
+
+// findResponse looks up the exact status, then its range like 2XX, then
+// the default response.
+func findResponse(responses map[string]Response, status int) (Response, bool) {
+	code := strconv.Itoa(status)
+	for _, key := range []string{code, code[:1] + "XX", code[:1] + "xx", "default"} {
+		if response, ok := responses[key]; ok {
+			return response, true
+		}
+	}
+	return Response{}, false
+}
+
+// findMedia looks up the content type, then application/* and */*.
+func findMedia(content map[string]MediaType, contentType string) (MediaType, bool) {
+	main, _, _ := strings.Cut(contentType, "/")
+	for _, key := range []string{contentType, main + "/*", "*/*"} {
+		for declared, media := range content {
+			if strings.EqualFold(strings.TrimSpace(strings.Split(declared, ";")[0]), key) {
+				return media, true
+			}
+		}
+	}
+	return MediaType{}, false
+}
+
+// validateBody decodes JSON bodies, other ones are checked as a string.
+func validateBody(res request_module.RequestResponse, contentType string, schema *Schema) []Problem {
+	if schema == nil || reflect.DeepEqual(*schema, Schema{}) {
+		return nil
+	}
+	var body any = res.Result
+	if contentType == "application/json" || strings.HasSuffix(contentType, "+json") {
+		if err := json.Unmarshal([]byte(res.Result), &body); err != nil {
+			return []Problem{{In: "body", Message: "the body is not valid JSON"}}
+		}
+	}
+	problems := ValidateValue(schema, body, "")
+	for i := range problems {
+		problems[i].In = "body"
+	}
+	return problems
+}
+
+// coerce reads a header value as the type of its schema.
+func coerce(schema *Schema, value string) any {
+	if schema == nil {
+		return value
+	}
+	switch schema.Type.Main() {
+	case "integer", "number":
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			return number
+		}
+	case "boolean":
+		if boolean, err := strconv.ParseBool(value); err == nil {
+			return boolean
+		}
+	}
+	return value
+}
+
+// ValidateValue checks a decoded JSON value against a schema, the problems
+// point at the wrong value from pointer.
+func ValidateValue(schema *Schema, value any, pointer string) []Problem {
+	if schema == nil {
+		return nil
+	}
+	problem := func(format string, args ...any) Problem {
+		return Problem{Pointer: pointer, Message: fmt.Sprintf(format, args...)}
+	}
+
+	var problems []Problem
+	for _, part := range schema.AllOf {
+		problems = append(problems, ValidateValue(part, value, pointer)...)
+	}
+	if len(schema.AnyOf) > 0 && matching(schema.AnyOf, value) == 0 {
+		problems = append(problems, problem("does not match any of the anyOf schemas"))
+	}
+	if len(schema.OneOf) > 0 {
+		if count := matching(schema.OneOf, value); count != 1 {
+			problems = append(problems, problem("matches %d of the oneOf schemas instead of one", count))
+		}
+	}
+
+	actual := jsonType(value)
+	if value == nil && schema.Nullable {
+		return problems
+	}
+	if len(schema.Type) > 0 && !schema.Type.Is(actual) && !(actual == "integer" && schema.Type.Is("number")) {
+		return append(problems, problem("expected %s, got %s", strings.Join(schema.Type, " or "), actual))
+	}
+	if len(schema.Enum) > 0 && !containsValue(schema.Enum, value) {
+		problems = append(problems, problem("%s is not one of the allowed values", describe(value)))
+	}
+	if schema.Const != nil && !reflect.DeepEqual(schema.Const, value) {
+		problems = append(problems, problem("expected %s, got %s", describe(schema.Const), describe(value)))
+	}
+
+	switch value := value.(type) {
+	case string:
+		length := utf8.RuneCountInString(value)
+		if schema.MinLength != nil && length < *schema.MinLength {
+			problems = append(problems, problem("shorter than %d characters", *schema.MinLength))
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			problems = append(problems, problem("longer than %d characters", *schema.MaxLength))
+		}
+		if schema.Pattern != "" {
+			if pattern, err := regexp.Compile(schema.Pattern); err == nil && !pattern.MatchString(value) {
+				problems = append(problems, problem("does not match the pattern %s", schema.Pattern))
+			}
+		}
+		if !validFormat(schema.Format, value) {
+			problems = append(problems, problem("is not a valid %s", schema.Format))
+		}
+	case float64:
+		if schema.Minimum != nil && value < *schema.Minimum {
+			problems = append(problems, problem("less than the minimum %v", *schema.Minimum))
+		}
+		if schema.Maximum != nil && value > *schema.Maximum {
+			problems = append(problems, problem("greater than the maximum %v", *schema.Maximum))
+		}
+	case []any:
+		if schema.MinItems != nil && len(value) < *schema.MinItems {
+			problems = append(problems, problem("has less than %d items", *schema.MinItems))
+		}
+		if schema.MaxItems != nil && len(value) > *schema.MaxItems {
+			problems = append(problems, problem("has more than %d items", *schema.MaxItems))
+		}
+		for i, item := range value {
+			problems = append(problems, ValidateValue(schema.Items, item, pointer+"/"+strconv.Itoa(i))...)
+		}
+	case map[string]any:
+		for _, name := range schema.Required {
+			if _, ok := value[name]; !ok {
+				problems = append(problems, problem("the required property %q is missing", name))
+			}
+		}
+		names := make([]string, 0, len(value))
+		for name := range value {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			nested := pointer + "/" + escapePointer(name)
+			if property, ok := schema.Properties[name]; ok {
+				problems = append(problems, ValidateValue(property, value[name], nested)...)
+			} else if schema.NoAdditional {
+				problems = append(problems, Problem{Pointer: nested, Message: "the property is not allowed"})
+			} else if schema.AdditionalProperties != nil {
+				problems = append(problems, ValidateValue(schema.AdditionalProperties, value[name], nested)...)
+			}
+		}
+	}
+	return problems
+}
+
+func matching(schemas []*Schema, value any) int {
+	count := 0
+	for _, schema := range schemas {
+		if len(ValidateValue(schema, value, "")) == 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// jsonType names the type of a value decoded by encoding/json, numbers
+// without a fraction are integers.
+func jsonType(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if value == math.Trunc(value) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return "unknown"
+}
+
+func containsValue(values []any, value any) bool {
+	for _, v := range values {
+		if reflect.DeepEqual(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func describe(value any) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// validFormat checks the common formats, unknown ones are accepted.
+func validFormat(format string, value string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", value)
+		return err == nil
+	case "uuid":
+		return uuidPattern.MatchString(value)
+	case "email":
+		local, domain, ok := strings.Cut(value, "@")
+		return ok && local != "" && domain != ""
+	case "uri", "url":
+		parsed, err := neturl.Parse(value)
+		return err == nil && parsed.Scheme != ""
+	}
+	return true
+}
+
+func escapePointer(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,170 @@
+package openapi_module
+
+import (
+	"net/http"
+	"os"
+	"testing"
+
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/stretchr/testify/assert"
+)
+
+const validationSpec = `
+openapi: 3.1.0
+info: {title: Users}
+servers: [{url: "https://api.example.com/v1"}]
+paths:
+  /users/{id}:
+    get:
+      responses:
+        200:
+          description: The user
+          headers:
+            X-Rate-Limit: {required: true, schema: {type: integer, maximum: 100}}
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id, name]
+                additionalProperties: false
+                properties:
+                  id: {type: integer}
+                  name: {type: string, minLength: 2}
+                  email: {type: [string, "null"], format: email}
+                  roles: {type: array, items: {enum: [admin, user]}}
+        4XX:
+          description: An error
+          content:
+            text/plain:
+              schema: {type: string, maxLength: 5}
+  /users/me:
+    get:
+      responses:
+        200: {description: The current user}
+`
+
+func response(method string, url string, status int, contentType string, body string) request_module.RequestResponse {
+	return request_module.RequestResponse{
+		StatusCode: status,
+		Headers:    http.Header{"Content-Type": {contentType}, "X-Rate-Limit": {"10"}},
+		Result:     body,
+		Request:    request_module.RequestOptions{Method: method, Url: url},
+	}
+}
+
+func TestFindOperation(t *testing.T) {
+	spec, _ := Parse([]byte(validationSpec))
+
+	operation, ok := spec.FindOperation("GET", "https://api.example.com/v1/users/42?full=1")
+	assert.True(t, ok)
+	assert.Equal(t, "/users/{id}", operation.Path)
+
+	operation, ok = spec.FindOperation("get", "http://localhost:8080/users/me/")
+	assert.True(t, ok)
+	assert.Equal(t, "/users/me", operation.Path, "the literal path should win over the parameter")
+
+	_, ok = spec.FindOperation("DELETE", "https://api.example.com/v1/users/42")
+	assert.False(t, ok)
+	_, ok = spec.FindOperation("GET", "https://api.example.com/v1/users/42/posts")
+	assert.False(t, ok)
+}
+
+func TestValidate_Spec(t *testing.T) {
+	spec, _ := Parse([]byte(validationSpec))
+	validator := &Validator{Source: "api.yaml", Spec: spec}
+
+	valid := validator.Validate(response("GET", "https://api.example.com/v1/users/1", 200, "application/json; charset=utf-8",
+		`{"id": 1, "name": "Ada", "email": null, "roles": ["admin"]}`))
+	assert.True(t, valid.Passed(), valid.Problems)
+	assert.Equal(t, "GET /users/{id}", valid.Against)
+
+	res := response("GET", "https://api.example.com/v1/users/1", 200, "application/json",
+		`{"id": 1.5, "name": "A", "email": "nope", "roles": ["root"], "extra/key": true}`)
+	res.Headers.Set("X-Rate-Limit", "500")
+	invalid := validator.Validate(res)
+	assert.Equal(t, []string{
+		"header X-Rate-Limit: greater than the maximum 100",
+		"body /email: is not a valid email",
+		"body /extra~1key: the property is not allowed",
+		"body /id: expected integer, got number",
+		"body /name: shorter than 2 characters",
+		"body /roles/0: \"root\" is not one of the allowed values",
+	}, problemStrings(invalid.Problems))
+
+	res.Headers.Del("X-Rate-Limit")
+	res.Result = `{"id": 1}`
+	assert.Equal(t, []string{
+		"header X-Rate-Limit: the required header is missing",
+		"body: the required property \"name\" is missing",
+	}, problemStrings(validator.Validate(res).Problems))
+
+	assert.Equal(t, []string{"body: longer than 5 characters"},
+		problemStrings(validator.Validate(response("GET", "/v1/users/1", 404, "text/plain", "not found")).Problems))
+	assert.Equal(t, []string{"status: the status 500 is not documented"},
+		problemStrings(validator.Validate(response("GET", "/v1/users/1", 500, "text/plain", "")).Problems))
+	assert.Equal(t, []string{"header Content-Type: the content type \"text/html\" is not documented"},
+		problemStrings(validator.Validate(response("GET", "/v1/users/1", 200, "text/html", "<p>")).Problems))
+	assert.Equal(t, []string{"body: the body is not valid JSON"},
+		problemStrings(validator.Validate(response("GET", "/v1/users/1", 200, "application/json", "{")).Problems))
+	assert.Equal(t, []string{"request: no operation of the spec matches POST /v1/users"},
+		problemStrings(validator.Validate(response("POST", "/v1/users", 201, "", "")).Problems))
+}
+
+func TestValidateValue_Combinators(t *testing.T) {
+	schema := &Schema{OneOf: []*Schema{
+		{Type: SchemaType{"string"}},
+		{Type: SchemaType{"integer"}},
+		{Type: SchemaType{"number"}, Minimum: ptr(10.0)},
+	}}
+	assert.Empty(t, ValidateValue(schema, "a", ""))
+	assert.Equal(t, "matches 2 of the oneOf schemas instead of one", ValidateValue(schema, 12.0, "")[0].Message)
+	assert.Equal(t, "does not match any of the anyOf schemas",
+		ValidateValue(&Schema{AnyOf: []*Schema{{Type: SchemaType{"boolean"}}}}, "a", "/x")[0].Message)
+	assert.Empty(t, ValidateValue(&Schema{Type: SchemaType{"string"}, Nullable: true}, nil, ""))
+	assert.Empty(t, ValidateValue(&Schema{Type: SchemaType{"number"}}, 3.0, ""), "an integer is a number")
+	assert.Len(t, ValidateValue(&Schema{AdditionalProperties: &Schema{Type: SchemaType{"string"}}},
+		map[string]any{"a": "b", "c": 1.0}, ""), 1)
+}
+
+func TestLoadValidator(t *testing.T) {
+	oldRead := readFile
+	defer func() { readFile = oldRead }()
+	files := map[string]string{
+		"api.yaml":    validationSpec,
+		"user.json":   `{"$schema": "https://json-schema.org/draft/2020-12/schema", "$defs": {"name": {"type": "string"}}, "type": "object", "properties": {"name": {"$ref": "#/$defs/name"}}}`,
+		"swagger.yml": `swagger: "2.0"`,
+	}
+	readFile = func(path string) ([]byte, error) {
+		if content, ok := files[path]; ok {
+			return []byte(content), nil
+		}
+		return nil, os.ErrNotExist
+	}
+
+	validator, err := LoadValidator("api.yaml")
+	assert.NoError(t, err)
+	assert.NotNil(t, validator.Spec)
+
+	validator, err = LoadValidator("user.json")
+	assert.NoError(t, err)
+	validation := validator.Validate(response("GET", "/anything", 200, "text/plain", `{"name": 1}`))
+	assert.Equal(t, "user.json", validation.Against)
+	assert.Equal(t, []string{"body /name: expected string, got integer"}, problemStrings(validation.Problems))
+
+	_, err = LoadValidator("swagger.yml")
+	assert.Error(t, err)
+	_, err = LoadValidator("missing.json")
+	assert.Error(t, err)
+}
+
+func problemStrings(problems []Problem) []string {
+	var strings []string
+	for _, problem := range problems {
+		strings = append(strings, problem.String())
+	}
+	return strings
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
@@ -0,0 +1,476 @@
+package postman_module
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strings"
+
+	auth_module "github.com/diogopereiradev/httpzen/internal/auth"
+	collection_module "github.com/diogopereiradev/httpzen/internal/collection"
+	environment_module "github.com/diogopereiradev/httpzen/internal/environment"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/diogopereiradev/httpzen/internal/utils/http_utility"
+)
+
+// The types follow the Postman Collection v2.1 schema, only the fields httpzen
+// can use are read. See https://schema.postman.com/collection/json/v2.1.0/draft-07/docs/index.html.
+
+type collection struct {
+	Info     info       `json:"info"`
+	Item     []item     `json:"item"`
+	Variable []variable `json:"variable"`
+	Auth     *auth      `json:"auth"`
+	Event    []event    `json:"event"`
+}
+
+type info struct {
+	Name   string `json:"name"`
+	Schema string `json:"schema"`
+}
+
+// item is either a folder, with items, or a request.
+type item struct {
+	Name    string   `json:"name"`
+	Item    []item   `json:"item"`
+	Request *request `json:"request"`
+	Auth    *auth    `json:"auth"`
+	Event   []event  `json:"event"`
+}
+
+type request struct {
+	Method string          `json:"method"`
+	Header []keyValue      `json:"header"`
+	Url    json.RawMessage `json:"url"`
+	Body   *body           `json:"body"`
+	Auth   *auth           `json:"auth"`
+}
+
+type url struct {
+	Raw      string          `json:"raw"`
+	Protocol string          `json:"protocol"`
+	Host     json.RawMessage `json:"host"`
+	Path     json.RawMessage `json:"path"`
+	Query    []keyValue      `json:"query"`
+	Variable []keyValue      `json:"variable"`
+}
+
+type keyValue struct {
+	Key      string          `json:"key"`
+	Value    string          `json:"value"`
+	Disabled bool            `json:"disabled"`
+	Type     string          `json:"type"`
+	Src      json.RawMessage `json:"src"`
+}
+
+type body struct {
+	Mode       string     `json:"mode"`
+	Raw        string     `json:"raw"`
+	Urlencoded []keyValue `json:"urlencoded"`
+	Formdata   []keyValue `json:"formdata"`
+	File       *struct {
+		Src string `json:"src"`
+	} `json:"file"`
+	Graphql *struct {
+		Query     string `json:"query"`
+		Variables string `json:"variables"`
+	} `json:"graphql"`
+	Options struct {
+		Raw struct {
+			Language string `json:"language"`
+		} `json:"raw"`
+	} `json:"options"`
+}
+
+type auth struct {
+	Type   string            `json:"type"`
+	Values map[string]string `json:"-"`
+}
+
+// UnmarshalJSON reads the attributes of the auth type, stored as a list of
+// key/value pairs under the type name, like "bearer": [{"key": "token"}].
+func (a *auth) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw["type"], &a.Type); err != nil {
+		return err
+	}
+
+	a.Values = map[string]string{}
+	var attributes []struct {
+		Key   string `json:"key"`
+		Value any    `json:"value"`
+	}
+	if err := json.Unmarshal(raw[a.Type], &attributes); err == nil {
+		for _, attribute := range attributes {
+			if attribute.Value != nil {
+				a.Values[attribute.Key] = fmt.Sprint(attribute.Value)
+			}
+		}
+	}
+	return nil
+}
+
+type variable struct {
+	Key      string `json:"key"`
+	Value    any    `json:"value"`
+	Disabled bool   `json:"disabled"`
+}
+
+type event struct {
+	Listen string `json:"listen"`
+	Script struct {
+		Exec json.RawMessage `json:"exec"`
+	} `json:"script"`
+}
+
+type environment struct {
+	Name   string `json:"name"`
+	Values []struct {
+		Key     string `json:"key"`
+		Value   any    `json:"value"`
+		Enabled *bool  `json:"enabled"`
+	} `json:"values"`
+}
+
+var rawLanguages = map[string]string{
+	"json":       "application/json",
+	"xml":        "application/xml",
+	"html":       "text/html",
+	"javascript": "application/javascript",
+	"text":       "text/plain",
+}
+
+type importer struct {
+	result collection_module.ImportResult
+}
+
+// Parse reads a collection export and, optionally, environment exports.
+// Scripts, unsupported auth types and dynamic variables are listed in
+// Skipped instead of failing the import.
+func Parse(data []byte, environments ...[]byte) (collection_module.ImportResult, error) {
+	var c collection
+	if err := json.Unmarshal(data, &c); err != nil {
+		return collection_module.ImportResult{}, errors.New("not a Postman collection: " + err.Error())
+	}
+	if c.Info.Schema != "" && !strings.Contains(c.Info.Schema, "v2.1") && !strings.Contains(c.Info.Schema, "v2.0") {
+		return collection_module.ImportResult{}, errors.New("only Postman collections v2.1 are supported, the schema is " + c.Info.Schema)
+	}
+	if c.Info.Name == "" {
+		return collection_module.ImportResult{}, errors.New("not a Postman collection: the info.name field is missing")
+	}
+
+	im := &importer{result: collection_module.ImportResult{
+		Collection: collection_module.Collection{Name: c.Info.Name, Variables: map[string]string{}},
+	}}
+	for _, v := range c.Variable {
+		if !v.Disabled && v.Value != nil {
+			im.result.Collection.Variables[v.Key] = fmt.Sprint(v.Value)
+		}
+	}
+	im.events("the collection", c.Event)
+	im.items(c.Item, "", c.Auth)
+
+	for _, data := range environments {
+		env, err := parseEnvironment(data)
+		if err != nil {
+			return collection_module.ImportResult{}, err
+		}
+		im.result.Environments = append(im.result.Environments, env)
+	}
+	return im.result, nil
+}
+
+func parseEnvironment(data []byte) (environment_module.Environment, error) {
+	var e environment
+	if err := json.Unmarshal(data, &e); err != nil || e.Name == "" {
+		return environment_module.Environment{}, errors.New("not a Postman environment export")
+	}
+
+	env := environment_module.Environment{Name: e.Name, Variables: map[string]string{}}
+	for _, value := range e.Values {
+		if (value.Enabled == nil || *value.Enabled) && value.Value != nil {
+			env.Variables[value.Key] = fmt.Sprint(value.Value)
+		}
+	}
+	return env, nil
+}
+
+func (im *importer) items(items []item, folder string, parentAuth *auth) {
+	for _, it := range items {
+		path := it.Name
+		if folder != "" {
+			path = folder + "/" + it.Name
+		}
+
+		if it.Request == nil {
+			folderAuth := parentAuth
+			if it.Auth != nil {
+				folderAuth = it.Auth
+			}
+			im.events("the folder \""+path+"\"", it.Event)
+			im.items(it.Item, path, folderAuth)
+			continue
+		}
+
+		im.events(path, it.Event)
+		requestAuth := parentAuth
+		if it.Request.Auth != nil {
+			requestAuth = it.Request.Auth
+		}
+		options := im.request(path, *it.Request, requestAuth)
+		im.result.Collection.Requests = append(im.result.Collection.Requests, collection_module.SavedRequest{
+			Name:    it.Name,
+			Folder:  folder,
+			Request: options,
+		})
+	}
+}
+
+func (im *importer) request(path string, r request, a *auth) request_module.RequestOptions {
+	options := request_module.RequestOptions{
+		Method:  strings.ToUpper(r.Method),
+		Url:     im.url(path, r.Url),
+		Headers: http.Header{},
+	}
+	if options.Method == "" {
+		options.Method = "GET"
+	}
+	for _, header := range r.Header {
+		if !header.Disabled {
+			options.Headers.Add(header.Key, header.Value)
+		}
+	}
+	if r.Body != nil {
+		options.Body = im.body(path, *r.Body, options.Headers.Get("Content-Type"))
+	}
+	if a != nil {
+		im.auth(path, *a, &options)
+	}
+
+	im.dynamicVariables(path, options)
+	return options
+}
+
+// url prefers the raw URL, it keeps the variables as they were written.
+// Path variables, like :id, are filled with their values.
+func (im *importer) url(path string, data json.RawMessage) string {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		return raw
+	}
+
+	var u url
+	if err := json.Unmarshal(data, &u); err != nil {
+		return ""
+	}
+	raw = u.Raw
+	if raw == "" {
+		raw = joinUrl(u)
+	}
+	for _, v := range u.Variable {
+		if v.Value == "" {
+			im.skip(path + ": the path variable :" + v.Key + " has no value")
+			continue
+		}
+		raw = replacePathVariable(raw, v.Key, v.Value)
+	}
+	return raw
+}
+
+func joinUrl(u url) string {
+	var host, path string
+	var parts []string
+	if json.Unmarshal(u.Host, &parts) == nil {
+		host = strings.Join(parts, ".")
+	} else {
+		_ = json.Unmarshal(u.Host, &host)
+	}
+	parts = nil
+	if json.Unmarshal(u.Path, &parts) == nil {
+		path = strings.Join(parts, "/")
+	} else {
+		_ = json.Unmarshal(u.Path, &path)
+	}
+
+	raw := host
+	if u.Protocol != "" {
+		raw = u.Protocol + "://" + raw
+	}
+	if path != "" {
+		raw += "/" + strings.TrimPrefix(path, "/")
+	}
+	var query []string
+	for _, q := range u.Query {
+		if !q.Disabled {
+			query = append(query, neturl.QueryEscape(q.Key)+"="+neturl.QueryEscape(q.Value))
+		}
+	}
+	if len(query) > 0 {
+		raw += "?" + strings.Join(query, "&")
+	}
+	return raw
+}
+
+func replacePathVariable(raw string, name string, value string) string {
+	base, query, hasQuery := strings.Cut(raw, "?")
+	segments := strings.Split(base, "/")
+	for i, segment := range segments {
+		if segment == ":"+name {
+			segments[i] = value
+		}
+	}
+	base = strings.Join(segments, "/")
+	if hasQuery {
+		return base + "?" + query
+	}
+	return base
+}
+
+func (im *importer) body(path string, b body, contentType string) []http_utility.HttpContentData {
+	switch b.Mode {
+	case "raw":
+		if b.Raw == "" {
+			return nil
+		}
+		if contentType == "" {
+			contentType = rawLanguages[b.Options.Raw.Language]
+		}
+		if contentType == "" {
+			contentType = "text/plain"
+		}
+		return []http_utility.HttpContentData{{ContentType: contentType, Value: b.Raw}}
+	case "urlencoded":
+		var data []http_utility.HttpContentData
+		for _, field := range b.Urlencoded {
+			if !field.Disabled {
+				data = append(data, http_utility.HttpContentData{ContentType: "application/x-www-form-urlencoded", Key: field.Key, Value: field.Value})
+			}
+		}
+		return data
+	case "formdata":
+		var data []http_utility.HttpContentData
+		for _, field := range b.Formdata {
+			if field.Disabled {
+				continue
+			}
+			value := field.Value
+			if field.Type == "file" {
+				value = fileSource(field.Src)
+				if value == "" {
+					im.skip(path + ": the form file \"" + field.Key + "\" has no path")
+					continue
+				}
+			}
+			data = append(data, http_utility.HttpContentData{ContentType: "multipart/form-data", Key: field.Key, Value: value})
+		}
+		return data
+	case "file":
+		if b.File == nil || b.File.Src == "" {
+			im.skip(path + ": the file body has no path")
+			return nil
+		}
+		return http_utility.NewFileBody(b.File.Src, contentType)
+	case "graphql":
+		if b.Graphql == nil {
+			return nil
+		}
+		return http_utility.NewGraphQLBody(b.Graphql.Query, b.Graphql.Variables, "")
+	case "":
+		return nil
+	}
+	im.skip(path + ": the " + b.Mode + " body mode is not supported")
+	return nil
+}
+
+// fileSource reads the path of a form file, Postman writes a single path
+// or a list of them.
+func fileSource(src json.RawMessage) string {
+	var path string
+	if json.Unmarshal(src, &path) == nil {
+		return path
+	}
+	var paths []string
+	if json.Unmarshal(src, &paths) == nil && len(paths) > 0 {
+		return paths[0]
+	}
+	return ""
+}
+
+func (im *importer) auth(path string, a auth, options *request_module.RequestOptions) {
+	switch a.Type {
+	case "noauth", "":
+	case "basic":
+		options.Auth = auth_module.AuthOptions{Type: auth_module.AuthBasic, Username: a.Values["username"], Password: a.Values["password"]}
+	case "digest":
+		options.Auth = auth_module.AuthOptions{Type: auth_module.AuthDigest, Username: a.Values["username"], Password: a.Values["password"]}
+	case "bearer":
+		options.Auth = auth_module.AuthOptions{Type: auth_module.AuthBearer, Token: a.Values["token"]}
+	case "oauth2":
+		if grant := a.Values["grant_type"]; grant != "" && grant != "client_credentials" {
+			im.skip(path + ": OAuth2 with the " + grant + " grant, only client credentials are supported")
+			return
+		}
+		options.Auth = auth_module.AuthOptions{
+			Type:         auth_module.AuthOAuth2,
+			TokenUrl:     a.Values["accessTokenUrl"],
+			ClientId:     a.Values["clientId"],
+			ClientSecret: a.Values["clientSecret"],
+			Scopes:       a.Values["scope"],
+		}
+	case "apikey":
+		// An API key is a plain header or query parameter.
+		key, value := a.Values["key"], a.Values["value"]
+		if a.Values["in"] == "query" {
+			options.Url = http_utility.AddQuery(options.Url, neturl.Values{key: {value}})
+		} else {
+			options.Headers.Set(key, value)
+		}
+	default:
+		im.skip(path + ": " + a.Type + " auth is not supported")
+	}
+}
+
+func (im *importer) events(owner string, events []event) {
+	for _, e := range events {
+		var lines []string
+		_ = json.Unmarshal(e.Script.Exec, &lines)
+		if strings.TrimSpace(strings.Join(lines, "")) == "" {
+			continue
+		}
+		switch e.Listen {
+		case "prerequest":
+			im.skip(owner + ": the pre-request script")
+		case "test":
+			im.skip(owner + ": the test script")
+		default:
+			im.skip(owner + ": the " + e.Listen + " script")
+		}
+	}
+}
+
+// dynamicVariables reports Postman's generated values, like {{$guid}}, they
+// are sent as they are.
+func (im *importer) dynamicVariables(path string, options request_module.RequestOptions) {
+	values := []string{options.Url}
+	for _, header := range options.Headers {
+		values = append(values, header...)
+	}
+	for _, part := range options.Body {
+		values = append(values, part.Value)
+	}
+	for _, value := range values {
+		if strings.Contains(value, "{{$") {
+			im.skip(path + ": dynamic variables like {{$guid}} are sent as they are")
+			return
+		}
+	}
+}
+
+func (im *importer) skip(message string) {
+	im.result.Skipped = append(im.result.Skipped, message)
+}
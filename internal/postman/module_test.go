@@ -0,0 +1,134 @@
+package postman_module
+
+import (
+	"testing"
+
+	auth_module "github.com/diogopereiradev/httpzen/internal/auth"
+	"github.com/diogopereiradev/httpzen/internal/utils/http_utility"
+	"github.com/stretchr/testify/assert"
+)
+
+const testCollection = `{
+	"info": {"name": "Shop API", "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"},
+	"auth": {"type": "bearer", "bearer": [{"key": "token", "value": "{{token}}", "type": "string"}]},
+	"variable": [{"key": "baseUrl", "value": "https://api.example.com"}, {"key": "off", "value": "x", "disabled": true}],
+	"event": [{"listen": "prerequest", "script": {"exec": ["pm.variables.set('a', 1)"]}}],
+	"item": [
+		{
+			"name": "Users",
+			"item": [
+				{
+					"name": "Get user",
+					"request": {
+						"method": "GET",
+						"header": [{"key": "Accept", "value": "application/json"}, {"key": "X-Off", "value": "1", "disabled": true}],
+						"url": {"raw": "{{baseUrl}}/users/:id?expand=true", "variable": [{"key": "id", "value": "42"}]}
+					}
+				},
+				{
+					"name": "Create user",
+					"event": [{"listen": "test", "script": {"exec": ["pm.test('ok')"]}}],
+					"request": {
+						"method": "POST",
+						"auth": {"type": "basic", "basic": [{"key": "username", "value": "alice"}, {"key": "password", "value": "secret"}]},
+						"url": "{{baseUrl}}/users",
+						"body": {"mode": "raw", "raw": "{\"name\": \"{{$randomFirstName}}\"}", "options": {"raw": {"language": "json"}}}
+					}
+				}
+			]
+		},
+		{
+			"name": "Login",
+			"request": {
+				"method": "POST",
+				"auth": {"type": "noauth"},
+				"url": {"protocol": "https", "host": ["auth", "example", "com"], "path": ["login"], "query": [{"key": "v", "value": "2"}]},
+				"body": {"mode": "urlencoded", "urlencoded": [{"key": "user", "value": "alice"}, {"key": "skip", "value": "1", "disabled": true}]}
+			}
+		},
+		{
+			"name": "Upload",
+			"request": {
+				"method": "POST",
+				"auth": {"type": "awsv4", "awsv4": []},
+				"url": "{{baseUrl}}/files",
+				"body": {"mode": "formdata", "formdata": [{"key": "title", "value": "Report", "type": "text"}, {"key": "file", "type": "file", "src": "/tmp/report.pdf"}]}
+			}
+		},
+		{
+			"name": "Key",
+			"request": {
+				"method": "GET",
+				"auth": {"type": "apikey", "apikey": [{"key": "key", "value": "api_key"}, {"key": "value", "value": "k"}, {"key": "in", "value": "query"}]},
+				"url": "{{baseUrl}}/items"
+			}
+		}
+	]
+}`
+
+const testEnvironment = `{
+	"name": "Staging",
+	"values": [{"key": "baseUrl", "value": "https://staging.example.com", "enabled": true}, {"key": "off", "value": "x", "enabled": false}],
+	"_postman_variable_scope": "environment"
+}`
+
+func TestParse(t *testing.T) {
+	result, err := Parse([]byte(testCollection), []byte(testEnvironment))
+	assert.NoError(t, err)
+
+	collection := result.Collection
+	assert.Equal(t, "Shop API", collection.Name)
+	assert.Equal(t, map[string]string{"baseUrl": "https://api.example.com"}, collection.Variables)
+	assert.Len(t, collection.Requests, 5)
+
+	get := collection.Requests[0]
+	assert.Equal(t, "Users", get.Folder)
+	assert.Equal(t, "GET", get.Request.Method)
+	assert.Equal(t, "{{baseUrl}}/users/42?expand=true", get.Request.Url)
+	assert.Equal(t, "application/json", get.Request.Headers.Get("Accept"))
+	assert.Empty(t, get.Request.Headers.Get("X-Off"))
+	assert.Equal(t, auth_module.AuthOptions{Type: auth_module.AuthBearer, Token: "{{token}}"}, get.Request.Auth, "the collection auth should be inherited")
+
+	create := collection.Requests[1]
+	assert.Equal(t, auth_module.AuthOptions{Type: auth_module.AuthBasic, Username: "alice", Password: "secret"}, create.Request.Auth)
+	assert.Equal(t, "application/json", create.Request.Body[0].ContentType)
+
+	login := collection.Requests[2]
+	assert.Empty(t, login.Folder)
+	assert.Equal(t, "https://auth.example.com/login?v=2", login.Request.Url)
+	assert.True(t, login.Request.Auth.IsEmpty())
+	assert.Equal(t, []http_utility.HttpContentData{{ContentType: "application/x-www-form-urlencoded", Key: "user", Value: "alice"}}, login.Request.Body)
+
+	upload := collection.Requests[3]
+	assert.Equal(t, []http_utility.HttpContentData{
+		{ContentType: "multipart/form-data", Key: "title", Value: "Report"},
+		{ContentType: "multipart/form-data", Key: "file", Value: "/tmp/report.pdf"},
+	}, upload.Request.Body)
+
+	assert.Equal(t, "{{baseUrl}}/items?api_key=k", collection.Requests[4].Request.Url)
+
+	assert.Len(t, result.Environments, 1)
+	assert.Equal(t, "Staging", result.Environments[0].Name)
+	assert.Equal(t, map[string]string{"baseUrl": "https://staging.example.com"}, result.Environments[0].Variables)
+
+	assert.Equal(t, []string{
+		"the collection: the pre-request script",
+		"Users/Create user: the test script",
+		"Users/Create user: dynamic variables like {{$guid}} are sent as they are",
+		"Upload: awsv4 auth is not supported",
+	}, result.Skipped)
+}
+
+func TestParse_Errors(t *testing.T) {
+	_, err := Parse([]byte(`not json`))
+	assert.Error(t, err)
+
+	_, err = Parse([]byte(`{"info": {"name": "Old", "schema": "https://schema.getpostman.com/json/collection/v1.0.0/collection.json"}}`))
+	assert.ErrorContains(t, err, "v2.1")
+
+	_, err = Parse([]byte(`{"item": []}`))
+	assert.Error(t, err)
+
+	_, err = Parse([]byte(testCollection), []byte(`{"values": []}`))
+	assert.ErrorContains(t, err, "environment")
+}
@@ -0,0 +1,77 @@
+package request_module
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	neturl "net/url"
+)
+
+// IpResult is the outcome of the request sent to one of the addresses of
+// the host, see RequestOptions.AllIps.
+type IpResult struct {
+	Ip            string  `json:"ip"`
+	StatusCode    int     `json:"status_code"`
+	StatusMessage string  `json:"status_message"`
+	ExecutionTime float64 `json:"execution_time"`
+	Size          int     `json:"size"`
+	// BodyHash is the start of the SHA-256 of the body, enough to tell
+	// different bodies apart in a table.
+	BodyHash string `json:"body_hash"`
+	Error    string `json:"error,omitempty"`
+}
+
+var lookupIps = net.LookupIP
+
+// compareIps sends the request to every address the URL host resolves to,
+// one after the other. The Host header and the TLS server name stay the
+// ones of the URL, only the dialed address changes.
+func compareIps(options RequestOptions, url string) []IpResult {
+	parsed, err := neturl.Parse(url)
+	if err != nil {
+		return nil
+	}
+	host, port := parsed.Hostname(), parsed.Port()
+	if port == "" {
+		port = "80"
+		if parsed.Scheme == "https" {
+			port = "443"
+		}
+	}
+
+	ips := []net.IP{net.ParseIP(host)}
+	if ips[0] == nil {
+		if ips, err = lookupIps(host); err != nil {
+			return []IpResult{{Ip: host, Error: ClassifyError(err).Error()}}
+		}
+	}
+
+	results := make([]IpResult, 0, len(ips))
+	for _, ip := range ips {
+		single := options
+		single.Url = url
+		single.AllIps = false
+		address := ip.String()
+		if ip.To4() == nil {
+			// An IPv6 address has colons of its own, see splitRoute.
+			address = "[" + address + "]"
+		}
+		single.Resolve = []string{net.JoinHostPort(host, port) + ":" + address}
+		single.ConnectTo = nil
+
+		result := IpResult{Ip: ip.String()}
+		res, err := runRequest(single)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			sum := sha256.Sum256([]byte(res.Result))
+			result.StatusCode = res.StatusCode
+			result.StatusMessage = res.StatusMessage
+			result.ExecutionTime = res.ExecutionTime
+			result.Size = len(res.Result)
+			result.BodyHash = hex.EncodeToString(sum[:])[:12]
+		}
+		results = append(results, result)
+	}
+	return results
+}
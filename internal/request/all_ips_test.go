@@ -0,0 +1,128 @@
+package request_module
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRunRequest_AllIps(t *testing.T) {
+	defer stubConfig()()
+
+	listener, err := net.Listen("tcp", "0.0.0.0:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	var hosts []string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hosts = append(hosts, r.Host)
+		local := r.Context().Value(http.LocalAddrContextKey).(net.Addr).String()
+		if strings.HasPrefix(local, "127.0.0.2:") {
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte("bad node"))
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+
+	lookupIps = func(host string) ([]net.IP, error) {
+		if host != "api.example.test" {
+			t.Errorf("Unexpected lookup of %q", host)
+		}
+		return []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("127.0.0.2"), net.ParseIP("127.0.0.3")}, nil
+	}
+	defer func() { lookupIps = net.LookupIP }()
+
+	res, err := RunRequest(RequestOptions{
+		Url:     "http://api.example.test:" + port + "/",
+		Method:  "GET",
+		Resolve: []string{"api.example.test:" + port + ":127.0.0.1"},
+		AllIps:  true,
+	})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if len(res.IpResults) != 3 {
+		t.Fatalf("Expected a result per address, got %+v", res.IpResults)
+	}
+
+	first, second := res.IpResults[0], res.IpResults[1]
+	if first.Ip != "127.0.0.1" || first.StatusCode != 200 || first.Size != 2 || len(first.BodyHash) != 12 {
+		t.Errorf("Unexpected result for 127.0.0.1: %+v", first)
+	}
+	if second.StatusCode != 502 || second.BodyHash == first.BodyHash {
+		t.Errorf("Expected 127.0.0.2 to stand out: %+v", second)
+	}
+	for _, host := range hosts {
+		if host != "api.example.test:"+port {
+			t.Errorf("Expected the original Host header on every request, got %q", host)
+		}
+	}
+	if !res.Request.AllIps {
+		t.Errorf("Expected the mode to be kept for a resend")
+	}
+}
+
+func TestCompareIps_Ipv6(t *testing.T) {
+	defer stubConfig()()
+
+	listener, err := net.Listen("tcp", "[::1]:0")
+	if err != nil {
+		t.Skip("IPv6 loopback not available")
+	}
+	var hosts []string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hosts = append(hosts, r.Host)
+		w.Write([]byte("ok"))
+	}))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+
+	lookupIps = func(host string) ([]net.IP, error) {
+		return []net.IP{net.ParseIP("::1")}, nil
+	}
+	defer func() { lookupIps = net.LookupIP }()
+
+	results := compareIps(RequestOptions{Method: "GET"}, "http://api.example.test:"+port+"/")
+	if len(results) != 1 || results[0].Ip != "::1" || results[0].Error != "" || results[0].StatusCode != 200 {
+		t.Fatalf("Expected the AAAA address to be requested, got %+v", results)
+	}
+	if len(hosts) != 1 || hosts[0] != "api.example.test:"+port {
+		t.Errorf("Expected the original Host header, got %v", hosts)
+	}
+}
+
+func TestCompareIps_LookupError(t *testing.T) {
+	lookupIps = func(host string) ([]net.IP, error) {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	defer func() { lookupIps = net.LookupIP }()
+
+	results := compareIps(RequestOptions{Method: "GET"}, "https://nope.test/")
+	if len(results) != 1 || results[0].Error == "" {
+		t.Errorf("Expected the lookup error to be reported, got %+v", results)
+	}
+}
+
+func TestCompareIps_Unreachable(t *testing.T) {
+	defer stubConfig()()
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := listener.Addr().String()
+	listener.Close()
+
+	results := compareIps(RequestOptions{Method: "GET"}, "http://"+addr+"/")
+	if len(results) != 1 || results[0].Ip != "127.0.0.1" || results[0].Error == "" {
+		t.Fatalf("Expected the connection error to be reported, got %+v", results)
+	}
+	if results[0].StatusCode != 0 {
+		t.Errorf("Expected no status for a failed request")
+	}
+}
@@ -0,0 +1,356 @@
+package request_module
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diogopereiradev/httpzen/internal/utils/http_utility"
+)
+
+type DownloadOptions struct {
+	// Path is the file to write, or a directory to write into. Without a
+	// file name, it comes from Content-Disposition or the URL.
+	Path string `json:"path,omitempty"`
+	// Checksum is "algorithm:hex" with sha256, sha512, sha1 or md5, a bare
+	// hex digest is taken as sha256.
+	Checksum string `json:"checksum,omitempty"`
+}
+
+type DownloadResult struct {
+	Path        string `json:"path"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"content_type"`
+	// ResumedFrom is the size of the partial file the download continued.
+	ResumedFrom int64 `json:"resumed_from,omitempty"`
+	// Checksum is the digest of the file, as "algorithm:hex".
+	Checksum string `json:"checksum,omitempty"`
+	Verified bool   `json:"verified,omitempty"`
+}
+
+var RunDownload = runDownload
+
+// Progress is reported at most this often, so a fast transfer doesn't
+// flood the viewer with updates.
+const progressInterval = 100 * time.Millisecond
+
+// Error pages are shown instead of saved, they are read up to this size.
+const maxDownloadErrorBody = 1024 * 1024
+
+var checksumAlgorithms = map[string]func() hash.Hash{
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+	"sha1":   sha1.New,
+	"md5":    md5.New,
+}
+
+// ParseChecksum splits "algorithm:hex" and checks the digest length.
+func ParseChecksum(value string) (string, string, error) {
+	algorithm, digest, found := strings.Cut(strings.TrimSpace(value), ":")
+	if !found {
+		algorithm, digest = "sha256", algorithm
+	}
+	algorithm = strings.ToLower(algorithm)
+	newHash, ok := checksumAlgorithms[algorithm]
+	if !ok {
+		return "", "", fmt.Errorf("unsupported checksum algorithm %q (expected sha256, sha512, sha1 or md5)", algorithm)
+	}
+	digest = strings.ToLower(digest)
+	if decoded, err := hex.DecodeString(digest); err != nil || len(decoded) != newHash().Size() {
+		return "", "", fmt.Errorf("invalid %s digest %q", algorithm, digest)
+	}
+	return algorithm, digest, nil
+}
+
+// runDownload streams the body to a file instead of keeping it in memory.
+// The file is written as "<name>.part" and renamed once complete, a partial
+// file left by an interrupted download is resumed with a Range request. The
+// ETag or Last-Modified of the first response is kept in
+// "<name>.part.validator" and sent as If-Range, so a resource that changed
+// in between is downloaded again instead of appended to the old one.
+// Error responses are not saved, their body is returned as the Result.
+func runDownload(ctx context.Context, options RequestOptions, handlers StreamHandlers) (RequestResponse, error) {
+	failed := RequestResponse{Request: options}
+	download := DownloadOptions{}
+	if options.Download != nil {
+		download = *options.Download
+	}
+
+	var algorithm, digest string
+	if download.Checksum != "" {
+		var err error
+		if algorithm, digest, err = ParseChecksum(download.Checksum); err != nil {
+			return failed, err
+		}
+	}
+
+	prepared, err := prepareRequest(options)
+	if err != nil {
+		return failed, err
+	}
+	defer prepared.close()
+	prepared.unbuffered(ctx)
+	// Range offsets count the bytes as stored, a transparently decompressed
+	// body would not match them.
+	if transport, ok := prepared.transport.base.(*http.Transport); ok {
+		transport.DisableCompression = true
+	}
+
+	dir, name := downloadTarget(download.Path)
+	partial := filepath.Join(dir, firstNonEmpty(name, urlFileName(prepared.url))) + ".part"
+	validatorFile := partial + ".validator"
+	var offset int64
+	if info, err := os.Stat(partial); err == nil && info.Size() > 0 {
+		// Without a validator nothing tells the partial file still matches
+		// the resource, it is downloaded again.
+		if validator, err := os.ReadFile(validatorFile); err == nil && len(validator) > 0 {
+			offset = info.Size()
+			prepared.req.SetHeader("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+			prepared.req.SetHeader("If-Range", string(validator))
+		}
+	}
+
+	startTime := time.Now()
+	res, err := prepared.execute()
+	if err != nil {
+		return failed, ClassifyError(timeoutError(err, options))
+	}
+	body := res.RawBody()
+	defer body.Close()
+
+	response := prepared.buildResponse(res, "", parseExecutionTimeInMilliseconds(startTime))
+	if handlers.OnStart != nil {
+		handlers.OnStart(response)
+	}
+
+	status := res.StatusCode()
+	complete := false
+	switch {
+	case status == http.StatusPartialContent && offset > 0:
+		if start, ok := contentRangeStart(res.Header().Get("Content-Range")); !ok || start != offset {
+			return response, fmt.Errorf("the server resumed at the wrong offset (%q)", res.Header().Get("Content-Range"))
+		}
+	case status == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// The partial file already holds the whole resource.
+		if size, ok := contentRangeSize(res.Header().Get("Content-Range")); !ok || size != offset {
+			return response, errors.New("the partial file is larger than the resource, delete " + partial + " to start over")
+		}
+		complete = true
+	case status >= 400:
+		data, _ := io.ReadAll(io.LimitReader(body, maxDownloadErrorBody))
+		response.Result = string(data)
+		return response, nil
+	default:
+		// The server ignored the Range, or the resource changed since the
+		// partial file was written, start over.
+		offset = 0
+	}
+
+	total := int64(-1)
+	if res.RawResponse.ContentLength >= 0 {
+		total = offset + res.RawResponse.ContentLength
+	}
+	if complete {
+		total = offset
+	}
+
+	if !complete {
+		if err := saveDownloadValidator(validatorFile, res.Header()); err != nil {
+			return response, err
+		}
+		if err := writeDownload(partial, offset, body, total, handlers.OnProgress); err != nil {
+			response.ExecutionTime = parseExecutionTimeInMilliseconds(startTime)
+			if ctx.Err() != nil {
+				return response, nil
+			}
+			return response, ClassifyError(timeoutError(err, options))
+		}
+	}
+
+	final := filepath.Join(dir, firstNonEmpty(name, http_utility.ContentDispositionFilename(res.Header().Get("Content-Disposition")), urlFileName(prepared.url)))
+	result := &DownloadResult{Path: final, ContentType: res.Header().Get("Content-Type"), ResumedFrom: offset}
+	if info, err := os.Stat(partial); err == nil {
+		result.Size = info.Size()
+	}
+
+	if algorithm != "" {
+		sum, err := fileChecksum(partial, algorithm)
+		if err != nil {
+			return response, err
+		}
+		result.Checksum = algorithm + ":" + sum
+		if sum != digest {
+			// A corrupted partial file would be resumed forever, drop it.
+			os.Remove(partial)
+			os.Remove(validatorFile)
+			return response, fmt.Errorf("checksum mismatch: expected %s:%s, got %s", algorithm, digest, result.Checksum)
+		}
+		result.Verified = true
+	}
+
+	if err := os.Rename(partial, final); err != nil {
+		return response, err
+	}
+	os.Remove(validatorFile)
+	response.Download = result
+	response.ExecutionTime = parseExecutionTimeInMilliseconds(startTime)
+	return response, nil
+}
+
+// downloadTarget splits the --download path into the directory and the file
+// name, the name is empty when only a directory was given.
+func downloadTarget(target string) (string, string) {
+	if target == "" {
+		return ".", ""
+	}
+	if strings.HasSuffix(target, "/") || strings.HasSuffix(target, string(filepath.Separator)) {
+		return target, ""
+	}
+	if info, err := os.Stat(target); err == nil && info.IsDir() {
+		return target, ""
+	}
+	return filepath.Dir(target), filepath.Base(target)
+}
+
+func urlFileName(url string) string {
+	parsed, err := neturl.Parse(url)
+	if err != nil {
+		return "download"
+	}
+	name := path.Base(parsed.Path)
+	if name == "." || name == "/" || name == "" {
+		return "download"
+	}
+	return name
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// contentRangeStart reads the first byte of "bytes 100-199/200".
+func contentRangeStart(header string) (int64, bool) {
+	rangeSpec, found := strings.CutPrefix(header, "bytes ")
+	if !found {
+		return 0, false
+	}
+	start, _, found := strings.Cut(rangeSpec, "-")
+	if !found {
+		return 0, false
+	}
+	value, err := strconv.ParseInt(start, 10, 64)
+	return value, err == nil
+}
+
+// contentRangeSize reads the size of "bytes */200".
+func contentRangeSize(header string) (int64, bool) {
+	_, size, found := strings.Cut(header, "/")
+	if !found {
+		return 0, false
+	}
+	value, err := strconv.ParseInt(size, 10, 64)
+	return value, err == nil
+}
+
+// downloadValidator is the If-Range value of a response: a strong ETag, or
+// the Last-Modified date. Weak ETags can't be used for a range.
+func downloadValidator(header http.Header) string {
+	if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return header.Get("Last-Modified")
+}
+
+// saveDownloadValidator keeps the validator of the response being written,
+// before its body, so an interrupted download can be resumed.
+func saveDownloadValidator(path string, header http.Header) error {
+	validator := downloadValidator(header)
+	if validator == "" {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(validator), 0644)
+}
+
+func writeDownload(partial string, offset int64, body io.Reader, total int64, onProgress func(int64, int64)) error {
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if offset > 0 {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	if err := os.MkdirAll(filepath.Dir(partial), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(partial, flags, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	received := offset
+	lastReport := time.Time{}
+	report := func(force bool) {
+		if onProgress != nil && (force || time.Since(lastReport) >= progressInterval) {
+			lastReport = time.Now()
+			onProgress(received, total)
+		}
+	}
+	report(true)
+
+	buffer := make([]byte, 32*1024)
+	for {
+		n, readErr := body.Read(buffer)
+		if n > 0 {
+			if _, err := file.Write(buffer[:n]); err != nil {
+				return err
+			}
+			received += int64(n)
+			report(false)
+		}
+		if errors.Is(readErr, io.EOF) {
+			report(true)
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
+
+func fileChecksum(path string, algorithm string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	h := checksumAlgorithms[algorithm]()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
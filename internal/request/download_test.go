@@ -0,0 +1,239 @@
+package request_module
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var downloadContent = bytes.Repeat([]byte("0123456789abcdef"), 8*1024)
+
+func downloadServer(t *testing.T, ranges *[]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ranges != nil {
+			*ranges = append(*ranges, r.Header.Get("Range"))
+		}
+		if r.URL.Path == "/missing" {
+			http.Error(w, "not here", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", `attachment; filename="data.bin"`)
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "data.bin", time.Time{}, bytes.NewReader(downloadContent))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func checksumOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestRunDownload(t *testing.T) {
+	defer stubConfig()()
+	server := downloadServer(t, nil)
+	dir := t.TempDir()
+
+	var progress [][2]int64
+	res, err := RunDownload(context.Background(), RequestOptions{
+		Url:      server.URL + "/files/latest",
+		Method:   "GET",
+		Timeout:  5 * time.Second,
+		Download: &DownloadOptions{Path: dir + "/", Checksum: "sha256:" + checksumOf(downloadContent)},
+	}, StreamHandlers{
+		OnProgress: func(received int64, total int64) { progress = append(progress, [2]int64{received, total}) },
+	})
+	if err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+
+	saved, _ := os.ReadFile(filepath.Join(dir, "data.bin"))
+	if !bytes.Equal(saved, downloadContent) {
+		t.Errorf("Expected the file to be named after Content-Disposition and hold the body")
+	}
+	if res.Download == nil || res.Download.Size != int64(len(downloadContent)) || !res.Download.Verified || res.Result != "" {
+		t.Errorf("Unexpected download result %+v", res.Download)
+	}
+	last := progress[len(progress)-1]
+	if last[0] != int64(len(downloadContent)) || last[1] != int64(len(downloadContent)) {
+		t.Errorf("Expected the last progress to be complete, got %v", last)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "latest.part")); !os.IsNotExist(err) {
+		t.Errorf("Expected the partial file to be renamed")
+	}
+}
+
+func TestRunDownload_Resume(t *testing.T) {
+	defer stubConfig()()
+	var ranges []string
+	server := downloadServer(t, &ranges)
+	target := filepath.Join(t.TempDir(), "copy.bin")
+
+	half := len(downloadContent) / 2
+	os.WriteFile(target+".part", downloadContent[:half], 0644)
+	os.WriteFile(target+".part.validator", []byte(`"v1"`), 0644)
+
+	res, err := RunDownload(context.Background(), RequestOptions{
+		Url:      server.URL + "/data.bin",
+		Method:   "GET",
+		Timeout:  5 * time.Second,
+		Download: &DownloadOptions{Path: target, Checksum: checksumOf(downloadContent)},
+	}, StreamHandlers{})
+	if err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+	if ranges[0] != "bytes="+strconv.Itoa(half)+"-" {
+		t.Errorf("Expected a Range request from the partial size, got %q", ranges[0])
+	}
+	saved, _ := os.ReadFile(target)
+	if !bytes.Equal(saved, downloadContent) || res.Download.ResumedFrom != int64(half) || res.Download.Path != target {
+		t.Errorf("Expected the download to continue the partial file, got %+v", res.Download)
+	}
+
+	if _, err := os.Stat(target + ".part.validator"); !os.IsNotExist(err) {
+		t.Errorf("Expected the validator to be removed with the partial file")
+	}
+
+	// The partial file is already complete, the server answers 416.
+	os.Rename(target, target+".part")
+	os.WriteFile(target+".part.validator", []byte(`"v1"`), 0644)
+	res, err = RunDownload(context.Background(), RequestOptions{
+		Url: server.URL + "/data.bin", Method: "GET", Timeout: 5 * time.Second,
+		Download: &DownloadOptions{Path: target},
+	}, StreamHandlers{})
+	if err != nil || res.Download == nil || res.Download.Size != int64(len(downloadContent)) {
+		t.Errorf("Expected a complete partial file to be accepted, got %+v, %v", res.Download, err)
+	}
+}
+
+func TestRunDownload_ResumeChangedResource(t *testing.T) {
+	defer stubConfig()()
+	var ranges []string
+	server := downloadServer(t, &ranges)
+	target := filepath.Join(t.TempDir(), "copy.bin")
+	options := RequestOptions{
+		Url: server.URL + "/data.bin", Method: "GET", Timeout: 5 * time.Second,
+		Download: &DownloadOptions{Path: target},
+	}
+
+	// The partial file comes from an older version of the resource, the
+	// server answers 200 and the download starts over.
+	os.WriteFile(target+".part", []byte("old content"), 0644)
+	os.WriteFile(target+".part.validator", []byte(`"v0"`), 0644)
+	res, err := RunDownload(context.Background(), options, StreamHandlers{})
+	if err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+	saved, _ := os.ReadFile(target)
+	if !bytes.Equal(saved, downloadContent) || res.Download.ResumedFrom != 0 {
+		t.Errorf("Expected the changed resource to be downloaded again, got %+v", res.Download)
+	}
+
+	// Without a validator, the partial file can't be trusted either.
+	os.WriteFile(target+".part", []byte("old content"), 0644)
+	res, err = RunDownload(context.Background(), options, StreamHandlers{})
+	if err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+	saved, _ = os.ReadFile(target)
+	if ranges[1] != "" || !bytes.Equal(saved, downloadContent) || res.Download.ResumedFrom != 0 {
+		t.Errorf("Expected no Range without a validator, got %q and %+v", ranges[1], res.Download)
+	}
+}
+
+func TestDownloadValidator(t *testing.T) {
+	cases := []struct {
+		header http.Header
+		want   string
+	}{
+		{http.Header{"Etag": {`"abc"`}, "Last-Modified": {"Wed, 21 Oct 2026 07:28:00 GMT"}}, `"abc"`},
+		{http.Header{"Etag": {`W/"abc"`}, "Last-Modified": {"Wed, 21 Oct 2026 07:28:00 GMT"}}, "Wed, 21 Oct 2026 07:28:00 GMT"},
+		{http.Header{}, ""},
+	}
+	for _, c := range cases {
+		if got := downloadValidator(c.header); got != c.want {
+			t.Errorf("downloadValidator(%v) = %q, want %q", c.header, got, c.want)
+		}
+	}
+}
+
+func TestRunDownload_ChecksumMismatch(t *testing.T) {
+	defer stubConfig()()
+	server := downloadServer(t, nil)
+	target := filepath.Join(t.TempDir(), "data.bin")
+
+	_, err := RunDownload(context.Background(), RequestOptions{
+		Url: server.URL + "/data.bin", Method: "GET", Timeout: 5 * time.Second,
+		Download: &DownloadOptions{Path: target, Checksum: "md5:" + strings.Repeat("0", 32)},
+	}, StreamHandlers{})
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("Expected a checksum mismatch, got %v", err)
+	}
+	for _, path := range []string{target, target + ".part", target + ".part.validator"} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed", path)
+		}
+	}
+}
+
+func TestRunDownload_ErrorStatus(t *testing.T) {
+	defer stubConfig()()
+	server := downloadServer(t, nil)
+	dir := t.TempDir()
+
+	res, err := RunDownload(context.Background(), RequestOptions{
+		Url: server.URL + "/missing", Method: "GET", Timeout: 5 * time.Second,
+		Download: &DownloadOptions{Path: dir},
+	}, StreamHandlers{})
+	if err != nil || res.StatusCode != 404 || !strings.Contains(res.Result, "not here") || res.Download != nil {
+		t.Errorf("Expected the error page to be shown instead of saved, got %d %q %v", res.StatusCode, res.Result, err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Expected nothing to be written, got %d files", len(entries))
+	}
+}
+
+func TestParseChecksum(t *testing.T) {
+	algorithm, digest, err := ParseChecksum("SHA1:" + strings.Repeat("AB", 20))
+	if err != nil || algorithm != "sha1" || digest != strings.Repeat("ab", 20) {
+		t.Errorf("Unexpected result %q %q %v", algorithm, digest, err)
+	}
+	if algorithm, _, err := ParseChecksum(strings.Repeat("0", 64)); err != nil || algorithm != "sha256" {
+		t.Errorf("Expected a bare digest to be sha256, got %q %v", algorithm, err)
+	}
+	for _, value := range []string{"crc32:1234", "sha256:abc", "md5:" + strings.Repeat("z", 32)} {
+		if _, _, err := ParseChecksum(value); err == nil {
+			t.Errorf("Expected %q to be rejected", value)
+		}
+	}
+}
+
+func TestDownloadTarget(t *testing.T) {
+	dir := t.TempDir()
+	cases := map[string][2]string{
+		"":                      {".", ""},
+		dir:                     {dir, ""},
+		"out/":                  {"out/", ""},
+		filepath.Join(dir, "a"): {dir, "a"},
+	}
+	for input, want := range cases {
+		gotDir, gotName := downloadTarget(input)
+		if gotDir != want[0] || gotName != want[1] {
+			t.Errorf("downloadTarget(%q) = %q, %q, want %v", input, gotDir, gotName, want)
+		}
+	}
+	if urlFileName("https://example.com/") != "download" || urlFileName("https://example.com/a/b.tar.gz?x=1") != "b.tar.gz" {
+		t.Error("Unexpected file names from URLs")
+	}
+}
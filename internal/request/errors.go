@@ -0,0 +1,164 @@
+package request_module
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+)
+
+type ErrorClass string
+
+const (
+	ErrorClassInvalidUrl        ErrorClass = "invalid_url"
+	ErrorClassAuth              ErrorClass = "auth"
+	ErrorClassDns               ErrorClass = "dns"
+	ErrorClassConnectionRefused ErrorClass = "connection_refused"
+	ErrorClassTimeout           ErrorClass = "timeout"
+	ErrorClassTls               ErrorClass = "tls"
+	ErrorClassProtocol          ErrorClass = "protocol"
+	ErrorClassUnknown           ErrorClass = "unknown"
+)
+
+// ExitCodes follows curl's exit codes where one exists, so scripts that
+// already branch on curl failures keep working.
+var ExitCodes = map[ErrorClass]int{
+	ErrorClassInvalidUrl:        3,
+	ErrorClassDns:               6,
+	ErrorClassConnectionRefused: 7,
+	ErrorClassProtocol:          8,
+	ErrorClassTimeout:           28,
+	ErrorClassTls:               35,
+	ErrorClassAuth:              67,
+	ErrorClassUnknown:           1,
+}
+
+var errorTitles = map[ErrorClass]string{
+	ErrorClassInvalidUrl:        "Invalid URL",
+	ErrorClassAuth:              "Authentication failed",
+	ErrorClassDns:               "DNS resolution failed",
+	ErrorClassConnectionRefused: "Connection refused",
+	ErrorClassTimeout:           "Request timed out",
+	ErrorClassTls:               "TLS handshake failed",
+	ErrorClassProtocol:          "Protocol error",
+	ErrorClassUnknown:           "Request failed",
+}
+
+var errorSuggestions = map[ErrorClass]string{
+	ErrorClassInvalidUrl:        "Check that the URL starts with http:// or https:// and has a valid host.",
+	ErrorClassAuth:              "Review the auth options or the auth saved in the environment with 'httpzen config'.",
+	ErrorClassDns:               "Check the host name for typos, your network connection and your DNS settings.",
+	ErrorClassConnectionRefused: "Make sure the server is running and listening on that host and port.",
+	ErrorClassTimeout:           "The server is slow or unreachable. Try again later or raise the timeout.",
+	ErrorClassTls:               "Check the server certificate, its host name and your system trust store.",
+	ErrorClassProtocol:          "The server answered with something that isn't valid HTTP. Check the scheme and port.",
+	ErrorClassUnknown:           "Check the error message above and try again.",
+}
+
+type RequestError struct {
+	Class ErrorClass
+	Err   error
+}
+
+func (e *RequestError) Error() string {
+	if e.Err == nil {
+		return e.Title()
+	}
+	return e.Title() + ": " + e.Err.Error()
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+func (e *RequestError) Title() string {
+	if title, ok := errorTitles[e.Class]; ok {
+		return title
+	}
+	return errorTitles[ErrorClassUnknown]
+}
+
+func (e *RequestError) Suggestion() string {
+	if suggestion, ok := errorSuggestions[e.Class]; ok {
+		return suggestion
+	}
+	return errorSuggestions[ErrorClassUnknown]
+}
+
+func newRequestError(class ErrorClass, err error) *RequestError {
+	return &RequestError{Class: class, Err: err}
+}
+
+// ClassifyError maps a transport error returned by resty/net/http to the
+// failure class shown to the user.
+func ClassifyError(err error) *RequestError {
+	if err == nil {
+		return nil
+	}
+
+	var requestErr *RequestError
+	if errors.As(err, &requestErr) {
+		return requestErr
+	}
+
+	var timeoutErr *TimeoutError
+	if errors.As(err, &timeoutErr) {
+		return newRequestError(ErrorClassTimeout, err)
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		if dnsErr.IsTimeout {
+			return newRequestError(ErrorClassTimeout, err)
+		}
+		return newRequestError(ErrorClassDns, err)
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return newRequestError(ErrorClassTimeout, err)
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return newRequestError(ErrorClassTimeout, err)
+	}
+
+	// ENOENT is a Unix socket that doesn't exist, nothing listens there.
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ENOENT) {
+		return newRequestError(ErrorClassConnectionRefused, err)
+	}
+
+	var recordErr tls.RecordHeaderError
+	var certErr *tls.CertificateVerificationError
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidCertErr x509.CertificateInvalidError
+	if errors.As(err, &recordErr) || errors.As(err, &certErr) || errors.As(err, &unknownAuthorityErr) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &invalidCertErr) || strings.Contains(err.Error(), "tls: ") {
+		return newRequestError(ErrorClassTls, err)
+	}
+
+	var protocolErr *http.ProtocolError
+	if errors.As(err, &protocolErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNRESET) || strings.Contains(err.Error(), "malformed HTTP") ||
+		strings.Contains(err.Error(), "http2: ") {
+		return newRequestError(ErrorClassProtocol, err)
+	}
+
+	return newRequestError(ErrorClassUnknown, err)
+}
+
+// ExitCode returns the process exit code for an error returned by RunRequest.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	if code, ok := ExitCodes[ClassifyError(err).Class]; ok {
+		return code
+	}
+	return 1
+}
@@ -0,0 +1,125 @@
+package request_module
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	config_module "github.com/diogopereiradev/httpzen/internal/config"
+	"github.com/diogopereiradev/httpzen/internal/utils/ip_utility"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func stubConfig() func() {
+	getConfig = func() config_module.Config { return config_module.Config{SlowResponseThreshold: 1000} }
+	lookupDomainIps = func(_ *resty.Response, _ net.Addr) []ip_utility.LookupIpInfo { return nil }
+	return func() {
+		getConfig = config_module.GetConfig
+		lookupDomainIps = ip_utility.LookupDomainIps
+	}
+}
+
+func classOf(err error) ErrorClass {
+	var requestErr *RequestError
+	if errors.As(err, &requestErr) {
+		return requestErr.Class
+	}
+	return ""
+}
+
+func TestClassifyError_Dns(t *testing.T) {
+	err := ClassifyError(&net.DNSError{Err: "no such host", Name: "invalid.test", IsNotFound: true})
+	assert.Equal(t, ErrorClassDns, err.Class)
+	assert.Equal(t, 6, ExitCode(err))
+}
+
+func TestClassifyError_ConnectionRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	_, reqErr := RunRequest(RequestOptions{Url: "http://" + addr, Method: "GET", Timeout: time.Second})
+	assert.Equal(t, ErrorClassConnectionRefused, classOf(reqErr))
+	assert.Equal(t, 7, ExitCode(reqErr))
+}
+
+func TestClassifyError_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	_, err := RunRequest(RequestOptions{Url: server.URL, Method: "GET", Timeout: 20 * time.Millisecond})
+	assert.Equal(t, ErrorClassTimeout, classOf(err))
+	assert.Equal(t, 28, ExitCode(err))
+}
+
+func TestClassifyError_Tls(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	_, err := RunRequest(RequestOptions{Url: server.URL, Method: "GET", Timeout: time.Second})
+	assert.Equal(t, ErrorClassTls, classOf(err))
+	assert.Equal(t, 35, ExitCode(err))
+}
+
+func TestClassifyError_Protocol(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			buf := make([]byte, 1024)
+			_, _ = conn.Read(buf)
+			_, _ = conn.Write([]byte("SSH-2.0-OpenSSH_9.0\r\n\r\n"))
+			conn.Close()
+		}
+	}()
+
+	_, reqErr := RunRequest(RequestOptions{Url: "http://" + listener.Addr().String(), Method: "GET", Timeout: time.Second})
+	assert.Equal(t, ErrorClassProtocol, classOf(reqErr))
+	assert.Equal(t, 8, ExitCode(reqErr))
+}
+
+func TestClassifyError_Unknown(t *testing.T) {
+	err := ClassifyError(errors.New("something else"))
+	assert.Equal(t, ErrorClassUnknown, err.Class)
+	assert.Equal(t, 1, ExitCode(err))
+	assert.NotEmpty(t, err.Suggestion())
+}
+
+func TestClassifyError_KeepsRequestError(t *testing.T) {
+	original := newRequestError(ErrorClassAuth, errors.New("bad token"))
+	assert.Same(t, original, ClassifyError(original))
+	assert.Equal(t, "Authentication failed: bad token", original.Error())
+	assert.Nil(t, ClassifyError(nil))
+	assert.Equal(t, 0, ExitCode(nil))
+}
+
+func TestRunRequest_LocalSuccess(t *testing.T) {
+	defer stubConfig()()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	resp, err := RunRequest(RequestOptions{Url: server.URL, Method: "GET", Timeout: time.Second})
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "ok", resp.Result)
+}
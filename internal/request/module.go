@@ -1,39 +1,65 @@
 package request_module
 
 import (
+	"errors"
+	"io"
 	"net/http"
-	"os"
+	"strings"
 	"time"
 
+	auth_module "github.com/diogopereiradev/httpzen/internal/auth"
 	config_module "github.com/diogopereiradev/httpzen/internal/config"
-	logger_module "github.com/diogopereiradev/httpzen/internal/logger"
 	"github.com/diogopereiradev/httpzen/internal/utils/http_utility"
 	"github.com/diogopereiradev/httpzen/internal/utils/ip_utility"
 	"github.com/go-resty/resty/v2"
 )
 
 type RequestOptions struct {
-	Timeout     time.Duration                  `json:"timeout"`
-	BypassError bool                           `json:"bypass_error"`
-	Headers     http.Header                    `json:"headers"`
-	Body        []http_utility.HttpContentData `json:"body"`
-	Url         string                         `json:"url"`
-	Method      string                         `json:"method"`
+	Timeout time.Duration                  `json:"timeout"`
+	Headers http.Header                    `json:"headers"`
+	Body    []http_utility.HttpContentData `json:"body"`
+	Url     string                         `json:"url"`
+	Method  string                         `json:"method"`
+	Auth    auth_module.AuthOptions        `json:"auth"`
+	// CustomMethod allows a Method outside of http_utility.HttpMethods,
+	// written as an uppercase token like PURGE.
+	CustomMethod bool `json:"custom_method,omitempty"`
+	// Protocol forces an HTTP version, see ParseProtocol.
+	Protocol string `json:"protocol,omitempty"`
+	// UnixSocket, Resolve and ConnectTo change where the connection is made,
+	// the Host header and the TLS server name still come from the URL.
+	UnixSocket string   `json:"unix_socket,omitempty"`
+	Resolve    []string `json:"resolve,omitempty"`
+	ConnectTo  []string `json:"connect_to,omitempty"`
+	// AllIps also sends the request to every address of the host and
+	// compares the results, see IpResult.
+	AllIps bool         `json:"all_ips,omitempty"`
+	Retry  RetryOptions `json:"retry,omitempty"`
+	// Timeouts bound the phases of the request, Timeout the whole of it.
+	Timeouts Timeouts `json:"timeouts,omitempty"`
+	// Download saves the body to a file, see RunDownload.
+	Download *DownloadOptions `json:"download,omitempty"`
+	// OnUploadProgress is called while a body streamed from disk is sent,
+	// total is -1 when its size isn't known.
+	OnUploadProgress func(sent int64, total int64) `json:"-"`
 }
 
-var Exit = os.Exit
-
 var RunRequest = runRequest
 var restyNew = resty.New
 var parseHttpMethod = http_utility.ParseHttpMethod
+var parseCustomHttpMethod = http_utility.ParseCustomHttpMethod
 var parseUrl = http_utility.ParseUrl
+var parseUnixSocketUrl = http_utility.ParseUnixSocketUrl
 var parseExecutionTimeInMilliseconds = http_utility.ParseExecutionTimeInMilliseconds
 var getConfig = config_module.GetConfig
 var lookupDomainIps = ip_utility.LookupDomainIps
-var loggerError = logger_module.Error
 var parseApplicationJson = http_utility.ParseApplicationJson
 var parseMultipartFormData = http_utility.ParseMultipartFormData
 var parseUrlEncodedForm = http_utility.ParseUrlEncodedForm
+var parseGraphQL = http_utility.ParseGraphQL
+var parseFileBody = http_utility.ParseFileBody
+var applyAuth = auth_module.Apply
+var invalidateAuthToken = auth_module.InvalidateToken
 
 type RequestResponse struct {
 	HttpVersion   string                         `json:"http_version"`
@@ -41,6 +67,8 @@ type RequestResponse struct {
 	StatusCode    int                            `json:"status_code"`
 	ExecutionTime float64                        `json:"execution_time"`
 	Headers       http.Header                    `json:"headers"`
+	Trailers      http.Header                    `json:"trailers,omitempty"`
+	Protocol      ProtocolInfo                   `json:"protocol"`
 	Body          []http_utility.HttpContentData `json:"body"`
 	Cookies       []*http.Cookie                 `json:"cookies"`
 	Request       RequestOptions                 `json:"request"`
@@ -48,22 +76,73 @@ type RequestResponse struct {
 	Host          string                         `json:"host"`
 	Method        string                         `json:"method"`
 	IpInfos       []ip_utility.LookupIpInfo      `json:"ip_infos"`
+	IpResults     []IpResult                     `json:"ip_results,omitempty"`
 	SlowResponse  bool                           `json:"slow_response"`
 	Result        string                         `json:"result"`
+	// Attempts has one entry per try when retries are enabled.
+	Attempts []RetryAttempt `json:"attempts,omitempty"`
+	// Download describes the saved file of a download, the body is not in
+	// Result then.
+	Download  *DownloadResult `json:"download,omitempty"`
+	StartedAt time.Time       `json:"started_at"`
+	Timings   Timings         `json:"timings"`
+}
+
+type preparedRequest struct {
+	options   RequestOptions
+	client    *resty.Client
+	transport *connectionTransport
+	route     *router
+	req       *resty.Request
+	method    string
+	url       string
+	body      http_utility.HandleParseResult
 }
 
-func runRequest(options RequestOptions) RequestResponse {
+// prepareRequest validates the options and builds the resty request shared by
+// the buffered and the streaming code paths.
+func prepareRequest(options RequestOptions) (*preparedRequest, error) {
 	method := parseHttpMethod(options.Method)
+	if method == "" && options.CustomMethod {
+		method = parseCustomHttpMethod(options.Method)
+	}
+	if method == "" && options.Method != "" {
+		if options.CustomMethod {
+			return nil, newRequestError(ErrorClassInvalidUrl, errors.New("invalid HTTP method \""+options.Method+"\", custom methods must be uppercase tokens, like PURGE"))
+		}
+		return nil, newRequestError(ErrorClassInvalidUrl, errors.New("unknown HTTP method \""+options.Method+"\", custom methods need --custom-method"))
+	}
 	url := parseUrl(options.Url)
 	if url == "" {
-		loggerError("Invalid URL. Please provide a valid URL (http:// or https://).", 70)
-		Exit(1)
-		return RequestResponse{}
+		return nil, newRequestError(ErrorClassInvalidUrl, errors.New("expected an http://, https:// or http+unix:// URL, got \""+options.Url+"\""))
+	}
+	if socket, socketUrl, ok := parseUnixSocketUrl(url); ok {
+		options.UnixSocket = socket
+		url = socketUrl
+	} else if strings.HasPrefix(url, "http+unix://") {
+		return nil, newRequestError(ErrorClassInvalidUrl, errors.New("expected the percent-encoded socket path as the host, like http+unix://%2Fvar%2Frun%2Fdocker.sock/info"))
+	}
+	if err := checkProtocol(options.Protocol, url); err != nil {
+		return nil, newRequestError(ErrorClassInvalidUrl, err)
+	}
+	route, err := newRouter(options)
+	if err != nil {
+		return nil, newRequestError(ErrorClassInvalidUrl, err)
 	}
 
 	client := restyNew()
 	client.SetTimeout(options.Timeout)
 
+	// The transport goes in before auth, digest auth wraps it.
+	base := newTransport(options.Protocol, route)
+	applyTimeouts(base, options.Timeouts, route)
+	transport := &connectionTransport{base: base, idle: options.Timeouts.Idle}
+	client.SetTransport(transport)
+
+	if err := applyAuth(client, options.Auth); err != nil {
+		return nil, newRequestError(ErrorClassAuth, err)
+	}
+
 	req := client.R()
 	headers := make(map[string]string)
 	for k, v := range options.Headers {
@@ -74,51 +153,158 @@ func runRequest(options RequestOptions) RequestResponse {
 
 	reqBody := HandleBody(options.Body)
 	if reqBody.ContentTypeHeader != "" {
+		if options.Headers == nil {
+			options.Headers = http.Header{}
+		}
 		headers["Content-Type"] = reqBody.ContentTypeHeader
 		options.Headers.Set("Content-Type", reqBody.ContentTypeHeader)
 	}
 
 	req.SetHeaders(headers)
-	req.SetBody(reqBody.Result)
 
-	startTime := time.Now()
+	prepared := &preparedRequest{
+		options:   options,
+		client:    client,
+		transport: transport,
+		route:     route,
+		req:       req,
+		method:    method,
+		url:       url,
+		body:      reqBody,
+	}
+	prepared.setBody()
+	if upload, ok := reqBody.Result.(*http_utility.UploadBody); ok {
+		client.SetPreRequestHook(func(_ *resty.Client, r *http.Request) error {
+			prepareUpload(r, upload, options.OnUploadProgress)
+			return nil
+		})
+	}
+	return prepared, nil
+}
 
-	res, err := req.Execute(method, url)
-	if err != nil {
-		if !options.BypassError {
-			loggerError("Failed to execute HTTP request: "+err.Error(), 70)
-			Exit(1)
+// close releases the connections of the request, an HTTP/3 transport holds a
+// UDP socket until it is closed.
+func (p *preparedRequest) close() {
+	if closer, ok := p.transport.base.(io.Closer); ok {
+		closer.Close()
+	}
+}
+
+// execute sends the prepared request. An OAuth2 token can be revoked before
+// its advertised expiry, so a 401 gets one more try with a fresh token.
+func (p *preparedRequest) execute() (*resty.Response, error) {
+	res, err := p.req.Execute(p.method, p.url)
+
+	if err == nil && res.StatusCode() == 401 && p.options.Auth.Type == auth_module.AuthOAuth2 {
+		invalidateAuthToken(p.options.Auth)
+		if authErr := applyAuth(p.client, p.options.Auth); authErr == nil {
+			p.setBody()
+			res, err = p.req.Execute(p.method, p.url)
 		}
-		return RequestResponse{}
 	}
+	return res, err
+}
 
-	executionTime := parseExecutionTimeInMilliseconds(startTime)
+func (p *preparedRequest) buildResponse(res *resty.Response, result string, executionTime float64) RequestResponse {
 	config := getConfig()
 
 	return RequestResponse{
 		HttpVersion:   res.RawResponse.Proto,
-		Result:        res.String(),
+		Result:        result,
 		StatusMessage: res.Status(),
 		StatusCode:    res.StatusCode(),
 		ExecutionTime: executionTime,
 		Headers:       res.Header(),
-		Body:          options.Body,
+		Trailers:      res.RawResponse.Trailer,
+		Protocol:      p.transport.protocolInfo(p.options.Protocol, res.RawResponse),
+		Body:          p.options.Body,
 		Cookies:       res.Cookies(),
 		Path:          res.Request.RawRequest.URL.Path,
 		Host:          res.Request.RawRequest.URL.Host,
 		Method:        res.Request.Method,
-		IpInfos:       lookupDomainIps(res),
+		IpInfos:       lookupDomainIps(res, p.route.usedAddr()),
 		SlowResponse:  executionTime > float64(config.SlowResponseThreshold),
+		StartedAt:     time.Now().Add(-time.Duration(executionTime * float64(time.Millisecond))),
+		Timings:       p.transport.trace.timings(executionTime),
 		Request: RequestOptions{
-			Url:     url,
-			Headers: options.Headers,
-			Method:  method,
-			Timeout: options.Timeout,
-			Body:    options.Body,
+			Url:        p.url,
+			Headers:    p.options.Headers,
+			Method:     p.method,
+			Timeout:    p.options.Timeout,
+			Body:       p.options.Body,
+			Auth:       p.options.Auth,
+			Protocol:   p.options.Protocol,
+			UnixSocket: p.options.UnixSocket,
+			Resolve:    p.options.Resolve,
+			ConnectTo:  p.options.ConnectTo,
+			AllIps:     p.options.AllIps,
+			Retry:      p.options.Retry,
+			Timeouts:   p.options.Timeouts,
+			Download:   p.options.Download,
 		},
 	}
 }
 
+// runRequest sends the request and returns a *RequestError describing why it
+// failed, if it did. On failure the returned response still carries the
+// request options so callers can offer a retry.
+func runRequest(options RequestOptions) (RequestResponse, error) {
+	failed := RequestResponse{Request: options}
+
+	prepared, err := prepareRequest(options)
+	if err != nil {
+		return failed, err
+	}
+	defer prepared.close()
+
+	var attempts []RetryAttempt
+	var res *resty.Response
+	var executionTime float64
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 {
+			prepared.setBody()
+		}
+		startTime := time.Now()
+		res, err = prepared.execute()
+		executionTime = parseExecutionTimeInMilliseconds(startTime)
+
+		if !options.Retry.enabled() {
+			break
+		}
+		record := RetryAttempt{ExecutionTime: executionTime}
+		if err != nil {
+			record.Error = ClassifyError(timeoutError(err, options)).Error()
+		} else {
+			record.StatusCode = res.StatusCode()
+		}
+		if attempt >= options.Retry.Attempts || !options.Retry.shouldRetry(prepared.method, res, err) {
+			attempts = append(attempts, record)
+			break
+		}
+		wait, retryAfter, ok := options.Retry.wait(attempt, res)
+		if !ok {
+			attempts = append(attempts, record)
+			break
+		}
+		record.Wait = wait
+		record.RetryAfter = retryAfter
+		attempts = append(attempts, record)
+		sleep(wait)
+	}
+
+	if err != nil {
+		failed.Attempts = attempts
+		return failed, ClassifyError(timeoutError(err, options))
+	}
+
+	response := prepared.buildResponse(res, res.String(), executionTime)
+	response.Attempts = attempts
+	if options.AllIps && prepared.options.UnixSocket == "" {
+		response.IpResults = compareIps(prepared.options, prepared.url)
+	}
+	return response, nil
+}
+
 func HandleBody(body []http_utility.HttpContentData) http_utility.HandleParseResult {
 	if len(body) == 0 {
 		return http_utility.HandleParseResult{}
@@ -126,6 +312,10 @@ func HandleBody(body []http_utility.HttpContentData) http_utility.HandleParseRes
 
 	contentType := body[0].ContentType
 
+	if body[0].Key == http_utility.FileBodyKey {
+		return parseFileBody(body[0])
+	}
+
 	if contentType == "application/json" {
 		return parseApplicationJson(body[0])
 	}
@@ -138,6 +328,10 @@ func HandleBody(body []http_utility.HttpContentData) http_utility.HandleParseRes
 		return parseUrlEncodedForm(body)
 	}
 
+	if contentType == http_utility.GraphQLContentType {
+		return parseGraphQL(body)
+	}
+
 	return http_utility.HandleParseResult{
 		ContentTypeHeader: contentType,
 		Result:            body[0].Value,
@@ -2,12 +2,14 @@ package request_module
 
 import (
 	"errors"
+	"net"
 	"net/http"
-	"os"
+	"net/http/httptest"
 	"reflect"
 	"testing"
 	"time"
 
+	auth_module "github.com/diogopereiradev/httpzen/internal/auth"
 	config_module "github.com/diogopereiradev/httpzen/internal/config"
 	"github.com/diogopereiradev/httpzen/internal/utils/http_utility"
 	ip_utility "github.com/diogopereiradev/httpzen/internal/utils/ip_utility"
@@ -15,29 +17,46 @@ import (
 )
 
 func TestRunRequest_InvalidURL(t *testing.T) {
-	called := false
-	Exit = func(code int) { called = true }
-	loggerError = func(msg string, maxWidth int) {}
-	defer func() { Exit = os.Exit; loggerError = nil }()
-
 	options := RequestOptions{
 		Url:    "invalid-url",
 		Method: "GET",
 	}
 
-	resp := RunRequest(options)
-	if !called {
-		t.Errorf("Exit should be called on invalid URL")
+	resp, err := RunRequest(options)
+	var requestErr *RequestError
+	if !errors.As(err, &requestErr) || requestErr.Class != ErrorClassInvalidUrl {
+		t.Errorf("Expected an invalid URL error, got %v", err)
 	}
 
-	if !reflect.DeepEqual(resp, RequestResponse{}) {
-		t.Errorf("Expected empty response on invalid URL")
+	if resp.Request.Url != "invalid-url" {
+		t.Errorf("Expected request options to be kept on error")
+	}
+}
+
+func TestRunRequest_CustomMethod(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Method))
+	}))
+	defer server.Close()
+
+	_, err := RunRequest(RequestOptions{Url: server.URL, Method: "PURGE"})
+	var requestErr *RequestError
+	if !errors.As(err, &requestErr) || requestErr.Class != ErrorClassInvalidUrl {
+		t.Errorf("Expected a custom method to be refused without the opt-in, got %v", err)
+	}
+
+	_, err = RunRequest(RequestOptions{Url: server.URL, Method: "purge", CustomMethod: true})
+	if !errors.As(err, &requestErr) || requestErr.Class != ErrorClassInvalidUrl {
+		t.Errorf("Expected a lowercase custom method to be refused, got %v", err)
+	}
+
+	resp, err := RunRequest(RequestOptions{Url: server.URL, Method: "PURGE", CustomMethod: true})
+	if err != nil || resp.Result != "PURGE" {
+		t.Errorf("Expected the custom method to be sent, got %q, %v", resp.Result, err)
 	}
 }
 
 func TestRunRequest_RequestError(t *testing.T) {
-	Exit = func(code int) {}
-	loggerError = func(msg string, maxWidth int) {}
 	restyNew = func() *resty.Client {
 		c := resty.New()
 		c.OnBeforeRequest(func(_ *resty.Client, req *resty.Request) error {
@@ -45,33 +64,87 @@ func TestRunRequest_RequestError(t *testing.T) {
 		})
 		return c
 	}
-	defer func() { Exit = os.Exit; loggerError = nil; restyNew = resty.New }()
+	defer func() { restyNew = resty.New }()
 
 	options := RequestOptions{
 		Url:    "http://localhost",
 		Method: "GET",
 	}
 
-	resp := RunRequest(options)
-	if !reflect.DeepEqual(resp, RequestResponse{}) {
-		t.Errorf("Expected empty response on request error")
+	resp, err := RunRequest(options)
+	if err == nil {
+		t.Errorf("Expected error on request failure")
+	}
+	if resp.StatusCode != 0 || resp.Request.Url != "http://localhost" {
+		t.Errorf("Expected empty response carrying the request options")
+	}
+}
+
+func TestRunRequest_AuthError(t *testing.T) {
+	_, err := RunRequest(RequestOptions{
+		Url:    "http://localhost",
+		Method: "GET",
+		Auth:   auth_module.AuthOptions{Type: auth_module.AuthBearer},
+	})
+
+	var requestErr *RequestError
+	if !errors.As(err, &requestErr) || requestErr.Class != ErrorClassAuth {
+		t.Errorf("Expected an auth error, got %v", err)
+	}
+}
+
+func TestRunRequest_OAuth2RetriesWithFreshToken(t *testing.T) {
+	tokens := []string{"stale", "fresh"}
+	invalidated := false
+	applyAuth = func(client *resty.Client, options auth_module.AuthOptions) error {
+		client.SetAuthToken(tokens[0])
+		return nil
+	}
+	invalidateAuthToken = func(options auth_module.AuthOptions) {
+		invalidated = true
+		tokens = tokens[1:]
+	}
+	getConfig = func() config_module.Config { return config_module.Config{SlowResponseThreshold: 1000} }
+	lookupDomainIps = func(_ *resty.Response, _ net.Addr) []ip_utility.LookupIpInfo { return nil }
+	defer func() {
+		applyAuth = auth_module.Apply
+		invalidateAuthToken = auth_module.InvalidateToken
+		getConfig = config_module.GetConfig
+		lookupDomainIps = ip_utility.LookupDomainIps
+	}()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	resp, _ := RunRequest(RequestOptions{
+		Url:    server.URL,
+		Method: "GET",
+		Auth:   auth_module.AuthOptions{Type: auth_module.AuthOAuth2, TokenUrl: "http://auth", ClientId: "id"},
+	})
+	if !invalidated {
+		t.Errorf("Expected the cached token to be invalidated")
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected retried request to succeed, got %d", resp.StatusCode)
 	}
 }
 
 func TestRunRequest_Success(t *testing.T) {
-	Exit = func(code int) {}
-	loggerError = func(msg string, maxWidth int) {}
 	getConfig = func() config_module.Config {
 		return config_module.Config{SlowResponseThreshold: 1000}
 	}
 
-	lookupDomainIps = func(_ *resty.Response) []ip_utility.LookupIpInfo {
+	lookupDomainIps = func(_ *resty.Response, _ net.Addr) []ip_utility.LookupIpInfo {
 		return []ip_utility.LookupIpInfo{{Ip: "127.0.0.1"}}
 	}
 
 	defer func() {
-		Exit = os.Exit
-		loggerError = nil
 		getConfig = config_module.GetConfig
 		lookupDomainIps = ip_utility.LookupDomainIps
 		restyNew = resty.New
@@ -85,7 +158,7 @@ func TestRunRequest_Success(t *testing.T) {
 		Body:    []http_utility.HttpContentData{{ContentType: "text/plain", Value: "test body"}},
 	}
 
-	resp := RunRequest(options)
+	resp, _ := RunRequest(options)
 	if resp.Request.Url != "https://google.com" {
 		t.Errorf("Expected url to be set")
 	}
@@ -162,6 +235,21 @@ func TestHandleBody_UrlEncoded(t *testing.T) {
 	}
 }
 
+func TestHandleBody_GraphQL(t *testing.T) {
+	res := HandleBody(http_utility.NewGraphQLBody("query Q { me { id } }", `{"a":1}`, "Q"))
+	if res.ContentTypeHeader != "application/json" {
+		t.Errorf("Expected GraphQL to be sent as application/json, got %q", res.ContentTypeHeader)
+	}
+
+	document, ok := res.Result.(map[string]any)
+	if !ok || document["query"] != "query Q { me { id } }" || document["operationName"] != "Q" {
+		t.Errorf("Unexpected GraphQL document: %#v", res.Result)
+	}
+	if variables, ok := document["variables"].(map[string]any); !ok || variables["a"] != float64(1) {
+		t.Errorf("Expected variables to be decoded, got %#v", document["variables"])
+	}
+}
+
 func TestHandleBody_Other(t *testing.T) {
 	res := HandleBody([]http_utility.HttpContentData{{ContentType: "text/plain", Value: "abc"}})
 	if res.ContentTypeHeader != "text/plain" || res.Result != "abc" {
@@ -0,0 +1,162 @@
+package request_module
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"time"
+
+	"github.com/quic-go/quic-go/http3"
+)
+
+const (
+	ProtocolAuto  = ""
+	ProtocolHttp1 = "http1.1"
+	ProtocolHttp2 = "http2"
+	ProtocolH2c   = "h2c"
+	ProtocolHttp3 = "http3"
+)
+
+var Protocols = []string{ProtocolHttp1, ProtocolHttp2, ProtocolH2c, ProtocolHttp3}
+
+type ProtocolInfo struct {
+	// Requested is the protocol forced with --protocol, empty when Go picked.
+	Requested  string `json:"requested,omitempty"`
+	Negotiated string `json:"negotiated"`
+	Alpn       string `json:"alpn,omitempty"`
+	TlsVersion string `json:"tls_version,omitempty"`
+	Reused     bool   `json:"reused"`
+}
+
+// ParseProtocol accepts the protocol names and the "1.1", "2" and "3" short
+// forms. An empty value lets Go negotiate.
+func ParseProtocol(value string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "":
+		return ProtocolAuto, nil
+	case "http1.1", "1.1", "http/1.1":
+		return ProtocolHttp1, nil
+	case "http2", "2", "h2", "http/2":
+		return ProtocolHttp2, nil
+	case "h2c":
+		return ProtocolH2c, nil
+	case "http3", "3", "h3", "http/3":
+		return ProtocolHttp3, nil
+	}
+	return "", fmt.Errorf("unsupported protocol %q (expected %s)", value, strings.Join(Protocols, ", "))
+}
+
+// checkProtocol rejects protocols that can't be used with the URL scheme,
+// instead of silently falling back to another one.
+func checkProtocol(protocol string, url string) error {
+	secure := strings.HasPrefix(url, "https://")
+	switch {
+	case protocol == ProtocolHttp2 && !secure:
+		return errors.New("HTTP/2 needs an https:// URL, use h2c for cleartext HTTP/2")
+	case protocol == ProtocolH2c && secure:
+		return errors.New("h2c is cleartext HTTP/2 and needs an http:// URL, use http2 for https://")
+	case protocol == ProtocolHttp3 && !secure:
+		return errors.New("HTTP/3 needs an https:// URL")
+	}
+	return nil
+}
+
+// newTransport builds the transport for the protocol, dialing through route
+// when the request is routed.
+func newTransport(protocol string, route *router) http.RoundTripper {
+	if protocol == ProtocolHttp3 {
+		transport := &http3.Transport{}
+		if route != nil {
+			transport.Dial = route.dialQuic
+		}
+		return transport
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if route != nil {
+		transport.DialContext = route.dialContext
+		if route.unixSocket != "" {
+			// Like curl, a Unix socket is never reached through a proxy.
+			transport.Proxy = nil
+		}
+	}
+	protocols := new(http.Protocols)
+	switch protocol {
+	case ProtocolHttp1:
+		protocols.SetHTTP1(true)
+	case ProtocolHttp2:
+		protocols.SetHTTP2(true)
+	case ProtocolH2c:
+		// Prior knowledge: the connection starts with the HTTP/2 preface,
+		// there is no HTTP/1.1 upgrade round trip.
+		protocols.SetUnencryptedHTTP2(true)
+	default:
+		return transport
+	}
+	transport.Protocols = protocols
+	return transport
+}
+
+// connectionTransport remembers whether the last request reused a
+// connection.
+type connectionTransport struct {
+	base   http.RoundTripper
+	reused bool
+	// idle is the idle body timeout, see Timeouts.
+	idle time.Duration
+	// trace holds the phase timings of the last round trip.
+	trace *timingTrace
+}
+
+func (t *connectionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reused := false
+	timing := newTimingTrace()
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			reused = info.Reused
+			timing.set(&timing.gotConn)
+		},
+	}
+	timing.hooks(trace)
+	t.trace = timing
+
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+	var res *http.Response
+	var err error
+	if t.idle > 0 {
+		res, err = t.roundTripWithIdleTimeout(req)
+	} else {
+		res, err = t.base.RoundTrip(req)
+	}
+	if err != nil {
+		return res, err
+	}
+	t.reused = reused
+	return res, nil
+}
+
+// roundTripWithIdleTimeout cancels the request when its body goes silent for
+// too long, see idleTimeoutBody.
+func (t *connectionTransport) roundTripWithIdleTimeout(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithCancel(req.Context())
+	res, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return res, err
+	}
+	res.Body = newIdleTimeoutBody(res.Body, t.idle, cancel)
+	return res, nil
+}
+
+func (t *connectionTransport) protocolInfo(protocol string, res *http.Response) ProtocolInfo {
+	info := ProtocolInfo{Requested: protocol, Negotiated: res.Proto, Reused: t.reused}
+	if res.TLS != nil {
+		info.Alpn = res.TLS.NegotiatedProtocol
+		info.TlsVersion = tls.VersionName(res.TLS.Version)
+	}
+	return info
+}
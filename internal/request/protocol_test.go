@@ -0,0 +1,124 @@
+package request_module
+
+import (
+	"crypto/tls"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/quic-go/quic-go/http3"
+)
+
+func TestParseProtocol(t *testing.T) {
+	cases := map[string]string{
+		"":         ProtocolAuto,
+		"1.1":      ProtocolHttp1,
+		"HTTP/1.1": ProtocolHttp1,
+		"http2":    ProtocolHttp2,
+		"h2":       ProtocolHttp2,
+		"h2c":      ProtocolH2c,
+		"3":        ProtocolHttp3,
+		"http3":    ProtocolHttp3,
+	}
+	for input, want := range cases {
+		got, err := ParseProtocol(input)
+		if err != nil || got != want {
+			t.Errorf("ParseProtocol(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+
+	if _, err := ParseProtocol("spdy"); err == nil {
+		t.Error("Expected an error for an unknown protocol")
+	}
+}
+
+func TestRunRequest_ProtocolNeedsMatchingScheme(t *testing.T) {
+	cases := map[string]string{
+		ProtocolHttp2: "http://localhost",
+		ProtocolHttp3: "http://localhost",
+		ProtocolH2c:   "https://localhost",
+	}
+	for protocol, url := range cases {
+		_, err := RunRequest(RequestOptions{Url: url, Method: "GET", Protocol: protocol})
+
+		var requestErr *RequestError
+		if !errors.As(err, &requestErr) || requestErr.Class != ErrorClassInvalidUrl {
+			t.Errorf("Expected an invalid URL error for %s on %s, got %v", protocol, url, err)
+		}
+	}
+}
+
+func TestNewTransport_Http3(t *testing.T) {
+	if _, ok := newTransport(ProtocolHttp3, nil).(*http3.Transport); !ok {
+		t.Error("Expected an HTTP/3 transport")
+	}
+}
+
+// get sends requests through the transport built for protocol and returns the
+// protocol details of the last response.
+func get(t *testing.T, server *httptest.Server, protocol string, requests int) ProtocolInfo {
+	base := newTransport(protocol, nil).(*http.Transport)
+	if server.TLS != nil {
+		base.TLSClientConfig = &tls.Config{RootCAs: server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs}
+	}
+	transport := &connectionTransport{base: base}
+	client := &http.Client{Transport: transport}
+	defer base.CloseIdleConnections()
+
+	var info ProtocolInfo
+	for range requests {
+		res, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Request over %s failed: %v", protocol, err)
+		}
+		io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+		info = transport.protocolInfo(protocol, res)
+	}
+	return info
+}
+
+func TestConnectionTransport_Protocols(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	})
+
+	tlsServer := httptest.NewUnstartedServer(handler)
+	tlsServer.EnableHTTP2 = true
+	tlsServer.StartTLS()
+	defer tlsServer.Close()
+
+	info := get(t, tlsServer, ProtocolHttp1, 1)
+	if info.Negotiated != "HTTP/1.1" || info.TlsVersion == "" {
+		t.Errorf("Unexpected HTTP/1.1 details: %+v", info)
+	}
+
+	info = get(t, tlsServer, ProtocolHttp2, 1)
+	if info.Negotiated != "HTTP/2.0" || info.Alpn != "h2" || info.Reused {
+		t.Errorf("Unexpected HTTP/2 details: %+v", info)
+	}
+
+	info = get(t, tlsServer, ProtocolHttp2, 2)
+	if !info.Reused || info.Requested != ProtocolHttp2 {
+		t.Errorf("Expected the second request on the same connection: %+v", info)
+	}
+
+	h2cServer := httptest.NewUnstartedServer(handler)
+	h2cServer.Config.Protocols = new(http.Protocols)
+	h2cServer.Config.Protocols.SetHTTP1(true)
+	h2cServer.Config.Protocols.SetUnencryptedHTTP2(true)
+	h2cServer.Start()
+	defer h2cServer.Close()
+
+	info = get(t, h2cServer, ProtocolH2c, 1)
+	if info.Negotiated != "HTTP/2.0" || info.Alpn != "" {
+		t.Errorf("Unexpected h2c details: %+v", info)
+	}
+
+	info = get(t, h2cServer, ProtocolAuto, 1)
+	if info.Negotiated != "HTTP/1.1" {
+		t.Errorf("Expected HTTP/1.1 on cleartext by default: %+v", info)
+	}
+}
@@ -0,0 +1,160 @@
+package request_module
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+var DefaultRetryStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// DefaultRetryErrorClasses are the failures that are usually transient, a
+// bad URL or a certificate problem won't go away by trying again. They are
+// only retried for idempotent methods, a POST that timed out may have been
+// processed already.
+var DefaultRetryErrorClasses = []ErrorClass{
+	ErrorClassConnectionRefused,
+	ErrorClassTimeout,
+	ErrorClassProtocol,
+}
+
+// idempotentMethods can be sent again without changing the result, the
+// RFC 9110 ones and the WebDAV methods that only read.
+var idempotentMethods = []string{"GET", "HEAD", "OPTIONS", "TRACE", "PUT", "DELETE", "PROPFIND", "REPORT", "SEARCH"}
+
+const (
+	DefaultRetryBackoff = 500 * time.Millisecond
+	DefaultRetryMaxWait = 30 * time.Second
+)
+
+type RetryOptions struct {
+	// Attempts counts the first try, 0 and 1 mean no retries.
+	Attempts    int   `json:"attempts,omitempty"`
+	StatusCodes []int `json:"status_codes,omitempty"`
+	// ErrorClasses set by the user are retried for any method, the default
+	// ones only for idempotent methods.
+	ErrorClasses []ErrorClass `json:"error_classes,omitempty"`
+	// Backoff is the wait before the first retry, it doubles on every
+	// retry up to MaxWait.
+	Backoff time.Duration `json:"backoff,omitempty"`
+	// MaxWait also caps Retry-After, the request gives up rather than
+	// retrying sooner than the server asked.
+	MaxWait time.Duration `json:"max_wait,omitempty"`
+}
+
+type RetryAttempt struct {
+	StatusCode    int     `json:"status_code,omitempty"`
+	Error         string  `json:"error,omitempty"`
+	ExecutionTime float64 `json:"execution_time"`
+	// Wait is how long the next attempt waited for, zero on the last one.
+	Wait       time.Duration `json:"wait,omitempty"`
+	RetryAfter bool          `json:"retry_after,omitempty"`
+}
+
+var sleep = time.Sleep
+var jitter = rand.Float64
+
+// ParseRetryStatusCodes reads a comma separated list of status codes.
+func ParseRetryStatusCodes(value string) ([]int, error) {
+	var codes []int
+	for _, part := range strings.Split(value, ",") {
+		code, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || code < 100 || code > 599 {
+			return nil, fmt.Errorf("invalid status code %q", part)
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// ParseRetryErrorClasses reads a comma separated list of failure classes,
+// written like the exit code table: dns, connection_refused, timeout...
+func ParseRetryErrorClasses(value string) ([]ErrorClass, error) {
+	var classes []ErrorClass
+	for _, part := range strings.Split(value, ",") {
+		class := ErrorClass(strings.ReplaceAll(strings.ToLower(strings.TrimSpace(part)), "-", "_"))
+		if _, ok := ExitCodes[class]; !ok {
+			return nil, fmt.Errorf("invalid failure class %q", part)
+		}
+		classes = append(classes, class)
+	}
+	return classes, nil
+}
+
+func (o RetryOptions) enabled() bool {
+	return o.Attempts > 1
+}
+
+func (o RetryOptions) shouldRetry(method string, res *resty.Response, err error) bool {
+	if err != nil {
+		classes := o.ErrorClasses
+		if len(classes) == 0 {
+			if !slices.Contains(idempotentMethods, method) {
+				return false
+			}
+			classes = DefaultRetryErrorClasses
+		}
+		return slices.Contains(classes, ClassifyError(err).Class)
+	}
+
+	codes := o.StatusCodes
+	if len(codes) == 0 {
+		codes = DefaultRetryStatusCodes
+	}
+	return slices.Contains(codes, res.StatusCode())
+}
+
+// wait returns how long to wait before the retry following attempt (1 for
+// the first try). Retry-After wins over the backoff, false means the server
+// asked for a longer wait than MaxWait.
+func (o RetryOptions) wait(attempt int, res *resty.Response) (time.Duration, bool, bool) {
+	maxWait := o.MaxWait
+	if maxWait <= 0 {
+		maxWait = DefaultRetryMaxWait
+	}
+
+	if res != nil {
+		if retryAfter, ok := parseRetryAfter(res.Header().Get("Retry-After")); ok {
+			return retryAfter, true, retryAfter <= maxWait
+		}
+	}
+
+	backoff := o.Backoff
+	if backoff <= 0 {
+		backoff = DefaultRetryBackoff
+	}
+	wait := min(backoff<<(attempt-1), maxWait)
+	if wait <= 0 {
+		wait = maxWait
+	}
+	// Half fixed, half random, so clients retrying together spread out
+	// without any of them retrying right away.
+	wait = wait/2 + time.Duration(jitter()*float64(wait/2))
+	return wait, false, true
+}
+
+// parseRetryAfter reads both forms of the header, seconds or an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,192 @@
+package request_module
+
+import (
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func stubRetry() (*[]time.Duration, func()) {
+	var waits []time.Duration
+	sleep = func(d time.Duration) { waits = append(waits, d) }
+	jitter = func() float64 { return 1 }
+	return &waits, func() {
+		sleep = time.Sleep
+		jitter = rand.Float64
+	}
+}
+
+func TestRunRequest_RetriesUntilSuccess(t *testing.T) {
+	defer stubConfig()()
+	waits, restore := stubRetry()
+	defer restore()
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch calls {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	res, err := RunRequest(RequestOptions{
+		Url:    server.URL,
+		Method: "POST",
+		Retry:  RetryOptions{Attempts: 5, Backoff: 100 * time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if res.StatusCode != 200 || calls != 3 {
+		t.Errorf("Expected success on the third try, got %d after %d calls", res.StatusCode, calls)
+	}
+	if !reflect.DeepEqual(*waits, []time.Duration{100 * time.Millisecond, 2 * time.Second}) {
+		t.Errorf("Unexpected waits %v", *waits)
+	}
+	if len(res.Attempts) != 3 || res.Attempts[0].StatusCode != 503 || !res.Attempts[1].RetryAfter || res.Attempts[2].Wait != 0 {
+		t.Errorf("Unexpected attempts %+v", res.Attempts)
+	}
+}
+
+func TestRunRequest_RetryGivesUp(t *testing.T) {
+	defer stubConfig()()
+	_, restore := stubRetry()
+	defer restore()
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	res, _ := RunRequest(RequestOptions{Url: server.URL, Method: "GET", Retry: RetryOptions{Attempts: 3}})
+	if calls != 3 || len(res.Attempts) != 3 || res.StatusCode != 502 {
+		t.Errorf("Expected three tries ending on 502, got %d calls and %+v", calls, res.Attempts)
+	}
+
+	calls = 0
+	res, _ = RunRequest(RequestOptions{Url: server.URL, Method: "GET", Retry: RetryOptions{Attempts: 3, StatusCodes: []int{503}}})
+	if calls != 1 || len(res.Attempts) != 1 {
+		t.Errorf("Expected 502 not to be retried, got %d calls", calls)
+	}
+
+	calls = 0
+	res, _ = RunRequest(RequestOptions{Url: server.URL, Method: "GET"})
+	if calls != 1 || res.Attempts != nil {
+		t.Errorf("Expected no retries by default")
+	}
+}
+
+func TestRunRequest_RetryAfterTooLong(t *testing.T) {
+	defer stubConfig()()
+	waits, restore := stubRetry()
+	defer restore()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	res, _ := RunRequest(RequestOptions{Url: server.URL, Method: "GET", Retry: RetryOptions{Attempts: 3}})
+	if len(*waits) != 0 || len(res.Attempts) != 1 {
+		t.Errorf("Expected no retry sooner than the server asked, got %v", *waits)
+	}
+}
+
+func TestRunRequest_RetriesErrors(t *testing.T) {
+	defer stubConfig()()
+	_, restore := stubRetry()
+	defer restore()
+
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := listener.Addr().String()
+	listener.Close()
+
+	res, err := RunRequest(RequestOptions{Url: "http://" + addr, Method: "GET", Retry: RetryOptions{Attempts: 2}})
+	if classOf(err) != ErrorClassConnectionRefused {
+		t.Fatalf("Expected a connection refused error, got %v", err)
+	}
+	if len(res.Attempts) != 2 || res.Attempts[0].Error == "" {
+		t.Errorf("Expected both failed tries to be recorded, got %+v", res.Attempts)
+	}
+}
+
+func TestRunRequest_RetriesErrorsOfIdempotentMethods(t *testing.T) {
+	defer stubConfig()()
+	_, restore := stubRetry()
+	defer restore()
+
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := listener.Addr().String()
+	listener.Close()
+
+	res, _ := RunRequest(RequestOptions{Url: "http://" + addr, Method: "POST", Retry: RetryOptions{Attempts: 2}})
+	if len(res.Attempts) != 1 {
+		t.Errorf("Expected a POST not to be retried on the default failures, got %+v", res.Attempts)
+	}
+
+	res, _ = RunRequest(RequestOptions{Url: "http://" + addr, Method: "POST", Retry: RetryOptions{
+		Attempts:     2,
+		ErrorClasses: []ErrorClass{ErrorClassConnectionRefused},
+	}})
+	if len(res.Attempts) != 2 {
+		t.Errorf("Expected a POST to be retried on the failures asked for, got %+v", res.Attempts)
+	}
+}
+
+func TestRetryOptions_Wait(t *testing.T) {
+	jitter = func() float64 { return 0 }
+	defer func() { jitter = rand.Float64 }()
+
+	options := RetryOptions{Backoff: time.Second, MaxWait: 5 * time.Second}
+	want := []time.Duration{500 * time.Millisecond, time.Second, 2 * time.Second, 2500 * time.Millisecond, 2500 * time.Millisecond}
+	for i, expected := range want {
+		if wait, _, _ := options.wait(i+1, nil); wait != expected {
+			t.Errorf("wait(%d) = %v, want %v", i+1, wait, expected)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if wait, ok := parseRetryAfter("120"); !ok || wait != 2*time.Minute {
+		t.Errorf("Expected seconds to be parsed, got %v", wait)
+	}
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if wait, ok := parseRetryAfter(date); !ok || wait < 59*time.Minute {
+		t.Errorf("Expected a date to be parsed, got %v", wait)
+	}
+	if _, ok := parseRetryAfter("soon"); ok {
+		t.Error("Expected an invalid value to be ignored")
+	}
+}
+
+func TestParseRetryOptions(t *testing.T) {
+	codes, err := ParseRetryStatusCodes("429, 503")
+	if err != nil || !reflect.DeepEqual(codes, []int{429, 503}) {
+		t.Errorf("Unexpected codes %v, %v", codes, err)
+	}
+	if _, err := ParseRetryStatusCodes("abc"); err == nil {
+		t.Error("Expected an invalid status code to fail")
+	}
+
+	classes, err := ParseRetryErrorClasses("timeout,connection-refused")
+	if err != nil || !reflect.DeepEqual(classes, []ErrorClass{ErrorClassTimeout, ErrorClassConnectionRefused}) {
+		t.Errorf("Unexpected classes %v, %v", classes, err)
+	}
+	if _, err := ParseRetryErrorClasses("flaky"); err == nil {
+		t.Error("Expected an unknown class to fail")
+	}
+}
@@ -0,0 +1,204 @@
+package request_module
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go"
+)
+
+type connectRule struct {
+	host, port             string
+	targetHost, targetPort string
+}
+
+// router sends the connections of a request somewhere else than the URL
+// host, while the Host header and the TLS server name keep pointing at it.
+// Only the dialed address changes.
+type router struct {
+	unixSocket string
+	// resolve maps "host:port" to the addresses to try, "*" matches any host.
+	resolve   map[string][]string
+	connectTo []connectRule
+	dialer    *net.Dialer
+
+	mutex sync.Mutex
+	used  net.Addr
+}
+
+// newRouter parses the routing options, it returns nil when the request
+// goes to the URL host as usual.
+func newRouter(options RequestOptions) (*router, error) {
+	if options.UnixSocket == "" && len(options.Resolve) == 0 && len(options.ConnectTo) == 0 {
+		return nil, nil
+	}
+	if options.UnixSocket != "" && options.Protocol == ProtocolHttp3 {
+		return nil, errors.New("HTTP/3 runs over UDP and can't use a Unix socket")
+	}
+
+	r := &router{
+		unixSocket: options.UnixSocket,
+		resolve:    map[string][]string{},
+		dialer:     &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second},
+	}
+	for _, rule := range options.Resolve {
+		if err := r.addResolve(rule); err != nil {
+			return nil, err
+		}
+	}
+	for _, rule := range options.ConnectTo {
+		if err := r.addConnectTo(rule); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// addResolve parses a curl style "host:port:addr[,addr]" rule.
+func (r *router) addResolve(rule string) error {
+	parts := splitRoute(rule)
+	if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+		return fmt.Errorf("invalid --resolve %q, expected host:port:addr[,addr]", rule)
+	}
+	if _, err := net.LookupPort("tcp", parts[1]); err != nil {
+		return fmt.Errorf("invalid --resolve %q, bad port %q", rule, parts[1])
+	}
+
+	var addresses []string
+	for _, address := range strings.Split(parts[2], ",") {
+		address = strings.Trim(strings.TrimSpace(address), "[]")
+		if net.ParseIP(address) == nil {
+			return fmt.Errorf("invalid --resolve %q, %q is not an IP address", rule, address)
+		}
+		addresses = append(addresses, net.JoinHostPort(address, parts[1]))
+	}
+	key := net.JoinHostPort(strings.ToLower(strings.Trim(parts[0], "[]")), parts[1])
+	r.resolve[key] = append(r.resolve[key], addresses...)
+	return nil
+}
+
+// addConnectTo parses a curl style "HOST1:PORT1:HOST2:PORT2" rule. An empty
+// HOST1 or PORT1 matches any, an empty HOST2 or PORT2 keeps the original.
+func (r *router) addConnectTo(rule string) error {
+	parts := splitRoute(rule)
+	if len(parts) != 4 {
+		return fmt.Errorf("invalid --connect-to %q, expected HOST1:PORT1:HOST2:PORT2", rule)
+	}
+	r.connectTo = append(r.connectTo, connectRule{
+		host:       strings.ToLower(strings.Trim(parts[0], "[]")),
+		port:       parts[1],
+		targetHost: strings.Trim(parts[2], "[]"),
+		targetPort: parts[3],
+	})
+	return nil
+}
+
+// splitRoute splits a rule on the colons that are not inside the brackets of
+// an IPv6 address.
+func splitRoute(rule string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, c := range rule {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ':':
+			if depth == 0 {
+				parts = append(parts, rule[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, rule[start:])
+}
+
+// targets returns the addresses to try for a "host:port" the transport wants
+// to dial. --connect-to is applied first, then --resolve.
+func (r *router) targets(addr string) []string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return []string{addr}
+	}
+	host = strings.ToLower(host)
+
+	for _, rule := range r.connectTo {
+		if (rule.host == "" || rule.host == host) && (rule.port == "" || rule.port == port) {
+			if rule.targetHost != "" {
+				host = strings.ToLower(rule.targetHost)
+			}
+			if rule.targetPort != "" {
+				port = rule.targetPort
+			}
+			break
+		}
+	}
+
+	if addresses, ok := r.resolve[net.JoinHostPort(host, port)]; ok {
+		return addresses
+	}
+	if addresses, ok := r.resolve[net.JoinHostPort("*", port)]; ok {
+		return addresses
+	}
+	return []string{net.JoinHostPort(host, port)}
+}
+
+func (r *router) dialContext(ctx context.Context, network string, addr string) (net.Conn, error) {
+	if r.unixSocket != "" {
+		conn, err := r.dialer.DialContext(ctx, "unix", r.unixSocket)
+		if err == nil {
+			r.record(conn.RemoteAddr())
+		}
+		return conn, err
+	}
+
+	var lastErr error
+	for _, target := range r.targets(addr) {
+		conn, err := r.dialer.DialContext(ctx, network, target)
+		if err == nil {
+			r.record(conn.RemoteAddr())
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// dialQuic is the HTTP/3 counterpart of dialContext. The TLS config already
+// carries the URL host as the server name.
+func (r *router) dialQuic(ctx context.Context, addr string, tlsCfg *tls.Config, cfg *quic.Config) (*quic.Conn, error) {
+	var lastErr error
+	for _, target := range r.targets(addr) {
+		conn, err := quic.DialAddrEarly(ctx, target, tlsCfg, cfg)
+		if err == nil {
+			r.record(conn.RemoteAddr())
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+func (r *router) record(addr net.Addr) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.used = addr
+}
+
+// usedAddr is the address the last connection was made to, nil when the
+// request was not routed.
+func (r *router) usedAddr() net.Addr {
+	if r == nil {
+		return nil
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.used
+}
@@ -0,0 +1,175 @@
+package request_module
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/diogopereiradev/httpzen/internal/utils/ip_utility"
+	"github.com/go-resty/resty/v2"
+)
+
+func TestSplitRoute(t *testing.T) {
+	got := splitRoute("example.com:443:[::1]:8443")
+	want := []string{"example.com", "443", "[::1]", "8443"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitRoute() = %q, want %q", got, want)
+	}
+}
+
+func TestRouter_Targets(t *testing.T) {
+	r, err := newRouter(RequestOptions{
+		Resolve:   []string{"api.example.com:443:10.0.0.1,[::1]", "*:80:10.0.0.9"},
+		ConnectTo: []string{"old.example.com::api.example.com:", ":8080:backend:9090"},
+	})
+	if err != nil {
+		t.Fatalf("newRouter() failed: %v", err)
+	}
+
+	cases := map[string][]string{
+		"api.example.com:443": {"10.0.0.1:443", "[::1]:443"},
+		"old.example.com:443": {"10.0.0.1:443", "[::1]:443"},
+		"other.test:80":       {"10.0.0.9:80"},
+		"other.test:8080":     {"backend:9090"},
+		"other.test:443":      {"other.test:443"},
+	}
+	for addr, want := range cases {
+		if got := r.targets(addr); !reflect.DeepEqual(got, want) {
+			t.Errorf("targets(%q) = %q, want %q", addr, got, want)
+		}
+	}
+}
+
+func TestNewRouter_Invalid(t *testing.T) {
+	invalid := []RequestOptions{
+		{Resolve: []string{"example.com:443"}},
+		{Resolve: []string{"example.com:port:10.0.0.1"}},
+		{Resolve: []string{"example.com:443:not-an-ip"}},
+		{ConnectTo: []string{"a:1:b"}},
+		{UnixSocket: "/tmp/api.sock", Protocol: ProtocolHttp3},
+	}
+	for _, options := range invalid {
+		if _, err := newRouter(options); err == nil {
+			t.Errorf("Expected an error for %+v", options)
+		}
+	}
+
+	if r, err := newRouter(RequestOptions{}); r != nil || err != nil {
+		t.Errorf("Expected no router without routing options")
+	}
+}
+
+// routedRequest runs a request and returns the Host header the server saw and
+// the address handed to lookupDomainIps.
+func routedRequest(t *testing.T, options RequestOptions) (RequestResponse, string, net.Addr) {
+	defer stubConfig()()
+	var used net.Addr
+	lookupDomainIps = func(_ *resty.Response, addr net.Addr) []ip_utility.LookupIpInfo {
+		used = addr
+		return nil
+	}
+
+	options.Method = "GET"
+	res, err := RunRequest(options)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	return res, res.Result, used
+}
+
+func hostEcho() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Host))
+	})
+}
+
+func TestRunRequest_Resolve(t *testing.T) {
+	server := httptest.NewServer(hostEcho())
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	res, host, used := routedRequest(t, RequestOptions{
+		Url:     "http://api.example.test:" + port + "/",
+		Resolve: []string{"api.example.test:" + port + ":127.0.0.1"},
+	})
+	if host != "api.example.test:"+port {
+		t.Errorf("Expected the original Host header, got %q", host)
+	}
+	if used == nil || used.String() != server.Listener.Addr().String() {
+		t.Errorf("Expected the dialed address to be reported, got %v", used)
+	}
+	if !reflect.DeepEqual(res.Request.Resolve, []string{"api.example.test:" + port + ":127.0.0.1"}) {
+		t.Errorf("Expected the resolve rules to be kept for a resend")
+	}
+}
+
+func TestRouter_ConnectToKeepsServerName(t *testing.T) {
+	server := httptest.NewTLSServer(hostEcho())
+	defer server.Close()
+
+	route, err := newRouter(RequestOptions{ConnectTo: []string{"example.com:443:" + server.Listener.Addr().String()}})
+	if err != nil {
+		t.Fatalf("newRouter() failed: %v", err)
+	}
+	// The test certificate is valid for example.com, so the handshake only
+	// succeeds when the server name is the URL host and not 127.0.0.1.
+	transport := newTransport(ProtocolAuto, route).(*http.Transport)
+	transport.TLSClientConfig = &tls.Config{RootCAs: server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs}
+	defer transport.CloseIdleConnections()
+
+	res, err := (&http.Client{Transport: transport}).Get("https://example.com/")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer res.Body.Close()
+	host, _ := io.ReadAll(res.Body)
+	if string(host) != "example.com" {
+		t.Errorf("Expected the original Host header, got %q", host)
+	}
+	if used := route.usedAddr(); used == nil || used.String() != server.Listener.Addr().String() {
+		t.Errorf("Expected the dialed address to be recorded, got %v", used)
+	}
+}
+
+func TestRunRequest_UnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "api.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("Unix sockets are not available: %v", err)
+	}
+	server := httptest.NewUnstartedServer(hostEcho())
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	_, host, used := routedRequest(t, RequestOptions{Url: "http://docker/info", UnixSocket: socket})
+	if host != "docker" {
+		t.Errorf("Expected the URL host as the Host header, got %q", host)
+	}
+	if used == nil || used.Network() != "unix" || used.String() != socket {
+		t.Errorf("Expected the socket to be reported, got %v", used)
+	}
+
+	res, host, _ := routedRequest(t, RequestOptions{Url: "http+unix://" + url.PathEscape(socket) + "/info"})
+	if host != "localhost" || res.Request.UnixSocket != socket || res.Request.Url != "http://localhost/info" {
+		t.Errorf("Unexpected http+unix request: host %q, options %+v", host, res.Request)
+	}
+}
+
+func TestRunRequest_MissingUnixSocket(t *testing.T) {
+	_, err := RunRequest(RequestOptions{Url: "http://localhost/", Method: "GET", UnixSocket: filepath.Join(t.TempDir(), "nope.sock")})
+	if classOf(err) != ErrorClassConnectionRefused {
+		t.Errorf("Expected a connection refused error, got %v", err)
+	}
+
+	_, err = RunRequest(RequestOptions{Url: "http+unix:///info", Method: "GET"})
+	if classOf(err) != ErrorClassInvalidUrl {
+		t.Errorf("Expected an invalid URL error, got %v", err)
+	}
+}
@@ -0,0 +1,229 @@
+package request_module
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type StreamEvent struct {
+	Id    string    `json:"id,omitempty"`
+	Event string    `json:"event,omitempty"`
+	Data  string    `json:"data"`
+	Time  time.Time `json:"time"`
+}
+
+type StreamHandlers struct {
+	// OnStart is called once the response headers arrive, before any event.
+	OnStart func(res RequestResponse)
+	OnEvent func(event StreamEvent)
+	// OnProgress reports the bytes of a download received so far, total is
+	// -1 when the server didn't tell the size.
+	OnProgress func(received int64, total int64)
+}
+
+var RunStreamRequest = runStreamRequest
+
+// StreamEventLimit keeps a long stream from growing without end, only the
+// most recent events are kept and the oldest go first.
+const StreamEventLimit = 1000
+
+// Lines longer than this (a huge NDJSON record, for example) end the stream
+// with a protocol error instead of growing the buffer without bound.
+const maxStreamLineSize = 4 * 1024 * 1024
+
+// runStreamRequest sends the request and reads the body as it arrives instead
+// of buffering it. Server-Sent Events are parsed into their fields, any other
+// content type is split into one event per line (chunked text, NDJSON...).
+// The stream ends when the server closes it or ctx is canceled, and the
+// returned response holds the last StreamEventLimit events as its Result.
+func runStreamRequest(ctx context.Context, options RequestOptions, handlers StreamHandlers) (RequestResponse, error) {
+	failed := RequestResponse{Request: options}
+
+	prepared, err := prepareRequest(options)
+	if err != nil {
+		return failed, err
+	}
+	defer prepared.close()
+
+	prepared.unbuffered(ctx)
+	if _, ok := prepared.req.Header["Accept"]; !ok {
+		prepared.req.SetHeader("Accept", "text/event-stream, application/x-ndjson, */*")
+	}
+
+	startTime := time.Now()
+
+	res, err := prepared.execute()
+	if err != nil {
+		return failed, ClassifyError(timeoutError(err, options))
+	}
+	body := res.RawBody()
+	defer body.Close()
+
+	response := prepared.buildResponse(res, "", parseExecutionTimeInMilliseconds(startTime))
+	if handlers.OnStart != nil {
+		handlers.OnStart(response)
+	}
+
+	var result streamResult
+	onEvent := func(event StreamEvent) {
+		result.add(FormatStreamEvent(event))
+		if handlers.OnEvent != nil {
+			handlers.OnEvent(event)
+		}
+	}
+
+	if IsEventStream(res.Header().Get("Content-Type")) {
+		err = ParseEventStream(body, onEvent)
+	} else {
+		err = ParseLineStream(body, onEvent)
+	}
+
+	response.Result = result.String()
+	response.Trailers = res.RawResponse.Trailer
+	response.ExecutionTime = parseExecutionTimeInMilliseconds(startTime)
+
+	if err != nil && !errors.Is(err, context.Canceled) && ctx.Err() == nil {
+		return response, ClassifyError(timeoutError(err, options))
+	}
+	return response, nil
+}
+
+// streamResult is a ring of the formatted events of a stream, it keeps the
+// last StreamEventLimit of them.
+type streamResult struct {
+	events []string
+	next   int
+}
+
+func (r *streamResult) add(event string) {
+	if len(r.events) < StreamEventLimit {
+		r.events = append(r.events, event)
+		return
+	}
+	r.events[r.next] = event
+	r.next = (r.next + 1) % StreamEventLimit
+}
+
+// String joins the events kept from the oldest to the newest.
+func (r *streamResult) String() string {
+	var b strings.Builder
+	for i := range r.events {
+		b.WriteString(r.events[(r.next+i)%len(r.events)])
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// unbuffered leaves the body to the caller, to read as it arrives. The
+// timeout only bounds the wait for the response headers, a stream or a big
+// download may legitimately take much longer. The idle timeout catches one
+// that stalled.
+func (p *preparedRequest) unbuffered(ctx context.Context) {
+	if transport, ok := p.transport.base.(*http.Transport); ok && p.options.Timeouts.ResponseHeader == 0 {
+		transport.ResponseHeaderTimeout = p.options.Timeout
+	}
+	p.client.SetTimeout(0)
+	p.req.SetContext(ctx)
+	p.req.SetDoNotParseResponse(true)
+}
+
+func IsEventStream(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "text/event-stream"
+}
+
+func newStreamScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxStreamLineSize)
+	return scanner
+}
+
+// ParseEventStream follows the WHATWG event stream format: fields accumulate
+// until a blank line dispatches the event, comments start with ':' and
+// multiple data lines are joined with '\n'.
+func ParseEventStream(r io.Reader, onEvent func(StreamEvent)) error {
+	scanner := newStreamScanner(r)
+
+	var current StreamEvent
+	var data []string
+	hasData := false
+	lastId := ""
+
+	dispatch := func() {
+		if hasData {
+			current.Data = strings.Join(data, "\n")
+			current.Id = lastId
+			current.Time = time.Now()
+			onEvent(current)
+		}
+		current = StreamEvent{}
+		data = nil
+		hasData = false
+	}
+
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" {
+			dispatch()
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "event":
+			current.Event = value
+		case "data":
+			data = append(data, value)
+			hasData = true
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				lastId = value
+			}
+		}
+	}
+	dispatch()
+	return scanner.Err()
+}
+
+func ParseLineStream(r io.Reader, onEvent func(StreamEvent)) error {
+	scanner := newStreamScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		onEvent(StreamEvent{Data: line, Time: time.Now()})
+	}
+	return scanner.Err()
+}
+
+// FormatStreamEvent renders an event the way it is kept in the response
+// Result, close to the wire format so it can be copied back as is.
+func FormatStreamEvent(event StreamEvent) string {
+	if event.Event == "" && event.Id == "" {
+		return event.Data
+	}
+
+	var b strings.Builder
+	if event.Event != "" {
+		b.WriteString("event: " + event.Event + "\n")
+	}
+	if event.Id != "" {
+		b.WriteString("id: " + event.Id + "\n")
+	}
+	for _, line := range strings.Split(event.Data, "\n") {
+		b.WriteString("data: " + line + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,158 @@
+package request_module
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEventStream(t *testing.T) {
+	input := ": keep-alive\n" +
+		"event: update\n" +
+		"id: 1\n" +
+		"data: first\n" +
+		"data: second\n" +
+		"\n" +
+		"data:no space\r\n" +
+		"\r\n" +
+		"event: ignored\n" +
+		"\n" +
+		"data: last"
+
+	var events []StreamEvent
+	err := ParseEventStream(strings.NewReader(input), func(e StreamEvent) { events = append(events, e) })
+	assert.NoError(t, err)
+	assert.Len(t, events, 3)
+
+	assert.Equal(t, "update", events[0].Event)
+	assert.Equal(t, "1", events[0].Id)
+	assert.Equal(t, "first\nsecond", events[0].Data)
+
+	assert.Equal(t, "", events[1].Event)
+	assert.Equal(t, "1", events[1].Id, "the last event id is kept until a new one is sent")
+	assert.Equal(t, "no space", events[1].Data)
+
+	assert.Equal(t, "last", events[2].Data)
+}
+
+func TestParseLineStream(t *testing.T) {
+	var events []StreamEvent
+	err := ParseLineStream(strings.NewReader("{\"a\":1}\n\n{\"a\":2}\r\n"), func(e StreamEvent) { events = append(events, e) })
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, `{"a":1}`, events[0].Data)
+	assert.Equal(t, `{"a":2}`, events[1].Data)
+}
+
+func TestFormatStreamEvent(t *testing.T) {
+	assert.Equal(t, "plain", FormatStreamEvent(StreamEvent{Data: "plain"}))
+	assert.Equal(t, "event: ping\nid: 7\ndata: a\ndata: b\n", FormatStreamEvent(StreamEvent{Event: "ping", Id: "7", Data: "a\nb"}))
+}
+
+func TestIsEventStream(t *testing.T) {
+	assert.True(t, IsEventStream("text/event-stream; charset=utf-8"))
+	assert.False(t, IsEventStream("application/x-ndjson"))
+	assert.False(t, IsEventStream(""))
+}
+
+func TestRunStreamRequest_EventStream(t *testing.T) {
+	defer stubConfig()()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+		for _, data := range []string{"one", "two", "three"} {
+			w.Write([]byte("event: tick\ndata: " + data + "\n\n"))
+			flusher.Flush()
+		}
+	}))
+	defer server.Close()
+
+	started := false
+	var events []StreamEvent
+	resp, err := RunStreamRequest(context.Background(), RequestOptions{Url: server.URL, Method: "GET", Timeout: time.Second}, StreamHandlers{
+		OnStart: func(res RequestResponse) {
+			started = true
+			assert.Equal(t, 200, res.StatusCode)
+		},
+		OnEvent: func(e StreamEvent) { events = append(events, e) },
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, started)
+	assert.Len(t, events, 3)
+	assert.Equal(t, "tick", events[2].Event)
+	assert.Equal(t, "three", events[2].Data)
+	assert.Contains(t, resp.Result, "data: two")
+}
+
+func TestRunStreamRequest_OutlivesTimeout(t *testing.T) {
+	defer stubConfig()()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.(http.Flusher).Flush()
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte("{\"done\":true}\n"))
+	}))
+	defer server.Close()
+
+	resp, err := RunStreamRequest(context.Background(), RequestOptions{Url: server.URL, Method: "GET", Timeout: 50 * time.Millisecond}, StreamHandlers{})
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"done\":true}\n", resp.Result)
+}
+
+func TestRunStreamRequest_Canceled(t *testing.T) {
+	defer stubConfig()()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("first\n"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	resp, err := RunStreamRequest(ctx, RequestOptions{Url: server.URL, Method: "GET", Timeout: time.Second}, StreamHandlers{
+		OnEvent: func(e StreamEvent) { cancel() },
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "first\n", resp.Result)
+}
+
+func TestRunStreamRequest_KeepsTheLastEvents(t *testing.T) {
+	defer stubConfig()()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		for i := 0; i < StreamEventLimit+500; i++ {
+			fmt.Fprintf(w, "%d\n", i)
+		}
+	}))
+	defer server.Close()
+
+	received := 0
+	resp, err := RunStreamRequest(context.Background(), RequestOptions{Url: server.URL, Method: "GET", Timeout: time.Second}, StreamHandlers{
+		OnEvent: func(e StreamEvent) { received++ },
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, StreamEventLimit+500, received, "every event still reaches the handler")
+
+	lines := strings.Split(strings.TrimSuffix(resp.Result, "\n"), "\n")
+	assert.Len(t, lines, StreamEventLimit)
+	assert.Equal(t, "500", lines[0])
+	assert.Equal(t, strconv.Itoa(StreamEventLimit+499), lines[len(lines)-1])
+}
+
+func TestRunStreamRequest_InvalidUrl(t *testing.T) {
+	resp, err := RunStreamRequest(context.Background(), RequestOptions{Url: "nope", Method: "GET"}, StreamHandlers{})
+	assert.Equal(t, ErrorClassInvalidUrl, classOf(err))
+	assert.Equal(t, "nope", resp.Request.Url)
+}
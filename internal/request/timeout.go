@@ -0,0 +1,178 @@
+package request_module
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	config_module "github.com/diogopereiradev/httpzen/internal/config"
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+)
+
+// Timeouts bound each phase of a request, RequestOptions.Timeout bounds the
+// whole of it. Zero keeps the transport default.
+type Timeouts struct {
+	Connect        time.Duration `json:"connect,omitempty"`
+	TlsHandshake   time.Duration `json:"tls_handshake,omitempty"`
+	ResponseHeader time.Duration `json:"response_header,omitempty"`
+	// Idle is the longest wait between two reads of the response body.
+	Idle time.Duration `json:"idle,omitempty"`
+}
+
+// ConfigTimeouts reads the timeouts from the config, where they are kept in
+// milliseconds, for requests that have no flags of their own.
+func ConfigTimeouts(config config_module.Config) (time.Duration, Timeouts) {
+	return time.Duration(config.Timeout) * time.Millisecond, Timeouts{
+		Connect:        time.Duration(config.ConnectTimeout) * time.Millisecond,
+		TlsHandshake:   time.Duration(config.TlsTimeout) * time.Millisecond,
+		ResponseHeader: time.Duration(config.ResponseHeaderTimeout) * time.Millisecond,
+		Idle:           time.Duration(config.IdleTimeout) * time.Millisecond,
+	}
+}
+
+const (
+	TimeoutPhaseConnect        = "connect"
+	TimeoutPhaseTlsHandshake   = "TLS handshake"
+	TimeoutPhaseResponseHeader = "response header"
+	TimeoutPhaseIdle           = "idle body"
+	TimeoutPhaseTotal          = "total"
+)
+
+// TimeoutError tells which phase of the request ran out of time.
+type TimeoutError struct {
+	Phase string
+	Limit time.Duration
+	Err   error
+}
+
+func (e *TimeoutError) Error() string {
+	message := "the " + e.Phase + " timeout"
+	if e.Limit > 0 {
+		message += " of " + e.Limit.String()
+	}
+	message += " ran out"
+	if e.Err != nil {
+		message += ": " + e.Err.Error()
+	}
+	return message
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// Timeout makes the error a net.Error, so generic timeout checks still see
+// it as one.
+func (e *TimeoutError) Timeout() bool {
+	return true
+}
+
+func (e *TimeoutError) Temporary() bool {
+	return false
+}
+
+// applyTimeouts sets the phase timeouts on the transport built by
+// newTransport. HTTP/3 has a single handshake timeout covering both the
+// connection and TLS.
+func applyTimeouts(transport http.RoundTripper, timeouts Timeouts, route *router) {
+	switch transport := transport.(type) {
+	case *http.Transport:
+		if timeouts.Connect > 0 {
+			if route != nil {
+				route.dialer.Timeout = timeouts.Connect
+			} else {
+				dialer := &net.Dialer{Timeout: timeouts.Connect, KeepAlive: 30 * time.Second}
+				transport.DialContext = dialer.DialContext
+			}
+		}
+		if timeouts.TlsHandshake > 0 {
+			transport.TLSHandshakeTimeout = timeouts.TlsHandshake
+		}
+		if timeouts.ResponseHeader > 0 {
+			transport.ResponseHeaderTimeout = timeouts.ResponseHeader
+		}
+	case *http3.Transport:
+		if handshake := timeouts.Connect + timeouts.TlsHandshake; handshake > 0 {
+			transport.QUICConfig = &quic.Config{HandshakeIdleTimeout: handshake}
+		}
+	}
+}
+
+// timeoutError wraps a timeout into a *TimeoutError naming its phase, other
+// errors are returned as they are. net/http only tells the phases apart in
+// its messages.
+func timeoutError(err error, options RequestOptions) error {
+	var timeoutErr *TimeoutError
+	if err == nil || errors.As(err, &timeoutErr) {
+		return err
+	}
+
+	message := err.Error()
+	var opErr *net.OpError
+	switch {
+	case strings.Contains(message, "TLS handshake timeout"):
+		return &TimeoutError{Phase: TimeoutPhaseTlsHandshake, Limit: phaseLimit(options.Timeouts.TlsHandshake, 10*time.Second), Err: err}
+	case strings.Contains(message, "timeout awaiting response headers"):
+		return &TimeoutError{Phase: TimeoutPhaseResponseHeader, Limit: options.Timeouts.ResponseHeader, Err: err}
+	case errors.As(err, &opErr) && opErr.Op == "dial" && opErr.Timeout():
+		return &TimeoutError{Phase: TimeoutPhaseConnect, Limit: phaseLimit(options.Timeouts.Connect, 30*time.Second), Err: err}
+	case strings.Contains(message, "Client.Timeout"), errors.Is(err, context.DeadlineExceeded):
+		return &TimeoutError{Phase: TimeoutPhaseTotal, Limit: options.Timeout, Err: err}
+	}
+	return err
+}
+
+func phaseLimit(limit time.Duration, fallback time.Duration) time.Duration {
+	if limit > 0 {
+		return limit
+	}
+	return fallback
+}
+
+// idleTimeoutBody cancels the request when the body stays silent for longer
+// than the idle timeout. Every read restarts the timer.
+type idleTimeoutBody struct {
+	body    io.ReadCloser
+	timeout time.Duration
+	timer   *time.Timer
+	cancel  context.CancelFunc
+
+	mutex    sync.Mutex
+	timedOut bool
+}
+
+func newIdleTimeoutBody(body io.ReadCloser, timeout time.Duration, cancel context.CancelFunc) *idleTimeoutBody {
+	b := &idleTimeoutBody{body: body, timeout: timeout, cancel: cancel}
+	b.timer = time.AfterFunc(timeout, func() {
+		b.mutex.Lock()
+		b.timedOut = true
+		b.mutex.Unlock()
+		cancel()
+	})
+	return b
+}
+
+func (b *idleTimeoutBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.timedOut {
+		return n, &TimeoutError{Phase: TimeoutPhaseIdle, Limit: b.timeout, Err: err}
+	}
+	if err == nil {
+		b.timer.Reset(b.timeout)
+	}
+	return n, err
+}
+
+func (b *idleTimeoutBody) Close() error {
+	b.timer.Stop()
+	b.cancel()
+	return b.body.Close()
+}
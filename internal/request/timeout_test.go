@@ -0,0 +1,163 @@
+package request_module
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	config_module "github.com/diogopereiradev/httpzen/internal/config"
+)
+
+// stallingServer answers after the headers, or in the middle of the body,
+// and then hangs until the test ends.
+func stallingServer(t *testing.T, beforeHeaders bool) *httptest.Server {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !beforeHeaders {
+			w.Write([]byte("partial"))
+			w.(http.Flusher).Flush()
+		}
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(func() {
+		close(release)
+		server.Close()
+	})
+	return server
+}
+
+func phaseOf(t *testing.T, err error) string {
+	if classOf(err) != ErrorClassTimeout {
+		t.Fatalf("Expected a timeout, got %v", err)
+	}
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("Expected a *TimeoutError, got %T", err)
+	}
+	return timeoutErr.Phase
+}
+
+func TestRunRequest_Timeouts(t *testing.T) {
+	defer stubConfig()()
+
+	_, err := RunRequest(RequestOptions{
+		Url:      stallingServer(t, true).URL,
+		Method:   "GET",
+		Timeout:  5 * time.Second,
+		Timeouts: Timeouts{ResponseHeader: 50 * time.Millisecond},
+	})
+	if phase := phaseOf(t, err); phase != TimeoutPhaseResponseHeader {
+		t.Errorf("Expected the response header phase, got %q", phase)
+	}
+
+	_, err = RunRequest(RequestOptions{
+		Url:      stallingServer(t, false).URL,
+		Method:   "GET",
+		Timeout:  5 * time.Second,
+		Timeouts: Timeouts{Idle: 50 * time.Millisecond},
+	})
+	if phase := phaseOf(t, err); phase != TimeoutPhaseIdle {
+		t.Errorf("Expected the idle body phase, got %q", phase)
+	}
+	if !strings.Contains(err.Error(), "idle body timeout of 50ms ran out") {
+		t.Errorf("Expected the phase and its limit in the message, got %v", err)
+	}
+
+	_, err = RunRequest(RequestOptions{
+		Url:     stallingServer(t, true).URL,
+		Method:  "GET",
+		Timeout: 50 * time.Millisecond,
+	})
+	if phase := phaseOf(t, err); phase != TimeoutPhaseTotal {
+		t.Errorf("Expected the total phase, got %q", phase)
+	}
+}
+
+func TestRunRequest_TlsHandshakeTimeout(t *testing.T) {
+	defer stubConfig()()
+
+	// Accepts connections but never answers the client hello.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		var conns []net.Conn
+		defer func() {
+			for _, conn := range conns {
+				conn.Close()
+			}
+		}()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conns = append(conns, conn)
+		}
+	}()
+
+	_, err = RunRequest(RequestOptions{
+		Url:      "https://" + listener.Addr().String(),
+		Method:   "GET",
+		Timeout:  5 * time.Second,
+		Timeouts: Timeouts{TlsHandshake: 50 * time.Millisecond},
+	})
+	if phase := phaseOf(t, err); phase != TimeoutPhaseTlsHandshake {
+		t.Errorf("Expected the TLS handshake phase, got %q", phase)
+	}
+}
+
+type dialTimeout struct{}
+
+func (dialTimeout) Error() string   { return "i/o timeout" }
+func (dialTimeout) Timeout() bool   { return true }
+func (dialTimeout) Temporary() bool { return true }
+
+func TestTimeoutError_Connect(t *testing.T) {
+	err := timeoutError(&net.OpError{Op: "dial", Net: "tcp", Err: dialTimeout{}}, RequestOptions{Timeouts: Timeouts{Connect: 2 * time.Second}})
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) || timeoutErr.Phase != TimeoutPhaseConnect || timeoutErr.Limit != 2*time.Second {
+		t.Fatalf("Expected a connect timeout of 2s, got %v", err)
+	}
+	if ExitCode(err) != 28 {
+		t.Errorf("Expected curl's timeout exit code, got %d", ExitCode(err))
+	}
+
+	other := errors.New("boom")
+	if timeoutError(other, RequestOptions{}) != other {
+		t.Error("Expected other errors to be kept as they are")
+	}
+}
+
+func TestApplyTimeouts(t *testing.T) {
+	transport := newTransport(ProtocolAuto, nil).(*http.Transport)
+	applyTimeouts(transport, Timeouts{TlsHandshake: time.Second, ResponseHeader: 2 * time.Second}, nil)
+	if transport.TLSHandshakeTimeout != time.Second || transport.ResponseHeaderTimeout != 2*time.Second {
+		t.Errorf("Unexpected transport timeouts: %v, %v", transport.TLSHandshakeTimeout, transport.ResponseHeaderTimeout)
+	}
+
+	route, _ := newRouter(RequestOptions{Resolve: []string{"a.test:443:10.0.0.1"}})
+	applyTimeouts(newTransport(ProtocolAuto, route), Timeouts{Connect: 3 * time.Second}, route)
+	if route.dialer.Timeout != 3*time.Second {
+		t.Errorf("Expected the routed dialer to use the connect timeout, got %v", route.dialer.Timeout)
+	}
+}
+
+func TestConfigTimeouts(t *testing.T) {
+	timeout, timeouts := ConfigTimeouts(config_module.Config{Timeout: 30000, ConnectTimeout: 1000, TlsTimeout: 2000, ResponseHeaderTimeout: 3000, IdleTimeout: 4000})
+	if timeout != 30*time.Second {
+		t.Errorf("Expected the whole request timeout in milliseconds, got %v", timeout)
+	}
+	if timeouts != (Timeouts{Connect: time.Second, TlsHandshake: 2 * time.Second, ResponseHeader: 3 * time.Second, Idle: 4 * time.Second}) {
+		t.Errorf("Unexpected phase timeouts: %+v", timeouts)
+	}
+}
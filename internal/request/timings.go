@@ -0,0 +1,105 @@
+package request_module
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timings break the request down like the HAR format does, in
+// milliseconds. Phases that didn't happen, like DNS and connect on a reused
+// connection, are -1. Connect includes the TLS handshake.
+type Timings struct {
+	Blocked float64 `json:"blocked"`
+	Dns     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Tls     float64 `json:"tls"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// timingTrace collects the phase timestamps of one round trip. The dial
+// callbacks run on other goroutines, hence the mutex.
+type timingTrace struct {
+	mutex sync.Mutex
+
+	start        time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	gotConn      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+}
+
+func newTimingTrace() *timingTrace {
+	return &timingTrace{start: time.Now()}
+}
+
+func (t *timingTrace) set(field *time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	// Happy eyeballs may dial twice, the first attempt counts.
+	if field.IsZero() {
+		*field = time.Now()
+	}
+}
+
+func (t *timingTrace) hooks(trace *httptrace.ClientTrace) {
+	trace.DNSStart = func(httptrace.DNSStartInfo) { t.set(&t.dnsStart) }
+	trace.DNSDone = func(httptrace.DNSDoneInfo) { t.set(&t.dnsDone) }
+	trace.ConnectStart = func(string, string) { t.set(&t.connectStart) }
+	trace.ConnectDone = func(string, string, error) { t.set(&t.connectDone) }
+	trace.TLSHandshakeStart = func() { t.set(&t.tlsStart) }
+	trace.TLSHandshakeDone = func(tls.ConnectionState, error) { t.set(&t.tlsDone) }
+	trace.WroteRequest = func(httptrace.WroteRequestInfo) { t.set(&t.wroteRequest) }
+	trace.GotFirstResponseByte = func() { t.set(&t.firstByte) }
+}
+
+// timings turns the timestamps into durations, receive is what is left of
+// the total once the body has been read.
+func (t *timingTrace) timings(total float64) Timings {
+	if t == nil {
+		return Timings{Blocked: -1, Dns: -1, Connect: -1, Tls: -1, Receive: total}
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	between := func(from time.Time, to time.Time) float64 {
+		if from.IsZero() || to.IsZero() {
+			return -1
+		}
+		return float64(to.Sub(from).Microseconds()) / 1000
+	}
+
+	connectEnd := t.connectDone
+	if !t.tlsDone.IsZero() {
+		connectEnd = t.tlsDone
+	}
+	firstStep := t.gotConn
+	for _, step := range []time.Time{t.connectStart, t.dnsStart} {
+		if !step.IsZero() {
+			firstStep = step
+		}
+	}
+
+	timings := Timings{
+		Blocked: between(t.start, firstStep),
+		Dns:     between(t.dnsStart, t.dnsDone),
+		Connect: between(t.connectStart, connectEnd),
+		Tls:     between(t.tlsStart, t.tlsDone),
+		Send:    max(between(t.gotConn, t.wroteRequest), 0),
+		Wait:    max(between(t.wroteRequest, t.firstByte), 0),
+	}
+	spent := timings.Send + timings.Wait
+	for _, phase := range []float64{timings.Blocked, timings.Dns, timings.Connect} {
+		spent += max(phase, 0)
+	}
+	timings.Receive = max(total-spent, 0)
+	return timings
+}
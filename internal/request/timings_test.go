@@ -0,0 +1,74 @@
+package request_module
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestConnectionTransport_Timings(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	base := newTransport(ProtocolAuto, nil).(*http.Transport)
+	base.TLSClientConfig = server.Client().Transport.(*http.Transport).TLSClientConfig
+	defer base.CloseIdleConnections()
+	transport := &connectionTransport{base: base}
+
+	res, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	io.ReadAll(res.Body)
+	res.Body.Close()
+
+	timings := transport.trace.timings(100)
+	if timings.Connect <= 0 || timings.Tls <= 0 || timings.Tls > timings.Connect {
+		t.Errorf("Expected the connect time to include the TLS handshake, got %+v", timings)
+	}
+	if timings.Dns != -1 {
+		t.Errorf("Expected no DNS lookup for an IP address, got %v", timings.Dns)
+	}
+	if timings.Wait < 20 {
+		t.Errorf("Expected the wait to cover the server delay, got %v", timings.Wait)
+	}
+}
+
+func TestRunRequest_StartedAt(t *testing.T) {
+	defer stubConfig()()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	before := time.Now()
+	res, err := RunRequest(RequestOptions{Url: server.URL, Method: "GET", Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if res.StartedAt.Before(before.Add(-time.Millisecond)) || res.StartedAt.After(time.Now()) {
+		t.Errorf("Unexpected start time %v", res.StartedAt)
+	}
+	if res.Timings.Connect < 0 || res.Timings.Tls != -1 {
+		t.Errorf("Expected a connect time without TLS, got %+v", res.Timings)
+	}
+}
+
+func TestTimingTrace_Unset(t *testing.T) {
+	var trace *timingTrace
+	if timings := trace.timings(12); timings.Receive != 12 || timings.Dns != -1 {
+		t.Errorf("Expected the whole time as receive without a trace, got %+v", timings)
+	}
+
+	trace = newTimingTrace()
+	trace.gotConn = trace.start.Add(time.Millisecond)
+	trace.wroteRequest = trace.start.Add(3 * time.Millisecond)
+	trace.firstByte = trace.start.Add(13 * time.Millisecond)
+	timings := trace.timings(20)
+	if timings.Connect != -1 || timings.Blocked != 1 || timings.Send != 2 || timings.Wait != 10 || timings.Receive != 7 {
+		t.Errorf("Unexpected timings on a reused connection %+v", timings)
+	}
+}
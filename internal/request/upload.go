@@ -0,0 +1,59 @@
+package request_module
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/diogopereiradev/httpzen/internal/utils/http_utility"
+)
+
+// setBody sets the body again before every try, a body streamed from disk
+// can only be read once.
+func (p *preparedRequest) setBody() {
+	if _, ok := p.body.Result.(*http_utility.UploadBody); ok {
+		// The pre-request hook replaces it with a counted reader, resty only
+		// needs to know there is a body.
+		p.req.SetBody(http.NoBody)
+		return
+	}
+	p.req.SetBody(p.body.Result)
+}
+
+// prepareUpload streams the body into the request with its length, when
+// known, so it isn't sent chunked. GetBody lets redirects and digest auth
+// send it again.
+func prepareUpload(r *http.Request, upload *http_utility.UploadBody, onProgress func(int64, int64)) {
+	open := func() (io.ReadCloser, error) {
+		return &uploadProgressReader{body: upload.Open(), total: upload.Length, onProgress: onProgress}, nil
+	}
+	r.Body, _ = open()
+	r.GetBody = open
+	r.ContentLength = upload.Length
+	if upload.Length == 0 {
+		r.Body = http.NoBody
+	}
+}
+
+// uploadProgressReader counts the bytes the transport reads from the body.
+type uploadProgressReader struct {
+	body       io.ReadCloser
+	sent       int64
+	total      int64
+	onProgress func(int64, int64)
+	lastReport time.Time
+}
+
+func (r *uploadProgressReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	r.sent += int64(n)
+	if r.onProgress != nil && (err != nil || time.Since(r.lastReport) >= progressInterval) {
+		r.lastReport = time.Now()
+		r.onProgress(r.sent, r.total)
+	}
+	return n, err
+}
+
+func (r *uploadProgressReader) Close() error {
+	return r.body.Close()
+}
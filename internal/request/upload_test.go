@@ -0,0 +1,111 @@
+package request_module
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/diogopereiradev/httpzen/internal/utils/http_utility"
+)
+
+func TestRunRequest_StreamsMultipartFiles(t *testing.T) {
+	defer stubConfig()()
+	content := bytes.Repeat([]byte("upload "), 64*1024)
+	path := filepath.Join(t.TempDir(), "big.bin")
+	os.WriteFile(path, content, 0644)
+
+	var contentLength int64
+	var transferEncoding []string
+	var received []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentLength = r.ContentLength
+		transferEncoding = r.TransferEncoding
+		file, _, err := r.FormFile("file")
+		if err == nil {
+			received, _ = io.ReadAll(file)
+		}
+	}))
+	defer server.Close()
+
+	var lastSent, lastTotal int64
+	_, err := RunRequest(RequestOptions{
+		Url:     server.URL,
+		Method:  "POST",
+		Timeout: 5 * time.Second,
+		Body: []http_utility.HttpContentData{
+			{ContentType: "multipart/form-data", Key: "name", Value: "value"},
+			{ContentType: "multipart/form-data", Key: "file", Value: path},
+		},
+		OnUploadProgress: func(sent int64, total int64) { lastSent, lastTotal = sent, total },
+	})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if !bytes.Equal(received, content) {
+		t.Errorf("Expected the server to receive the file, got %d bytes", len(received))
+	}
+	if contentLength <= int64(len(content)) || len(transferEncoding) != 0 {
+		t.Errorf("Expected a Content-Length instead of chunks, got %d %v", contentLength, transferEncoding)
+	}
+	if lastSent != contentLength || lastTotal != contentLength {
+		t.Errorf("Expected the last progress to be complete, got %d/%d of %d", lastSent, lastTotal, contentLength)
+	}
+}
+
+func TestRunRequest_RetrySendsFileBodyAgain(t *testing.T) {
+	defer stubConfig()()
+	sleep = func(time.Duration) {}
+	defer func() { sleep = time.Sleep }()
+
+	path := filepath.Join(t.TempDir(), "data.json")
+	os.WriteFile(path, []byte(`{"ok":true}`), 0644)
+
+	var bodies []string
+	var contentTypes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(data))
+		contentTypes = append(contentTypes, r.Header.Get("Content-Type"))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	res, err := RunRequest(RequestOptions{
+		Url:     server.URL,
+		Method:  "PUT",
+		Timeout: 5 * time.Second,
+		Body:    http_utility.NewFileBody(path, ""),
+		Retry:   RetryOptions{Attempts: 2},
+	})
+	if err != nil || res.StatusCode != 200 {
+		t.Fatalf("Expected the retry to succeed, got %d %v", res.StatusCode, err)
+	}
+	if len(bodies) != 2 || bodies[0] != `{"ok":true}` || bodies[1] != bodies[0] {
+		t.Errorf("Expected the file to be sent on both tries, got %q", bodies)
+	}
+	if contentTypes[0] != "application/json" {
+		t.Errorf("Expected the type from the extension, got %q", contentTypes[0])
+	}
+}
+
+func TestHandleBody_File(t *testing.T) {
+	called := false
+	parseFileBody = func(data http_utility.HttpContentData) http_utility.HandleParseResult {
+		called = true
+		return http_utility.HandleParseResult{ContentTypeHeader: data.ContentType}
+	}
+	defer func() { parseFileBody = http_utility.ParseFileBody }()
+
+	// A file body keeps its own type, even one with a parser of its own.
+	res := HandleBody(http_utility.NewFileBody("/tmp/a.json", ""))
+	if !called || res.ContentTypeHeader != "application/json" {
+		t.Errorf("Expected ParseFileBody to be called, got %+v", res)
+	}
+}
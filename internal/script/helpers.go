@@ -0,0 +1,62 @@
+package script_module
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+
+	"github.com/dop251/goja"
+)
+
+// installHelpers adds what signatures usually need: hashes and HMACs as hex,
+// base64 and random UUIDs. Date.now() and JSON come with the runtime.
+func installHelpers(vm *goja.Runtime) {
+	hashes := map[string]func() hash.Hash{
+		"md5":    md5.New,
+		"sha1":   sha1.New,
+		"sha256": sha256.New,
+		"sha512": sha512.New,
+	}
+
+	crypto := vm.NewObject()
+	for name, newHash := range hashes {
+		crypto.Set(name, func(data string) string {
+			h := newHash()
+			h.Write([]byte(data))
+			return hex.EncodeToString(h.Sum(nil))
+		})
+	}
+	for name, newHash := range map[string]func() hash.Hash{"hmacSha1": sha1.New, "hmacSha256": sha256.New, "hmacSha512": sha512.New} {
+		crypto.Set(name, func(key string, data string) string {
+			h := hmac.New(newHash, []byte(key))
+			h.Write([]byte(data))
+			return hex.EncodeToString(h.Sum(nil))
+		})
+	}
+	crypto.Set("randomUUID", func() string {
+		b := make([]byte, 16)
+		rand.Read(b)
+		b[6] = b[6]&0x0f | 0x40
+		b[8] = b[8]&0x3f | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+	})
+	vm.Set("crypto", crypto)
+
+	vm.Set("btoa", func(data string) string {
+		return base64.StdEncoding.EncodeToString([]byte(data))
+	})
+	vm.Set("atob", func(data string) string {
+		decoded, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			panic(vm.NewGoError(err))
+		}
+		return string(decoded)
+	})
+}
@@ -0,0 +1,362 @@
+package script_module
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/diogopereiradev/httpzen/internal/utils/http_utility"
+	"github.com/dop251/goja"
+)
+
+// TestsFailedExitCode is returned by headless runs when a test of a
+// post-response script fails or the script throws.
+const TestsFailedExitCode = 66
+
+type Phase string
+
+const (
+	PhasePre  Phase = "pre-request"
+	PhasePost Phase = "post-response"
+)
+
+type LogEntry struct {
+	Phase   Phase  `json:"phase"`
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+type TestResult struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message,omitempty"`
+}
+
+// Result collects what the scripts of a request printed and tested, Errors
+// has the exceptions they didn't catch.
+type Result struct {
+	Logs   []LogEntry   `json:"logs,omitempty"`
+	Tests  []TestResult `json:"tests,omitempty"`
+	Errors []string     `json:"errors,omitempty"`
+}
+
+func (r Result) Passed() bool {
+	if len(r.Errors) > 0 {
+		return false
+	}
+	for _, test := range r.Tests {
+		if !test.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+func (r Result) Empty() bool {
+	return len(r.Logs) == 0 && len(r.Tests) == 0 && len(r.Errors) == 0
+}
+
+// Runner runs the pre-request and post-response scripts of a request. The
+// scripts only reach what the runner gives them: the request, the response,
+// the variables, the console and a few hashing helpers. Files, network and
+// timers are not available.
+type Runner struct {
+	Pre  string
+	Post string
+	// Variables are read and set by the scripts, a collection run shares
+	// its session variables here.
+	Variables map[string]string
+	// Timeout stops a script stuck in a loop.
+	Timeout time.Duration
+
+	pre Result
+}
+
+var DefaultTimeout = 5 * time.Second
+
+var readFile = os.ReadFile
+
+func NewRunner(pre string, post string, variables map[string]string) *Runner {
+	if variables == nil {
+		variables = map[string]string{}
+	}
+	return &Runner{Pre: pre, Post: post, Variables: variables, Timeout: DefaultTimeout}
+}
+
+// Load reads a script file, an empty path gives an empty script.
+func Load(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	data, err := readFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// BeforeRequest runs the pre-request script, which may change the method,
+// URL, headers and raw body of options. An exception stops the request.
+func (r *Runner) BeforeRequest(options *request_module.RequestOptions) error {
+	r.pre = Result{}
+	if strings.TrimSpace(r.Pre) == "" {
+		return nil
+	}
+
+	var request *goja.Object
+	err := r.run(PhasePre, r.Pre, &r.pre, func(vm *goja.Runtime) {
+		request = requestObject(vm, *options)
+		vm.Set("request", request)
+	})
+	if err != nil {
+		return err
+	}
+	readRequest(request, options)
+	return nil
+}
+
+// AfterResponse runs the post-response script and returns its result along
+// with the one of the pre-request script. It can run again for the same
+// request, on a refetch.
+func (r *Runner) AfterResponse(res request_module.RequestResponse) Result {
+	result := Result{
+		Logs:   append([]LogEntry{}, r.pre.Logs...),
+		Tests:  append([]TestResult{}, r.pre.Tests...),
+		Errors: append([]string{}, r.pre.Errors...),
+	}
+	if strings.TrimSpace(r.Post) == "" {
+		return result
+	}
+	r.run(PhasePost, r.Post, &result, func(vm *goja.Runtime) {
+		vm.Set("request", requestObject(vm, res.Request))
+		vm.Set("response", responseObject(vm, res))
+	})
+	return result
+}
+
+// PreResult is the result of the pre-request script, for requests that
+// failed before having a response.
+func (r *Runner) PreResult() Result {
+	return r.pre
+}
+
+func (r *Runner) run(phase Phase, source string, result *Result, setup func(vm *goja.Runtime)) error {
+	vm := goja.New()
+	install(vm, phase, result, r.Variables)
+	setup(vm)
+
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	timer := time.AfterFunc(timeout, func() {
+		vm.Interrupt("timeout")
+	})
+	_, err := vm.RunString(source)
+	timer.Stop()
+
+	if err == nil {
+		return nil
+	}
+	message := exceptionMessage(err)
+	var interrupted *goja.InterruptedError
+	if errors.As(err, &interrupted) {
+		message = "the script ran for more than " + timeout.String()
+	}
+	message = string(phase) + " script: " + message
+	result.Errors = append(result.Errors, message)
+	return errors.New(message)
+}
+
+// exceptionMessage is the message of a thrown Error, or the thrown value.
+func exceptionMessage(err error) string {
+	var exception *goja.Exception
+	if !errors.As(err, &exception) {
+		return err.Error()
+	}
+	if object, ok := exception.Value().(*goja.Object); ok {
+		if message := object.Get("message"); message != nil && !goja.IsUndefined(message) {
+			return message.String()
+		}
+	}
+	return exception.Value().String()
+}
+
+const prelude = `
+function assert(condition, message) {
+	if (!condition) throw new Error(message || "assertion failed");
+}
+assert.equal = function (actual, expected, message) {
+	var a = JSON.stringify(actual), e = JSON.stringify(expected);
+	if (a !== e) throw new Error((message ? message + ": " : "") + "expected " + e + ", got " + a);
+};
+`
+
+func install(vm *goja.Runtime, phase Phase, result *Result, variables map[string]string) {
+	console := vm.NewObject()
+	for _, level := range []string{"log", "info", "debug", "warn", "error"} {
+		console.Set(level, func(call goja.FunctionCall) goja.Value {
+			parts := make([]string, 0, len(call.Arguments))
+			for _, argument := range call.Arguments {
+				parts = append(parts, format(argument))
+			}
+			result.Logs = append(result.Logs, LogEntry{Phase: phase, Level: level, Message: strings.Join(parts, " ")})
+			return goja.Undefined()
+		})
+	}
+	vm.Set("console", console)
+
+	variablesObject := vm.NewObject()
+	variablesObject.Set("get", func(name string) goja.Value {
+		if value, ok := variables[name]; ok {
+			return vm.ToValue(value)
+		}
+		return goja.Undefined()
+	})
+	variablesObject.Set("has", func(name string) bool {
+		_, ok := variables[name]
+		return ok
+	})
+	variablesObject.Set("set", func(name string, value goja.Value) {
+		variables[name] = format(value)
+	})
+	variablesObject.Set("unset", func(name string) {
+		delete(variables, name)
+	})
+	vm.Set("variables", variablesObject)
+
+	vm.Set("test", func(name string, fn goja.Callable) {
+		test := TestResult{Name: name, Passed: true}
+		if _, err := fn(goja.Undefined()); err != nil {
+			test.Passed, test.Message = false, exceptionMessage(err)
+		}
+		result.Tests = append(result.Tests, test)
+	})
+
+	installHelpers(vm)
+	vm.RunString(prelude)
+}
+
+// format writes a value for the console and the variables: strings as they
+// are, objects as JSON.
+func format(value goja.Value) string {
+	if value == nil || goja.IsUndefined(value) {
+		return "undefined"
+	}
+	if goja.IsNull(value) {
+		return "null"
+	}
+	if _, ok := value.(*goja.Object); ok {
+		if _, isFunction := goja.AssertFunction(value); !isFunction {
+			if encoded, err := json.Marshal(value.Export()); err == nil {
+				return string(encoded)
+			}
+		}
+	}
+	return value.String()
+}
+
+// rawBody is the body a script can read and replace, a single part without
+// a key. Forms and files are left to the request.
+func rawBody(body []http_utility.HttpContentData) (http_utility.HttpContentData, bool) {
+	if len(body) == 1 && body[0].Key == "" {
+		return body[0], true
+	}
+	return http_utility.HttpContentData{}, false
+}
+
+func headersObject(vm *goja.Runtime, headers http.Header) *goja.Object {
+	object := vm.NewObject()
+	for name, values := range headers {
+		object.Set(name, strings.Join(values, ", "))
+	}
+	return object
+}
+
+func requestObject(vm *goja.Runtime, options request_module.RequestOptions) *goja.Object {
+	request := vm.NewObject()
+	request.Set("method", options.Method)
+	request.Set("url", options.Url)
+	request.Set("headers", headersObject(vm, options.Headers))
+	if body, ok := rawBody(options.Body); ok {
+		request.Set("body", body.Value)
+	}
+	return request
+}
+
+// readRequest applies the changes of the pre-request script. A body set by
+// the script replaces the previous one, objects are sent as JSON.
+func readRequest(request *goja.Object, options *request_module.RequestOptions) {
+	if method := request.Get("method"); method != nil && !goja.IsUndefined(method) {
+		options.Method = strings.ToUpper(method.String())
+	}
+	if url := request.Get("url"); url != nil && !goja.IsUndefined(url) {
+		options.Url = url.String()
+	}
+
+	headers := http.Header{}
+	if object, ok := request.Get("headers").(*goja.Object); ok {
+		for _, name := range object.Keys() {
+			value := object.Get(name)
+			if value == nil || goja.IsUndefined(value) || goja.IsNull(value) {
+				continue
+			}
+			headers.Set(name, value.String())
+		}
+	}
+	options.Headers = headers
+
+	previous, hadBody := rawBody(options.Body)
+	body := request.Get("body")
+	if body == nil || goja.IsUndefined(body) || goja.IsNull(body) {
+		if hadBody {
+			options.Body = nil
+		}
+		return
+	}
+	_, isObject := body.(*goja.Object)
+	value := format(body)
+	if hadBody && value == previous.Value {
+		return
+	}
+
+	contentType := headers.Get("Content-Type")
+	switch {
+	case contentType != "":
+	case hadBody && previous.ContentType != "":
+		contentType = previous.ContentType
+	case isObject:
+		contentType = "application/json"
+	default:
+		contentType = "text/plain"
+	}
+	options.Body = []http_utility.HttpContentData{{ContentType: contentType, Value: value}}
+}
+
+func responseObject(vm *goja.Runtime, res request_module.RequestResponse) *goja.Object {
+	response := vm.NewObject()
+	response.Set("status", res.StatusCode)
+	response.Set("statusText", res.StatusMessage)
+	response.Set("headers", headersObject(vm, res.Headers))
+	response.Set("body", res.Result)
+	response.Set("time", res.ExecutionTime)
+	response.Set("header", func(name string) goja.Value {
+		if values := res.Headers.Values(name); len(values) > 0 {
+			return vm.ToValue(strings.Join(values, ", "))
+		}
+		return goja.Undefined()
+	})
+	response.Set("json", func() goja.Value {
+		var value any
+		if err := json.Unmarshal([]byte(res.Result), &value); err != nil {
+			panic(vm.NewGoError(fmt.Errorf("the body is not JSON: %w", err)))
+		}
+		return vm.ToValue(value)
+	})
+	return response
+}
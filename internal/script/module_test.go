@@ -0,0 +1,183 @@
+package script_module
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/diogopereiradev/httpzen/internal/utils/http_utility"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBeforeRequest_ChangesTheRequest(t *testing.T) {
+	runner := NewRunner(`
+		var ts = "1700000000";
+		request.headers["X-Timestamp"] = ts;
+		request.headers["X-Signature"] = crypto.hmacSha256("secret", request.method + request.url + ts);
+		delete request.headers["X-Remove"];
+		request.url += "?ts=" + ts;
+		var body = JSON.parse(request.body);
+		body.signed = true;
+		request.body = JSON.stringify(body);
+		variables.set("ts", ts);
+		console.log("signed", {ts: ts});
+	`, "", nil)
+	options := request_module.RequestOptions{
+		Method:  "POST",
+		Url:     "https://api.example.com/orders",
+		Headers: http.Header{"X-Remove": {"1"}, "Accept": {"application/json"}},
+		Body:    []http_utility.HttpContentData{{ContentType: "application/json", Value: `{"id":1}`}},
+	}
+
+	assert.NoError(t, runner.BeforeRequest(&options))
+	assert.Equal(t, "https://api.example.com/orders?ts=1700000000", options.Url)
+	assert.Equal(t, "1700000000", options.Headers.Get("X-Timestamp"))
+	assert.Equal(t, "a68db5340abc37c7eb3ed5336e0bf3c59200ff646e81fa2fcb88e18defe33361", options.Headers.Get("X-Signature"))
+	assert.Empty(t, options.Headers.Get("X-Remove"))
+	assert.Equal(t, "application/json", options.Headers.Get("Accept"))
+	assert.Equal(t, []http_utility.HttpContentData{{ContentType: "application/json", Value: `{"id":1,"signed":true}`}}, options.Body)
+	assert.Equal(t, "1700000000", runner.Variables["ts"])
+	assert.Equal(t, []LogEntry{{Phase: PhasePre, Level: "log", Message: `signed {"ts":"1700000000"}`}}, runner.PreResult().Logs)
+}
+
+func TestBeforeRequest_Body(t *testing.T) {
+	form := []http_utility.HttpContentData{{ContentType: "application/x-www-form-urlencoded", Key: "a", Value: "1"}}
+	options := request_module.RequestOptions{Method: "POST", Body: form}
+	assert.NoError(t, NewRunner(`request.method = "put"`, "", nil).BeforeRequest(&options))
+	assert.Equal(t, "PUT", options.Method)
+	assert.Equal(t, form, options.Body, "a form should be left as it is")
+
+	options = request_module.RequestOptions{Method: "POST"}
+	assert.NoError(t, NewRunner(`request.body = {a: 1}`, "", nil).BeforeRequest(&options))
+	assert.Equal(t, []http_utility.HttpContentData{{ContentType: "application/json", Value: `{"a":1}`}}, options.Body)
+
+	options = request_module.RequestOptions{Method: "POST", Headers: http.Header{"Content-Type": {"text/csv"}}}
+	assert.NoError(t, NewRunner(`request.body = "a,b"`, "", nil).BeforeRequest(&options))
+	assert.Equal(t, "text/csv", options.Body[0].ContentType)
+
+	options = request_module.RequestOptions{Method: "POST", Body: []http_utility.HttpContentData{{ContentType: "text/plain", Value: "x"}}}
+	assert.NoError(t, NewRunner(`delete request.body`, "", nil).BeforeRequest(&options))
+	assert.Nil(t, options.Body)
+}
+
+func TestBeforeRequest_Errors(t *testing.T) {
+	options := request_module.RequestOptions{Method: "GET", Url: "http://a"}
+	runner := NewRunner(`console.log("before"); throw new Error("no token")`, "", nil)
+
+	err := runner.BeforeRequest(&options)
+	assert.EqualError(t, err, "pre-request script: no token")
+	assert.Equal(t, "http://a", options.Url, "the request should be left as it was")
+	assert.Len(t, runner.PreResult().Logs, 1)
+	assert.False(t, runner.PreResult().Passed())
+
+	err = NewRunner(`request.url = (`, "", nil).BeforeRequest(&options)
+	assert.ErrorContains(t, err, "pre-request script: ")
+
+	err = NewRunner(`fetch("http://a")`, "", nil).BeforeRequest(&options)
+	assert.ErrorContains(t, err, "fetch is not defined")
+
+	runner = NewRunner(`while (true) {}`, "", nil)
+	runner.Timeout = 50 * time.Millisecond
+	assert.EqualError(t, runner.BeforeRequest(&options), "pre-request script: the script ran for more than 50ms")
+}
+
+func TestAfterResponse(t *testing.T) {
+	variables := map[string]string{"user": "ana"}
+	runner := NewRunner(`console.info("pre")`, `
+		test("status is 200", function () { assert(response.status === 200, "bad status") });
+		test("has the user", function () { assert.equal(response.json().user, variables.get("user")) });
+		test("fails", function () { assert.equal(response.json().id, 2, "id") });
+		variables.set("id", response.json().id);
+		variables.set("ctype", response.header("content-type"));
+		console.warn(request.method, response.time, response.statusText);
+	`, variables)
+	options := request_module.RequestOptions{Method: "GET"}
+	assert.NoError(t, runner.BeforeRequest(&options))
+
+	result := runner.AfterResponse(request_module.RequestResponse{
+		StatusCode:    200,
+		StatusMessage: "200 OK",
+		ExecutionTime: 12.5,
+		Headers:       http.Header{"Content-Type": {"application/json"}},
+		Result:        `{"user":"ana","id":1}`,
+		Request:       options,
+	})
+
+	assert.Equal(t, []TestResult{
+		{Name: "status is 200", Passed: true},
+		{Name: "has the user", Passed: true},
+		{Name: "fails", Message: "id: expected 2, got 1"},
+	}, result.Tests)
+	assert.False(t, result.Passed())
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, []LogEntry{
+		{Phase: PhasePre, Level: "info", Message: "pre"},
+		{Phase: PhasePost, Level: "warn", Message: "GET 12.5 200 OK"},
+	}, result.Logs)
+	assert.Equal(t, "1", variables["id"])
+	assert.Equal(t, "application/json", variables["ctype"])
+
+	again := runner.AfterResponse(request_module.RequestResponse{StatusCode: 200, Result: `{"user":"ana","id":2}`})
+	assert.Len(t, again.Logs, 2, "a refetch should not keep the logs of the previous response")
+	assert.True(t, again.Passed())
+}
+
+func TestAfterResponse_Errors(t *testing.T) {
+	result := NewRunner("", `response.json()`, nil).AfterResponse(request_module.RequestResponse{Result: "<html>"})
+	assert.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0], "post-response script: the body is not JSON")
+
+	runner := NewRunner("", `test("loop", function () { while (true) {} }); console.log("after")`, nil)
+	runner.Timeout = 50 * time.Millisecond
+	result = runner.AfterResponse(request_module.RequestResponse{})
+	assert.Equal(t, []string{"post-response script: the script ran for more than 50ms"}, result.Errors)
+	assert.Empty(t, result.Logs, "the script should stop at the timeout")
+
+	assert.True(t, NewRunner("", "", nil).AfterResponse(request_module.RequestResponse{}).Empty())
+}
+
+func TestHelpers(t *testing.T) {
+	runner := NewRunner(`
+		variables.set("md5", crypto.md5("abc"));
+		variables.set("sha1", crypto.sha1("abc"));
+		variables.set("sha256", crypto.sha256("abc"));
+		variables.set("hmac", crypto.hmacSha1("key", "abc"));
+		variables.set("b64", btoa("user:pass"));
+		variables.set("plain", atob("dXNlcjpwYXNz"));
+		variables.set("uuid", crypto.randomUUID());
+		variables.set("now", typeof Date.now());
+	`, "", nil)
+	assert.NoError(t, runner.BeforeRequest(&request_module.RequestOptions{}))
+
+	assert.Equal(t, "900150983cd24fb0d6963f7d28e17f72", runner.Variables["md5"])
+	assert.Equal(t, "a9993e364706816aba3e25717850c26c9cd0d89d", runner.Variables["sha1"])
+	assert.Equal(t, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad", runner.Variables["sha256"])
+	assert.Len(t, runner.Variables["hmac"], 40)
+	assert.Equal(t, "dXNlcjpwYXNz", runner.Variables["b64"])
+	assert.Equal(t, "user:pass", runner.Variables["plain"])
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, runner.Variables["uuid"])
+	assert.Equal(t, "number", runner.Variables["now"])
+}
+
+func TestLoad(t *testing.T) {
+	old := readFile
+	t.Cleanup(func() { readFile = old })
+	readFile = func(path string) ([]byte, error) {
+		if path == "pre.js" {
+			return []byte("console.log(1)"), nil
+		}
+		return nil, os.ErrNotExist
+	}
+
+	source, err := Load("pre.js")
+	assert.NoError(t, err)
+	assert.Equal(t, "console.log(1)", source)
+	source, err = Load("")
+	assert.NoError(t, err)
+	assert.Empty(t, source)
+	_, err = Load("missing.js")
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
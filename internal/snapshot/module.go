@@ -0,0 +1,117 @@
+package snapshot_module
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	app_path_util "github.com/diogopereiradev/httpzen/internal/utils/app_path"
+)
+
+// Snapshot is a response kept as a golden copy, later responses to its
+// request are diffed against it.
+type Snapshot struct {
+	Name     string                         `json:"name"`
+	SavedAt  time.Time                      `json:"saved_at"`
+	Response request_module.RequestResponse `json:"response"`
+}
+
+// Snapshots are stored like collections, the whole body is kept.
+var SNAPSHOTS_FILE_NAME = "snapshots.json"
+
+var mkdirAll = os.MkdirAll
+var readFile = os.ReadFile
+var writeFile = os.WriteFile
+
+func GetSnapshotsFilePath() string {
+	return app_path_util.GetConfigPath() + "/" + SNAPSHOTS_FILE_NAME
+}
+
+func loadSnapshots() (map[string]Snapshot, error) {
+	snapshots := map[string]Snapshot{}
+
+	data, err := readFile(GetSnapshotsFilePath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return snapshots, nil
+		}
+		return nil, err
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return snapshots, nil
+	}
+	if err := json.Unmarshal(data, &snapshots); err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+func saveSnapshots(snapshots map[string]Snapshot) error {
+	if err := mkdirAll(app_path_util.GetConfigPath(), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(snapshots, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Responses carry the auth of their request, keep the file private.
+	return writeFile(GetSnapshotsFilePath(), data, 0600)
+}
+
+// GetSnapshot returns the named snapshot, ok is false when it was never
+// saved.
+func GetSnapshot(name string) (Snapshot, bool) {
+	snapshots, err := loadSnapshots()
+	if err != nil {
+		return Snapshot{Name: name}, false
+	}
+	snapshot, ok := snapshots[name]
+	snapshot.Name = name
+	return snapshot, ok
+}
+
+func ListSnapshots() []Snapshot {
+	snapshots, err := loadSnapshots()
+	if err != nil {
+		return []Snapshot{}
+	}
+
+	list := make([]Snapshot, 0, len(snapshots))
+	for name, snapshot := range snapshots {
+		snapshot.Name = name
+		list = append(list, snapshot)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// SaveSnapshot keeps a response under a name, replacing the previous
+// snapshot with that name.
+func SaveSnapshot(name string, res request_module.RequestResponse) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("snapshot name cannot be empty")
+	}
+	snapshots, err := loadSnapshots()
+	if err != nil {
+		return err
+	}
+	snapshots[name] = Snapshot{Name: name, SavedAt: time.Now(), Response: res}
+	return saveSnapshots(snapshots)
+}
+
+// DeleteSnapshot removes a snapshot, ok is false when there was none.
+func DeleteSnapshot(name string) (bool, error) {
+	snapshots, err := loadSnapshots()
+	if err != nil {
+		return false, err
+	}
+	if _, ok := snapshots[name]; !ok {
+		return false, nil
+	}
+	delete(snapshots, name)
+	return true, saveSnapshots(snapshots)
+}
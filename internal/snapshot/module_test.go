@@ -0,0 +1,81 @@
+package snapshot_module
+
+import (
+	"os"
+	"testing"
+
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/stretchr/testify/assert"
+)
+
+func stubFiles(t *testing.T) map[string][]byte {
+	files := map[string][]byte{}
+	oldMkdir, oldRead, oldWrite := mkdirAll, readFile, writeFile
+	t.Cleanup(func() { mkdirAll, readFile, writeFile = oldMkdir, oldRead, oldWrite })
+
+	mkdirAll = func(string, os.FileMode) error { return nil }
+	readFile = func(path string) ([]byte, error) {
+		if data, ok := files[path]; ok {
+			return data, nil
+		}
+		return nil, os.ErrNotExist
+	}
+	writeFile = func(path string, data []byte, perm os.FileMode) error {
+		assert.Equal(t, os.FileMode(0600), perm)
+		files[path] = data
+		return nil
+	}
+	return files
+}
+
+func TestSaveSnapshot(t *testing.T) {
+	stubFiles(t)
+
+	snapshot, ok := GetSnapshot("health")
+	assert.False(t, ok)
+	assert.Equal(t, "health", snapshot.Name)
+	assert.Empty(t, ListSnapshots())
+
+	res := request_module.RequestResponse{StatusCode: 200, Result: `{"ok":true}`, Request: request_module.RequestOptions{Method: "GET", Url: "http://a/health"}}
+	assert.NoError(t, SaveSnapshot("health", res))
+	assert.NoError(t, SaveSnapshot("users", request_module.RequestResponse{StatusCode: 201}))
+	res.StatusCode = 503
+	assert.NoError(t, SaveSnapshot("health", res))
+	assert.Error(t, SaveSnapshot(" ", res))
+
+	snapshot, ok = GetSnapshot("health")
+	assert.True(t, ok)
+	assert.Equal(t, 503, snapshot.Response.StatusCode)
+	assert.Equal(t, "http://a/health", snapshot.Response.Request.Url)
+	assert.False(t, snapshot.SavedAt.IsZero())
+
+	list := ListSnapshots()
+	assert.Len(t, list, 2)
+	assert.Equal(t, "health", list[0].Name)
+	assert.Equal(t, "users", list[1].Name)
+}
+
+func TestDeleteSnapshot(t *testing.T) {
+	stubFiles(t)
+	assert.NoError(t, SaveSnapshot("health", request_module.RequestResponse{StatusCode: 200}))
+
+	ok, err := DeleteSnapshot("missing")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = DeleteSnapshot("health")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	_, found := GetSnapshot("health")
+	assert.False(t, found)
+}
+
+func TestGetSnapshot_BrokenFile(t *testing.T) {
+	files := stubFiles(t)
+	files[GetSnapshotsFilePath()] = []byte("{")
+
+	_, ok := GetSnapshot("health")
+	assert.False(t, ok)
+	assert.Empty(t, ListSnapshots())
+	assert.Error(t, SaveSnapshot("health", request_module.RequestResponse{}))
+}
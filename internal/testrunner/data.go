@@ -0,0 +1,85 @@
+package testrunner_module
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var readFile = os.ReadFile
+var writeFile = os.WriteFile
+
+// LoadData reads the iteration data, a CSV file with a header row or a JSON
+// array of objects, one iteration per row or object.
+func LoadData(path string) ([]map[string]string, error) {
+	data, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return parseCsv(data)
+	case ".json":
+		return parseJson(data)
+	}
+	return nil, errors.New("the data file must be .csv or .json")
+}
+
+func parseCsv(data []byte) ([]map[string]string, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, errors.New("invalid CSV: " + err.Error())
+	}
+	if len(records) < 2 {
+		return nil, errors.New("the CSV file needs a header row and at least one row of values")
+	}
+
+	header := records[0]
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := map[string]string{}
+		for i, name := range header {
+			row[strings.TrimSpace(name)] = record[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// parseJson keeps the strings as they are and writes the other values as
+// JSON, like the extractors do.
+func parseJson(data []byte) ([]map[string]string, error) {
+	var objects []map[string]any
+	if err := json.Unmarshal(data, &objects); err != nil {
+		return nil, errors.New("the JSON file must be an array of objects: " + err.Error())
+	}
+	if len(objects) == 0 {
+		return nil, errors.New("the JSON file has no iterations")
+	}
+
+	rows := make([]map[string]string, 0, len(objects))
+	for _, object := range objects {
+		row := map[string]string{}
+		for name, value := range object {
+			switch value := value.(type) {
+			case string:
+				row[name] = value
+			case nil:
+				row[name] = ""
+			default:
+				encoded, err := json.Marshal(value)
+				if err != nil {
+					return nil, fmt.Errorf("the value of %s can't be used: %w", name, err)
+				}
+				row[name] = string(encoded)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -0,0 +1,49 @@
+package testrunner_module
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func stubData(t *testing.T, files map[string]string) {
+	old := readFile
+	t.Cleanup(func() { readFile = old })
+	readFile = func(path string) ([]byte, error) {
+		if data, ok := files[path]; ok {
+			return []byte(data), nil
+		}
+		return nil, os.ErrNotExist
+	}
+}
+
+func TestLoadData(t *testing.T) {
+	stubData(t, map[string]string{
+		"users.csv":  "user, password\nana,\"a,1\"\nbob,b2\n",
+		"users.json": `[{"user": "ana", "age": 30, "tags": ["a"], "none": null}, {"user": "bob"}]`,
+	})
+
+	rows, err := LoadData("users.csv")
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]string{{"user": "ana", "password": "a,1"}, {"user": "bob", "password": "b2"}}, rows)
+
+	rows, err = LoadData("users.json")
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]string{{"user": "ana", "age": "30", "tags": `["a"]`, "none": ""}, {"user": "bob"}}, rows)
+}
+
+func TestLoadData_Errors(t *testing.T) {
+	stubData(t, map[string]string{
+		"header.csv":  "user\n",
+		"broken.csv":  "a,b\n1\n",
+		"object.json": `{"user": "ana"}`,
+		"empty.json":  `[]`,
+		"users.txt":   "ana",
+	})
+
+	for _, path := range []string{"header.csv", "broken.csv", "object.json", "empty.json", "users.txt", "missing.csv"} {
+		_, err := LoadData(path)
+		assert.Error(t, err, path)
+	}
+}
@@ -0,0 +1,233 @@
+package testrunner_module
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	collection_module "github.com/diogopereiradev/httpzen/internal/collection"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	script_module "github.com/diogopereiradev/httpzen/internal/script"
+)
+
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusRunning Status = "running"
+	StatusPassed  Status = "passed"
+	StatusFailed  Status = "failed"
+	StatusSkipped Status = "skipped"
+)
+
+// Case is one saved request in one iteration of the run.
+type Case struct {
+	Iteration int
+	Request   collection_module.SavedRequest
+	Status    Status
+	Duration  time.Duration
+	// Failures says why the case failed: the request error, the extractors
+	// and the failed tests or script errors.
+	Failures []string
+	Script   script_module.Result
+	// Response is nil when the request could not be sent.
+	Response *request_module.RequestResponse
+}
+
+func (c Case) Name() string {
+	return c.Request.Path()
+}
+
+type Options struct {
+	Collection  collection_module.Collection
+	Environment map[string]string
+	// Data has the variables of each iteration, Iterations repeats the run
+	// when there is no data.
+	Data       []map[string]string
+	Iterations int
+	// Parallel sends up to this many requests at once. The requests are then
+	// independent, the variables set by one are not seen by the others.
+	Parallel      int
+	StopOnFailure bool
+	// Timeout limits each request that has no timeout of its own.
+	Timeout  time.Duration
+	Timeouts request_module.Timeouts
+}
+
+type Run struct {
+	Collection string
+	StartedAt  time.Time
+	Duration   time.Duration
+	Cases      []Case
+}
+
+var RunRequestFunc = request_module.RunRequest
+
+// Plan lists the cases of the run, every iteration of every request, all
+// pending.
+func Plan(options Options) []Case {
+	var cases []Case
+	for iteration := range iterations(options) {
+		for _, saved := range options.Collection.Requests {
+			cases = append(cases, Case{Iteration: iteration + 1, Request: saved, Status: StatusPending})
+		}
+	}
+	return cases
+}
+
+func iterations(options Options) int {
+	if len(options.Data) > 0 {
+		return len(options.Data)
+	}
+	return max(options.Iterations, 1)
+}
+
+// Execute runs the cases of Plan. update is called from the running
+// goroutines with a copy of a case each time it changes, the cases left when
+// ctx is done or a failure stops the run end as skipped.
+func Execute(ctx context.Context, options Options, update func(index int, c Case)) Run {
+	run := Run{Collection: options.Collection.Name, StartedAt: time.Now(), Cases: Plan(options)}
+	perIteration := len(options.Collection.Requests)
+	if perIteration == 0 {
+		return run
+	}
+
+	var mutex sync.Mutex
+	stopped := false
+	set := func(index int, c Case) {
+		mutex.Lock()
+		run.Cases[index] = c
+		if c.Status == StatusFailed && options.StopOnFailure {
+			stopped = true
+		}
+		mutex.Unlock()
+		if update != nil {
+			update(index, c)
+		}
+	}
+	// start marks the case as running, or skipped once the run is stopped.
+	start := func(index int) (Case, bool) {
+		mutex.Lock()
+		c := run.Cases[index]
+		skip := stopped || ctx.Err() != nil
+		mutex.Unlock()
+		if skip {
+			c.Status = StatusSkipped
+			set(index, c)
+			return c, false
+		}
+		c.Status = StatusRunning
+		set(index, c)
+		return c, true
+	}
+
+	if options.Parallel <= 1 {
+		var session *collection_module.Session
+		for index := range run.Cases {
+			// Each iteration starts a new session, the requests of an
+			// iteration share it.
+			if index%perIteration == 0 {
+				session = collection_module.NewSession()
+			}
+			if c, ok := start(index); ok {
+				set(index, runCase(c, options, session))
+			}
+		}
+	} else {
+		jobs := make(chan int)
+		var workers sync.WaitGroup
+		for range options.Parallel {
+			workers.Add(1)
+			go func() {
+				defer workers.Done()
+				for index := range jobs {
+					if c, ok := start(index); ok {
+						set(index, runCase(c, options, collection_module.NewSession()))
+					}
+				}
+			}()
+		}
+		for index := range run.Cases {
+			jobs <- index
+		}
+		close(jobs)
+		workers.Wait()
+	}
+
+	run.Duration = time.Since(run.StartedAt)
+	return run
+}
+
+// runCase sends the request of a case with its scripts and extractors. The
+// variables are, from the weakest, the collection ones, the environment, the
+// iteration data and the session.
+func runCase(c Case, options Options, session *collection_module.Session) Case {
+	var data map[string]string
+	if c.Iteration <= len(options.Data) {
+		data = options.Data[c.Iteration-1]
+	}
+	variables := []map[string]string{options.Environment, data, session.Variables}
+
+	request := options.Collection.Resolve(c.Request, variables...)
+	if request.Timeout == 0 {
+		request.Timeout = options.Timeout
+	}
+	request.Timeouts = options.Timeouts
+
+	startedAt := time.Now()
+	runner := script_module.NewRunner(c.Request.PreScript, c.Request.PostScript, session.Variables)
+	if err := runner.BeforeRequest(&request); err != nil {
+		c.Script = runner.PreResult()
+		return fail(c, startedAt, err.Error())
+	}
+	request = collection_module.Collection{}.Resolve(collection_module.SavedRequest{Request: request}, variables...)
+
+	res, err := RunRequestFunc(request)
+	if err != nil {
+		c.Script = runner.PreResult()
+		return fail(c, startedAt, request_module.ClassifyError(err).Error())
+	}
+	c.Response = &res
+
+	var failures []string
+	_, errs := session.Extract(c.Request, res)
+	for _, err := range errs {
+		failures = append(failures, "could not extract "+err.Error())
+	}
+	c.Script = runner.AfterResponse(res)
+	for _, test := range c.Script.Tests {
+		if !test.Passed {
+			failures = append(failures, "test \""+test.Name+"\" failed: "+test.Message)
+		}
+	}
+	failures = append(failures, c.Script.Errors...)
+
+	if len(failures) > 0 {
+		return fail(c, startedAt, failures...)
+	}
+	c.Status = StatusPassed
+	c.Duration = time.Since(startedAt)
+	return c
+}
+
+func fail(c Case, startedAt time.Time, failures ...string) Case {
+	c.Status = StatusFailed
+	c.Failures = append(c.Failures, failures...)
+	c.Duration = time.Since(startedAt)
+	return c
+}
+
+// Counts tells how many cases passed, failed and were skipped.
+func (r Run) Counts() (passed int, failed int, skipped int) {
+	for _, c := range r.Cases {
+		switch c.Status {
+		case StatusPassed:
+			passed++
+		case StatusFailed:
+			failed++
+		case StatusSkipped:
+			skipped++
+		}
+	}
+	return passed, failed, skipped
+}
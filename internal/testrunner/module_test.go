@@ -0,0 +1,176 @@
+package testrunner_module
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	collection_module "github.com/diogopereiradev/httpzen/internal/collection"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/stretchr/testify/assert"
+)
+
+func testCollection() collection_module.Collection {
+	return collection_module.Collection{
+		Name:      "api",
+		Variables: map[string]string{"host": "http://a"},
+		Requests: []collection_module.SavedRequest{
+			{Name: "login", Request: request_module.RequestOptions{Method: "POST", Url: "{{host}}/login?user={{user}}"},
+				Extract: []collection_module.Extractor{{Variable: "token", Type: collection_module.ExtractJson, Expression: "$.token"}}},
+			{Name: "me", Request: request_module.RequestOptions{Method: "GET", Url: "{{host}}/me", Headers: http.Header{"Authorization": {"Bearer {{token}}"}}},
+				PostScript: `test("ok", function () { assert(response.status === 200, "status " + response.status) })`},
+		},
+	}
+}
+
+type sentRequests struct {
+	mutex sync.Mutex
+	all   []request_module.RequestOptions
+}
+
+func stubRequests(t *testing.T, respond func(options request_module.RequestOptions) (request_module.RequestResponse, error)) *sentRequests {
+	sent := &sentRequests{}
+	old := RunRequestFunc
+	t.Cleanup(func() { RunRequestFunc = old })
+	RunRequestFunc = func(options request_module.RequestOptions) (request_module.RequestResponse, error) {
+		sent.mutex.Lock()
+		sent.all = append(sent.all, options)
+		sent.mutex.Unlock()
+		return respond(options)
+	}
+	return sent
+}
+
+func okResponse(options request_module.RequestOptions) (request_module.RequestResponse, error) {
+	return request_module.RequestResponse{StatusCode: 200, Request: options, Result: `{"token":"t-` + options.Url[len(options.Url)-1:] + `"}`}, nil
+}
+
+func TestPlan(t *testing.T) {
+	cases := Plan(Options{Collection: testCollection(), Iterations: 2})
+	assert.Len(t, cases, 4)
+	assert.Equal(t, 2, cases[3].Iteration)
+	assert.Equal(t, "me", cases[3].Name())
+	assert.Equal(t, StatusPending, cases[0].Status)
+
+	cases = Plan(Options{Collection: testCollection(), Iterations: 5, Data: []map[string]string{{}, {}, {}}})
+	assert.Len(t, cases, 6, "the data should set the iterations")
+}
+
+func TestExecute_InOrder(t *testing.T) {
+	sent := stubRequests(t, okResponse)
+	var updates []Status
+
+	run := Execute(context.Background(), Options{
+		Collection:  testCollection(),
+		Environment: map[string]string{"user": "env"},
+		Data:        []map[string]string{{"user": "ana"}, {"user": "bob"}},
+		Timeout:     3 * time.Second,
+	}, func(index int, c Case) {
+		if index == 0 {
+			updates = append(updates, c.Status)
+		}
+	})
+
+	assert.Equal(t, "api", run.Collection)
+	assert.Equal(t, []Status{StatusRunning, StatusPassed}, updates)
+	passed, failed, skipped := run.Counts()
+	assert.Equal(t, []int{4, 0, 0}, []int{passed, failed, skipped})
+	assert.Equal(t, "http://a/login?user=ana", sent.all[0].Url)
+	assert.Equal(t, "Bearer t-a", sent.all[1].Headers.Get("Authorization"), "the token should be extracted from the login")
+	assert.Equal(t, "http://a/login?user=bob", sent.all[2].Url)
+	assert.Equal(t, 3*time.Second, sent.all[0].Timeout)
+	assert.Len(t, run.Cases[1].Script.Tests, 1)
+	assert.NotNil(t, run.Cases[1].Response)
+}
+
+func TestExecute_Failures(t *testing.T) {
+	stubRequests(t, func(options request_module.RequestOptions) (request_module.RequestResponse, error) {
+		if options.Method == "GET" {
+			return request_module.RequestResponse{StatusCode: 500, Request: options}, nil
+		}
+		return request_module.RequestResponse{StatusCode: 200, Request: options, Result: "<html>"}, nil
+	})
+
+	run := Execute(context.Background(), Options{Collection: testCollection()}, nil)
+
+	assert.Equal(t, StatusFailed, run.Cases[0].Status)
+	assert.Equal(t, []string{"could not extract token: the body is not JSON"}, run.Cases[0].Failures)
+	assert.Equal(t, StatusFailed, run.Cases[1].Status, "the run should go on after a failure")
+	assert.Equal(t, []string{`test "ok" failed: status 500`}, run.Cases[1].Failures)
+}
+
+func TestExecute_StopOnFailure(t *testing.T) {
+	sent := stubRequests(t, func(options request_module.RequestOptions) (request_module.RequestResponse, error) {
+		return request_module.RequestResponse{}, &request_module.RequestError{Class: request_module.ErrorClassConnectionRefused, Err: errors.New("refused")}
+	})
+
+	run := Execute(context.Background(), Options{Collection: testCollection(), Iterations: 2, StopOnFailure: true}, nil)
+
+	assert.Len(t, sent.all, 1)
+	assert.Equal(t, StatusFailed, run.Cases[0].Status)
+	assert.Nil(t, run.Cases[0].Response)
+	assert.Contains(t, run.Cases[0].Failures[0], "refused")
+	passed, failed, skipped := run.Counts()
+	assert.Equal(t, []int{0, 1, 3}, []int{passed, failed, skipped})
+}
+
+func TestExecute_PreScriptFailure(t *testing.T) {
+	sent := stubRequests(t, okResponse)
+	collection := testCollection()
+	collection.Requests[0].PreScript = `console.log("signing"); throw new Error("no key")`
+
+	run := Execute(context.Background(), Options{Collection: collection}, nil)
+
+	assert.Len(t, sent.all, 1, "the login should not be sent")
+	assert.Equal(t, []string{"pre-request script: no key"}, run.Cases[0].Failures)
+	assert.Len(t, run.Cases[0].Script.Logs, 1)
+}
+
+func TestExecute_Parallel(t *testing.T) {
+	var running, most int
+	var mutex sync.Mutex
+	sent := stubRequests(t, func(options request_module.RequestOptions) (request_module.RequestResponse, error) {
+		mutex.Lock()
+		running++
+		most = max(most, running)
+		mutex.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mutex.Lock()
+		running--
+		mutex.Unlock()
+		return okResponse(options)
+	})
+
+	run := Execute(context.Background(), Options{Collection: testCollection(), Iterations: 3, Parallel: 3}, nil)
+
+	assert.Len(t, sent.all, 6)
+	assert.Equal(t, 3, most)
+	passed, failed, skipped := run.Counts()
+	assert.Equal(t, []int{6, 0, 0}, []int{passed, failed, skipped})
+	for _, options := range sent.all {
+		if options.Method == "GET" {
+			assert.Equal(t, "Bearer {{token}}", options.Headers.Get("Authorization"), "the token of the login is not shared in parallel")
+		}
+	}
+}
+
+func TestExecute_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sent := stubRequests(t, func(options request_module.RequestOptions) (request_module.RequestResponse, error) {
+		cancel()
+		return okResponse(options)
+	})
+
+	run := Execute(ctx, Options{Collection: testCollection()}, nil)
+
+	assert.Len(t, sent.all, 1)
+	assert.Equal(t, StatusSkipped, run.Cases[1].Status)
+}
+
+func TestExecute_Empty(t *testing.T) {
+	run := Execute(context.Background(), Options{Collection: collection_module.Collection{Name: "empty"}}, nil)
+	assert.Empty(t, run.Cases)
+}
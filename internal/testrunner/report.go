@@ -0,0 +1,234 @@
+package testrunner_module
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	auth_module "github.com/diogopereiradev/httpzen/internal/auth"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	script_module "github.com/diogopereiradev/httpzen/internal/script"
+)
+
+// maxReportBody keeps a large response from filling the CI logs.
+const maxReportBody = 10000
+
+type JsonReport struct {
+	Collection string       `json:"collection"`
+	StartedAt  time.Time    `json:"started_at"`
+	Duration   float64      `json:"duration_ms"`
+	Passed     int          `json:"passed"`
+	Failed     int          `json:"failed"`
+	Skipped    int          `json:"skipped"`
+	Results    []JsonResult `json:"results"`
+}
+
+type JsonResult struct {
+	Iteration  int                        `json:"iteration"`
+	Request    string                     `json:"request"`
+	Status     Status                     `json:"status"`
+	Duration   float64                    `json:"duration_ms"`
+	StatusCode int                        `json:"status_code,omitempty"`
+	Failures   []string                   `json:"failures,omitempty"`
+	Tests      []script_module.TestResult `json:"tests,omitempty"`
+	Logs       []script_module.LogEntry   `json:"logs,omitempty"`
+	// Response is only attached to the failed cases.
+	Response *request_module.RequestResponse `json:"response,omitempty"`
+}
+
+// Json builds the JSON report of a run.
+func Json(run Run) ([]byte, error) {
+	passed, failed, skipped := run.Counts()
+	report := JsonReport{
+		Collection: run.Collection,
+		StartedAt:  run.StartedAt,
+		Duration:   milliseconds(run.Duration),
+		Passed:     passed,
+		Failed:     failed,
+		Skipped:    skipped,
+		Results:    make([]JsonResult, 0, len(run.Cases)),
+	}
+	for _, c := range run.Cases {
+		result := JsonResult{
+			Iteration: c.Iteration,
+			Request:   c.Name(),
+			Status:    c.Status,
+			Duration:  milliseconds(c.Duration),
+			Failures:  c.Failures,
+			Tests:     c.Script.Tests,
+			Logs:      c.Script.Logs,
+		}
+		if c.Response != nil {
+			result.StatusCode = c.Response.StatusCode
+			if c.Status == StatusFailed {
+				result.Response = redact(*c.Response)
+			}
+		}
+		report.Results = append(report.Results, result)
+	}
+	return json.MarshalIndent(report, "", "  ")
+}
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Skipped   int         `xml:"skipped,attr"`
+	Time      string      `xml:"time,attr"`
+	Timestamp string      `xml:"timestamp,attr"`
+	Cases     []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *struct{}     `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Details string `xml:",chardata"`
+}
+
+// JUnit builds the JUnit XML report of a run, a test suite per iteration
+// and a test case per request.
+func JUnit(run Run) ([]byte, error) {
+	passed, failed, skipped := run.Counts()
+	suites := junitSuites{
+		Name:     run.Collection,
+		Tests:    passed + failed + skipped,
+		Failures: failed,
+		Skipped:  skipped,
+		Time:     seconds(run.Duration),
+	}
+
+	iterations := 0
+	for _, c := range run.Cases {
+		iterations = max(iterations, c.Iteration)
+	}
+	for iteration := 1; iteration <= iterations; iteration++ {
+		suite := junitSuite{Name: run.Collection, Timestamp: run.StartedAt.Format("2006-01-02T15:04:05")}
+		if iterations > 1 {
+			suite.Name += " #" + strconv.Itoa(iteration)
+		}
+
+		var duration time.Duration
+		for _, c := range run.Cases {
+			if c.Iteration != iteration {
+				continue
+			}
+			duration += c.Duration
+			testCase := junitCase{Name: c.Name(), Classname: run.Collection, Time: seconds(c.Duration)}
+			switch c.Status {
+			case StatusFailed:
+				suite.Failures++
+				testCase.Failure = &junitFailure{
+					Message: strings.Join(c.Failures, "; "),
+					Type:    "failure",
+					Details: failureDetails(c),
+				}
+			case StatusSkipped, StatusPending:
+				suite.Skipped++
+				testCase.Skipped = &struct{}{}
+			}
+			testCase.SystemOut = logs(c.Script)
+			suite.Tests++
+			suite.Cases = append(suite.Cases, testCase)
+		}
+		suite.Time = seconds(duration)
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	encoded, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(encoded, '\n')...), nil
+}
+
+// failureDetails describes a failed case for the CI logs: the reasons, then
+// the request and the response.
+func failureDetails(c Case) string {
+	var lines []string
+	lines = append(lines, c.Failures...)
+	if c.Response == nil {
+		return strings.Join(lines, "\n")
+	}
+
+	res := redact(*c.Response)
+	lines = append(lines, "", res.Request.Method+" "+res.Request.Url)
+	status := res.StatusMessage
+	if status == "" {
+		status = strconv.Itoa(res.StatusCode)
+	}
+	lines = append(lines, "", strings.TrimSpace(res.HttpVersion+" "+status))
+
+	names := make([]string, 0, len(res.Headers))
+	for name := range res.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range res.Headers[name] {
+			lines = append(lines, name+": "+value)
+		}
+	}
+	if res.Result != "" {
+		lines = append(lines, "", res.Result)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func logs(result script_module.Result) string {
+	var lines []string
+	for _, entry := range result.Logs {
+		lines = append(lines, fmt.Sprintf("[%s] %s: %s", entry.Phase, entry.Level, entry.Message))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// redact leaves the credentials of the auth out of a response attached to a
+// report, CI systems publish them. Long bodies are cut.
+func redact(res request_module.RequestResponse) *request_module.RequestResponse {
+	auth := res.Request.Auth
+	res.Request.Auth = auth_module.AuthOptions{Type: auth.Type, Username: auth.Username, TokenUrl: auth.TokenUrl, ClientId: auth.ClientId, Scopes: auth.Scopes}
+	if len(res.Result) > maxReportBody {
+		res.Result = res.Result[:maxReportBody] + "\n[cut, " + strconv.Itoa(len(res.Result)) + " bytes in total]"
+	}
+	return &res
+}
+
+func milliseconds(duration time.Duration) float64 {
+	return float64(duration.Microseconds()) / 1000
+}
+
+func seconds(duration time.Duration) string {
+	return strconv.FormatFloat(duration.Seconds(), 'f', 3, 64)
+}
+
+// WriteReport writes a report built by Json or JUnit.
+func WriteReport(path string, build func(run Run) ([]byte, error), run Run) error {
+	data, err := build(run)
+	if err != nil {
+		return err
+	}
+	return writeFile(path, data, 0644)
+}
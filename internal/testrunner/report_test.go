@@ -0,0 +1,96 @@
+package testrunner_module
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	auth_module "github.com/diogopereiradev/httpzen/internal/auth"
+	collection_module "github.com/diogopereiradev/httpzen/internal/collection"
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	script_module "github.com/diogopereiradev/httpzen/internal/script"
+	"github.com/stretchr/testify/assert"
+)
+
+func testRun() Run {
+	failedResponse := &request_module.RequestResponse{
+		HttpVersion:   "HTTP/1.1",
+		StatusCode:    500,
+		StatusMessage: "500 Internal Server Error",
+		Headers:       http.Header{"Content-Type": {"application/json"}},
+		Result:        `{"error":"boom"}`,
+		Request: request_module.RequestOptions{Method: "GET", Url: "http://a/me",
+			Auth: auth_module.AuthOptions{Type: auth_module.AuthBearer, Token: "secret-token"}},
+	}
+	return Run{
+		Collection: "api",
+		StartedAt:  time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Duration:   1500 * time.Millisecond,
+		Cases: []Case{
+			{Iteration: 1, Request: collection_module.SavedRequest{Name: "login", Folder: "auth"}, Status: StatusPassed, Duration: 120 * time.Millisecond,
+				Response: &request_module.RequestResponse{StatusCode: 200},
+				Script:   script_module.Result{Logs: []script_module.LogEntry{{Phase: script_module.PhasePost, Level: "log", Message: "hi"}}}},
+			{Iteration: 1, Request: collection_module.SavedRequest{Name: "me"}, Status: StatusFailed, Duration: 30 * time.Millisecond,
+				Failures: []string{`test "ok" failed: status 500`}, Response: failedResponse,
+				Script: script_module.Result{Tests: []script_module.TestResult{{Name: "ok", Message: "status 500"}}}},
+			{Iteration: 2, Request: collection_module.SavedRequest{Name: "login", Folder: "auth"}, Status: StatusSkipped},
+			{Iteration: 2, Request: collection_module.SavedRequest{Name: "me"}, Status: StatusSkipped},
+		},
+	}
+}
+
+func TestJson(t *testing.T) {
+	data, err := Json(testRun())
+	assert.NoError(t, err)
+
+	var report JsonReport
+	assert.NoError(t, json.Unmarshal(data, &report))
+	assert.Equal(t, "api", report.Collection)
+	assert.Equal(t, 1500.0, report.Duration)
+	assert.Equal(t, []int{1, 1, 2}, []int{report.Passed, report.Failed, report.Skipped})
+	assert.Len(t, report.Results, 4)
+	assert.Equal(t, "auth/login", report.Results[0].Request)
+	assert.Equal(t, 200, report.Results[0].StatusCode)
+	assert.Nil(t, report.Results[0].Response, "passed cases should not carry the response")
+	assert.Equal(t, `{"error":"boom"}`, report.Results[1].Response.Result)
+	assert.Equal(t, auth_module.AuthBearer, report.Results[1].Response.Request.Auth.Type)
+	assert.NotContains(t, string(data), "secret-token")
+}
+
+func TestJUnit(t *testing.T) {
+	data, err := JUnit(testRun())
+	assert.NoError(t, err)
+	xml := string(data)
+
+	assert.True(t, strings.HasPrefix(xml, `<?xml version="1.0" encoding="UTF-8"?>`))
+	assert.Contains(t, xml, `<testsuites name="api" tests="4" failures="1" skipped="2" time="1.500">`)
+	assert.Contains(t, xml, `<testsuite name="api #1" tests="2" failures="1" skipped="0" time="0.150" timestamp="2026-01-02T03:04:05">`)
+	assert.Contains(t, xml, `<testsuite name="api #2" tests="2" failures="0" skipped="2"`)
+	assert.Contains(t, xml, `<testcase name="auth/login" classname="api" time="0.120">`)
+	assert.Contains(t, xml, `<system-out>[post-response] log: hi</system-out>`)
+	assert.Contains(t, xml, `<failure message="test &#34;ok&#34; failed: status 500" type="failure">`)
+	assert.Contains(t, xml, "GET http://a/me&#xA;&#xA;HTTP/1.1 500 Internal Server Error&#xA;Content-Type: application/json&#xA;&#xA;{&#34;error&#34;:&#34;boom&#34;}</failure>")
+	assert.Contains(t, xml, "<skipped></skipped>")
+	assert.NotContains(t, xml, "secret-token")
+}
+
+func TestRedact_CutsLongBodies(t *testing.T) {
+	res := redact(request_module.RequestResponse{Result: strings.Repeat("a", maxReportBody+5)})
+	assert.True(t, strings.HasSuffix(res.Result, "[cut, 10005 bytes in total]"))
+}
+
+func TestWriteReport(t *testing.T) {
+	old := writeFile
+	t.Cleanup(func() { writeFile = old })
+	var written string
+	writeFile = func(path string, data []byte, _ os.FileMode) error {
+		written = path
+		return nil
+	}
+
+	assert.NoError(t, WriteReport("report.xml", JUnit, testRun()))
+	assert.Equal(t, "report.xml", written)
+}
@@ -0,0 +1,212 @@
+package http_utility
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strings"
+)
+
+// RequestItems are the HTTPie style arguments given after the URL:
+//
+//	Header:value   a request header
+//	param==value   a query string parameter
+//	field=value    a string field of the body
+//	field:=json    a raw JSON value, like a number, a list or an object
+//	field@file     a file uploaded in a multipart form
+//	=@file         a file sent as the raw body
+//
+// A separator is escaped with a backslash, like a\:b=c for the field "a:b".
+type RequestItems struct {
+	Headers http.Header
+	Query   neturl.Values
+	Body    []HttpContentData
+	// Files are the files the body reads, to check they exist before the
+	// request is sent.
+	Files []string
+}
+
+type requestItem struct {
+	key       string
+	separator string
+	value     string
+}
+
+// Longer separators first, so ":=" wins over ":" at the same position.
+var requestItemSeparators = []string{":=", "==", "=", ":", "@"}
+
+// ParseRequestItems reads the items into headers, query parameters and a
+// body. Fields build a JSON object, or a form when form is true, which is
+// sent as multipart when a file is uploaded.
+func ParseRequestItems(items []string, form bool) (RequestItems, error) {
+	result := RequestItems{Headers: http.Header{}, Query: neturl.Values{}}
+
+	var fields []requestItem
+	rawBody := ""
+	hasFiles := false
+	for _, raw := range items {
+		if path, ok := strings.CutPrefix(raw, "=@"); ok {
+			if path == "" || rawBody != "" {
+				return RequestItems{}, fmt.Errorf("invalid request item %q: expected a single =@file raw body", raw)
+			}
+			rawBody = path
+			result.Files = append(result.Files, path)
+			continue
+		}
+
+		item, err := splitRequestItem(raw)
+		if err != nil {
+			return RequestItems{}, err
+		}
+		switch item.separator {
+		case ":":
+			result.Headers.Add(item.key, strings.TrimSpace(item.value))
+		case "==":
+			result.Query.Add(item.key, item.value)
+		case ":=":
+			if form {
+				return RequestItems{}, fmt.Errorf("invalid request item %q: raw JSON fields can't be sent in a form", raw)
+			}
+			if !json.Valid([]byte(item.value)) {
+				return RequestItems{}, fmt.Errorf("invalid request item %q: the value is not valid JSON", raw)
+			}
+			fields = append(fields, item)
+		case "@":
+			if !form {
+				return RequestItems{}, fmt.Errorf("invalid request item %q: file fields need --form", raw)
+			}
+			hasFiles = true
+			result.Files = append(result.Files, item.value)
+			fields = append(fields, item)
+		default:
+			fields = append(fields, item)
+		}
+	}
+
+	if rawBody != "" {
+		if len(fields) > 0 {
+			return RequestItems{}, fmt.Errorf("a raw =@file body can't be sent with data fields")
+		}
+		result.Body = NewFileBody(rawBody, result.Headers.Get("Content-Type"))
+		return result, nil
+	}
+	if len(fields) == 0 {
+		return result, nil
+	}
+
+	switch {
+	case form && hasFiles:
+		for _, field := range fields {
+			value := field.value
+			// ParseMultipartFormData takes values that look like paths to
+			// existing files as files.
+			if field.separator == "@" && !strings.ContainsAny(value, "/\\") {
+				value = "./" + value
+			}
+			result.Body = append(result.Body, HttpContentData{ContentType: "multipart/form-data", Key: field.key, Value: value})
+		}
+	case form:
+		for _, field := range fields {
+			result.Body = append(result.Body, HttpContentData{ContentType: "application/x-www-form-urlencoded", Key: field.key, Value: field.value})
+		}
+	default:
+		document, err := requestItemsJson(fields)
+		if err != nil {
+			return RequestItems{}, err
+		}
+		result.Body = []HttpContentData{{ContentType: "application/json", Value: document}}
+	}
+	return result, nil
+}
+
+// splitRequestItem cuts the item at its first unescaped separator.
+func splitRequestItem(raw string) (requestItem, error) {
+	for i := 0; i < len(raw); i++ {
+		if raw[i] == '\\' {
+			i++
+			continue
+		}
+		for _, separator := range requestItemSeparators {
+			if strings.HasPrefix(raw[i:], separator) {
+				key := unescapeRequestItem(raw[:i])
+				if key == "" {
+					return requestItem{}, fmt.Errorf("invalid request item %q: missing the name before %q", raw, separator)
+				}
+				return requestItem{key: key, separator: separator, value: raw[i+len(separator):]}, nil
+			}
+		}
+	}
+	return requestItem{}, fmt.Errorf("invalid request item %q: expected Header:value, param==value, field=value, field:=json, field@file or =@file", raw)
+}
+
+func unescapeRequestItem(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) {
+			i++
+		}
+		b.WriteByte(value[i])
+	}
+	return b.String()
+}
+
+// requestItemsJson writes the fields as a JSON object in the order they were
+// given, a repeated field keeps its last value.
+func requestItemsJson(fields []requestItem) (string, error) {
+	values := map[string]json.RawMessage{}
+	var order []string
+	for _, field := range fields {
+		value := json.RawMessage(field.value)
+		if field.separator != ":=" {
+			encoded, err := json.Marshal(field.value)
+			if err != nil {
+				return "", err
+			}
+			value = encoded
+		}
+		if _, exists := values[field.key]; !exists {
+			order = append(order, field.key)
+		}
+		values[field.key] = value
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range order {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		encodedKey, _ := json.Marshal(key)
+		buf.Write(encodedKey)
+		buf.WriteByte(':')
+		if err := json.Compact(&buf, values[key]); err != nil {
+			return "", err
+		}
+	}
+	buf.WriteByte('}')
+	return buf.String(), nil
+}
+
+// AddQuery appends the parameters to the query string of the URL. Variable
+// references, like {{page}}, are kept as they are to be expanded later.
+func AddQuery(url string, query neturl.Values) string {
+	if len(query) == 0 {
+		return url
+	}
+	base, fragment, hasFragment := strings.Cut(url, "#")
+	separator := "?"
+	if strings.Contains(base, "?") {
+		separator = "&"
+		if strings.HasSuffix(base, "?") || strings.HasSuffix(base, "&") {
+			separator = ""
+		}
+	}
+	encoded := strings.NewReplacer("%7B%7B", "{{", "%7D%7D", "}}").Replace(query.Encode())
+	base += separator + encoded
+	if hasFragment {
+		base += "#" + fragment
+	}
+	return base
+}
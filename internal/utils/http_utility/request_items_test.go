@@ -0,0 +1,132 @@
+package http_utility
+
+import (
+	neturl "net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRequestItems_Json(t *testing.T) {
+	items, err := ParseRequestItems([]string{
+		"X-Api-Key:secret",
+		"Accept: application/json",
+		"page==2",
+		"q==a b",
+		"name=John",
+		"age:=30",
+		"tags:=[\"a\", \"b\"]",
+		`a\:b=c`,
+		"name=Jane",
+	}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if items.Headers.Get("X-Api-Key") != "secret" || items.Headers.Get("Accept") != "application/json" {
+		t.Errorf("unexpected headers %v", items.Headers)
+	}
+	if !reflect.DeepEqual(items.Query, neturl.Values{"page": {"2"}, "q": {"a b"}}) {
+		t.Errorf("unexpected query %v", items.Query)
+	}
+	want := `{"name":"Jane","age":30,"tags":["a","b"],"a:b":"c"}`
+	if len(items.Body) != 1 || items.Body[0].ContentType != "application/json" || items.Body[0].Value != want {
+		t.Errorf("unexpected body %+v, want %s", items.Body, want)
+	}
+}
+
+func TestParseRequestItems_Form(t *testing.T) {
+	items, err := ParseRequestItems([]string{"name=John", "city=New York"}, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []HttpContentData{
+		{ContentType: "application/x-www-form-urlencoded", Key: "name", Value: "John"},
+		{ContentType: "application/x-www-form-urlencoded", Key: "city", Value: "New York"},
+	}
+	if !reflect.DeepEqual(items.Body, want) {
+		t.Errorf("unexpected body %+v", items.Body)
+	}
+
+	items, err = ParseRequestItems([]string{"name=John", "photo@me.png", "doc@/tmp/a.pdf"}, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want = []HttpContentData{
+		{ContentType: "multipart/form-data", Key: "name", Value: "John"},
+		{ContentType: "multipart/form-data", Key: "photo", Value: "./me.png"},
+		{ContentType: "multipart/form-data", Key: "doc", Value: "/tmp/a.pdf"},
+	}
+	if !reflect.DeepEqual(items.Body, want) {
+		t.Errorf("expected a multipart body with files, got %+v", items.Body)
+	}
+	if !reflect.DeepEqual(items.Files, []string{"me.png", "/tmp/a.pdf"}) {
+		t.Errorf("unexpected files %v", items.Files)
+	}
+}
+
+func TestParseRequestItems_RawBody(t *testing.T) {
+	items, err := ParseRequestItems([]string{"=@data.xml", "Content-Type:text/xml"}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items.Body) != 1 || items.Body[0].Key != FileBodyKey || items.Body[0].Value != "data.xml" || items.Body[0].ContentType != "text/xml" {
+		t.Errorf("unexpected body %+v", items.Body)
+	}
+	if !reflect.DeepEqual(items.Files, []string{"data.xml"}) {
+		t.Errorf("unexpected files %v", items.Files)
+	}
+}
+
+func TestParseRequestItems_NoBody(t *testing.T) {
+	items, err := ParseRequestItems([]string{"Authorization:Bearer a:b", "redirect==https://x.test/?a=1"}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if items.Body != nil || items.Headers.Get("Authorization") != "Bearer a:b" || items.Query.Get("redirect") != "https://x.test/?a=1" {
+		t.Errorf("unexpected items %+v", items)
+	}
+}
+
+func TestParseRequestItems_Errors(t *testing.T) {
+	cases := []struct {
+		items []string
+		form  bool
+		err   string
+	}{
+		{[]string{"justtext"}, false, "expected Header:value"},
+		{[]string{"=value"}, false, "missing the name"},
+		{[]string{"age:={"}, false, "not valid JSON"},
+		{[]string{"age:=1"}, true, "can't be sent in a form"},
+		{[]string{"photo@me.png"}, false, "need --form"},
+		{[]string{"=@a.bin", "=@b.bin"}, false, "single =@file"},
+		{[]string{"=@a.bin", "name=x"}, false, "can't be sent with data fields"},
+	}
+	for _, c := range cases {
+		if _, err := ParseRequestItems(c.items, c.form); err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("ParseRequestItems(%v) = %v, want an error containing %q", c.items, err, c.err)
+		}
+	}
+}
+
+func TestAddQuery(t *testing.T) {
+	query := neturl.Values{"a": {"1 2"}}
+	cases := map[string]string{
+		"https://x.test/path":          "https://x.test/path?a=1+2",
+		"https://x.test/path?b=2":      "https://x.test/path?b=2&a=1+2",
+		"https://x.test/path?":         "https://x.test/path?a=1+2",
+		"https://x.test/path#frag":     "https://x.test/path?a=1+2#frag",
+		"https://x.test/path?b=2#frag": "https://x.test/path?b=2&a=1+2#frag",
+	}
+	for url, want := range cases {
+		if got := AddQuery(url, query); got != want {
+			t.Errorf("AddQuery(%q) = %q, want %q", url, got, want)
+		}
+	}
+	if AddQuery("https://x.test", nil) != "https://x.test" {
+		t.Error("expected the URL to be kept without parameters")
+	}
+	if got := AddQuery("{{baseUrl}}/items", neturl.Values{"page": {"{{page}}"}}); got != "{{baseUrl}}/items?page={{page}}" {
+		t.Errorf("expected the variable to be kept, got %q", got)
+	}
+}
@@ -0,0 +1,136 @@
+package http_utility
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+
+	logger_module "github.com/diogopereiradev/httpzen/internal/logger"
+)
+
+// FileBodyKey marks a raw body read from the file in Value, sent as it is
+// with the ContentType of the entry.
+const FileBodyKey = "@file"
+
+// UploadBody is a request body read from disk while it is sent, files are
+// never held in memory. Every Open starts the body over, for retries and
+// redirects.
+type UploadBody struct {
+	// Length is -1 when a file has no known size, like a named pipe, the
+	// body is sent chunked then.
+	Length int64
+	parts  []uploadPart
+}
+
+// uploadPart is either bytes built in memory, like multipart headers and
+// fields, or a file opened when the reader gets to it.
+type uploadPart struct {
+	data []byte
+	path string
+}
+
+func (b *UploadBody) addData(data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	b.parts = append(b.parts, uploadPart{data: bytes.Clone(data)})
+	if b.Length >= 0 {
+		b.Length += int64(len(data))
+	}
+}
+
+func (b *UploadBody) addFile(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	b.parts = append(b.parts, uploadPart{path: path})
+	if !info.Mode().IsRegular() {
+		b.Length = -1
+	} else if b.Length >= 0 {
+		b.Length += info.Size()
+	}
+	return nil
+}
+
+func (b *UploadBody) Open() io.ReadCloser {
+	return &uploadReader{parts: b.parts}
+}
+
+type uploadReader struct {
+	parts   []uploadPart
+	current io.Reader
+	file    *os.File
+}
+
+func (r *uploadReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.parts) == 0 {
+				return 0, io.EOF
+			}
+			part := r.parts[0]
+			r.parts = r.parts[1:]
+			if part.path == "" {
+				r.current = bytes.NewReader(part.data)
+				continue
+			}
+			file, err := os.Open(part.path)
+			if err != nil {
+				return 0, err
+			}
+			r.file, r.current = file, file
+		}
+
+		n, err := r.current.Read(p)
+		if err == io.EOF {
+			r.closeFile()
+			r.current = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (r *uploadReader) Close() error {
+	r.parts = nil
+	r.current = nil
+	r.closeFile()
+	return nil
+}
+
+func (r *uploadReader) closeFile() {
+	if r.file != nil {
+		r.file.Close()
+		r.file = nil
+	}
+}
+
+func NewFileBody(path string, contentType string) []HttpContentData {
+	if contentType == "" {
+		contentType = FileContentType(path)
+	}
+	return []HttpContentData{{ContentType: contentType, Key: FileBodyKey, Value: path}}
+}
+
+// FileContentType guesses the type of a file from its extension.
+func FileContentType(path string) string {
+	if contentType := mime.TypeByExtension(filepath.Ext(path)); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+func ParseFileBody(data HttpContentData) HandleParseResult {
+	body := &UploadBody{}
+	if err := body.addFile(data.Value); err != nil {
+		logger_module.Error("Failed to read the body file: "+err.Error(), 70)
+		return HandleParseResult{}
+	}
+	return HandleParseResult{ContentTypeHeader: data.ContentType, Result: body}
+}
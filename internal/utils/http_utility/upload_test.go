@@ -0,0 +1,71 @@
+package http_utility
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUploadBody_OpenStartsOver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.txt")
+	os.WriteFile(path, []byte("file content"), 0644)
+
+	body := &UploadBody{}
+	body.addData([]byte("head-"))
+	body.addFile(path)
+	body.addData([]byte("-tail"))
+
+	for range 2 {
+		reader := body.Open()
+		data, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil || string(data) != "head-file content-tail" {
+			t.Fatalf("unexpected body %q, %v", data, err)
+		}
+	}
+	if body.Length != int64(len("head-file content-tail")) {
+		t.Errorf("unexpected length %d", body.Length)
+	}
+}
+
+func TestUploadBody_MissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.txt")
+	os.WriteFile(path, []byte("x"), 0644)
+
+	body := &UploadBody{}
+	body.addFile(path)
+	os.Remove(path)
+
+	if _, err := io.ReadAll(body.Open()); err == nil {
+		t.Error("expected the read to fail once the file is gone")
+	}
+	if err := body.addFile(path); err == nil {
+		t.Error("expected a missing file to be rejected")
+	}
+}
+
+func TestParseFileBody(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "photo.png")
+	os.WriteFile(path, []byte("png"), 0644)
+
+	data := NewFileBody(path, "")
+	if data[0].ContentType != "image/png" || data[0].Key != FileBodyKey {
+		t.Fatalf("unexpected body %+v", data)
+	}
+	res := ParseFileBody(data[0])
+	if res.ContentTypeHeader != "image/png" {
+		t.Errorf("unexpected content type %q", res.ContentTypeHeader)
+	}
+	if content := readUploadBody(t, res); content != "png" {
+		t.Errorf("unexpected content %q", content)
+	}
+
+	if FileContentType("archive.unknownext") != "application/octet-stream" {
+		t.Error("expected unknown extensions to be sent as octet-stream")
+	}
+	if !strings.HasPrefix(NewFileBody(path, "text/csv")[0].ContentType, "text/csv") {
+		t.Error("expected the given content type to be kept")
+	}
+}
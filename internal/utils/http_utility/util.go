@@ -3,9 +3,13 @@ package http_utility
 import (
 	"bytes"
 	"encoding/json"
-	"io"
+	"mime"
 	"mime/multipart"
+	"net"
+	"net/http"
+	neturl "net/url"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -38,9 +42,64 @@ func ParseApplicationJson(data HttpContentData) HandleParseResult {
 	return HandleParseResult{ContentTypeHeader: data.ContentType, Result: jsonData}
 }
 
+// GraphQLContentType marks a GraphQL body, kept as "query", "variables" and
+// "operationName" entries and sent as a JSON document.
+const GraphQLContentType = "application/graphql"
+
+func NewGraphQLBody(query string, variables string, operationName string) []HttpContentData {
+	body := []HttpContentData{{ContentType: GraphQLContentType, Key: "query", Value: query}}
+	if strings.TrimSpace(variables) != "" {
+		body = append(body, HttpContentData{ContentType: GraphQLContentType, Key: "variables", Value: variables})
+	}
+	if operationName != "" {
+		body = append(body, HttpContentData{ContentType: GraphQLContentType, Key: "operationName", Value: operationName})
+	}
+	return body
+}
+
+// GetGraphQLBody returns the parts of a GraphQL body, ok is false when the
+// body is of another type.
+func GetGraphQLBody(data []HttpContentData) (query string, variables string, operationName string, ok bool) {
+	if len(data) == 0 || data[0].ContentType != GraphQLContentType {
+		return "", "", "", false
+	}
+	for _, part := range data {
+		switch part.Key {
+		case "query":
+			query = part.Value
+		case "variables":
+			variables = part.Value
+		case "operationName":
+			operationName = part.Value
+		}
+	}
+	return query, variables, operationName, true
+}
+
+func ParseGraphQL(data []HttpContentData) HandleParseResult {
+	query, variables, operationName, _ := GetGraphQLBody(data)
+
+	document := map[string]any{"query": query}
+	if variables != "" {
+		var parsed any
+		if err := json.Unmarshal([]byte(variables), &parsed); err != nil {
+			logger_module.Error("Failed to parse GraphQL variables: "+err.Error(), 70)
+			return HandleParseResult{}
+		}
+		document["variables"] = parsed
+	}
+	if operationName != "" {
+		document["operationName"] = operationName
+	}
+	return HandleParseResult{ContentTypeHeader: "application/json", Result: document}
+}
+
+// ParseMultipartFormData builds the body as an *UploadBody, values that are
+// paths to existing files are sent as file parts streamed from disk.
 func ParseMultipartFormData(data []HttpContentData) HandleParseResult {
 	var buf bytes.Buffer
 	writer := multipart.NewWriter(&buf)
+	body := &UploadBody{}
 
 	for _, part := range data {
 		var unmarshalResult map[string]string
@@ -51,12 +110,12 @@ func ParseMultipartFormData(data []HttpContentData) HandleParseResult {
 
 		if len(pair) > 0 {
 			if _, err := GetFileByPath(part.Value); err == nil {
-				file, err := os.Open(part.Value)
-				if err == nil {
-					defer file.Close()
-					if fw, err2 := writer.CreateFormFile(part.Key, part.Value); err2 == nil {
-						_, _ = io.Copy(fw, file)
-					}
+				if _, err := writer.CreateFormFile(part.Key, part.Value); err == nil {
+					// The part headers go before the file, the next part
+					// starts after it.
+					body.addData(buf.Bytes())
+					buf.Reset()
+					body.addFile(part.Value)
 					continue
 				}
 			}
@@ -68,9 +127,10 @@ func ParseMultipartFormData(data []HttpContentData) HandleParseResult {
 		}
 	}
 	writer.Close()
+	body.addData(buf.Bytes())
 	return HandleParseResult{
 		ContentTypeHeader: writer.FormDataContentType(),
-		Result:            &buf,
+		Result:            body,
 	}
 }
 
@@ -90,32 +150,160 @@ func ParseUrlEncodedForm(data []HttpContentData) HandleParseResult {
 	}
 }
 
+type HttpMethodInfo struct {
+	Name       string
+	AllowsBody bool
+	Hint       string
+}
+
+// HttpMethods lists every method httpzen sends without --custom-method: the
+// RFC 9110 set, PATCH and the WebDAV extensions.
+var HttpMethods = []HttpMethodInfo{
+	{Name: "GET", AllowsBody: false, Hint: "Fetch a resource"},
+	{Name: "HEAD", AllowsBody: false, Hint: "Fetch only the headers of a resource"},
+	{Name: "POST", AllowsBody: true, Hint: "Submit data or create a resource"},
+	{Name: "PUT", AllowsBody: true, Hint: "Replace a resource with the request body"},
+	{Name: "PATCH", AllowsBody: true, Hint: "Partially update a resource"},
+	{Name: "DELETE", AllowsBody: true, Hint: "Remove a resource"},
+	{Name: "OPTIONS", AllowsBody: true, Hint: "List allowed methods, useful for CORS preflight checks"},
+	{Name: "TRACE", AllowsBody: false, Hint: "Echo the request back through proxies (no body)"},
+	{Name: "CONNECT", AllowsBody: false, Hint: "Open a tunnel, the URL should point to a proxy"},
+	{Name: "PROPFIND", AllowsBody: true, Hint: "WebDAV: read properties, set a Depth header"},
+	{Name: "PROPPATCH", AllowsBody: true, Hint: "WebDAV: change properties with an XML body"},
+	{Name: "MKCOL", AllowsBody: true, Hint: "WebDAV: create a collection"},
+	{Name: "COPY", AllowsBody: false, Hint: "WebDAV: copy a resource, set a Destination header"},
+	{Name: "MOVE", AllowsBody: false, Hint: "WebDAV: move a resource, set a Destination header"},
+	{Name: "LOCK", AllowsBody: true, Hint: "WebDAV: lock a resource"},
+	{Name: "UNLOCK", AllowsBody: false, Hint: "WebDAV: unlock a resource, set a Lock-Token header"},
+	{Name: "REPORT", AllowsBody: true, Hint: "WebDAV: run a report query"},
+	{Name: "SEARCH", AllowsBody: true, Hint: "WebDAV: run a search query"},
+}
+
 func ParseHttpMethod(method string) string {
-	switch strings.ToLower(method) {
-	case "get":
-		return "GET"
-	case "post":
-		return "POST"
-	case "put":
-		return "PUT"
-	case "delete":
-		return "DELETE"
-	case "patch":
-		return "PATCH"
-	case "head":
-		return "HEAD"
-	default:
+	upper := strings.ToUpper(method)
+	for _, info := range HttpMethods {
+		if info.Name == upper {
+			return info.Name
+		}
+	}
+	return ""
+}
+
+// ParseCustomHttpMethod accepts any method written as an uppercase RFC 9110
+// token, e.g. "PURGE" or "X-SYNC".
+func ParseCustomHttpMethod(method string) string {
+	if method == "" || strings.ToUpper(method) != method {
 		return ""
 	}
+	for _, r := range method {
+		if r > 127 || !(r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("!#$%&'*+-.^_`|~", r)) {
+			return ""
+		}
+	}
+	return method
+}
+
+func GetHttpMethodInfo(method string) (HttpMethodInfo, bool) {
+	for _, info := range HttpMethods {
+		if info.Name == method {
+			return info, true
+		}
+	}
+	return HttpMethodInfo{}, false
+}
+
+// HttpMethodAllowsBody reports whether a body is expected for the method.
+// Custom methods are assumed to accept one.
+func HttpMethodAllowsBody(method string) bool {
+	if info, ok := GetHttpMethodInfo(method); ok {
+		return info.AllowsBody
+	}
+	return true
 }
 
 func ParseUrl(url string) string {
-	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "http+unix://") {
 		return ""
 	}
 	return url
 }
 
+// ParseUnixSocketUrl splits a "http+unix://%2Fvar%2Frun%2Fdocker.sock/path"
+// URL into the socket path and the http:// URL to send over it, with
+// "localhost" as its host.
+func ParseUnixSocketUrl(rawUrl string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(rawUrl, "http+unix://")
+	if !ok {
+		return "", "", false
+	}
+	encoded, path, _ := strings.Cut(rest, "/")
+	socket, err := neturl.PathUnescape(encoded)
+	if err != nil || socket == "" {
+		return "", "", false
+	}
+	return socket, "http://localhost/" + path, true
+}
+
+// ParseWebSocketUrl accepts ws:// and wss:// URLs, http(s) URLs are mapped to
+// their WebSocket scheme.
+func ParseWebSocketUrl(url string) string {
+	switch {
+	case strings.HasPrefix(url, "ws://"), strings.HasPrefix(url, "wss://"):
+		return url
+	case strings.HasPrefix(url, "http://"):
+		return "ws://" + strings.TrimPrefix(url, "http://")
+	case strings.HasPrefix(url, "https://"):
+		return "wss://" + strings.TrimPrefix(url, "https://")
+	}
+	return ""
+}
+
+// ParseGrpcTarget turns "host:port", "http://host:port" or "https://host"
+// into the address to dial. http:// targets are always plaintext, the port
+// defaults to 443, or 80 for plaintext connections. An empty address means
+// the target is invalid.
+func ParseGrpcTarget(target string, plaintext bool) (string, bool) {
+	switch {
+	case strings.HasPrefix(target, "http://"):
+		target = strings.TrimPrefix(target, "http://")
+		plaintext = true
+	case strings.HasPrefix(target, "https://"):
+		target = strings.TrimPrefix(target, "https://")
+	}
+	target = strings.TrimSuffix(target, "/")
+	if target == "" || strings.ContainsAny(target, "/ ") {
+		return "", plaintext
+	}
+
+	host, port, err := net.SplitHostPort(target)
+	if err != nil {
+		host = strings.Trim(target, "[]")
+		port = "443"
+		if plaintext {
+			port = "80"
+		}
+	}
+	if host == "" {
+		return "", plaintext
+	}
+	return net.JoinHostPort(host, port), plaintext
+}
+
+// ParseHeaders turns "Key: value" flag values into a header set, entries
+// without a colon are ignored.
+func ParseHeaders(headers []string) http.Header {
+	result := http.Header{}
+	for _, header := range headers {
+		parts := strings.SplitN(header, ":", 2)
+		if len(parts) == 2 {
+			key := strings.TrimSpace(parts[0])
+			value := strings.TrimSpace(parts[1])
+			result.Add(key, value)
+		}
+	}
+	return result
+}
+
 func ParseExecutionTimeInMilliseconds(start time.Time) float64 {
 	executionTime := time.Since(start)
 	ms := float64(executionTime.Nanoseconds()) / 1e6
@@ -139,6 +327,43 @@ func DetectContentType(result string) string {
 	return "text"
 }
 
+// IsBinaryContentType tells whether a response of this content type can't be
+// shown as text. An unknown type is treated as text, the body decides then.
+func IsBinaryContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"), strings.HasSuffix(mediaType, "+xml"),
+		strings.Contains(mediaType, "json"), strings.Contains(mediaType, "xml"),
+		strings.Contains(mediaType, "javascript"), strings.Contains(mediaType, "yaml"),
+		mediaType == "application/x-www-form-urlencoded", mediaType == "application/graphql":
+		return false
+	case strings.HasPrefix(mediaType, "image/"), strings.HasPrefix(mediaType, "audio/"),
+		strings.HasPrefix(mediaType, "video/"), strings.HasPrefix(mediaType, "font/"),
+		strings.HasPrefix(mediaType, "application/"):
+		return true
+	}
+	return false
+}
+
+// ContentDispositionFilename returns the file name a Content-Disposition
+// header suggests, reduced to its base name so it can't point outside the
+// download directory.
+func ContentDispositionFilename(header string) string {
+	_, params, err := mime.ParseMediaType(header)
+	if err != nil {
+		return ""
+	}
+	name := filepath.Base(strings.ReplaceAll(params["filename"], "\\", "/"))
+	if name == "." || name == ".." || name == "/" {
+		return ""
+	}
+	return name
+}
+
 func GetFileByPath(path string) (*FileInfoData, error) {
 	if len(strings.TrimSpace(path)) == 0 {
 		return &FileInfoData{Name: "", PathIsValid: false}, os.ErrNotExist
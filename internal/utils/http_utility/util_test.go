@@ -1,7 +1,7 @@
 package http_utility
 
 import (
-	"bytes"
+	"io"
 	"os"
 	"strings"
 	"testing"
@@ -47,12 +47,30 @@ func TestParseMultipartFormData(t *testing.T) {
 		t.Errorf("expected multipart content type")
 	}
 
-	buf, ok := res.Result.(*bytes.Buffer)
-	if !ok || buf.Len() == 0 {
-		t.Errorf("expected buffer result")
+	content := readUploadBody(t, res)
+	if !strings.Contains(content, `name="field1"`) {
+		t.Errorf("expected the field, got %q", content)
 	}
 }
 
+// readUploadBody reads the whole body and checks it against its Length.
+func readUploadBody(t *testing.T, res HandleParseResult) string {
+	body, ok := res.Result.(*UploadBody)
+	if !ok {
+		t.Fatalf("expected an upload body, got %T", res.Result)
+	}
+	reader := body.Open()
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body.Length != int64(len(data)) {
+		t.Errorf("expected Length %d to match the %d bytes read", body.Length, len(data))
+	}
+	return string(data)
+}
+
 func TestParseMultipartFormData_FileOpenError(t *testing.T) {
 	data := []HttpContentData{
 		{Key: "file1", Value: "/path/that/does/not/exist.txt"},
@@ -102,9 +120,8 @@ func TestParseMultipartFormData_ValidPath(t *testing.T) {
 		t.Errorf("expected multipart content type")
 	}
 
-	buf, ok := res.Result.(*bytes.Buffer)
-	if !ok || buf.Len() == 0 {
-		t.Errorf("expected buffer result")
+	if content := readUploadBody(t, res); !strings.Contains(content, "package http_utility") {
+		t.Errorf("expected the file content")
 	}
 }
 
@@ -118,9 +135,8 @@ func TestParseMultipartFormData_ValidUnmarshal(t *testing.T) {
 		t.Errorf("expected multipart content type")
 	}
 
-	buf, ok := res.Result.(*bytes.Buffer)
-	if !ok || buf.Len() == 0 {
-		t.Errorf("expected buffer result")
+	if content := readUploadBody(t, res); !strings.Contains(content, `{"foo":"bar"}`) {
+		t.Errorf("expected the field value")
 	}
 }
 
@@ -139,15 +155,51 @@ func TestParseUrlEncodedForm(t *testing.T) {
 	}
 }
 
+func TestGraphQLBody(t *testing.T) {
+	body := NewGraphQLBody("{ me { id } }", "  ", "")
+	if len(body) != 1 {
+		t.Fatalf("expected blank variables and operation to be left out, got %v", body)
+	}
+
+	query, variables, operation, ok := GetGraphQLBody(NewGraphQLBody("{ a }", `{"x":1}`, "Op"))
+	if !ok || query != "{ a }" || variables != `{"x":1}` || operation != "Op" {
+		t.Errorf("unexpected GraphQL parts: %q %q %q %v", query, variables, operation, ok)
+	}
+
+	if _, _, _, ok := GetGraphQLBody([]HttpContentData{{ContentType: "application/json"}}); ok {
+		t.Errorf("expected other content types to be rejected")
+	}
+}
+
+func TestParseGraphQL(t *testing.T) {
+	res := ParseGraphQL(NewGraphQLBody("{ a }", "", ""))
+	document := res.Result.(map[string]any)
+	if _, ok := document["variables"]; ok {
+		t.Errorf("expected no variables key")
+	}
+	if document["query"] != "{ a }" {
+		t.Errorf("expected query to be kept")
+	}
+
+	res = ParseGraphQL(NewGraphQLBody("{ a }", "{invalid", ""))
+	if res.Result != nil {
+		t.Errorf("expected invalid variables to fail")
+	}
+}
+
 func TestParseHttpMethod(t *testing.T) {
 	cases := map[string]string{
-		"get":    "GET",
-		"post":   "POST",
-		"put":    "PUT",
-		"delete": "DELETE",
-		"patch":  "PATCH",
-		"head":   "HEAD",
-		"other":  "",
+		"get":      "GET",
+		"post":     "POST",
+		"put":      "PUT",
+		"delete":   "DELETE",
+		"patch":    "PATCH",
+		"head":     "HEAD",
+		"options":  "OPTIONS",
+		"Trace":    "TRACE",
+		"propfind": "PROPFIND",
+		"mkcol":    "MKCOL",
+		"other":    "",
 	}
 
 	for in, want := range cases {
@@ -157,6 +209,32 @@ func TestParseHttpMethod(t *testing.T) {
 	}
 }
 
+func TestParseCustomHttpMethod(t *testing.T) {
+	cases := map[string]string{
+		"PURGE":    "PURGE",
+		"X-SYNC":   "X-SYNC",
+		"purge":    "",
+		"BAD VERB": "",
+		"":         "",
+		"ÇA":       "",
+	}
+
+	for in, want := range cases {
+		if got := ParseCustomHttpMethod(in); got != want {
+			t.Errorf("ParseCustomHttpMethod(%q): expected %q, got %q", in, want, got)
+		}
+	}
+}
+
+func TestHttpMethodAllowsBody(t *testing.T) {
+	if HttpMethodAllowsBody("GET") || HttpMethodAllowsBody("HEAD") || HttpMethodAllowsBody("TRACE") {
+		t.Errorf("expected GET, HEAD and TRACE to not expect a body")
+	}
+	if !HttpMethodAllowsBody("POST") || !HttpMethodAllowsBody("PROPFIND") || !HttpMethodAllowsBody("PURGE") {
+		t.Errorf("expected POST, PROPFIND and custom methods to accept a body")
+	}
+}
+
 func TestParseUrl(t *testing.T) {
 	if ParseUrl("http://foo") != "http://foo" {
 		t.Errorf("expected valid url")
@@ -169,6 +247,27 @@ func TestParseUrl(t *testing.T) {
 	if ParseUrl("ftp://foo") != "" {
 		t.Errorf("expected empty for invalid url")
 	}
+
+	if ParseUrl("http+unix://%2Ftmp%2Fapi.sock/info") == "" {
+		t.Errorf("expected http+unix url to be valid")
+	}
+}
+
+func TestParseUnixSocketUrl(t *testing.T) {
+	socket, url, ok := ParseUnixSocketUrl("http+unix://%2Fvar%2Frun%2Fdocker.sock/v1.43/info?all=1")
+	if !ok || socket != "/var/run/docker.sock" || url != "http://localhost/v1.43/info?all=1" {
+		t.Errorf("unexpected result: %q %q %v", socket, url, ok)
+	}
+
+	if _, url, ok := ParseUnixSocketUrl("http+unix://%2Ftmp%2Fapi.sock"); !ok || url != "http://localhost/" {
+		t.Errorf("expected root path, got %q", url)
+	}
+
+	for _, input := range []string{"http://foo", "http+unix:///path", "http+unix://%zz/path"} {
+		if _, _, ok := ParseUnixSocketUrl(input); ok {
+			t.Errorf("expected %q to be rejected", input)
+		}
+	}
 }
 
 func TestParseExecutionTimeInMilliseconds(t *testing.T) {
@@ -237,3 +336,75 @@ func TestGetFileByPath(t *testing.T) {
 		t.Errorf("expected valid path")
 	}
 }
+
+func TestParseGrpcTarget(t *testing.T) {
+	cases := []struct {
+		target        string
+		plaintext     bool
+		wantAddress   string
+		wantPlaintext bool
+	}{
+		{"localhost:50051", false, "localhost:50051", false},
+		{"localhost:50051", true, "localhost:50051", true},
+		{"api.example.com", false, "api.example.com:443", false},
+		{"api.example.com", true, "api.example.com:80", true},
+		{"http://localhost:9000", false, "localhost:9000", true},
+		{"https://api.example.com/", false, "api.example.com:443", false},
+		{"[::1]", false, "[::1]:443", false},
+		{"localhost:50051/pkg.Service", false, "", false},
+		{"", false, "", false},
+		{":50051", false, "", false},
+	}
+	for _, c := range cases {
+		address, plaintext := ParseGrpcTarget(c.target, c.plaintext)
+		if address != c.wantAddress || plaintext != c.wantPlaintext {
+			t.Errorf("ParseGrpcTarget(%q, %v) = %q, %v, want %q, %v", c.target, c.plaintext, address, plaintext, c.wantAddress, c.wantPlaintext)
+		}
+	}
+}
+
+func TestParseWebSocketUrl(t *testing.T) {
+	cases := map[string]string{
+		"ws://localhost/socket": "ws://localhost/socket",
+		"wss://example.com":     "wss://example.com",
+		"http://localhost:8080": "ws://localhost:8080",
+		"https://example.com/a": "wss://example.com/a",
+		"ftp://example.com":     "",
+		"example.com":           "",
+	}
+	for input, want := range cases {
+		if got := ParseWebSocketUrl(input); got != want {
+			t.Errorf("ParseWebSocketUrl(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestIsBinaryContentType(t *testing.T) {
+	for _, contentType := range []string{"image/png", "application/octet-stream", "application/pdf", "video/mp4", "application/zip"} {
+		if !IsBinaryContentType(contentType) {
+			t.Errorf("expected %q to be binary", contentType)
+		}
+	}
+	for _, contentType := range []string{"text/plain; charset=utf-8", "application/json", "application/problem+json", "image/svg+xml", "application/javascript", "", "invalid;;"} {
+		if IsBinaryContentType(contentType) {
+			t.Errorf("expected %q to be text", contentType)
+		}
+	}
+}
+
+func TestContentDispositionFilename(t *testing.T) {
+	cases := map[string]string{
+		`attachment; filename="report.pdf"`:                 "report.pdf",
+		`attachment; filename*=UTF-8''r%C3%A9sum%C3%A9.txt`: "résumé.txt",
+		`attachment; filename="../../etc/passwd"`:           "passwd",
+		`attachment; filename="C:\\temp\\file.bin"`:         "file.bin",
+		`attachment`:                "",
+		`attachment; filename=".."`: "",
+		``:                          "",
+	}
+	for header, want := range cases {
+		if got := ContentDispositionFilename(header); got != want {
+			t.Errorf("ContentDispositionFilename(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
@@ -97,11 +97,19 @@ func FetchIpInfo(ipType string, ip string) (LookupIpInfo, error) {
 	return info, nil
 }
 
-func LookupDomainIps(res *resty.Response) []LookupIpInfo {
+// LookupDomainIps lists the addresses the request host resolves to. When the
+// connection was routed elsewhere (a Unix socket, --resolve or --connect-to)
+// used is the address that was actually dialed, and the only one reported.
+func LookupDomainIps(res *resty.Response, used net.Addr) []LookupIpInfo {
+	if used != nil {
+		return lookupUsedAddress(used)
+	}
+
 	host := res.Request.RawRequest.URL.Host
 	if strings.Contains(host, ":") {
 		host = strings.Split(host, ":")[0]
 	}
+
 	ips, err := IpLookupFunc(host)
 	if err != nil {
 		return []LookupIpInfo{}
@@ -109,17 +117,38 @@ func LookupDomainIps(res *resty.Response) []LookupIpInfo {
 
 	var ipList []LookupIpInfo
 	for _, ip := range ips {
-		ipType := ""
-		if ip.To4() != nil {
-			ipType = "IPv4"
-		} else {
-			ipType = "IPv6"
-		}
-
-		ipInfo, err := FetchIpInfo(ipType, ip.String())
+		ipInfo, err := FetchIpInfo(ipType(ip), ip.String())
 		if err == nil {
 			ipList = append(ipList, ipInfo)
 		}
 	}
 	return ipList
 }
+
+func lookupUsedAddress(addr net.Addr) []LookupIpInfo {
+	if addr.Network() == "unix" {
+		return []LookupIpInfo{{Type: "Unix socket", Ip: addr.String()}}
+	}
+
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		host = addr.String()
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return []LookupIpInfo{}
+	}
+
+	ipInfo, err := FetchIpInfo(ipType(ip), ip.String())
+	if err != nil {
+		return []LookupIpInfo{}
+	}
+	return []LookupIpInfo{ipInfo}
+}
+
+func ipType(ip net.IP) string {
+	if ip.To4() != nil {
+		return "IPv4"
+	}
+	return "IPv6"
+}
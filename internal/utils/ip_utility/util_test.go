@@ -103,7 +103,7 @@ func TestLookupDomainIps(t *testing.T) {
 			},
 		},
 	}
-	ips := LookupDomainIps(res)
+	ips := LookupDomainIps(res, nil)
 	assert.True(t, len(ips) >= 1)
 	for _, ip := range ips {
 		assert.NotEmpty(t, ip.Ip)
@@ -119,7 +119,7 @@ func TestLookupDomainIps_Error(t *testing.T) {
 			},
 		},
 	}
-	ips := LookupDomainIps(res)
+	ips := LookupDomainIps(res, nil)
 	assert.Len(t, ips, 0)
 }
 
@@ -131,7 +131,7 @@ func TestLookupDomainIps_HostnameWithPort(t *testing.T) {
 			},
 		},
 	}
-	ips := LookupDomainIps(res)
+	ips := LookupDomainIps(res, nil)
 	assert.True(t, len(ips) >= 1)
 	for _, ip := range ips {
 		assert.NotEmpty(t, ip.Ip)
@@ -153,8 +153,35 @@ func TestLookupDomainIps_Ipv6Protocol(t *testing.T) {
 			},
 		},
 	}
-	ips := LookupDomainIps(res)
+	ips := LookupDomainIps(res, nil)
 	assert.Len(t, ips, 1)
 	assert.Equal(t, "::1", ips[0].Ip)
 	assert.Equal(t, "IPv6", ips[0].Type)
 }
+
+func TestLookupDomainIps_UsedAddress(t *testing.T) {
+	originalLookupIP := IpLookupFunc
+	defer func() { IpLookupFunc = originalLookupIP }()
+	IpLookupFunc = func(host string) ([]net.IP, error) {
+		t.Error("the host should not be resolved when the used address is known")
+		return nil, nil
+	}
+	ip_cache_module.SetIpInfoToCache("10_0_0_7", map[string]any{"Type": "IPv4", "Ip": "10.0.0.7"})
+	defer ip_cache_module.ClearCache()
+
+	res := &resty.Response{
+		Request: &resty.Request{
+			RawRequest: &http.Request{
+				URL: &url.URL{Host: "api.example.com"},
+			},
+		},
+	}
+
+	ips := LookupDomainIps(res, &net.TCPAddr{IP: net.ParseIP("10.0.0.7"), Port: 443})
+	assert.Len(t, ips, 1)
+	assert.Equal(t, "10.0.0.7", ips[0].Ip)
+	assert.Equal(t, "IPv4", ips[0].Type)
+
+	ips = LookupDomainIps(res, &net.UnixAddr{Name: "/var/run/docker.sock", Net: "unix"})
+	assert.Equal(t, []LookupIpInfo{{Type: "Unix socket", Ip: "/var/run/docker.sock"}}, ips)
+}
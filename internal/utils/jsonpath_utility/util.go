@@ -0,0 +1,172 @@
+package jsonpath_utility
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// segment is one step of a path: a property name, an index, a wildcard, or
+// any of them after .. to look at every level below.
+type segment struct {
+	name      string
+	index     int
+	isIndex   bool
+	wildcard  bool
+	recursive bool
+}
+
+// Query returns the values of a document decoded by encoding/json matched by
+// a JSONPath like $.items[0].name, $.items[*].id, $..id or $['a key']. The
+// filters and slices of JSONPath are not supported.
+func Query(document any, path string) ([]any, error) {
+	segments, err := parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	current := []any{document}
+	for _, seg := range segments {
+		var next []any
+		for _, value := range current {
+			candidates := []any{value}
+			if seg.recursive {
+				candidates = descendants(value)
+			}
+			for _, candidate := range candidates {
+				next = append(next, seg.apply(candidate)...)
+			}
+		}
+		current = next
+	}
+	return current, nil
+}
+
+func parse(path string) ([]segment, error) {
+	path = strings.TrimSpace(path)
+	rest, ok := strings.CutPrefix(path, "$")
+	if !ok {
+		return nil, errors.New("a JSONPath starts with $")
+	}
+
+	var segments []segment
+	for rest != "" {
+		seg := segment{}
+		if after, ok := strings.CutPrefix(rest, ".."); ok {
+			seg.recursive, rest = true, after
+		} else if after, ok := strings.CutPrefix(rest, "."); ok {
+			rest = after
+		} else if !strings.HasPrefix(rest, "[") {
+			return nil, errors.New("unexpected \"" + rest + "\" in the JSONPath " + path)
+		}
+
+		if strings.HasPrefix(rest, "[") {
+			end := closingBracket(rest)
+			if end < 0 {
+				return nil, errors.New("missing ] in the JSONPath " + path)
+			}
+			inside := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+
+			switch {
+			case inside == "*":
+				seg.wildcard = true
+			case len(inside) >= 2 && (inside[0] == '\'' || inside[0] == '"') && inside[len(inside)-1] == inside[0]:
+				seg.name = inside[1 : len(inside)-1]
+			default:
+				index, err := strconv.Atoi(inside)
+				if err != nil {
+					return nil, errors.New("unsupported selector [" + inside + "] in the JSONPath " + path)
+				}
+				seg.index, seg.isIndex = index, true
+			}
+		} else {
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			seg.name, rest = rest[:end], rest[end:]
+			if seg.name == "*" {
+				seg.name, seg.wildcard = "", true
+			} else if seg.name == "" {
+				return nil, errors.New("empty property name in the JSONPath " + path)
+			}
+		}
+		segments = append(segments, seg)
+	}
+	return segments, nil
+}
+
+// closingBracket finds the ] of a selector, skipping the ones in quotes.
+func closingBracket(value string) int {
+	var quote byte
+	for i := 1; i < len(value); i++ {
+		switch {
+		case quote != 0 && value[i] == quote:
+			quote = 0
+		case quote == 0 && (value[i] == '\'' || value[i] == '"'):
+			quote = value[i]
+		case quote == 0 && value[i] == ']':
+			return i
+		}
+	}
+	return -1
+}
+
+func (s segment) apply(value any) []any {
+	switch value := value.(type) {
+	case map[string]any:
+		if s.wildcard {
+			keys := make([]string, 0, len(value))
+			for key := range value {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			matches := make([]any, 0, len(keys))
+			for _, key := range keys {
+				matches = append(matches, value[key])
+			}
+			return matches
+		}
+		if nested, ok := value[s.name]; ok && !s.isIndex {
+			return []any{nested}
+		}
+	case []any:
+		if s.wildcard {
+			return value
+		}
+		if s.isIndex {
+			index := s.index
+			if index < 0 {
+				index += len(value)
+			}
+			if index >= 0 && index < len(value) {
+				return []any{value[index]}
+			}
+		}
+	}
+	return nil
+}
+
+// descendants lists the value and every value nested in it, in document
+// order with the object keys sorted.
+func descendants(value any) []any {
+	values := []any{value}
+	switch value := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			values = append(values, descendants(value[key])...)
+		}
+	case []any:
+		for _, nested := range value {
+			values = append(values, descendants(nested)...)
+		}
+	}
+	return values
+}
@@ -0,0 +1,50 @@
+package jsonpath_utility
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const document = `{
+	"data": {"token": "abc", "user": {"id": 7, "tags": ["a", "b"]}},
+	"items": [{"id": 1, "name": "one"}, {"id": 2, "name": "two"}],
+	"a key": {"x.y": true}
+}`
+
+func TestQuery(t *testing.T) {
+	var decoded any
+	assert.NoError(t, json.Unmarshal([]byte(document), &decoded))
+
+	tests := []struct {
+		path string
+		want []any
+	}{
+		{"$.data.token", []any{"abc"}},
+		{"$.data.user.tags[1]", []any{"b"}},
+		{"$.data.user.tags[-1]", []any{"b"}},
+		{"$.items[*].id", []any{1.0, 2.0}},
+		{"$.items.*.name", []any{"one", "two"}},
+		{"$['a key'][\"x.y\"]", []any{true}},
+		{"$..id", []any{7.0, 1.0, 2.0}},
+		{"$..user.id", []any{7.0}},
+		{"$.missing", nil},
+		{"$.items[5]", nil},
+		{"$", []any{decoded}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := Query(decoded, tt.path)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestQuery_Errors(t *testing.T) {
+	for _, path := range []string{"data.token", "$.items[?(@.id>1)]", "$.items[0", "$.a..", "$x"} {
+		_, err := Query(map[string]any{}, path)
+		assert.Error(t, err, path)
+	}
+}
@@ -0,0 +1,158 @@
+package watch_module
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+)
+
+// Condition is a check on a response, like status=200, status!=5xx,
+// latency<500ms or body~ready.
+type Condition struct {
+	Field    string
+	Operator string
+	Value    string
+}
+
+var conditionFields = []string{"status", "latency", "body"}
+
+// The two characters operators come first, so != is not read as !.
+var conditionOperators = []string{"!=", "<=", ">=", "!~", "=", "<", ">", "~"}
+
+func ParseCondition(value string) (Condition, error) {
+	for _, field := range conditionFields {
+		rest, ok := strings.CutPrefix(value, field)
+		if !ok {
+			continue
+		}
+		for _, operator := range conditionOperators {
+			expected, ok := strings.CutPrefix(rest, operator)
+			if !ok {
+				continue
+			}
+			condition := Condition{Field: field, Operator: operator, Value: expected}
+			return condition, condition.validate()
+		}
+		return Condition{}, errors.New("the condition \"" + value + "\" has no operator, use =, !=, <, <=, >, >=, ~ or !~")
+	}
+	return Condition{}, errors.New("the condition \"" + value + "\" must be on status, latency or body, like status=200")
+}
+
+func (c Condition) validate() error {
+	switch c.Field {
+	case "status":
+		if c.Operator == "~" || c.Operator == "!~" {
+			return errors.New("status can't be compared with " + c.Operator)
+		}
+		if statusClass(c.Value) {
+			if c.Operator != "=" && c.Operator != "!=" {
+				return errors.New("a status class like " + c.Value + " only works with = and !=")
+			}
+			return nil
+		}
+		if _, err := strconv.Atoi(c.Value); err != nil {
+			return errors.New("the status \"" + c.Value + "\" is not a number or a class like 2xx")
+		}
+	case "latency":
+		if c.Operator == "~" || c.Operator == "!~" {
+			return errors.New("latency can't be compared with " + c.Operator)
+		}
+		if _, err := latency(c.Value); err != nil {
+			return err
+		}
+	case "body":
+		if c.Operator != "=" && c.Operator != "!=" && c.Operator != "~" && c.Operator != "!~" {
+			return errors.New("the body can only be compared with =, !=, ~ or !~")
+		}
+	}
+	return nil
+}
+
+func (c Condition) String() string {
+	return c.Field + c.Operator + c.Value
+}
+
+// statusClass tells whether a status is a class, like 2xx.
+func statusClass(value string) bool {
+	return len(value) == 3 && value[0] >= '1' && value[0] <= '5' && strings.EqualFold(value[1:], "xx")
+}
+
+// latency reads a duration, or a number of milliseconds.
+func latency(value string) (float64, error) {
+	if milliseconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return milliseconds, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, errors.New("the latency \"" + value + "\" is not a duration like 500ms")
+	}
+	return float64(duration.Microseconds()) / 1000, nil
+}
+
+// Holds tells whether a response meets the condition, a failed request meets
+// none.
+func (c Condition) Holds(res request_module.RequestResponse, err error) bool {
+	if err != nil {
+		return false
+	}
+
+	switch c.Field {
+	case "status":
+		if statusClass(c.Value) {
+			same := strconv.Itoa(res.StatusCode)[:1] == c.Value[:1]
+			return same == (c.Operator == "=")
+		}
+		expected, _ := strconv.Atoi(c.Value)
+		return compare(float64(res.StatusCode), c.Operator, float64(expected))
+	case "latency":
+		expected, _ := latency(c.Value)
+		return compare(res.ExecutionTime, c.Operator, expected)
+	case "body":
+		switch c.Operator {
+		case "=":
+			return strings.TrimSpace(res.Result) == c.Value
+		case "!=":
+			return strings.TrimSpace(res.Result) != c.Value
+		case "~":
+			return strings.Contains(res.Result, c.Value)
+		case "!~":
+			return !strings.Contains(res.Result, c.Value)
+		}
+	}
+	return false
+}
+
+func compare(actual float64, operator string, expected float64) bool {
+	switch operator {
+	case "=":
+		return actual == expected
+	case "!=":
+		return actual != expected
+	case "<":
+		return actual < expected
+	case "<=":
+		return actual <= expected
+	case ">":
+		return actual > expected
+	case ">=":
+		return actual >= expected
+	}
+	return false
+}
+
+// Met tells whether a response meets all the conditions, there must be at
+// least one.
+func Met(conditions []Condition, res request_module.RequestResponse, err error) bool {
+	if len(conditions) == 0 {
+		return false
+	}
+	for _, condition := range conditions {
+		if !condition.Holds(res, err) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+package watch_module
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCondition(t *testing.T) {
+	for value, expected := range map[string]Condition{
+		"status=200":    {Field: "status", Operator: "=", Value: "200"},
+		"status!=5xx":   {Field: "status", Operator: "!=", Value: "5xx"},
+		"status>=400":   {Field: "status", Operator: ">=", Value: "400"},
+		"latency<500ms": {Field: "latency", Operator: "<", Value: "500ms"},
+		"body~ready":    {Field: "body", Operator: "~", Value: "ready"},
+		"body!~a=b":     {Field: "body", Operator: "!~", Value: "a=b"},
+		`body={"ok":1}`: {Field: "body", Operator: "=", Value: `{"ok":1}`},
+		"latency<=1.5s": {Field: "latency", Operator: "<=", Value: "1.5s"},
+		"latency>250":   {Field: "latency", Operator: ">", Value: "250"},
+	} {
+		condition, err := ParseCondition(value)
+		assert.NoError(t, err, value)
+		assert.Equal(t, expected, condition, value)
+		assert.Equal(t, value, condition.String())
+	}
+
+	for _, value := range []string{"code=200", "status", "status~200", "status=ok", "status<2xx", "latency=soon", "latency~1", "body<1"} {
+		_, err := ParseCondition(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestCondition_Holds(t *testing.T) {
+	ok := response(200, " ready \n", 120)
+	for value, expected := range map[string]bool{
+		"status=200":    true,
+		"status!=200":   false,
+		"status=2xx":    true,
+		"status!=5xx":   true,
+		"status<300":    true,
+		"latency<500ms": true,
+		"latency>=0.2s": false,
+		"latency<=120":  true,
+		"body=ready":    true,
+		"body~read":     true,
+		"body!~down":    true,
+		"body!=ready":   false,
+	} {
+		condition, err := ParseCondition(value)
+		assert.NoError(t, err, value)
+		assert.Equal(t, expected, condition.Holds(ok, nil), value)
+		assert.False(t, condition.Holds(ok, refused), value)
+	}
+}
+
+func TestMet(t *testing.T) {
+	status, _ := ParseCondition("status=200")
+	body, _ := ParseCondition("body~ready")
+
+	assert.True(t, Met([]Condition{status, body}, response(200, "ready", 1), nil))
+	assert.False(t, Met([]Condition{status, body}, response(200, "starting", 1), nil))
+	assert.False(t, Met(nil, response(200, "ready", 1), nil))
+}
@@ -0,0 +1,161 @@
+package watch_module
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+)
+
+// DefaultHistoryLimit keeps a long watch from growing without end, the
+// oldest samples go first.
+const DefaultHistoryLimit = 500
+
+// Sample is one request of a watch.
+type Sample struct {
+	Attempt    int
+	At         time.Time
+	StatusCode int
+	// Error is the class of a failed request, empty when it got a response.
+	Error string
+	// Latency is in milliseconds, like RequestResponse.ExecutionTime.
+	Latency  float64
+	BodyHash string
+	// The changes are against the previous sample, the first one has none.
+	StatusChanged bool
+	BodyChanged   bool
+}
+
+func (s Sample) Changed() bool {
+	return s.StatusChanged || s.BodyChanged
+}
+
+// Status is the status code, or the error of a failed request.
+func (s Sample) Status() string {
+	if s.Error != "" {
+		return s.Error
+	}
+	return strconv.Itoa(s.StatusCode)
+}
+
+type History struct {
+	Samples []Sample
+	Limit   int
+
+	attempts int
+}
+
+func NewHistory() *History {
+	return &History{Limit: DefaultHistoryLimit}
+}
+
+// Add records a response, or the error of a failed request, and tells what
+// changed since the previous one.
+func (h *History) Add(res request_module.RequestResponse, err error, at time.Time) Sample {
+	h.attempts++
+	sample := Sample{Attempt: h.attempts, At: at, Latency: res.ExecutionTime}
+	if err != nil {
+		sample.Error = string(request_module.ClassifyError(err).Class)
+	} else {
+		sample.StatusCode = res.StatusCode
+		sample.BodyHash = BodyHash(res.Result)
+	}
+
+	if previous, ok := h.Last(); ok {
+		sample.StatusChanged = previous.Status() != sample.Status()
+		sample.BodyChanged = previous.BodyHash != "" && sample.BodyHash != "" && previous.BodyHash != sample.BodyHash
+	}
+
+	h.Samples = append(h.Samples, sample)
+	if h.Limit > 0 && len(h.Samples) > h.Limit {
+		h.Samples = h.Samples[len(h.Samples)-h.Limit:]
+	}
+	return sample
+}
+
+func (h *History) Last() (Sample, bool) {
+	if len(h.Samples) == 0 {
+		return Sample{}, false
+	}
+	return h.Samples[len(h.Samples)-1], true
+}
+
+// Previous is the sample before the last one.
+func (h *History) Previous() (Sample, bool) {
+	if len(h.Samples) < 2 {
+		return Sample{}, false
+	}
+	return h.Samples[len(h.Samples)-2], true
+}
+
+func (h *History) Latencies() []float64 {
+	latencies := make([]float64, 0, len(h.Samples))
+	for _, sample := range h.Samples {
+		latencies = append(latencies, sample.Latency)
+	}
+	return latencies
+}
+
+// BodyHash is a short hash of a body, enough to tell two bodies apart.
+func BodyHash(body string) string {
+	sum := sha256.Sum256([]byte(body))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// FormatSample writes a sample on one line, for headless watches.
+func FormatSample(sample Sample) string {
+	line := fmt.Sprintf("[%s] #%d %s %.0fms", sample.At.Format("15:04:05"), sample.Attempt, sample.Status(), sample.Latency)
+	if sample.BodyHash != "" {
+		line += " body " + sample.BodyHash
+	}
+
+	var changes []string
+	if sample.StatusChanged {
+		changes = append(changes, "status")
+	}
+	if sample.BodyChanged {
+		changes = append(changes, "body")
+	}
+	if len(changes) > 0 {
+		line += " (" + strings.Join(changes, " and ") + " changed)"
+	}
+	return line
+}
+
+type Options struct {
+	Request  request_module.RequestOptions
+	Interval time.Duration
+	// Until stops the watch once all the conditions are met, an empty list
+	// watches until ctx is done.
+	Until []Condition
+}
+
+var RunRequestFunc = request_module.RunRequest
+var After = time.After
+
+// Watch sends the request every interval until the conditions are met or ctx
+// is done. It returns the last response, whether the conditions were met and
+// the error of the last request.
+func Watch(ctx context.Context, options Options, history *History, onSample func(sample Sample, res request_module.RequestResponse, err error)) (request_module.RequestResponse, bool, error) {
+	for {
+		res, err := RunRequestFunc(options.Request)
+		sample := history.Add(res, err, time.Now())
+		if onSample != nil {
+			onSample(sample, res, err)
+		}
+		if Met(options.Until, res, err) {
+			return res, true, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return res, false, err
+		case <-After(options.Interval):
+		}
+	}
+}
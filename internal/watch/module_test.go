@@ -0,0 +1,116 @@
+package watch_module
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/stretchr/testify/assert"
+)
+
+func response(status int, body string, latency float64) request_module.RequestResponse {
+	return request_module.RequestResponse{StatusCode: status, Result: body, ExecutionTime: latency}
+}
+
+var refused = &request_module.RequestError{Class: request_module.ErrorClassConnectionRefused, Err: errors.New("refused")}
+
+func TestHistory_Add(t *testing.T) {
+	history := NewHistory()
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	first := history.Add(request_module.RequestResponse{ExecutionTime: 2}, refused, at)
+	assert.Equal(t, 1, first.Attempt)
+	assert.Equal(t, "connection_refused", first.Status())
+	assert.False(t, first.Changed())
+
+	second := history.Add(response(503, "down", 10), nil, at)
+	assert.True(t, second.StatusChanged)
+	assert.False(t, second.BodyChanged, "there was no body to compare with")
+
+	third := history.Add(response(503, "still down", 12), nil, at)
+	assert.False(t, third.StatusChanged)
+	assert.True(t, third.BodyChanged)
+
+	fourth := history.Add(response(503, "still down", 9), nil, at)
+	assert.False(t, fourth.Changed())
+	assert.Equal(t, []float64{2, 10, 12, 9}, history.Latencies())
+
+	previous, _ := history.Previous()
+	assert.Equal(t, 3, previous.Attempt)
+}
+
+func TestHistory_Limit(t *testing.T) {
+	history := &History{Limit: 2}
+	for range 5 {
+		history.Add(response(200, "", 1), nil, time.Now())
+	}
+	assert.Len(t, history.Samples, 2)
+	last, ok := history.Last()
+	assert.True(t, ok)
+	assert.Equal(t, 5, last.Attempt)
+}
+
+func TestFormatSample(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.Equal(t, "[03:04:05] #2 200 35ms body 2cf24dba5fb0 (status and body changed)",
+		FormatSample(Sample{Attempt: 2, At: at, StatusCode: 200, Latency: 35.2, BodyHash: BodyHash("hello"), StatusChanged: true, BodyChanged: true}))
+	assert.Equal(t, "[03:04:05] #1 timeout 5000ms", FormatSample(Sample{Attempt: 1, At: at, Error: "timeout", Latency: 5000}))
+}
+
+func stubWatch(t *testing.T, responses ...request_module.RequestResponse) *[]time.Duration {
+	oldRun, oldAfter := RunRequestFunc, After
+	t.Cleanup(func() { RunRequestFunc, After = oldRun, oldAfter })
+
+	var waits []time.Duration
+	calls := 0
+	RunRequestFunc = func(request_module.RequestOptions) (request_module.RequestResponse, error) {
+		res := responses[min(calls, len(responses)-1)]
+		calls++
+		if res.StatusCode == 0 {
+			return res, refused
+		}
+		return res, nil
+	}
+	After = func(d time.Duration) <-chan time.Time {
+		waits = append(waits, d)
+		c := make(chan time.Time, 1)
+		c <- time.Now()
+		return c
+	}
+	return &waits
+}
+
+func TestWatch_Until(t *testing.T) {
+	waits := stubWatch(t, request_module.RequestResponse{}, response(502, "", 1), response(200, "ok", 1))
+	until, _ := ParseCondition("status=200")
+	history := NewHistory()
+	var samples []Sample
+
+	res, met, err := Watch(context.Background(), Options{Interval: 5 * time.Second, Until: []Condition{until}}, history,
+		func(sample Sample, _ request_module.RequestResponse, _ error) { samples = append(samples, sample) })
+
+	assert.True(t, met)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Len(t, samples, 3)
+	assert.Equal(t, []time.Duration{5 * time.Second, 5 * time.Second}, *waits)
+}
+
+func TestWatch_Cancelled(t *testing.T) {
+	stubWatch(t, response(502, "", 1))
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+
+	res, met, _ := Watch(ctx, Options{Interval: time.Second}, NewHistory(), func(Sample, request_module.RequestResponse, error) {
+		calls++
+		if calls == 3 {
+			cancel()
+		}
+	})
+
+	assert.False(t, met)
+	assert.Equal(t, 502, res.StatusCode)
+	assert.GreaterOrEqual(t, calls, 3)
+}
@@ -0,0 +1,288 @@
+package websocket_module
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/diogopereiradev/httpzen/internal/utils/http_utility"
+	"github.com/gorilla/websocket"
+)
+
+type Direction string
+
+const (
+	DirectionSent     Direction = "sent"
+	DirectionReceived Direction = "received"
+	// DirectionInfo marks lines about the connection itself, like the
+	// handshake or a read error, that are not frames on the wire.
+	DirectionInfo Direction = "info"
+)
+
+type Frame struct {
+	Direction Direction `json:"direction"`
+	Opcode    int       `json:"opcode"`
+	Data      string    `json:"data"`
+	CloseCode int       `json:"close_code,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+type ConnectOptions struct {
+	Url     string        `json:"url"`
+	Headers http.Header   `json:"headers"`
+	Timeout time.Duration `json:"timeout"`
+}
+
+type Session struct {
+	Url      string
+	Response *http.Response
+
+	conn    *websocket.Conn
+	onFrame func(Frame)
+	writeMu sync.Mutex
+	closed  chan struct{}
+}
+
+var Connect = connect
+var now = time.Now
+var readFile = os.ReadFile
+var writeFile = os.WriteFile
+
+// writeWait bounds every write so a stuck peer cannot freeze the console.
+const writeWait = 5 * time.Second
+
+// connect performs the handshake and starts reading frames in the background.
+// onFrame is called from the reader goroutine for every frame sent or
+// received, control frames included, until the connection is closed.
+func connect(options ConnectOptions, onFrame func(Frame)) (*Session, error) {
+	url := http_utility.ParseWebSocketUrl(options.Url)
+	if url == "" {
+		return nil, &request_module.RequestError{
+			Class: request_module.ErrorClassInvalidUrl,
+			Err:   errors.New("expected a ws://, wss://, http:// or https:// URL, got \"" + options.Url + "\""),
+		}
+	}
+
+	dialer := *websocket.DefaultDialer
+	dialer.HandshakeTimeout = options.Timeout
+
+	conn, res, err := dialer.DialContext(context.Background(), url, options.Headers)
+	if err != nil {
+		if errors.Is(err, websocket.ErrBadHandshake) && res != nil {
+			err = fmt.Errorf("%w: server answered %s", err, res.Status)
+		}
+		return nil, request_module.ClassifyError(err)
+	}
+
+	session := &Session{
+		Url:      url,
+		Response: res,
+		conn:     conn,
+		onFrame:  onFrame,
+		closed:   make(chan struct{}),
+	}
+
+	conn.SetPingHandler(func(data string) error {
+		session.emit(Frame{Direction: DirectionReceived, Opcode: websocket.PingMessage, Data: data})
+		err := session.writeControl(websocket.PongMessage, []byte(data))
+		if err == nil {
+			session.emit(Frame{Direction: DirectionSent, Opcode: websocket.PongMessage, Data: data})
+		}
+		return nil
+	})
+	conn.SetPongHandler(func(data string) error {
+		session.emit(Frame{Direction: DirectionReceived, Opcode: websocket.PongMessage, Data: data})
+		return nil
+	})
+	conn.SetCloseHandler(func(code int, text string) error {
+		session.emit(Frame{Direction: DirectionReceived, Opcode: websocket.CloseMessage, Data: text, CloseCode: code})
+		message := websocket.FormatCloseMessage(code, "")
+		if session.writeControl(websocket.CloseMessage, message) == nil {
+			session.emit(Frame{Direction: DirectionSent, Opcode: websocket.CloseMessage, CloseCode: code})
+		}
+		return nil
+	})
+
+	session.emit(Frame{Direction: DirectionInfo, Data: "Connected to " + url + " (" + res.Status + ")"})
+	go session.readLoop()
+
+	return session, nil
+}
+
+func (s *Session) emit(frame Frame) {
+	frame.Time = now()
+	if s.onFrame != nil {
+		s.onFrame(frame)
+	}
+}
+
+func (s *Session) readLoop() {
+	defer close(s.closed)
+	for {
+		opcode, data, err := s.conn.ReadMessage()
+		if err != nil {
+			var closeErr *websocket.CloseError
+			isCloseErr := errors.As(err, &closeErr)
+			if isCloseErr && closeErr.Code == websocket.CloseAbnormalClosure {
+				s.emit(Frame{Direction: DirectionInfo, Data: "Connection lost without a close frame (1006 Abnormal Closure)"})
+			} else if !isCloseErr && !errors.Is(err, net.ErrClosed) {
+				s.emit(Frame{Direction: DirectionInfo, Data: "Connection lost: " + err.Error()})
+			}
+			s.emit(Frame{Direction: DirectionInfo, Data: "Connection closed"})
+			s.conn.Close()
+			return
+		}
+		s.emit(Frame{Direction: DirectionReceived, Opcode: opcode, Data: string(data)})
+	}
+}
+
+func (s *Session) writeControl(opcode int, data []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return s.conn.WriteControl(opcode, data, time.Now().Add(writeWait))
+}
+
+func (s *Session) SendText(data string) error {
+	s.writeMu.Lock()
+	s.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	err := s.conn.WriteMessage(websocket.TextMessage, []byte(data))
+	s.writeMu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	s.emit(Frame{Direction: DirectionSent, Opcode: websocket.TextMessage, Data: data})
+	return nil
+}
+
+func (s *Session) SendPing(data string) error {
+	if err := s.writeControl(websocket.PingMessage, []byte(data)); err != nil {
+		return err
+	}
+	s.emit(Frame{Direction: DirectionSent, Opcode: websocket.PingMessage, Data: data})
+	return nil
+}
+
+// Close starts the closing handshake and waits a moment for the server to
+// answer before dropping the connection.
+func (s *Session) Close(code int, reason string) error {
+	err := s.writeControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason))
+	if err == nil {
+		s.emit(Frame{Direction: DirectionSent, Opcode: websocket.CloseMessage, Data: reason, CloseCode: code})
+	}
+
+	select {
+	case <-s.closed:
+	case <-time.After(time.Second):
+		s.conn.Close()
+		<-s.closed
+	}
+	return err
+}
+
+// Done is closed once the connection is gone, whoever closed it.
+func (s *Session) Done() <-chan struct{} {
+	return s.closed
+}
+
+func OpcodeName(opcode int) string {
+	switch opcode {
+	case websocket.TextMessage:
+		return "TEXT"
+	case websocket.BinaryMessage:
+		return "BINARY"
+	case websocket.CloseMessage:
+		return "CLOSE"
+	case websocket.PingMessage:
+		return "PING"
+	case websocket.PongMessage:
+		return "PONG"
+	}
+	return ""
+}
+
+// CloseCodeName returns the RFC 6455 name of a close code, if it has one.
+func CloseCodeName(code int) string {
+	names := map[int]string{
+		websocket.CloseNormalClosure:           "Normal Closure",
+		websocket.CloseGoingAway:               "Going Away",
+		websocket.CloseProtocolError:           "Protocol Error",
+		websocket.CloseUnsupportedData:         "Unsupported Data",
+		websocket.CloseNoStatusReceived:        "No Status Received",
+		websocket.CloseAbnormalClosure:         "Abnormal Closure",
+		websocket.CloseInvalidFramePayloadData: "Invalid Payload Data",
+		websocket.ClosePolicyViolation:         "Policy Violation",
+		websocket.CloseMessageTooBig:           "Message Too Big",
+		websocket.CloseMandatoryExtension:      "Mandatory Extension",
+		websocket.CloseInternalServerErr:       "Internal Error",
+		websocket.CloseServiceRestart:          "Service Restart",
+		websocket.CloseTryAgainLater:           "Try Again Later",
+		websocket.CloseTLSHandshake:            "TLS Handshake",
+	}
+	return names[code]
+}
+
+// FormatFrame renders a frame as a single log line, the same format is used
+// by the console and by exported session logs.
+func FormatFrame(frame Frame) string {
+	timestamp := frame.Time.Format("15:04:05.000")
+
+	if frame.Direction == DirectionInfo {
+		return timestamp + " --       " + frame.Data
+	}
+
+	arrow := "<-"
+	if frame.Direction == DirectionSent {
+		arrow = "->"
+	}
+
+	line := fmt.Sprintf("%s %s %-6s", timestamp, arrow, OpcodeName(frame.Opcode))
+	if frame.Opcode == websocket.CloseMessage {
+		line += fmt.Sprintf(" %d", frame.CloseCode)
+		if name := CloseCodeName(frame.CloseCode); name != "" {
+			line += " (" + name + ")"
+		}
+	}
+	if frame.Data != "" {
+		line += " " + strings.ReplaceAll(frame.Data, "\n", "\\n")
+	}
+	return line
+}
+
+func ExportLog(path string, url string, frames []Frame) error {
+	var b strings.Builder
+	b.WriteString("# WebSocket session with " + url + ", exported at " + now().Format(time.RFC3339) + "\n")
+	for _, frame := range frames {
+		b.WriteString(FormatFrame(frame) + "\n")
+	}
+	return writeFile(path, []byte(b.String()), 0644)
+}
+
+// ReadScript returns the messages of a script file, one per line. Blank lines
+// and lines starting with '#' are skipped.
+func ReadScript(path string) ([]string, error) {
+	data, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []string
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		messages = append(messages, line)
+	}
+	return messages, scanner.Err()
+}
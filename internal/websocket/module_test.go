@@ -0,0 +1,216 @@
+package websocket_module
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	request_module "github.com/diogopereiradev/httpzen/internal/request"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+type frameRecorder struct {
+	mu     sync.Mutex
+	frames []Frame
+}
+
+func (r *frameRecorder) add(frame Frame) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.frames = append(r.frames, frame)
+}
+
+func (r *frameRecorder) has(direction Direction, opcode int, data string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, f := range r.frames {
+		if f.Direction == direction && f.Opcode == opcode && f.Data == data {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *frameRecorder) waitFor(t *testing.T, direction Direction, opcode int, data string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if r.has(direction, opcode, data) {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("frame %s %s %q not received, got %+v", direction, OpcodeName(opcode), data, r.frames)
+}
+
+func newEchoServer(t *testing.T, onConnect func(conn *websocket.Conn, r *http.Request)) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		if onConnect != nil {
+			onConnect(conn, r)
+		}
+		for {
+			opcode, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			conn.WriteMessage(opcode, data)
+		}
+	}))
+}
+
+func TestConnect_EchoAndHeaders(t *testing.T) {
+	var gotHeader string
+	server := newEchoServer(t, func(conn *websocket.Conn, r *http.Request) {
+		gotHeader = r.Header.Get("X-Token")
+	})
+	defer server.Close()
+
+	recorder := &frameRecorder{}
+	session, err := Connect(ConnectOptions{
+		Url:     server.URL,
+		Headers: http.Header{"X-Token": {"secret"}},
+		Timeout: time.Second,
+	}, recorder.add)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(session.Url, "ws://"))
+
+	assert.NoError(t, session.SendText(`{"hello":"world"}`))
+	recorder.waitFor(t, DirectionSent, websocket.TextMessage, `{"hello":"world"}`)
+	recorder.waitFor(t, DirectionReceived, websocket.TextMessage, `{"hello":"world"}`)
+	assert.Equal(t, "secret", gotHeader)
+
+	assert.NoError(t, session.SendPing("p1"))
+	recorder.waitFor(t, DirectionReceived, websocket.PongMessage, "p1")
+
+	assert.NoError(t, session.Close(websocket.CloseNormalClosure, "bye"))
+	recorder.waitFor(t, DirectionReceived, websocket.CloseMessage, "")
+	<-session.Done()
+}
+
+func TestConnect_AnswersServerPing(t *testing.T) {
+	server := newEchoServer(t, func(conn *websocket.Conn, r *http.Request) {
+		conn.WriteControl(websocket.PingMessage, []byte("are you there"), time.Now().Add(time.Second))
+	})
+	defer server.Close()
+
+	recorder := &frameRecorder{}
+	session, err := Connect(ConnectOptions{Url: server.URL, Timeout: time.Second}, recorder.add)
+	assert.NoError(t, err)
+	defer session.Close(websocket.CloseNormalClosure, "")
+
+	recorder.waitFor(t, DirectionReceived, websocket.PingMessage, "are you there")
+	recorder.waitFor(t, DirectionSent, websocket.PongMessage, "are you there")
+}
+
+func TestConnect_ServerClose(t *testing.T) {
+	server := newEchoServer(t, func(conn *websocket.Conn, r *http.Request) {
+		conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "not allowed"), time.Now().Add(time.Second))
+	})
+	defer server.Close()
+
+	recorder := &frameRecorder{}
+	session, err := Connect(ConnectOptions{Url: server.URL, Timeout: time.Second}, recorder.add)
+	assert.NoError(t, err)
+
+	select {
+	case <-session.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the session to end")
+	}
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	found := false
+	for _, f := range recorder.frames {
+		if f.Opcode == websocket.CloseMessage && f.Direction == DirectionReceived {
+			found = true
+			assert.Equal(t, websocket.ClosePolicyViolation, f.CloseCode)
+			assert.Equal(t, "not allowed", f.Data)
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestConnect_Errors(t *testing.T) {
+	_, err := Connect(ConnectOptions{Url: "ftp://nope"}, nil)
+	var requestErr *request_module.RequestError
+	assert.True(t, errors.As(err, &requestErr))
+	assert.Equal(t, request_module.ErrorClassInvalidUrl, requestErr.Class)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	_, err = Connect(ConnectOptions{Url: server.URL, Timeout: time.Second}, nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "403")
+}
+
+func TestConnect_HandshakeTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	_, err := Connect(ConnectOptions{Url: server.URL, Timeout: 50 * time.Millisecond}, nil)
+	assert.Equal(t, request_module.ErrorClassTimeout, request_module.ClassifyError(err).Class)
+}
+
+func TestFormatFrame(t *testing.T) {
+	at := time.Date(2024, 1, 1, 10, 20, 30, 400000000, time.UTC)
+
+	assert.Equal(t, "10:20:30.400 -> TEXT   hi\\nthere", FormatFrame(Frame{Direction: DirectionSent, Opcode: websocket.TextMessage, Data: "hi\nthere", Time: at}))
+	assert.Equal(t, "10:20:30.400 <- PING  ", FormatFrame(Frame{Direction: DirectionReceived, Opcode: websocket.PingMessage, Time: at}))
+	assert.Equal(t, "10:20:30.400 <- CLOSE  1001 (Going Away) restart", FormatFrame(Frame{Direction: DirectionReceived, Opcode: websocket.CloseMessage, CloseCode: 1001, Data: "restart", Time: at}))
+	assert.Equal(t, "10:20:30.400 --       Connected", FormatFrame(Frame{Direction: DirectionInfo, Data: "Connected", Time: at}))
+}
+
+func TestExportLog(t *testing.T) {
+	var written string
+	var writtenPath string
+	writeFile = func(name string, data []byte, perm os.FileMode) error {
+		writtenPath = name
+		written = string(data)
+		return nil
+	}
+	defer func() { writeFile = os.WriteFile }()
+
+	at := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	err := ExportLog("session.log", "ws://localhost", []Frame{
+		{Direction: DirectionSent, Opcode: websocket.TextMessage, Data: "a", Time: at},
+		{Direction: DirectionReceived, Opcode: websocket.TextMessage, Data: "b", Time: at},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "session.log", writtenPath)
+	assert.Contains(t, written, "# WebSocket session with ws://localhost")
+	assert.Contains(t, written, "10:00:00.000 -> TEXT   a\n")
+	assert.Contains(t, written, "10:00:00.000 <- TEXT   b\n")
+}
+
+func TestReadScript(t *testing.T) {
+	readFile = func(name string) ([]byte, error) {
+		return []byte("# greeting\n{\"type\":\"hello\"}\r\n\n  \nsubscribe news\n"), nil
+	}
+	defer func() { readFile = os.ReadFile }()
+
+	messages, err := ReadScript("script.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`{"type":"hello"}`, "subscribe news"}, messages)
+
+	readFile = func(name string) ([]byte, error) { return nil, os.ErrNotExist }
+	_, err = ReadScript("missing.txt")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}